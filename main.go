@@ -1,20 +1,46 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
+	"unicode"
 
 	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/browse"
+	"github.com/jjuliano/runner/pkg/graphql"
+	"github.com/jjuliano/runner/pkg/progress"
+	"github.com/jjuliano/runner/pkg/report"
 	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/jjuliano/runner/pkg/rpc"
 	"github.com/jjuliano/runner/pkg/runnerexec"
+	"github.com/jjuliano/runner/pkg/server"
+	"github.com/jjuliano/runner/pkg/shell"
+	"github.com/jjuliano/runner/pkg/sqlitestore"
+	"github.com/jjuliano/runner/pkg/tracing"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
@@ -25,12 +51,10 @@ var (
 func initConfig(logger *log.Logger) {
 	logger.Debug("Initializing configuration...")
 
-	viper.SetConfigName("runner")
-	viper.AddConfigPath(".")
-	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("Workflow file 'runner.yml' not found in the current directory.")
+	// A missing runner.yml is left to the commands to report, so that
+	// init can create it.
+	if err := readConfig(userConfigFile(), "."); err != nil {
+		fmt.Printf("Error reading the configuration: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -39,6 +63,84 @@ func initConfig(logger *log.Logger) {
 	}
 }
 
+// configListKeys are the configuration keys holding lists, whose
+// environment variables separate the items with commas or spaces.
+var configListKeys = []string{"workflows", "overlays", "targets", "licenses.deny", "pluginPath"}
+
+// configFlags maps the flags defaulting to the configuration to their
+// configuration key.
+var configFlags = map[string]string{
+	"format":  "format",
+	"workers": "workers",
+	"addr":    "serve.addr",
+}
+
+// userConfigFile returns the configuration file of the user,
+// $XDG_CONFIG_HOME/runner/config.yaml, by default under ~/.config, or ""
+// when the home directory is unknown.
+func userConfigFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "runner", "config.yaml")
+}
+
+// readConfig reads the configuration, from the highest precedence to the
+// lowest: the RUNNER_* environment variables, runner.yml in projectDir, and
+// the configuration file of the user at userFile. Missing files are
+// skipped.
+func readConfig(userFile, projectDir string) error {
+	viper.SetEnvPrefix("runner")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	if userFile != "" {
+		viper.SetConfigFile(userFile)
+		if err := viper.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error reading %s: %w", userFile, err)
+		}
+		viper.SetConfigFile("")
+	}
+
+	viper.SetConfigName("runner")
+	viper.AddConfigPath(projectDir)
+	if err := viper.MergeInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("error reading the workflow file: %w", err)
+		}
+	}
+
+	for _, key := range configListKeys {
+		env := "RUNNER_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if value, ok := os.LookupEnv(env); ok {
+			viper.Set(key, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }))
+		}
+	}
+	return nil
+}
+
+// applyConfig sets the flags of configFlags that the command line leaves
+// unset to their configured value, if any.
+func applyConfig(c *cobra.Command) error {
+	var err error
+	c.Flags().VisitAll(func(flag *pflag.Flag) {
+		key, ok := configFlags[flag.Name]
+		if !ok || flag.Changed || !viper.IsSet(key) || err != nil {
+			return
+		}
+		if setErr := c.Flags().Set(flag.Name, viper.GetString(key)); setErr != nil {
+			err = fmt.Errorf("invalid %s in the configuration: %w", key, setErr)
+		}
+	})
+	return err
+}
+
 func setRunnerParams(params string) {
 	for i, param := range strings.Split(params, ";") {
 		envVar := fmt.Sprintf("RUNNER_PARAMS%d", i+1)
@@ -90,39 +192,846 @@ func writeEnvToFile(envFilePath string) error {
 }
 
 func createRootCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var lockfile string
+	var overlays []string
+	var sources []string
+	var store string
+	var logLevel string
+	var limit, page int
+	// loadCatalog loads the lockfile, or the workflows, sources and store
+	// with the overlays applied, for the command and its arguments.
+	loadCatalog := func(command string, args []string) error {
+		if lockfile != "" {
+			return dr.LoadLockfile(lockfile)
+		}
+		if err := loadResourceFiles(dr); err != nil {
+			return err
+		}
+		for _, source := range sources {
+			if err := dr.LoadSource(source); err != nil {
+				return err
+			}
+		}
+		if store != "" {
+			if err := useStore(dr, store); err != nil {
+				return err
+			}
+			load := dr.LoadStore
+			if dependents, ok := storeLookupCommands[command]; ok && len(args) > 0 {
+				load = func() error { return dr.LoadStored(args, dependents) }
+			}
+			if err := load(); err != nil {
+				return err
+			}
+		}
+		if len(viper.GetStringSlice("workflows")) == 0 && len(sources) == 0 && store == "" {
+			return fmt.Errorf("no workflows defined in the configuration file, and no --source or --store given, run 'runner init' to create a starter catalog")
+		}
+		for _, file := range append(viper.GetStringSlice("overlays"), overlays...) {
+			if err := dr.LoadOverlays(file); err != nil {
+				return err
+			}
+		}
+		dr.BuildDependencies()
+		return nil
+	}
+
 	rootCmd := &cobra.Command{
 		Use:   "runner",
 		Short: "a graph-based orchestrator",
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			if err := applyConfig(c); err != nil {
+				return err
+			}
+			// Cobra only checks the required flags after this hook,
+			// once the catalog is loaded, and not as usage errors.
+			if err := c.ValidateRequiredFlags(); err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			if dr.Quiet && !c.Flags().Changed("log-level") {
+				logLevel = "error"
+			}
+			if err := resolver.SetLogLevel(logLevel); err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			if dr.Porcelain != "" {
+				if err := resolver.ValidatePorcelain(dr.Porcelain); err != nil {
+					return &resolver.UsageError{Err: err}
+				}
+				if slices.Contains(porcelainUnsupported, c.Name()) || c.HasParent() && c.Parent().Name() == "completion" {
+					return &resolver.UsageError{Err: fmt.Errorf("%s does not support --porcelain", c.CommandPath())}
+				}
+			}
+			// init creates the catalog the other commands load, and the
+			// completion scripts don't depend on it. Completing loads the
+			// catalog when needed, see completeResources.
+			if c.Name() == "init" || isCompletionCmd(c) {
+				return nil
+			}
+			// Only run and status keep the records by default, so that the
+			// other commands, such as serve, don't write them.
+			if !c.Flags().Changed("records-file") && (c.Name() == "run" || c.Name() == "status") {
+				dr.RecordsFile = projectStateFile(dr, "records.yaml")
+			}
+			if err := resolver.ValidateFormat(dr.OutputFormat); err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			if err := resolver.ValidateFailurePolicy(dr.FailurePolicy); err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			if !c.Flags().Changed("load-mode") {
+				dr.LoadMode = resolver.LoadLenient
+				if slices.Contains(strictCommands, c.Name()) {
+					dr.LoadMode = resolver.LoadStrict
+				}
+			}
+			if err := resolver.ValidateLoadMode(dr.LoadMode); err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			pagination, err := resolver.NewPage(page, limit)
+			if err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			dr.Page = pagination
+			if path, ok := strings.CutPrefix(dr.Template, "@"); ok {
+				text, err := afero.ReadFile(dr.Fs, path)
+				if err != nil {
+					return fmt.Errorf("error reading template file %s: %w", path, err)
+				}
+				dr.Template = string(text)
+			}
+			if err := resolver.ValidateTemplate(dr.Template); err != nil {
+				return &resolver.UsageError{Err: err}
+			}
+			// The commands of the shell query the catalog it loaded.
+			if c.Context().Value(shellQuery{}) != nil {
+				return nil
+			}
+			// validate loads leniently to report every load issue as a
+			// finding, with the issues of strict loading as errors.
+			if c.Name() == "validate" {
+				loadMode := dr.LoadMode
+				dr.LoadMode = resolver.LoadLenient
+				defer func() { dr.LoadMode = loadMode }()
+			}
+			if err := loadCatalog(c.Name(), args); err != nil || lockfile != "" {
+				return err
+			}
+			if err := dr.CheckRequirements(); err != nil {
+				return err
+			}
+			if c.Name() == "validate" {
+				return nil
+			}
+			for _, warning := range dr.LoadWarnings() {
+				dr.Logger.Warn(warning.String())
+			}
+			return nil
+		},
 	}
 	rootCmd.PersistentFlags().StringVar(&params, "params", "", "extra parameters, semi-colon separated")
+	rootCmd.PersistentFlags().BoolVar(&dr.IncludeOptional, "include-optional", false, "include optional dependencies even when missing from the catalog")
+	rootCmd.PersistentFlags().StringToStringVar(&dr.ProviderPreferences, "prefer", dr.ProviderPreferences, "preferred providers for virtual resources, as virtual=resource pairs")
+	rootCmd.PersistentFlags().BoolVar(&dr.WarnOnConflict, "warn-conflicts", false, "warn instead of failing when conflicting resources are resolved together")
+	rootCmd.PersistentFlags().StringVar(&dr.OutputFormat, "format", resolver.FormatPlain, "output format of listings: "+strings.Join(resolver.Formats, ", "))
+	rootCmd.PersistentFlags().StringToStringVar(&dr.MetadataFilter, "meta", nil, "only list the resources with the given metadata, as key=value pairs")
+	rootCmd.PersistentFlags().IntVar(&limit, "limit", 0, "maximum number of resources listed by index, query, search and the other listings, 0 for no limit")
+	rootCmd.PersistentFlags().IntVar(&page, "page", 1, "page of --limit resources to list, starting at 1")
+	rootCmd.PersistentFlags().StringVar(&dr.Porcelain, "porcelain", "", "write tab-separated records with stable fields for scripts instead of --format and --template, in the given version: "+strings.Join(resolver.PorcelainVersions, ", "))
+	rootCmd.PersistentFlags().Lookup("porcelain").NoOptDefVal = resolver.PorcelainV1
+	rootCmd.PersistentFlags().StringVar(&dr.Template, "template", "", "text/template rendering listings instead of --format, or @file to read it from a file")
+	rootCmd.PersistentFlags().IntVar(&dr.MaxDepth, "depth", 0, "maximum depth of traversals, 0 for no limit")
+	rootCmd.PersistentFlags().IntVar(&dr.MaxTraversalDepth, "max-traversal-depth", 0, "fail instead of traversing closures more than this many requirements deep, 0 for no limit")
+	rootCmd.PersistentFlags().IntVar(&dr.MaxClosureSize, "max-closure-size", 0, "fail instead of traversing closures of more than this many resources, 0 for no limit")
+	rootCmd.PersistentFlags().IntVar(&dr.MaxPaths, "max-paths", 0, "fail instead of listing more than this many paths with tree, why and --compact, 0 for no limit")
+	rootCmd.PersistentFlags().BoolVar(&dr.CompactPaths, "compact", false, "print one 'resource -> requirement' line per resource reached by depends, or 'resource -> dependent' by rdepends, instead of a tree")
+	rootCmd.PersistentFlags().IntVar(&dr.Retry.Attempts, "attempts", 0, "default maximum number of executions of a failing resource, including the first one")
+	rootCmd.PersistentFlags().DurationVar(&dr.Retry.Backoff, "retry-backoff", time.Second, "default delay before the first retry, doubled on every attempt")
+	rootCmd.PersistentFlags().DurationVar(&dr.Timeout, "timeout", 0, "default timeout of every resource, 0 for no timeout")
+	rootCmd.PersistentFlags().StringVar((*string)(&dr.FailurePolicy), "on-failure", string(resolver.FailFast), "what to do when a resource fails: "+strings.Join(resolver.FailurePolicies, ", "))
+	rootCmd.PersistentFlags().StringSliceVar(&sources, "source", nil, "additional catalog sources: files, directories, globs, URLs or git+<url>[//<dir>][#<ref>] repositories")
+	rootCmd.PersistentFlags().StringVar(&store, "store", "", "resource store to load the catalog from: a directory of manifests, a SQLite database (.db) or the URL of a runner serve API")
+	rootCmd.PersistentFlags().StringSliceVar(&overlays, "overlay", nil, "overlay files patching the loaded resources, applied after the configured ones")
+	rootCmd.PersistentFlags().StringVar(&lockfile, "lockfile", "", "resolve strictly from the given lockfile instead of the workflows")
+	rootCmd.PersistentFlags().StringVar((*string)(&dr.LoadMode), "load-mode", "", "how to handle unknown fields, duplicate resources and missing requirements: "+strings.Join(resolver.LoadModes, ", ")+"; strict for the commands running, locking, serving or changing resources and for validate, lenient for the others by default")
+	rootCmd.PersistentFlags().IntVar(&dr.Workers, "workers", 0, "goroutines checking the catalog in validate and redundant, 0 for one per CPU")
+	rootCmd.PersistentFlags().BoolVar(&dr.StrictEnv, "strict-env", false, "fail when a manifest refers to an undefined environment variable without default")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "minimum level of the logged messages: "+strings.Join(resolver.LogLevels, ", "))
+	rootCmd.PersistentFlags().StringVar(&dr.RecordsFile, "records-file", "", "file recording the outcome of every resource run, by default under the cache directory for run and status")
+	rootCmd.PersistentFlags().BoolVarP(&dr.Quiet, "quiet", "q", false, "only log errors, and don't print the output of traversals and run steps")
 
+	// Invalid command lines exit with ExitUsage.
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return &resolver.UsageError{Err: err}
+	})
 	addCommands(rootCmd, dr)
+	rootCmd.AddCommand(createServeCmd(dr))
+	rootCmd.AddCommand(createWhyCmd(dr))
+	rootCmd.AddCommand(createLicensesCmd(dr))
+	rootCmd.AddCommand(createRunCmd(dr))
+	rootCmd.AddCommand(createValidateCmd(dr))
+	rootCmd.AddCommand(createReportCmd(dr))
+	rootCmd.AddCommand(createInitCmd(dr))
+	rootCmd.AddCommand(createAddCmd(dr))
+	rootCmd.AddCommand(createEditCmd(dr))
+	rootCmd.AddCommand(createRemoveCmd(dr))
+	rootCmd.AddCommand(createShellCmd(dr))
+
+	// Completion runs none of the hooks of the command completed, so the
+	// catalog is loaded leniently on the first completion of a resource ID.
+	completeResources := func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(dr.ListResources()) == 0 {
+			dr.LoadMode = resolver.LoadLenient
+			if err := loadCatalog("", nil); err != nil {
+				cobra.CompDebugln(err.Error(), false)
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		return resourceCompletions(dr, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	for _, c := range rootCmd.Commands() {
+		if slices.Contains(resourceCommands, c.Name()) {
+			c.ValidArgsFunction = func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				// No completion past the arguments the command accepts.
+				if c.Args != nil && c.Args(c, append(args, toComplete)) != nil {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+				return completeResources(c, args, toComplete)
+			}
+		}
+		// Invalid arguments exit with ExitUsage too.
+		if args := c.Args; args != nil {
+			c.Args = func(c *cobra.Command, a []string) error {
+				if err := args(c, a); err != nil {
+					return &resolver.UsageError{Err: err}
+				}
+				return nil
+			}
+		}
+		for _, flag := range resourceFlags[c.Name()] {
+			c.RegisterFlagCompletionFunc(flag, func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				// List flags take comma-separated IDs: complete the last one.
+				done := toComplete[:strings.LastIndex(toComplete, ",")+1]
+				completions, directive := completeResources(c, strings.Split(strings.TrimSuffix(done, ","), ","), toComplete[len(done):])
+				for i, completion := range completions {
+					completions[i] = done + completion
+				}
+				return completions, directive
+			})
+		}
+	}
 
 	return rootCmd
 }
 
+// strictCommands are the commands loading the catalog strictly unless
+// --load-mode is given: the ones running, locking, serving or changing
+// resources, and validate. The others only read the catalog, and load it
+// leniently so that a catalog with issues can still be explored.
+var strictCommands = []string{"add", "edit", "lock", "remove", "run", "serve", "validate"}
+
+// porcelainUnsupported are the commands without porcelain records, writing
+// files of their own formats, such as lockfiles or exports, or an
+// interactive view.
+var porcelainUnsupported = []string{"browse", "completion", "export", "lock"}
+
+// resourceCommands are the commands taking resource IDs as arguments, which
+// complete them from the catalog.
+var resourceCommands = []string{
+	"depends", "deprecations", "edit", "impact", "levels", "licenses", "lock", "orphans", "owners",
+	"rdepends", "remove", "run", "show", "status", "tree", "tree-list", "why",
+}
+
+// storeLookupCommands are the commands only querying the resources given as
+// arguments, which load them from the --store one at a time, with their
+// requirements and, when set, the resources requiring them, instead of
+// listing it.
+var storeLookupCommands = map[string]bool{
+	"depends": false, "show": false, "tree": false, "tree-list": false,
+	"impact": true, "rdepends": true,
+}
+
+// resourceFlags are the flags taking resource IDs, by command.
+var resourceFlags = map[string][]string{
+	"add":  {"require"},
+	"edit": {"require", "remove-require"},
+	"why":  {"target"},
+}
+
+// resourceCompletions returns the IDs of the resources starting with
+// toComplete, but the ones in args, described by their name or description.
+func resourceCompletions(dr *resolver.DependencyResolver, args []string, toComplete string) []string {
+	given := dr.IDs.IDs(args)
+	var completions []string
+	for _, entry := range dr.CompleteIDs(toComplete) {
+		if slices.Contains(given, entry.Id) {
+			continue
+		}
+		description := entry.Name
+		if description == "" {
+			description = entry.Desc
+		}
+		if description == "" {
+			completions = append(completions, entry.Id)
+		} else {
+			completions = append(completions, entry.Id+"\t"+description)
+		}
+	}
+	return completions
+}
+
+// isCompletionCmd reports whether the command generates a completion
+// script, or completes a command line.
+func isCompletionCmd(c *cobra.Command) bool {
+	switch c.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "completion":
+		return true
+	}
+	return c.HasParent() && c.Parent().Name() == "completion"
+}
+
+func createServeCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var addr, grpcAddr, grpcCert, grpcKey, grpcClientCA, pprofAddr string
+	var watch, graphQL, grpcExecute bool
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the resources and dependency graph over HTTP",
+		RunE: func(c *cobra.Command, args []string) error {
+			if watch {
+				ctx, cancel := context.WithCancel(c.Context())
+				defer cancel()
+				go func() {
+					if err := dr.Watch(ctx); err != nil && ctx.Err() == nil {
+						dr.Logger.Error("Stopped watching the catalog", "err", err)
+					}
+				}()
+			}
+			errs := make(chan error, 3)
+			if grpcAddr != "" {
+				srv, err := newGRPCServer(dr, grpcCert, grpcKey, grpcClientCA, grpcExecute)
+				if err != nil {
+					return err
+				}
+				lis, err := net.Listen("tcp", grpcAddr)
+				if err != nil {
+					return fmt.Errorf("error serving gRPC: %w", err)
+				}
+				defer srv.Stop()
+				dr.Logger.Info("Serving gRPC", "addr", grpcAddr, "tls", grpcCert != "", "mtls", grpcClientCA != "", "execute", grpcExecute)
+				go func() {
+					if err := srv.Serve(lis); err != nil {
+						errs <- fmt.Errorf("error serving gRPC: %w", err)
+					}
+				}()
+			}
+			if pprofAddr != "" {
+				lis, err := net.Listen("tcp", pprofAddr)
+				if err != nil {
+					return fmt.Errorf("error serving profiles: %w", err)
+				}
+				defer lis.Close()
+				dr.Logger.Info("Serving profiles", "addr", pprofAddr)
+				go func() {
+					if err := server.ServeListener(lis, server.NewDebugHandler()); err != nil {
+						errs <- fmt.Errorf("error serving profiles: %w", err)
+					}
+				}()
+			}
+			go func() {
+				if graphQL {
+					mux := http.NewServeMux()
+					mux.Handle("/", server.NewHandler(dr))
+					mux.Handle("/graphql", graphql.NewHandler(dr))
+					errs <- server.Serve(addr, mux)
+					return
+				}
+				errs <- server.ListenAndServe(addr, dr)
+			}()
+			return <-errs
+		},
+	}
+	serveCmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().StringVar(&grpcAddr, "grpc-addr", "", "address to serve the gRPC API on, none when empty")
+	serveCmd.Flags().StringVar(&grpcCert, "grpc-tls-cert", "", "certificate file serving the gRPC API over TLS, with --grpc-tls-key")
+	serveCmd.Flags().StringVar(&grpcKey, "grpc-tls-key", "", "private key file of --grpc-tls-cert")
+	serveCmd.Flags().StringVar(&grpcClientCA, "grpc-client-ca", "", "CA certificates file the gRPC clients must present a certificate signed by, with --grpc-tls-cert")
+	serveCmd.Flags().BoolVar(&grpcExecute, "grpc-allow-execute", false, "let gRPC clients run resources with ExecutePlan, which requires --grpc-client-ca")
+	serveCmd.Flags().StringVar(&pprofAddr, "pprof-addr", "", "address to serve the net/http/pprof profiles on, none when empty")
+	serveCmd.Flags().BoolVar(&graphQL, "graphql", false, "also serve a GraphQL API on /graphql")
+	serveCmd.Flags().BoolVar(&watch, "watch", false, "reload the catalog when its files change")
+	return serveCmd
+}
+
+// newGRPCServer returns the gRPC server of the resolver, over TLS when a
+// certificate is given, and authenticating the clients when a client CA is
+// given. Running resources executes shell commands on behalf of the clients,
+// so it must be allowed, and is only allowed to authenticated clients.
+func newGRPCServer(dr *resolver.DependencyResolver, certFile, keyFile, clientCAFile string, execute bool) (*grpc.Server, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, &resolver.UsageError{Err: fmt.Errorf("--grpc-tls-cert and --grpc-tls-key must be given together")}
+	}
+	if clientCAFile != "" && certFile == "" {
+		return nil, &resolver.UsageError{Err: fmt.Errorf("--grpc-client-ca requires --grpc-tls-cert and --grpc-tls-key")}
+	}
+	if execute && clientCAFile == "" {
+		return nil, &resolver.UsageError{Err: fmt.Errorf("--grpc-allow-execute requires --grpc-client-ca, --grpc-tls-cert and --grpc-tls-key")}
+	}
+	var opts []grpc.ServerOption
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the gRPC TLS certificate: %w", err)
+		}
+		config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		if clientCAFile != "" {
+			data, err := os.ReadFile(clientCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading the gRPC client CA: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("error reading the gRPC client CA: no certificate found in %s", clientCAFile)
+			}
+			config.ClientAuth, config.ClientCAs = tls.RequireAndVerifyClientCert, pool
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+	return rpc.NewServer(dr, rpc.Options{AllowExecute: execute}, opts...), nil
+}
+
+func createWhyCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var target string
+	whyCmd := &cobra.Command{
+		Use:   "why [resource]",
+		Short: "Show every dependency path from the target to the given resource",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return dr.HandleWhyCommand(dr.IDs.ID(args[0]), dr.IDs.ID(target))
+		},
+	}
+	whyCmd.Flags().StringVar(&target, "target", "", "resource whose closure includes the given resource")
+	whyCmd.MarkFlagRequired("target")
+	return whyCmd
+}
+
+func createLicensesCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var deny []string
+	licensesCmd := &cobra.Command{
+		Use:   "licenses [resource]",
+		Short: "List the licenses in the closure of the given resource",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if !c.Flags().Changed("deny") {
+				deny = viper.GetStringSlice("licenses.deny")
+			}
+			return dr.HandleLicensesCommand(dr.IDs.ID(args[0]), deny)
+		},
+	}
+	licensesCmd.Flags().StringSliceVar(&deny, "deny", nil, "licenses failing the command when found in the closure, (none) for resources without a license")
+	return licensesCmd
+}
+
+func createRunCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var noProgress bool
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the commands for the given resources",
+		RunE: func(c *cobra.Command, args []string) error {
+			args = dr.IDs.IDs(args)
+			if err := dr.CheckTargets(args); err != nil {
+				return err
+			}
+			if !c.Flags().Changed("checkpoint-file") {
+				dr.CheckpointFile = projectStateFile(dr, "checkpoint.yaml")
+			}
+			// Keep the standard output to the records of the results.
+			if dr.Porcelain != "" && dr.StepOutput == nil {
+				dr.StepOutput = os.Stderr
+			}
+			if noProgress {
+				return dr.HandleRunCommand(args)
+			}
+			var results []resolver.ResourceResult
+			err := progress.Run(c.Context(), dr, func(ctx context.Context) error {
+				var err error
+				results, err = dr.Execute(ctx, args)
+				return err
+			})
+			return dr.ReportRun(results, err)
+		},
+	}
+	runCmd.Flags().BoolVar(&noProgress, "no-progress", false, "log the progress of the run line by line instead of showing a progress view")
+	runCmd.Flags().StringVar(&dr.CheckpointFile, "checkpoint-file", "", "file the state of the run is saved to until it succeeds, by default under the cache directory")
+	runCmd.Flags().BoolVar(&dr.RollbackOnFailure, "rollback", false, "roll back the completed resources in reverse order when a resource fails")
+	runCmd.Flags().BoolVar(&dr.Force, "force", false, "execute every resource, even the ones unchanged since their last successful run")
+	runCmd.Flags().BoolVar(&dr.Resume, "resume", false, "resume the run saved in the checkpoint file, skipping the completed resources")
+	return runCmd
+}
+
+// projectStateFile returns the path of the named file keeping the state of
+// the runs started from the working directory, under the cache directory so
+// that runs leave the working directory untouched, or "" without a cache
+// directory.
+func projectStateFile(dr *resolver.DependencyResolver, name string) string {
+	wd, err := os.Getwd()
+	if err != nil || dr.CacheDir == "" {
+		return ""
+	}
+	key := sha256.Sum256([]byte(wd))
+	return filepath.Join(dr.CacheDir, "projects", hex.EncodeToString(key[:]), name)
+}
+
+func createValidateCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var warnings, strict bool
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Run every check of the catalog, failing on errors",
+		RunE: func(c *cobra.Command, args []string) error {
+			return dr.HandleValidateCommand(warnings, strict)
+		},
+	}
+	validateCmd.Flags().BoolVar(&warnings, "warnings", false, "list the non-fatal issues of the catalog too")
+	validateCmd.Flags().BoolVar(&strict, "strict", false, "list the warnings and fail on them like on errors")
+	return validateCmd
+}
+
+func createInitCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var opts resolver.InitOptions
+	initCmd := &cobra.Command{
+		Use:   "init [directory]",
+		Short: "Create a starter catalog with an example resource",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			paths, err := resolver.InitCatalog(dr.Fs, dir, opts)
+			if errors.Is(err, os.ErrExist) {
+				return fmt.Errorf("%w, use --force to overwrite it", err)
+			} else if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				dr.PrintStatus([]string{"wrote", path}, "📄 Wrote %s\n", path)
+			}
+			if dr.Porcelain == "" {
+				resolver.PrintMessage("Try it with 'runner run hello'\n")
+			}
+			return nil
+		},
+	}
+	initCmd.Flags().StringVar(&opts.ResourceDir, "resource-dir", "", "write one manifest per resource under this directory instead of a single resources.yaml")
+	initCmd.Flags().BoolVar(&opts.Force, "force", false, "overwrite the existing files")
+	return initCmd
+}
+
+func createAddCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var file, exec string
+	var entry resolver.ResourceNodeEntry
+	addCmd := &cobra.Command{
+		Use:   "add [resource]",
+		Short: "Add a resource to a manifest",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if file == "" {
+				manifest, err := defaultManifest(dr)
+				if err != nil {
+					return err
+				}
+				file = manifest
+			}
+			entry.Id = args[0]
+			if exec != "" {
+				entry.Run = []resolver.RunStep{{Name: "run", Exec: exec}}
+			}
+			path, err := dr.AddResourceToManifest(file, entry)
+			if err != nil {
+				return err
+			}
+			dr.PrintStatus([]string{"added", dr.IDs.ID(entry.Id), path}, "✅ Added resource '%s' to %s\n", dr.IDs.ID(entry.Id), path)
+			return nil
+		},
+	}
+	addCmd.Flags().StringVar(&file, "file", "", "manifest to add the resource to, or directory to write its own manifest in, the first local workflow by default")
+	addCmd.Flags().StringVar(&entry.Name, "name", "", "name of the resource")
+	addCmd.Flags().StringVar(&entry.Desc, "desc", "", "description of the resource")
+	addCmd.Flags().StringVar(&entry.Category, "category", "", "category of the resource")
+	addCmd.Flags().StringSliceVar(&entry.Requires, "require", nil, "resources the resource requires")
+	addCmd.Flags().StringVar(&exec, "exec", "", "command run by the resource")
+	return addCmd
+}
+
+// defaultManifest returns the first configured workflow that is a local
+// file or directory, where add writes the resources without --file.
+func defaultManifest(dr *resolver.DependencyResolver) (string, error) {
+	for _, workflow := range viper.GetStringSlice("workflows") {
+		if _, err := dr.Fs.Stat(workflow); err == nil {
+			return workflow, nil
+		}
+	}
+	return "", fmt.Errorf("no local workflow to add the resource to, use --file")
+}
+
+func createEditCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var require, unrequire []string
+	editCmd := &cobra.Command{
+		Use:   "edit [resource]",
+		Short: "Edit the requirements of a resource in its manifest",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(require) == 0 && len(unrequire) == 0 {
+				return &resolver.UsageError{Err: fmt.Errorf("nothing to edit, use --require or --remove-require")}
+			}
+			if len(require) > 0 {
+				if err := dr.AddRequirementsInManifest(args[0], require); err != nil {
+					return err
+				}
+			}
+			if len(unrequire) > 0 {
+				if err := dr.RemoveRequirementsInManifest(args[0], unrequire); err != nil {
+					return err
+				}
+			}
+			dr.PrintStatus([]string{"edited", dr.IDs.ID(args[0])}, "✅ Edited resource '%s'\n", dr.IDs.ID(args[0]))
+			return nil
+		},
+	}
+	editCmd.Flags().StringSliceVar(&require, "require", nil, "requirements to append to the resource")
+	editCmd.Flags().StringSliceVar(&unrequire, "remove-require", nil, "requirements to remove from the resource")
+	return editCmd
+}
+
+func createRemoveCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var force bool
+	removeCmd := &cobra.Command{
+		Use:   "remove [resource]",
+		Short: "Remove a resource from its manifest",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := dr.RemoveResourceFromManifest(args[0], force); err != nil {
+				return err
+			}
+			dr.PrintStatus([]string{"removed", dr.IDs.ID(args[0])}, "✅ Removed resource '%s'\n", dr.IDs.ID(args[0]))
+			return nil
+		},
+	}
+	removeCmd.Flags().BoolVar(&force, "force", false, "remove the resource even when other resources require it")
+	return removeCmd
+}
+
+// shellQuery is the context key marking the commands run by the shell, which
+// query the catalog it loaded instead of loading it again.
+type shellQuery struct{}
+
+func createShellCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "Query the catalog interactively, loading it once for every command",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			// Every command starts from the flags given to the shell.
+			var flags []string
+			c.Flags().Visit(func(f *pflag.Flag) { flags = append(flags, flagArg(f)) })
+			ctx := context.WithValue(c.Context(), shellQuery{}, true)
+			sh := &shell.Shell{
+				Prompt: "runner> ",
+				In:     c.InOrStdin(),
+				Out:    c.OutOrStdout(),
+				Exec: func(words []string) error {
+					query := createRootCmd(dr)
+					if found, _, err := query.Find(words); err == nil && found != query {
+						switch {
+						case found.Name() == c.Name():
+							return fmt.Errorf("already in the shell")
+						case slices.Contains(shellUnavailable, found.Name()):
+							return fmt.Errorf("'%s' is not available in the shell", found.Name())
+						}
+					}
+					query.SetArgs(append(slices.Clone(flags), shellArgs(query, words)...))
+					query.SilenceErrors, query.SilenceUsage = true, true
+					return query.ExecuteContext(ctx)
+				},
+				Complete: func(words []string, toComplete string) []string {
+					var out bytes.Buffer
+					query := createRootCmd(dr)
+					query.SetArgs(append(append([]string{cobra.ShellCompNoDescRequestCmd}, words...), toComplete))
+					query.SetOut(&out)
+					query.SetErr(io.Discard)
+					if err := query.ExecuteContext(ctx); err != nil {
+						return nil
+					}
+					// The completions end with the directive, such as ":4".
+					var completions []string
+					for _, line := range strings.Split(out.String(), "\n") {
+						if strings.HasPrefix(line, ":") {
+							break
+						}
+						if line != "" {
+							completions = append(completions, line)
+						}
+					}
+					return completions
+				},
+			}
+			if dr.Porcelain == "" {
+				resolver.PrintMessage("📦 Loaded %d resources, enter 'help' to list the commands and 'exit' to quit\n", len(dr.ListResources()))
+			}
+			return sh.Run()
+		},
+	}
+}
+
+// shellUnavailable are the commands refused by the shell: the ones running
+// resources, serving the catalog or changing the working directory, which
+// would block or outlive the session.
+var shellUnavailable = []string{"init", "run", "serve"}
+
+// shellArgs returns the arguments of the command entered in the shell as
+// words: the name=value words naming a flag of the command set that flag,
+// such as depth=3, and the field=value words of a query match the field to
+// the value, such as category=example or deprecated=true.
+func shellArgs(root *cobra.Command, words []string) []string {
+	c, _, err := root.Find(words)
+	if err != nil || c == root {
+		return words
+	}
+	args := []string{words[0]}
+	condition := false
+	for _, word := range words[1:] {
+		name, value, ok := strings.Cut(word, "=")
+		switch {
+		case !ok || strings.HasPrefix(word, "-") || strings.HasPrefix(value, "="):
+		case c.Flags().Lookup(name) != nil || c.InheritedFlags().Lookup(name) != nil:
+			args, condition = append(args, "--"+word), false
+			continue
+		case c.Name() == "query" && isQueryField(name):
+			if condition {
+				args = append(args, "&&")
+			}
+			if value != "true" && value != "false" {
+				value = strconv.Quote(value)
+			}
+			args, condition = append(args, name+" == "+value), true
+			continue
+		}
+		args, condition = append(args, word), false
+	}
+	return args
+}
+
+// isQueryField reports whether name can be a field of a query.
+func isQueryField(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// flagArg returns the argument setting the flag to its current value.
+func flagArg(f *pflag.Flag) string {
+	value := f.Value.String()
+	// Lists and maps print their items between brackets.
+	if _, ok := f.Value.(pflag.SliceValue); ok || f.Value.Type() == "stringToString" {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	}
+	return "--" + f.Name + "=" + value
+}
+
+func createReportCmd(dr *resolver.DependencyResolver) *cobra.Command {
+	var htmlDir, markdownFile string
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate a static report of the catalog",
+		RunE: func(c *cobra.Command, args []string) error {
+			if htmlDir == "" && markdownFile == "" {
+				return &resolver.UsageError{Err: fmt.Errorf("no report format given, use --html or --markdown")}
+			}
+			if htmlDir != "" {
+				if err := report.WriteHTML(dr, dr.Fs, htmlDir); err != nil {
+					return err
+				}
+				index := filepath.Join(htmlDir, "index.html")
+				dr.PrintStatus([]string{"wrote", index}, "📄 Wrote the HTML report to %s\n", index)
+			}
+			if markdownFile == "-" {
+				return report.WriteMarkdown(dr, os.Stdout)
+			}
+			if markdownFile != "" {
+				f, err := dr.Fs.Create(markdownFile)
+				if err != nil {
+					return fmt.Errorf("error creating %s: %w", markdownFile, err)
+				}
+				defer f.Close()
+				if err := report.WriteMarkdown(dr, f); err != nil {
+					return err
+				}
+				dr.PrintStatus([]string{"wrote", markdownFile}, "📄 Wrote the Markdown report to %s\n", markdownFile)
+			}
+			return nil
+		},
+	}
+	reportCmd.Flags().StringVar(&htmlDir, "html", "", "directory to write a self-contained HTML report to")
+	reportCmd.Flags().StringVar(&markdownFile, "markdown", "", "file to write a Markdown report to, - for the standard output")
+	return reportCmd
+}
+
 func addCommands(rootCmd *cobra.Command, dr *resolver.DependencyResolver) {
 	commands := []struct {
 		use       string
 		shortDesc string
 		handler   func(*resolver.DependencyResolver, []string) error
 	}{
-		{"depends", "List dependencies of the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleDependsCommand(args) }},
-		{"rdepends", "List reverse dependencies of the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleRDependsCommand(args) }},
-		{"show", "Show details of the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleShowCommand(args) }},
+		{"depends", "List dependencies of the given resources", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleDependsCommand(args) })},
+		{"orphans", "List resources unreachable from the given or configured targets", withTargets(func(dr *resolver.DependencyResolver, args []string) error {
+			if len(args) == 0 {
+				args = dr.IDs.IDs(viper.GetStringSlice("targets"))
+			}
+			return dr.HandleOrphansCommand(args)
+		})},
+		{"deprecations", "List the deprecated resources in the closure of the given resource", withTargets(func(dr *resolver.DependencyResolver, args []string) error {
+			if len(args) != 1 {
+				return &resolver.UsageError{Err: fmt.Errorf("expected a single resource, got %d", len(args))}
+			}
+			return dr.HandleDeprecationsCommand(args[0])
+		})},
+		{"owned", "List resources owned by any of the given owners", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleOwnedCommand(args) }},
+		{"owners", "List who owns the resources in the closure of the given resource", withTargets(func(dr *resolver.DependencyResolver, args []string) error {
+			if len(args) != 1 {
+				return &resolver.UsageError{Err: fmt.Errorf("expected a single resource, got %d", len(args))}
+			}
+			return dr.HandleOwnersCommand(args[0])
+		})},
+		{"query", "List resources matching the given query", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleQueryCommand(args) }},
+		{"rdepends", "List reverse dependencies of the given resources", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleRDependsCommand(args) })},
+		{"show", "Show details of the given resources", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleShowCommand(args) })},
 		{"search", "Search for the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleSearchCommand(args) }},
 		{"category", "List categories of the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleCategoryCommand(args) }},
-		{"tree", "Show dependency tree of the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleTreeCommand(args) }},
-		{"tree-list", "Show dependency tree list of the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleTreeListCommand(args) }},
+		{"stats", "Show statistics about the dependency graph", func(dr *resolver.DependencyResolver, _ []string) error { return dr.HandleStatsCommand() }},
+		{"tree", "Show dependency tree of the given resources", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleTreeCommand(args) })},
+		{"tree-list", "Show dependency tree list of the given resources", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleTreeListCommand(args) })},
+		{"impact", "List resources that would break if the given resources were removed", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleImpactCommand(args) })},
 		{"index", "List all resource entries", func(dr *resolver.DependencyResolver, _ []string) error { return dr.HandleIndexCommand() }}, // Ignoring args here
-		{"run", "Run the commands for the given resources", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleRunCommand(args) }},
+		{"leaves", "List resources without requirements", func(dr *resolver.DependencyResolver, _ []string) error { return dr.HandleLeavesCommand() }},
+		{"levels", "List the execution levels of the given resources", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleLevelsCommand(args) })},
+		{"lock", "Write the lockfile of the given resource", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleLockCommand(args) })},
+		{"cycles", "List groups of resources that depend on each other", func(dr *resolver.DependencyResolver, _ []string) error { return dr.HandleCyclesCommand() }},
+		{"diff", "Show the differences between two resource files", func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleDiffCommand(args) }},
+		{"redundant", "List requirements already implied by other requirements", func(dr *resolver.DependencyResolver, _ []string) error { return dr.HandleRedundantCommand() }},
+		{"browse", "Browse resources and their dependencies interactively", func(dr *resolver.DependencyResolver, _ []string) error { return browse.Run(dr) }},
+		{"export", "Write the catalog and dependency graph in the given format: " + strings.Join(resolver.ExportFormats(), ", "), func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleExportCommand(args) }},
+		{"status", "Show which of the given resources, or of all resources, are installed, pending or failed", withTargets(func(dr *resolver.DependencyResolver, args []string) error { return dr.HandleStatusCommand(args) })},
+		{"roots", "List resources no other resource requires", func(dr *resolver.DependencyResolver, _ []string) error { return dr.HandleRootsCommand() }},
 	}
 
 	for _, cmd := range commands {
 		cmd := cmd // Capture the loop variable
 		rootCmd.AddCommand(&cobra.Command{
-			Use:   cmd.use,
-			Short: cmd.shortDesc,
+			Use:     cmd.use,
+			Aliases: commandAliases[cmd.use],
+			Short:   cmd.shortDesc,
 			RunE: func(c *cobra.Command, args []string) error {
 				return cmd.handler(dr, args)
 			},
@@ -130,9 +1039,22 @@ func addCommands(rootCmd *cobra.Command, dr *resolver.DependencyResolver) {
 	}
 }
 
-func handleCommand(fn func([]string) error, args []string) {
-	if err := fn(args); err != nil {
-		resolver.LogErrorExit("Command execution failed", err)
+// commandAliases are the short names of the commands, by command.
+var commandAliases = map[string][]string{
+	"depends":  {"deps"},
+	"rdepends": {"rdeps"},
+}
+
+// withTargets wraps the handler of a command taking resource IDs, to
+// normalize them following the IDs policy of the catalog, and fail with a
+// NotFoundError for unknown resources.
+func withTargets(handler func(*resolver.DependencyResolver, []string) error) func(*resolver.DependencyResolver, []string) error {
+	return func(dr *resolver.DependencyResolver, args []string) error {
+		args = dr.CurrentIDs(dr.IDs.IDs(args))
+		if err := dr.CheckTargets(args); err != nil {
+			return err
+		}
+		return handler(dr, args)
 	}
 }
 
@@ -150,26 +1072,43 @@ func main() {
 
 	signalCleanup(logger, workDir)
 
+	dependencyResolver := createDependencyResolver(logger, workDir, nil)
+
 	envFilePath := filepath.Join(workDir, ".runner_env")
 	if err := writeEnvToFile(envFilePath); err != nil {
 		logger.Fatalf("Failed to write environment to file: %s - %v", envFilePath, err)
 	}
 
-	if err := resolver.SourceEnvFile(envFilePath); err != nil {
+	if err := dependencyResolver.SourceEnvFile(envFilePath); err != nil {
 		logger.Fatalf("Failed to source environment file: %s - %v", envFilePath, err)
 	}
 
+	// The shell inherits the environment sourced above.
 	session := createShellSession(logger)
 	defer session.Close()
+	dependencyResolver.ShellSession = session
 
-	dependencyResolver := createDependencyResolver(logger, workDir, session)
-
-	loadResourceFiles(dependencyResolver)
+	shutdownTracing, err := tracing.Start(context.Background())
+	if err != nil {
+		logger.Fatalf("Failed to start tracing: %v", err)
+	}
 
 	rootCmd := createRootCmd(dependencyResolver)
-	if err := rootCmd.Execute(); err != nil {
-		resolver.PrintMessage("%v\n", err)
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err := shutdownTracing(context.Background()); err != nil {
+		logger.Errorf("Failed to export traces: %v", err)
+	}
+	// The root command runs nothing: its errors are unknown commands.
+	if err != nil && cmd == rootCmd {
+		err = &resolver.UsageError{Err: err}
+	}
+	if err != nil {
+		// Cobra already wrote the error to the standard error, which is
+		// where scripts reading porcelain records expect it.
+		if dependencyResolver.Porcelain == "" {
+			resolver.PrintMessage("%v\n", err)
+		}
+		os.Exit(resolver.ExitCode(err))
 	}
 }
 
@@ -193,22 +1132,61 @@ func createShellSession(logger *log.Logger) *runnerexec.ShellSession {
 }
 
 func createDependencyResolver(logger *log.Logger, workDir string, session *runnerexec.ShellSession) *resolver.DependencyResolver {
-	dr, err := resolver.NewGraphResolver(afero.NewOsFs(), logger, workDir, session)
+	dr, err := resolver.NewGraphResolver(afero.NewOsFs(), resolver.NewCharmLogger(logger), workDir, session)
 	if err != nil {
 		logger.Fatalf("Failed to create dependency resolver: %v", err)
 	}
+	dr.CacheDir = viper.GetString("cacheDir")
+	dr.PluginPath = viper.GetStringSlice("pluginPath")
+	if dr.CacheDir == "" {
+		if cacheDir, err := os.UserCacheDir(); err == nil {
+			dr.CacheDir = filepath.Join(cacheDir, "runner")
+		}
+	}
+	if err := viper.UnmarshalKey("verify", &dr.Verifications); err != nil {
+		logger.Fatalf("Invalid catalog verifications: %v", err)
+	}
+	if err := viper.UnmarshalKey("ids", &dr.IDs); err != nil {
+		logger.Fatalf("Invalid ID policy: %v", err)
+	}
+	if err := resolver.ValidateIDPolicy(dr.IDs); err != nil {
+		logger.Fatalf("Invalid ID policy: %v", err)
+	}
+	if err := viper.UnmarshalKey("webhooks", &dr.Webhooks); err != nil {
+		logger.Fatalf("Invalid webhooks: %v", err)
+	}
+	if err := resolver.ValidateWebhooks(dr.Webhooks); err != nil {
+		logger.Fatalf("Invalid webhooks: %v", err)
+	}
 	return dr
 }
 
-func loadResourceFiles(dr *resolver.DependencyResolver) {
-	resourceFiles := viper.GetStringSlice("workflows")
-	if len(resourceFiles) == 0 {
-		log.Fatalf("No workflows defined in the configuration file")
+// useStore sets the store at the given location to load the catalog from:
+// the URL of a runner serve API, a SQLite database, or a directory of
+// manifests.
+func useStore(dr *resolver.DependencyResolver, location string) error {
+	var store resolver.ResourceStore
+	switch ext := filepath.Ext(location); {
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		store = resolver.NewHTTPStore(location)
+	case ext == ".db" || ext == ".sqlite":
+		db, err := sqlitestore.Open(location)
+		if err != nil {
+			return err
+		}
+		store = db
+	default:
+		store = resolver.NewFileStore(dr.Fs, location)
 	}
+	dr.UseStore(store)
+	return nil
+}
 
-	for _, file := range resourceFiles {
-		if err := dr.LoadResourceEntries(file); err != nil {
-			resolver.LogErrorExit(fmt.Sprintf("Error loading resource entries from %s", file), err)
+func loadResourceFiles(dr *resolver.DependencyResolver) error {
+	for _, file := range viper.GetStringSlice("workflows") {
+		if err := dr.LoadSource(file); err != nil {
+			return fmt.Errorf("error loading resource entries from %s: %w", file, err)
 		}
 	}
+	return nil
 }
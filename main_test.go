@@ -3,20 +3,40 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jjuliano/runner/pkg/resolver"
 
 	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/rpc/resolverpb"
 	"github.com/jjuliano/runner/pkg/runnerexec"
 	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 // Helper function to capture command output
@@ -35,6 +55,8 @@ func captureOutput(f func()) string {
 	return buf.String()
 }
 
+// initTestConfig writes a configuration listing a remote workflow, served
+// until the end of the test, and a local one.
 func initTestConfig(t *testing.T) (afero.Fs, string, string) {
 	fs := afero.NewOsFs()
 	tempDir := filepath.Join(os.TempDir(), "runner_test")
@@ -47,35 +69,7 @@ func initTestConfig(t *testing.T) (afero.Fs, string, string) {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
 
-	// Create the test_resources.yaml file
-	file, err := fs.Create(localFile)
-	if err != nil {
-		t.Fatalf("failed to create local file: %v", err)
-	}
-	file.Close()
-
-	// Create the test_config.yaml file
-	file, err = fs.Create(configFile)
-	if err != nil {
-		t.Fatalf("failed to create config file: %v", err)
-	}
-	file.Close()
-
-	// Defer the deletion of the temp directory
-	t.Cleanup(func() {
-		err := os.RemoveAll(tempDir)
-		if err != nil {
-			t.Fatalf("failed to remove temp dir: %v", err)
-		}
-	})
-
-	return fs, configFile, localFile
-}
-
-func setupTestResolver(fs afero.Fs, configFile string, localFile string) *resolver.DependencyResolver {
-	logger := log.New(nil)
-
-	localYAMLContent := []byte(`
+	remoteYAMLContent := []byte(`
 resources:
   - id: "res1"
     name: "Id 1"
@@ -87,6 +81,10 @@ resources:
     desc: "Long description 2"
     category: "cat2"
     requires: ["res3"]
+`)
+
+	localYAMLContent := []byte(`
+resources:
   - id: "res3"
     name: "Id 3"
     desc: "Long description 3"
@@ -94,9 +92,11 @@ resources:
     requires: []
 `)
 
+	// The commands load the workflows when they run.
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(localYAMLContent))
+		w.Write([]byte(remoteYAMLContent))
 	}))
+	t.Cleanup(server.Close)
 
 	runnerConfigContent := []byte(`
 workflows:
@@ -104,9 +104,31 @@ workflows:
   - ` + localFile + `
 `)
 
-	afero.WriteFile(fs, configFile, []byte(runnerConfigContent), 0644)
-	afero.WriteFile(fs, localFile, []byte(localYAMLContent), 0644)
-	defer server.Close()
+	// Create the test_resources.yaml file
+	if err := afero.WriteFile(fs, localFile, localYAMLContent, 0644); err != nil {
+		t.Fatalf("failed to create local file: %v", err)
+	}
+
+	// Create the test_config.yaml file
+	if err := afero.WriteFile(fs, configFile, runnerConfigContent, 0644); err != nil {
+		t.Fatalf("failed to create config file: %v", err)
+	}
+
+	// Defer the deletion of the temp directory
+	t.Cleanup(func() {
+		err := os.RemoveAll(tempDir)
+		if err != nil {
+			t.Fatalf("failed to remove temp dir: %v", err)
+		}
+	})
+
+	return fs, configFile, localFile
+}
+
+// setupTestResolver reads the configuration and returns an empty resolver:
+// like in main, the commands load the configured workflows.
+func setupTestResolver(fs afero.Fs, configFile string, localFile string) *resolver.DependencyResolver {
+	logger := log.New(nil)
 
 	viper.SetConfigName(configFile)
 	viper.SetConfigType("yaml")
@@ -124,19 +146,11 @@ workflows:
 	}
 	defer session.Close()
 
-	dependencyResolver, err := resolver.NewGraphResolver(fs, logger, "", session)
+	dependencyResolver, err := resolver.NewGraphResolver(fs, resolver.NewCharmLogger(logger), "", session)
 	if err != nil {
 		log.Fatalf("Failed to create dependency dependencyResolver: %v", err)
 	}
 
-	resourceFiles := viper.GetStringSlice("workflows")
-	for _, file := range resourceFiles {
-		if err := dependencyResolver.LoadResourceEntries(file); err != nil {
-			resolver.PrintError("Error loading resource entries", err)
-			os.Exit(1)
-		}
-	}
-
 	return dependencyResolver
 }
 
@@ -154,7 +168,7 @@ func TestDependsCommand(t *testing.T) {
 		}
 	})
 
-	expectedOutput := "res1\nres1 -> res2\nres1 -> res2 -> res3\n"
+	expectedOutput := "res1\n└── res2\n    └── res3\n"
 	if !strings.Contains(output, expectedOutput) {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
@@ -174,7 +188,7 @@ func TestRDependsCommand(t *testing.T) {
 		}
 	})
 
-	expectedOutput := "res3\nres3 -> res2\nres3 -> res2 -> res1\n"
+	expectedOutput := "res3\n└── res2\n    └── res1\n"
 	if !strings.Contains(output, expectedOutput) {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
@@ -294,7 +308,7 @@ func TestDependsCommand_CircularDependency(t *testing.T) {
 		}
 	})
 
-	expectedOutput := "res1\nres1 -> res2\nres1 -> res2 -> res3"
+	expectedOutput := "res1\n└── res2\n    └── res3"
 	if !strings.Contains(output, expectedOutput) {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
@@ -333,26 +347,736 @@ func TestIndexCommand(t *testing.T) {
 🏷️  Category: cat3
 🔗 Requirements: []
 
-📦 Id: res1
-📛 Name: Id 1
-📝 Description: Long description 1
-🏷️  Category: cat1
-🔗 Requirements: [res2]
+`
+	if !strings.Contains(output, expectedOutput) {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
 
-📦 Id: res2
-📛 Name: Id 2
-📝 Description: Long description 2
-🏷️  Category: cat2
-🔗 Requirements: [res3]
+func TestDiffCommand(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	resolver := setupTestResolver(fs, configFile, localFile)
+	rootCmd := createRootCmd(resolver)
 
-📦 Id: res3
-📛 Name: Id 3
-📝 Description: Long description 3
-🏷️  Category: cat3
-🔗 Requirements: []
+	oldFile := filepath.Join(filepath.Dir(localFile), "old_resources.yaml")
+	afero.WriteFile(fs, oldFile, []byte(`
+resources:
+  - id: "res1"
+    name: "Id 1"
+    desc: "Long description 1"
+    category: "cat1"
+    requires: ["res2"]
+  - id: "res2"
+    name: "Id 2"
+    desc: "Long description 2"
+    category: "cat2"
+    requires: ["res3"]
+  - id: "res3"
+    name: "Id 3"
+    desc: "Long description 3"
+    category: "cat3"
+    requires: []
+`), 0644)
 
-`
+	newFile := filepath.Join(filepath.Dir(localFile), "new_resources.yaml")
+	afero.WriteFile(fs, newFile, []byte(`
+resources:
+  - id: "res1"
+    name: "Id 1"
+    desc: "Long description 1"
+    category: "cat1"
+    requires: ["res3"]
+  - id: "res3"
+    name: "Id 3"
+    desc: "Long description 3"
+    category: "cat3"
+    requires: []
+`), 0644)
+
+	rootCmd.SetArgs([]string{"diff", oldFile, newFile})
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "➖ Removed: res2\n✏️  Changed: res1\n➕ Edge: res1 -> res3\n➖ Edge: res1 -> res2\n➖ Edge: res2 -> res3\n"
+	if !strings.Contains(output, expectedOutput) {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestWhyCommand(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	args := []string{"why", "res3", "--target", "res1"}
+	rootCmd.SetArgs(args)
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "res1 -> res2 -> res3\n"
+	if !strings.Contains(output, expectedOutput) {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestDependsCommand_JSON(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	args := []string{"depends", "res1", "--format", "json"}
+	rootCmd.SetArgs(args)
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "{\n  \"res1\": [\n    \"res2\",\n    \"res3\"\n  ]\n}\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestIndexCommand_Table(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	args := []string{"index", "--format", "table"}
+	rootCmd.SetArgs(args)
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "ID    NAME  CATEGORY  REQUIRES  DESCRIPTION\nres1  Id 1  cat1      res2      Long description 1\n"
+	if !strings.Contains(output, expectedOutput) {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestIndexCommand_Page(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	rootCmd.SetArgs([]string{"index", "--format", "table", "--limit", "1", "--page", "2"})
+	output := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "ID    NAME  CATEGORY  REQUIRES  DESCRIPTION\nres2  Id 2  cat2      res3      Long description 2\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestInvalidPage(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+
+	rootCmd.SetArgs([]string{"index", "--page", "2"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "requires a limit") {
+		t.Errorf("Expected an error for a page without limit, got %v", err)
+	}
+}
+
+func TestInvalidFormat(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+
+	rootCmd.SetArgs([]string{"index", "--format", "xml"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestPorcelainFlag(t *testing.T) {
+	dr := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(dr)
+
+	rootCmd.SetArgs([]string{"depends", "res1", "--porcelain", "--format", "json"})
+	output := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+	if expected := "res1\tres2\nres1\tres3\n"; output != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, output)
+	}
+
+	rootCmd = createRootCmd(dr)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs([]string{"index", "--porcelain=v0"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unsupported porcelain version") {
+		t.Errorf("Expected an error for an unsupported porcelain version, got %v", err)
+	}
+
+	for _, args := range [][]string{{"lock", "res1"}, {"export", "dot"}, {"completion", "bash"}} {
+		rootCmd = createRootCmd(dr)
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append(args, "--porcelain"))
+		output := captureOutput(func() {
+			if err := rootCmd.Execute(); resolver.ExitCode(err) != resolver.ExitUsage {
+				t.Errorf("%s: expected a usage error, got %v", args[0], err)
+			}
+		})
+		if output != "" {
+			t.Errorf("%s: expected no output, got %q", args[0], output)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"depends", "res1"}, 0},
+		{[]string{"depends", "res1", "nope"}, 3},
+		{[]string{"run", "nope"}, 3},
+		{[]string{"why", "res2"}, 2},
+		{[]string{"index", "--bogus"}, 2},
+		{[]string{"index", "--format", "xml"}, 2},
+		{[]string{"owners", "res1", "res2"}, 2},
+	}
+	for _, test := range tests {
+		rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(test.args)
+		var err error
+		captureOutput(func() { err = rootCmd.Execute() })
+		if code := resolver.ExitCode(err); code != test.expected {
+			t.Errorf("%v: expected exit code %d, got %d for %v", test.args, test.expected, code, err)
+		}
+	}
+}
+
+func TestServeCommand_GRPCErrors(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer lis.Close()
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"serve", "--addr", "127.0.0.1:0", "--grpc-addr", "127.0.0.1:0", "--grpc-allow-execute"}, "--grpc-allow-execute requires"},
+		{[]string{"serve", "--addr", "127.0.0.1:0", "--grpc-addr", "127.0.0.1:0", "--grpc-allow-execute", "--grpc-tls-cert", "cert.pem", "--grpc-tls-key", "key.pem"}, "--grpc-allow-execute requires --grpc-client-ca"},
+		{[]string{"serve", "--addr", "127.0.0.1:0", "--grpc-addr", "127.0.0.1:0", "--grpc-client-ca", "ca.pem"}, "--grpc-client-ca requires"},
+		{[]string{"serve", "--addr", "127.0.0.1:0", "--grpc-addr", "127.0.0.1:0", "--grpc-tls-cert", "cert.pem"}, "must be given together"},
+		{[]string{"serve", "--addr", "127.0.0.1:0", "--grpc-addr", lis.Addr().String()}, "error serving gRPC"},
+	}
+	for _, test := range tests {
+		rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(test.args)
+		var err error
+		captureOutput(func() { err = rootCmd.Execute() })
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%v: expected an error containing %q, got %v", test.args, test.expected, err)
+		}
+	}
+}
+
+func TestServeCommand_PprofListenError(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer lis.Close()
+
+	rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs([]string{"serve", "--addr", "127.0.0.1:0", "--pprof-addr", lis.Addr().String()})
+	captureOutput(func() { err = rootCmd.Execute() })
+	if err == nil || !strings.Contains(err.Error(), "error serving profiles") {
+		t.Errorf("Expected the pprof listen error, got %v", err)
+	}
+}
+
+func TestDependsCommand_Depth(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	args := []string{"depends", "res1", "--depth", "1"}
+	rootCmd.SetArgs(args)
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "res1\n└── res2\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestRootsCommand(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	args := []string{"roots"}
+	rootCmd.SetArgs(args)
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "📦 res1\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestTemplateFlag(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	args := []string{"index", "--template", "{{len .}} resources"}
+	rootCmd.SetArgs(args)
+
+	output := captureOutput(func() {
+		err := rootCmd.Execute()
+		if err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "3 resources"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestQuietFlag(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+
+	rootCmd.SetArgs([]string{"depends", "res1", "--quiet"})
+	output := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+	if output != "" {
+		t.Errorf("Expected no output, got:\n%s", output)
+	}
+}
+
+func TestInvalidLogLevel(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	rootCmd := createRootCmd(resolver)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+
+	rootCmd.SetArgs([]string{"index", "--log-level", "loud"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown log level 'loud'") {
+		t.Errorf("Expected an error for an unknown log level, got %v", err)
+	}
+}
+
+func TestDependsCommand_CaseInsensitive(t *testing.T) {
+	resolver := setupTestResolver(initTestConfig(t))
+	resolver.IDs.CaseInsensitive = true
+	rootCmd := createRootCmd(resolver)
+
+	rootCmd.SetArgs([]string{"depends", "RES1"})
+	output := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expectedOutput := "res1\n└── res2\n    └── res3\n"
 	if !strings.Contains(output, expectedOutput) {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
 }
+
+func TestLoadMode_Default(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	source := filepath.Join(filepath.Dir(localFile), "dangling.yaml")
+	afero.WriteFile(fs, source, []byte("resources:\n  - id: extra\n    requires: [missing]\n"), 0644)
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"show", "extra"}, resolver.ExitOK},
+		{[]string{"depends", "res1"}, resolver.ExitOK},
+		{[]string{"run", "res3"}, resolver.ExitValidation},
+		{[]string{"show", "extra", "--load-mode", "strict"}, resolver.ExitValidation},
+	}
+	for _, test := range tests {
+		rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append(test.args, "--source", source))
+		var err error
+		captureOutput(func() { err = rootCmd.Execute() })
+		if code := resolver.ExitCode(err); code != test.expected {
+			t.Errorf("%v: expected exit code %d, got %d for %v", test.args, test.expected, code, err)
+		}
+	}
+}
+
+func TestValidateCommand(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	source := filepath.Join(filepath.Dir(localFile), "extra.yaml")
+	afero.WriteFile(fs, source, []byte("resources:\n  - id: extra\n    requires: [missing]\n    optional: [docs]\n"), 0644)
+
+	validate := func(args ...string) (string, error) {
+		rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{"validate", "--source", source}, args...))
+		var err error
+		output := captureOutput(func() { err = rootCmd.Execute() })
+		return output, err
+	}
+
+	var validationErr *resolver.ValidationError
+	output, err := validate()
+	if !errors.As(err, &validationErr) || validationErr.Errors != 1 || !strings.Contains(output, "missing resource 'missing' is required by extra [missing-requirement]") {
+		t.Errorf("Expected the missing requirement to fail, got %v:\n%s", err, output)
+	}
+	if strings.Contains(output, "missing-optional") {
+		t.Errorf("Expected no warnings without --warnings, got:\n%s", output)
+	}
+
+	if output, err := validate("--load-mode", "lenient"); err != nil || !strings.Contains(output, "resources are valid") {
+		t.Errorf("Expected a lenient catalog to be valid, got %v:\n%s", err, output)
+	}
+	output, err = validate("--load-mode", "lenient", "--strict", "--format", "json")
+	if !errors.As(err, &validationErr) || validationErr.Errors != 0 || validationErr.Warnings != 2 {
+		t.Errorf("Expected the warnings to fail in strict mode, got %v", err)
+	}
+	var findings []resolver.Finding
+	if err := json.Unmarshal([]byte(output), &findings); err != nil || len(findings) != 2 || findings[0].Severity != resolver.SeverityWarning {
+		t.Errorf("Expected the findings as JSON, got %v:\n%s", err, output)
+	}
+}
+
+func TestInitCommand(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+	dir := t.TempDir()
+
+	initCatalog := func(args ...string) (string, error) {
+		rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{"init", dir}, args...))
+		var err error
+		output := captureOutput(func() { err = rootCmd.Execute() })
+		return output, err
+	}
+
+	output, err := initCatalog("--resource-dir", "catalog")
+	if err != nil || !strings.Contains(output, filepath.Join(dir, "catalog", "hello.yaml")) {
+		t.Fatalf("Expected the starter catalog to be written, got %v:\n%s", err, output)
+	}
+	if config, _ := afero.ReadFile(fs, filepath.Join(dir, "runner.yml")); string(config) != "workflows:\n  - catalog/\n" {
+		t.Errorf("Expected runner.yml to load the resource directory, got %q", config)
+	}
+	if _, err := initCatalog(); err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Errorf("Expected the existing runner.yml to fail, got %v", err)
+	}
+	if _, err := initCatalog("--force"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestManifestCommands(t *testing.T) {
+	fs, configFile, localFile := initTestConfig(t)
+
+	// Every command loads the catalog from the manifests as rewritten.
+	run := func(args ...string) error {
+		rootCmd := createRootCmd(setupTestResolver(fs, configFile, localFile))
+		rootCmd.SetOut(io.Discard)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(args)
+		var err error
+		captureOutput(func() { err = rootCmd.Execute() })
+		return err
+	}
+
+	if err := run("add", "res4", "--desc", "Fourth", "--require", "res3", "--exec", "echo 4"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, _ := afero.ReadFile(fs, localFile)
+	if !strings.Contains(string(data), "\n  - id: res4\n    desc: Fourth\n    requires:\n      - res3\n    run:\n      - name: run\n        exec: echo 4\n") {
+		t.Errorf("Expected the resource in the first local workflow, got:\n%s", data)
+	}
+
+	if err := run("add", "res5", "--file", localFile); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := run("edit", "res4", "--require", "res5", "--remove-require", "res3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, _ := afero.ReadFile(fs, localFile); !strings.Contains(string(data), "    requires:\n      - res5\n") {
+		t.Errorf("Expected the requirements to be edited, got:\n%s", data)
+	}
+	if err := run("edit", "res4"); err == nil {
+		t.Error("Expected an edit without changes to fail")
+	}
+
+	if err := run("remove", "res5"); err == nil {
+		t.Error("Expected removing a required resource to fail")
+	}
+	if err := run("remove", "res4"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, _ := afero.ReadFile(fs, localFile); strings.Contains(string(data), "res4") {
+		t.Errorf("Expected the resource to be removed, got:\n%s", data)
+	}
+}
+
+func TestResourceCompletion(t *testing.T) {
+	complete := func(args ...string) []string {
+		rootCmd := createRootCmd(setupTestResolver(initTestConfig(t)))
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(io.Discard)
+		rootCmd.SetArgs(append([]string{cobra.ShellCompNoDescRequestCmd}, args...))
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		// The last line is the directive.
+		return lines[:len(lines)-1]
+	}
+
+	if got := complete("tree", "res"); !reflect.DeepEqual(got, []string{"res1", "res2", "res3"}) {
+		t.Errorf("Expected every resource, got %v", got)
+	}
+	if got := complete("depends", "res1", "res"); !reflect.DeepEqual(got, []string{"res2", "res3"}) {
+		t.Errorf("Expected the resources not given yet, got %v", got)
+	}
+	if got := complete("why", "res1", "res"); len(got) != 0 {
+		t.Errorf("Expected no completion past the single argument, got %v", got)
+	}
+	if got := complete("edit", "res1", "--require", "res2,res"); !reflect.DeepEqual(got, []string{"res2,res1", "res2,res3"}) {
+		t.Errorf("Expected the last ID of the list to complete, got %v", got)
+	}
+	if got := complete("index", ""); len(got) != 0 {
+		t.Errorf("Expected no resource completion for index, got %v", got)
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	userDir, projectDir := t.TempDir(), t.TempDir()
+	userFile := filepath.Join(userDir, "runner", "config.yaml")
+	os.MkdirAll(filepath.Dir(userFile), 0755)
+	os.WriteFile(userFile, []byte("workflows: [user.yaml]\nformat: json\nserve:\n  addr: \":9000\"\ntargets: [app]\n"), 0644)
+	os.WriteFile(filepath.Join(projectDir, "runner.yml"), []byte("workflows: [project.yaml]\nformat: yaml\n"), 0644)
+	t.Setenv("RUNNER_FORMAT", "table")
+	t.Setenv("RUNNER_OVERLAYS", "a.yaml, b.yaml")
+
+	if err := readConfig(userFile, projectDir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if workflows := viper.GetStringSlice("workflows"); !reflect.DeepEqual(workflows, []string{"project.yaml"}) {
+		t.Errorf("Expected runner.yml to override the user configuration, got %v", workflows)
+	}
+	if targets := viper.GetStringSlice("targets"); !reflect.DeepEqual(targets, []string{"app"}) {
+		t.Errorf("Expected the user configuration as default, got %v", targets)
+	}
+	if overlays := viper.GetStringSlice("overlays"); !reflect.DeepEqual(overlays, []string{"a.yaml", "b.yaml"}) {
+		t.Errorf("Expected the comma-separated overlays of the environment, got %v", overlays)
+	}
+
+	var format, addr string
+	cmd := &cobra.Command{Use: "serve"}
+	cmd.Flags().StringVar(&format, "format", "plain", "")
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "")
+	if err := applyConfig(cmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if format != "table" || addr != ":9000" {
+		t.Errorf("Expected the environment, then the files, to set the flags, got %q and %q", format, addr)
+	}
+	cmd.Flags().Set("format", "plain")
+	if format = "plain"; applyConfig(cmd) != nil || format != "plain" {
+		t.Errorf("Expected the command line to take precedence, got %q", format)
+	}
+
+	viper.Reset()
+	if err := readConfig(filepath.Join(userDir, "missing.yaml"), t.TempDir()); err != nil {
+		t.Errorf("Expected missing configuration files to be skipped, got %v", err)
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	rootCmd := createRootCmd(setupTestResolver(initTestConfig(t)))
+	rootCmd.SetArgs([]string{"shell", "--compact"})
+	rootCmd.SetIn(strings.NewReader("deps res1 depth=1\nquery category=cat3 name=\"Id 3\"\nrdeps res3\nunknown\nsearch zzzzzz\nsearch\nrun res1\nserve\nshell\ndeps res1 depth=1\nexit\n"))
+
+	output := captureOutput(func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Failed to execute command: %v", err)
+		}
+	})
+
+	expected := "📦 Loaded 3 resources, enter 'help' to list the commands and 'exit' to quit\n" +
+		"res1\nres1 -> res2\n" +
+		"📦 res3\n" +
+		"res3\nres3 -> res2\nres2 -> res1\n" +
+		"❌ unknown command \"unknown\" for \"runner\"\n" +
+		"❌ no resource matches 'zzzzzz'\n" +
+		"❌ a search query is required\n" +
+		"❌ 'run' is not available in the shell\n" +
+		"❌ 'serve' is not available in the shell\n" +
+		"❌ already in the shell\n" +
+		"res1\nres1 -> res2\n"
+	if output != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+func TestProjectStateFile(t *testing.T) {
+	dr := &resolver.DependencyResolver{CacheDir: t.TempDir()}
+	path := projectStateFile(dr, "checkpoint.yaml")
+	if !strings.HasPrefix(path, dr.CacheDir) || filepath.Base(path) != "checkpoint.yaml" {
+		t.Errorf("Expected a checkpoint under %s, got %s", dr.CacheDir, path)
+	}
+	if again := projectStateFile(dr, "checkpoint.yaml"); again != path {
+		t.Errorf("Expected the same file for the same directory, got %s and %s", path, again)
+	}
+	if path := projectStateFile(&resolver.DependencyResolver{}, "checkpoint.yaml"); path != "" {
+		t.Errorf("Expected no file without a cache directory, got %s", path)
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	rootCmd := createRootCmd(setupTestResolver(initTestConfig(t)))
+	tests := []struct {
+		words, expected []string
+	}{
+		{[]string{"tree", "z", "depth=3"}, []string{"tree", "z", "--depth=3"}},
+		{[]string{"query", "category=example", "name=a b"}, []string{"query", `category == "example"`, "&&", `name == "a b"`}},
+		{[]string{"query", "deprecated=false"}, []string{"query", "deprecated == false"}},
+		{[]string{"query", "len(requires)", "==", "0"}, []string{"query", "len(requires)", "==", "0"}},
+		{[]string{"rdeps", "m", "--format=json", "x=y"}, []string{"rdeps", "m", "--format=json", "x=y"}},
+		{[]string{"unknown", "depth=3"}, []string{"unknown", "depth=3"}},
+	}
+	for _, test := range tests {
+		if got := shellArgs(rootCmd, test.words); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.words, test.expected, got)
+		}
+	}
+}
+
+// writeTestCert writes a certificate for 127.0.0.1 and its key to dir,
+// signed by parent, or self-signed as a CA when parent is nil.
+func writeTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed to create a certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(filepath.Join(dir, name+"-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func TestNewGRPCServer_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	writeTestCert(t, dir, "server", ca, caKey)
+	writeTestCert(t, dir, "client", ca, caKey)
+
+	dr := setupTestResolver(initTestConfig(t))
+	srv, err := newGRPCServer(dr, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem"), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	call := func(config *tls.Config) error {
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(config)))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = resolverpb.NewResolverClient(conn).ExecutePlan(ctx, &resolverpb.ExecutePlanRequest{})
+		return err
+	}
+
+	// Clients without a certificate of the CA are rejected.
+	if err := call(&tls.Config{RootCAs: roots}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected a client without a certificate to be rejected, got %v", err)
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := call(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{cert}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected an authenticated client to reach ExecutePlan, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package browse
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+type view int
+
+const (
+	directView view = iota
+	transitiveView
+	reverseView
+)
+
+// defaultPageSize is the number of resources listed at once until the size
+// of the terminal is known.
+const defaultPageSize = 20
+
+// chromeLines is the number of lines of the view around the listed resources.
+const chromeLines = 9
+
+var viewTitles = map[view]string{
+	directView:     "direct dependencies",
+	transitiveView: "transitive dependencies",
+	reverseView:    "reverse dependencies",
+}
+
+// Model is the bubbletea model of the interactive resource browser.
+type Model struct {
+	dr        *resolver.DependencyResolver
+	history   []string
+	view      view
+	items     []string
+	cursor    int
+	filter    string
+	filtering bool
+	pageSize  int
+}
+
+// New creates a browser model listing every resource of the resolver.
+func New(dr *resolver.DependencyResolver) Model {
+	m := Model{dr: dr, pageSize: defaultPageSize}
+	m.refresh()
+	return m
+}
+
+// Run starts the interactive browser and blocks until the user quits.
+func Run(dr *resolver.DependencyResolver) error {
+	_, err := tea.NewProgram(New(dr), tea.WithAltScreen()).Run()
+	return err
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.pageSize = max(size.Height-chromeLines, 1)
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.filtering {
+		switch key.Type {
+		case tea.KeyEnter:
+			m.filtering = false
+		case tea.KeyEsc:
+			m.filtering = false
+			m.filter = ""
+		case tea.KeyBackspace:
+			if len(m.filter) > 0 {
+				m.filter = m.filter[:len(m.filter)-1]
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.filter += string(key.Runes)
+		}
+		m.cursor = 0
+		m.refresh()
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "pgdown":
+		m.cursor = min(m.cursor+m.pageSize, max(len(m.items)-1, 0))
+	case "pgup":
+		m.cursor = max(m.cursor-m.pageSize, 0)
+	case "/":
+		m.filtering = true
+	case "enter", "right", "l":
+		if len(m.items) > 0 {
+			m.history = append(m.history, m.items[m.cursor])
+			m.filter = ""
+			m.cursor = 0
+			m.refresh()
+		}
+	case "esc", "backspace", "left", "h":
+		if len(m.history) > 0 {
+			m.history = m.history[:len(m.history)-1]
+			m.filter = ""
+			m.cursor = 0
+			m.refresh()
+		}
+	case "tab":
+		m.view = (m.view + 1) % view(len(viewTitles))
+		m.cursor = 0
+		m.refresh()
+	}
+	return m, nil
+}
+
+// View implements tea.Model.
+func (m Model) View() string {
+	var b strings.Builder
+
+	if current, ok := m.current(); ok {
+		entry, _ := m.dr.GetResource(current)
+		fmt.Fprintf(&b, "📦 %s - %s\n", current, entry.Name)
+		if entry.Desc != "" {
+			fmt.Fprintf(&b, "📝 %s\n", entry.Desc)
+		}
+		fmt.Fprintf(&b, "🔗 %s\n\n", viewTitles[m.view])
+	} else {
+		b.WriteString("📦 Resources\n\n")
+	}
+
+	if m.filtering || m.filter != "" {
+		fmt.Fprintf(&b, "🔍 %s\n\n", m.filter)
+	}
+
+	if len(m.items) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	// Only the page holding the cursor is rendered, so catalogs of any
+	// size fit the terminal.
+	start := m.cursor / m.pageSize * m.pageSize
+	end := min(start+m.pageSize, len(m.items))
+	for i := start; i < end; i++ {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		b.WriteString(cursor + m.items[i] + "\n")
+	}
+	if pages := (len(m.items) + m.pageSize - 1) / m.pageSize; pages > 1 {
+		fmt.Fprintf(&b, "\n  page %d/%d, %d resources\n", start/m.pageSize+1, pages, len(m.items))
+	}
+
+	b.WriteString("\n↑/↓ move • pgup/pgdn page • enter open • esc back • tab switch view • / filter • q quit\n")
+	return b.String()
+}
+
+// Items returns the resources currently listed.
+func (m Model) Items() []string {
+	return m.items
+}
+
+func (m Model) current() (string, bool) {
+	if len(m.history) == 0 {
+		return "", false
+	}
+	return m.history[len(m.history)-1], true
+}
+
+func (m *Model) refresh() {
+	var items []string
+	if current, ok := m.current(); ok {
+		switch m.view {
+		case directView:
+			items = m.dr.DirectDependencies(current)
+		case transitiveView:
+			items = m.dr.TransitiveDependencies(current)
+		case reverseView:
+			items = m.dr.ReverseDependencies(current)
+		}
+	} else {
+		for _, entry := range m.dr.ListResources() {
+			items = append(items, entry.Id)
+		}
+	}
+
+	if m.filter != "" {
+		items = fuzzy.FindFold(m.filter, items)
+	}
+
+	m.items = items
+	if m.cursor >= len(m.items) {
+		m.cursor = max(len(m.items)-1, 0)
+	}
+}
@@ -0,0 +1,133 @@
+package browse
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+)
+
+func setupTestResolver(t *testing.T) *resolver.DependencyResolver {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.Resources = []resolver.ResourceNodeEntry{
+		{Id: "git", Name: "Git"},
+		{Id: "tag", Name: "Tag", Requires: []string{"git"}},
+		{Id: "organize", Name: "Organize", Desc: "Organize files", Requires: []string{"tag"}},
+	}
+	dr.BuildDependencies()
+	return dr
+}
+
+func press(m tea.Model, keys ...string) tea.Model {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestBrowse_ListsResources(t *testing.T) {
+	m := New(setupTestResolver(t))
+
+	if items := strings.Join(m.Items(), ","); items != "git,tag,organize" {
+		t.Errorf("Expected all resources, got %s", items)
+	}
+	if !strings.Contains(m.View(), "> git") {
+		t.Errorf("Expected cursor on the first resource, got:\n%s", m.View())
+	}
+}
+
+func TestBrowse_DrillDown(t *testing.T) {
+	m := press(New(setupTestResolver(t)), "down", "down", "enter")
+
+	view := m.View()
+	if !strings.Contains(view, "📦 organize - Organize") || !strings.Contains(view, "direct dependencies") {
+		t.Errorf("Expected the details of 'organize', got:\n%s", view)
+	}
+	if items := strings.Join(m.(Model).Items(), ","); items != "tag" {
+		t.Errorf("Expected direct dependencies [tag], got %s", items)
+	}
+
+	m = press(m, "tab")
+	if items := strings.Join(m.(Model).Items(), ","); items != "tag,git" {
+		t.Errorf("Expected transitive dependencies [tag git], got %s", items)
+	}
+
+	m = press(m, "enter", "tab")
+	if items := strings.Join(m.(Model).Items(), ","); items != "organize" {
+		t.Errorf("Expected reverse dependencies [organize], got %s", items)
+	}
+
+	m = press(m, "esc", "esc")
+	if items := strings.Join(m.(Model).Items(), ","); items != "git,tag,organize" {
+		t.Errorf("Expected to be back at the resource list, got %s", items)
+	}
+}
+
+func TestBrowse_Filter(t *testing.T) {
+	m := press(New(setupTestResolver(t)), "/", "o", "r", "g", "enter")
+
+	if items := strings.Join(m.(Model).Items(), ","); items != "organize" {
+		t.Errorf("Expected filtered resources [organize], got %s", items)
+	}
+	if !strings.Contains(m.View(), "🔍 org") {
+		t.Errorf("Expected the filter to be shown, got:\n%s", m.View())
+	}
+
+	m = press(m, "/", "esc")
+	if items := strings.Join(m.(Model).Items(), ","); items != "git,tag,organize" {
+		t.Errorf("Expected the filter to be cleared, got %s", items)
+	}
+}
+
+func TestBrowse_Quit(t *testing.T) {
+	_, cmd := New(setupTestResolver(t)).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil {
+		t.Fatalf("Expected a quit command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("Expected a quit message")
+	}
+}
+
+func TestBrowse_Pages(t *testing.T) {
+	var m tea.Model = New(setupTestResolver(t))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: chromeLines + 2})
+
+	view := m.View()
+	if !strings.Contains(view, "> git") || !strings.Contains(view, "  tag") || strings.Contains(view, "organize") {
+		t.Errorf("Expected the first page of 2 resources, got:\n%s", view)
+	}
+	if !strings.Contains(view, "page 1/2, 3 resources") {
+		t.Errorf("Expected the page number, got:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	view = m.View()
+	if !strings.Contains(view, "> organize") || strings.Contains(view, "git") || !strings.Contains(view, "page 2/2") {
+		t.Errorf("Expected the second page, got:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if !strings.Contains(m.View(), "> git") {
+		t.Errorf("Expected the first page again, got:\n%s", m.View())
+	}
+}
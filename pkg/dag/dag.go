@@ -0,0 +1,256 @@
+// Package dag implements traversals and orderings of directed graphs over any
+// comparable node type. Graphs may have cycles: traversals visit every node at
+// most once, and orderings ignore the edges closing a cycle.
+package dag
+
+// Graph is a directed graph mapping every node to its successors, in order.
+// Nodes without successors may be left out.
+type Graph[K comparable] map[K][]K
+
+// frame is an entry of the explicit stack used by the traversals, so that
+// arbitrarily deep chains don't grow the goroutine stack.
+type frame[K comparable] struct {
+	node K
+	next int
+}
+
+// Successors returns the successors of node.
+func (g Graph[K]) Successors(node K) []K {
+	return g[node]
+}
+
+// Reverse returns the graph with every edge inverted. The predecessors of a
+// node are listed in the order of nodes, which should hold every node with
+// successors. Repeated nodes and repeated edges are only counted once.
+func (g Graph[K]) Reverse(nodes []K) Graph[K] {
+	inverted := make(Graph[K])
+	seen := make(map[K]bool, len(nodes))
+	for _, node := range nodes {
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		for _, succ := range g[node] {
+			if n := len(inverted[succ]); n > 0 && inverted[succ][n-1] == node {
+				continue
+			}
+			inverted[succ] = append(inverted[succ], node)
+		}
+	}
+	return inverted
+}
+
+// Closure returns the nodes reachable from root, excluding root, in
+// breadth-first order. Nodes more than maxDepth edges away are left out
+// unless maxDepth is 0.
+func (g Graph[K]) Closure(root K, maxDepth int) []K {
+	seen := map[K]bool{root: true}
+	queue := []K{root}
+	var closure []K
+	for depth := 1; len(queue) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		var next []K
+		for _, node := range queue {
+			for _, succ := range g[node] {
+				if seen[succ] {
+					continue
+				}
+				seen[succ] = true
+				closure = append(closure, succ)
+				next = append(next, succ)
+			}
+		}
+		queue = next
+	}
+	return closure
+}
+
+// distances returns the number of edges of the shortest path from root to
+// every node at most maxDepth edges away.
+func (g Graph[K]) distances(root K, maxDepth int) map[K]int {
+	dist := map[K]int{root: 0}
+	queue := []K{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if dist[node] == maxDepth {
+			continue
+		}
+		for _, succ := range g[node] {
+			if _, seen := dist[succ]; !seen {
+				dist[succ] = dist[node] + 1
+				queue = append(queue, succ)
+			}
+		}
+	}
+	return dist
+}
+
+// PostOrder returns root and the nodes reachable from it, every node after
+// its successors, skipping the nodes already marked in visited and marking
+// the ones it returns. Nodes more than maxDepth edges away are left out
+// unless maxDepth is 0, by their shortest path from root, so that the nodes
+// returned are the ones of the Closure. Sharing visited between calls orders
+// the union of several closures.
+func (g Graph[K]) PostOrder(root K, visited map[K]bool, maxDepth int) []K {
+	if visited[root] {
+		return nil
+	}
+	visited[root] = true
+
+	// A node reached through a long path first is still expanded when a
+	// shorter path keeps its successors within maxDepth.
+	var dist map[K]int
+	if maxDepth > 0 {
+		dist = g.distances(root, maxDepth)
+	}
+
+	var order []K
+	stack := []frame[K]{{node: root}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		succs := g[top.node]
+		if top.next >= len(succs) {
+			order = append(order, top.node)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		succ := succs[top.next]
+		top.next++
+		if visited[succ] || (maxDepth > 0 && dist[top.node] >= maxDepth) {
+			continue
+		}
+
+		visited[succ] = true
+		stack = append(stack, frame[K]{node: succ})
+	}
+	return order
+}
+
+// Depths returns the length of the longest chain of edges starting at every
+// node reachable from nodes, ignoring the edges that close a cycle.
+func (g Graph[K]) Depths(nodes []K) map[K]int {
+	depth := make(map[K]int, len(nodes))
+	// visiting holds the nodes of the current path, whose depth is not known
+	// yet: an edge to one of them closes a cycle.
+	visiting := make(map[K]bool)
+	var visit func(node K)
+	visit = func(node K) {
+		visiting[node] = true
+		for _, succ := range g[node] {
+			if visiting[succ] {
+				continue
+			}
+			if _, done := depth[succ]; !done {
+				visit(succ)
+			}
+			depth[node] = max(depth[node], depth[succ]+1)
+		}
+		depth[node] = max(depth[node], 0)
+		delete(visiting, node)
+	}
+	for _, node := range nodes {
+		if _, done := depth[node]; !done {
+			visit(node)
+		}
+	}
+	return depth
+}
+
+// ShortestPath returns the shortest path from one node to another, following
+// edges breadth-first, or nil when there is none.
+func (g Graph[K]) ShortestPath(from, to K) []K {
+	parents := map[K]K{}
+	seen := map[K]bool{from: true}
+	queue := []K{from}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node == to {
+			var path []K
+			for ; node != from; node = parents[node] {
+				path = append(path, node)
+			}
+			path = append(path, from)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, succ := range g[node] {
+			if seen[succ] {
+				continue
+			}
+			seen[succ] = true
+			parents[succ] = node
+			queue = append(queue, succ)
+		}
+	}
+	return nil
+}
+
+// Components groups the nodes reachable from nodes into strongly connected
+// components using Tarjan's algorithm. Every component of more than one
+// node, or a node with an edge to itself, is a cycle. Components are listed
+// in reverse topological order, successors first.
+func (g Graph[K]) Components(nodes []K) [][]K {
+	index := make(map[K]int, len(nodes))
+	lowlink := make(map[K]int, len(nodes))
+	onStack := make(map[K]bool, len(nodes))
+	var stack []K
+	var components [][]K
+
+	for _, root := range nodes {
+		if _, ok := index[root]; ok {
+			continue
+		}
+
+		index[root] = len(index)
+		lowlink[root] = index[root]
+		stack = append(stack, root)
+		onStack[root] = true
+		frames := []frame[K]{{node: root}}
+
+		for len(frames) > 0 {
+			top := &frames[len(frames)-1]
+			succs := g[top.node]
+			if top.next < len(succs) {
+				succ := succs[top.next]
+				top.next++
+				if _, ok := index[succ]; !ok {
+					index[succ] = len(index)
+					lowlink[succ] = index[succ]
+					stack = append(stack, succ)
+					onStack[succ] = true
+					frames = append(frames, frame[K]{node: succ})
+				} else if onStack[succ] {
+					lowlink[top.node] = min(lowlink[top.node], index[succ])
+				}
+				continue
+			}
+
+			node := top.node
+			frames = frames[:len(frames)-1]
+			if len(frames) > 0 {
+				parent := frames[len(frames)-1].node
+				lowlink[parent] = min(lowlink[parent], lowlink[node])
+			}
+			if lowlink[node] != index[node] {
+				continue
+			}
+
+			var component []K
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				component = append(component, member)
+				if member == node {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	return components
+}
@@ -0,0 +1,109 @@
+package dag
+
+import (
+	"reflect"
+	"testing"
+)
+
+// diamond is 1 -> 2, 3 -> 4, with 4 -> 5.
+var diamond = Graph[int]{1: {2, 3}, 2: {4}, 3: {4}, 4: {5}}
+
+// cyclic is 1 -> 2 -> 3 -> 1, with 3 -> 4.
+var cyclic = Graph[int]{1: {2}, 2: {3}, 3: {1, 4}}
+
+// shortcut is 1 -> 2 -> 3 -> 4, with 1 -> 3: within two edges of 1, 3 is
+// first reached at the limit through 2, and 4 only through the shortcut.
+var shortcut = Graph[int]{1: {2, 3}, 2: {3}, 3: {4}}
+
+func TestReverse(t *testing.T) {
+	got := Graph[int]{1: {2, 2, 3}, 2: {3}}.Reverse([]int{1, 2, 1})
+	want := Graph[int]{2: {1}, 3: {1, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestClosure(t *testing.T) {
+	if got := diamond.Closure(1, 0); !reflect.DeepEqual(got, []int{2, 3, 4, 5}) {
+		t.Errorf("Expected [2 3 4 5], got %v", got)
+	}
+	if got := diamond.Closure(1, 1); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("Expected [2 3] within one edge, got %v", got)
+	}
+	if got := shortcut.Closure(1, 2); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("Expected [2 3 4] within two edges, got %v", got)
+	}
+	if got := cyclic.Closure(1, 0); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("Expected the cycle to be visited once, got %v", got)
+	}
+	if got := diamond.Closure(5, 0); got != nil {
+		t.Errorf("Expected an empty closure, got %v", got)
+	}
+}
+
+func TestPostOrder(t *testing.T) {
+	visited := make(map[int]bool)
+	if got := diamond.PostOrder(2, visited, 0); !reflect.DeepEqual(got, []int{5, 4, 2}) {
+		t.Errorf("Expected [5 4 2], got %v", got)
+	}
+	if got := diamond.PostOrder(1, visited, 0); !reflect.DeepEqual(got, []int{3, 1}) {
+		t.Errorf("Expected the visited nodes to be skipped, got %v", got)
+	}
+	if got := diamond.PostOrder(1, visited, 0); got != nil {
+		t.Errorf("Expected nothing left to order, got %v", got)
+	}
+	if got := diamond.PostOrder(1, make(map[int]bool), 1); !reflect.DeepEqual(got, []int{2, 3, 1}) {
+		t.Errorf("Expected [2 3 1] within one edge, got %v", got)
+	}
+	if got := shortcut.PostOrder(1, make(map[int]bool), 2); !reflect.DeepEqual(got, []int{4, 3, 2, 1}) {
+		t.Errorf("Expected the closure within two edges, got %v", got)
+	}
+	if got := cyclic.PostOrder(1, make(map[int]bool), 0); !reflect.DeepEqual(got, []int{4, 3, 2, 1}) {
+		t.Errorf("Expected the edge closing the cycle to be ignored, got %v", got)
+	}
+}
+
+func TestDepths(t *testing.T) {
+	want := map[int]int{1: 3, 2: 2, 3: 2, 4: 1, 5: 0}
+	if got := diamond.Depths([]int{1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := cyclic.Depths([]int{1}); got[1] != 3 || got[4] != 0 {
+		t.Errorf("Expected the edge closing the cycle to be ignored, got %v", got)
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	if got := diamond.ShortestPath(1, 5); !reflect.DeepEqual(got, []int{1, 2, 4, 5}) {
+		t.Errorf("Expected [1 2 4 5], got %v", got)
+	}
+	if got := diamond.ShortestPath(3, 3); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("Expected [3], got %v", got)
+	}
+	if got := diamond.ShortestPath(5, 1); got != nil {
+		t.Errorf("Expected no path, got %v", got)
+	}
+}
+
+func TestComponents(t *testing.T) {
+	got := cyclic.Components([]int{1})
+	want := [][]int{{4}, {3, 2, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if got := diamond.Components([]int{1}); len(got) != 5 {
+		t.Errorf("Expected a component per node of an acyclic graph, got %v", got)
+	}
+}
+
+func TestGraph_OtherNodeTypes(t *testing.T) {
+	type task struct{ name string }
+	build, test, deploy := task{"build"}, task{"test"}, task{"deploy"}
+	tasks := Graph[task]{deploy: {test, build}, test: {build}}
+
+	got := tasks.PostOrder(deploy, make(map[task]bool), 0)
+	if !reflect.DeepEqual(got, []task{build, test, deploy}) {
+		t.Errorf("Expected build, test, deploy, got %v", got)
+	}
+}
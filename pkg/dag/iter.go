@@ -0,0 +1,111 @@
+package dag
+
+import "iter"
+
+// DFS returns an iterator over root and the nodes reachable from it, root
+// first, in depth-first order. Every node is visited once. The successors of
+// a node are read through successors when the iteration reaches it, so they
+// can come from a graph changing concurrently, and breaking out of the loop
+// early skips the rest of the graph.
+func DFS[K comparable](root K, successors func(K) []K) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		visited := map[K]bool{root: true}
+		if !yield(root) {
+			return
+		}
+		stack := []frame[K]{{node: root}}
+		succs := [][]K{successors(root)}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next >= len(succs[len(succs)-1]) {
+				stack = stack[:len(stack)-1]
+				succs = succs[:len(succs)-1]
+				continue
+			}
+
+			succ := succs[len(succs)-1][top.next]
+			top.next++
+			if visited[succ] {
+				continue
+			}
+			visited[succ] = true
+			if !yield(succ) {
+				return
+			}
+			stack = append(stack, frame[K]{node: succ})
+			succs = append(succs, successors(succ))
+		}
+	}
+}
+
+// BFS returns an iterator over root and the nodes reachable from it, root
+// first, in breadth-first order. Like DFS, it reads the graph as it goes.
+func BFS[K comparable](root K, successors func(K) []K) iter.Seq[K] {
+	return BFSWithin(root, 0, successors)
+}
+
+// BFSWithin is BFS leaving out the nodes more than maxDepth edges away from
+// root, unless maxDepth is 0. Past root, it yields the nodes of Closure in
+// the same order.
+func BFSWithin[K comparable](root K, maxDepth int, successors func(K) []K) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		visited := map[K]bool{root: true}
+		queue := []K{root}
+		depths := []int{0}
+		for len(queue) > 0 {
+			node, depth := queue[0], depths[0]
+			queue, depths = queue[1:], depths[1:]
+			if !yield(node) {
+				return
+			}
+			if maxDepth > 0 && depth == maxDepth {
+				continue
+			}
+			for _, succ := range successors(node) {
+				if !visited[succ] {
+					visited[succ] = true
+					queue = append(queue, succ)
+					depths = append(depths, depth+1)
+				}
+			}
+		}
+	}
+}
+
+// TopoOrder returns an iterator over nodes and the nodes reachable from them,
+// every node after its successors, starting from nodes in order. Edges
+// closing a cycle are ignored. Like DFS, it reads the graph as it goes.
+func TopoOrder[K comparable](nodes []K, successors func(K) []K) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		visited := make(map[K]bool, len(nodes))
+		for _, root := range nodes {
+			if visited[root] {
+				continue
+			}
+			visited[root] = true
+
+			stack := []frame[K]{{node: root}}
+			succs := [][]K{successors(root)}
+			for len(stack) > 0 {
+				top := &stack[len(stack)-1]
+				if top.next >= len(succs[len(succs)-1]) {
+					if !yield(top.node) {
+						return
+					}
+					stack = stack[:len(stack)-1]
+					succs = succs[:len(succs)-1]
+					continue
+				}
+
+				succ := succs[len(succs)-1][top.next]
+				top.next++
+				if visited[succ] {
+					continue
+				}
+				visited[succ] = true
+				stack = append(stack, frame[K]{node: succ})
+				succs = append(succs, successors(succ))
+			}
+		}
+	}
+}
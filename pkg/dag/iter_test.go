@@ -0,0 +1,64 @@
+package dag
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDFS(t *testing.T) {
+	if got := slices.Collect(DFS(1, diamond.Successors)); !reflect.DeepEqual(got, []int{1, 2, 4, 5, 3}) {
+		t.Errorf("Expected [1 2 4 5 3], got %v", got)
+	}
+	if got := slices.Collect(DFS(1, cyclic.Successors)); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected [1 2 3 4], got %v", got)
+	}
+
+	var reads []int
+	successors := func(node int) []int {
+		reads = append(reads, node)
+		return diamond[node]
+	}
+	for node := range DFS(1, successors) {
+		if node == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(reads, []int{1}) {
+		t.Errorf("Expected only the successors of 1 to be read, got %v", reads)
+	}
+}
+
+func TestBFS(t *testing.T) {
+	if got := slices.Collect(BFS(1, diamond.Successors)); !reflect.DeepEqual(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected [1 2 3 4 5], got %v", got)
+	}
+	for node := range BFS(1, diamond.Successors) {
+		if node == 3 {
+			break
+		}
+	}
+}
+
+func TestBFSWithin(t *testing.T) {
+	for _, maxDepth := range []int{0, 1, 2} {
+		got := slices.Collect(BFSWithin(1, maxDepth, diamond.Successors))
+		want := append([]int{1}, diamond.Closure(1, maxDepth)...)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("With depth %d, expected %v, got %v", maxDepth, want, got)
+		}
+	}
+}
+
+func TestTopoOrder(t *testing.T) {
+	got := slices.Collect(TopoOrder([]int{3, 1}, diamond.Successors))
+	if !reflect.DeepEqual(got, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("Expected [5 4 3 2 1], got %v", got)
+	}
+	if got := slices.Collect(TopoOrder([]int{1}, cyclic.Successors)); !reflect.DeepEqual(got, []int{4, 3, 2, 1}) {
+		t.Errorf("Expected [4 3 2 1], got %v", got)
+	}
+	for range TopoOrder([]int{1}, diamond.Successors) {
+		break
+	}
+}
@@ -0,0 +1,134 @@
+package dag
+
+import "iter"
+
+// WalkPaths visits the nodes reachable from root, root first, in depth-first
+// order, passing the path from root to the node. The path is only valid
+// during the call. Every node is visited once, unless maxDepth is not 0: nodes
+// more than maxDepth edges away are then not visited, and a node visited
+// through a path longer than a later one is visited again through the
+// shorter one, so that the nodes beyond it within maxDepth edges are visited
+// too. The walk stops when visit returns false.
+func (g Graph[K]) WalkPaths(root K, maxDepth int, visit func(path []K) bool) {
+	// depth holds the number of edges of the shortest path a node was
+	// visited through.
+	depth := map[K]int{root: 0}
+	stack := []frame[K]{{node: root}}
+	path := []K{root}
+	if !visit(path) {
+		return
+	}
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		succs := g[top.node]
+		if top.next >= len(succs) {
+			stack = stack[:len(stack)-1]
+			path = path[:len(path)-1]
+			continue
+		}
+
+		succ := succs[top.next]
+		top.next++
+		if maxDepth > 0 && len(stack) > maxDepth {
+			continue
+		}
+		if d, seen := depth[succ]; seen && (maxDepth == 0 || d <= len(stack)) {
+			continue
+		}
+
+		depth[succ] = len(stack)
+		stack = append(stack, frame[K]{node: succ})
+		path = append(path, succ)
+		if !visit(path) {
+			return
+		}
+	}
+}
+
+// Branches returns an iterator over the paths from root down to a node
+// without successors, in depth-first order. When maxDepth is not 0, paths are
+// cut maxDepth edges below root. Paths running into a cycle are skipped. Their
+// number can grow exponentially with the size of the graph, so callers stop
+// the iteration when they have enough of them. Every path is a new slice.
+func (g Graph[K]) Branches(root K, maxDepth int) iter.Seq[[]K] {
+	return func(yield func([]K) bool) {
+		if len(g[root]) == 0 {
+			yield([]K{root})
+			return
+		}
+
+		onPath := map[K]bool{root: true}
+		stack := []frame[K]{{node: root}}
+		path := []K{root}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			succs := g[top.node]
+			if top.next >= len(succs) || (maxDepth > 0 && len(path) > maxDepth) {
+				onPath[top.node] = false
+				stack = stack[:len(stack)-1]
+				path = path[:len(path)-1]
+				continue
+			}
+
+			succ := succs[top.next]
+			top.next++
+			if onPath[succ] {
+				continue
+			}
+
+			onPath[succ] = true
+			stack = append(stack, frame[K]{node: succ})
+			path = append(path, succ)
+			if len(g[succ]) == 0 || (maxDepth > 0 && len(path) > maxDepth) {
+				if !yield(append([]K(nil), path...)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Paths returns an iterator over the simple paths from one node to another,
+// in depth-first order. Paths longer than maxDepth edges are skipped unless
+// maxDepth is 0. When within is not nil, only the nodes it accepts are
+// descended into, which prunes the search to the nodes known to reach to.
+// Every path is a new slice.
+func (g Graph[K]) Paths(from, to K, maxDepth int, within func(K) bool) iter.Seq[[]K] {
+	return func(yield func([]K) bool) {
+		if from == to {
+			yield([]K{from})
+			return
+		}
+
+		onPath := map[K]bool{from: true}
+		stack := []frame[K]{{node: from}}
+		path := []K{from}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			succs := g[top.node]
+			if top.next >= len(succs) || (maxDepth > 0 && len(path) > maxDepth) {
+				onPath[top.node] = false
+				stack = stack[:len(stack)-1]
+				path = path[:len(path)-1]
+				continue
+			}
+
+			succ := succs[top.next]
+			top.next++
+			if onPath[succ] || (within != nil && !within(succ)) {
+				continue
+			}
+			if succ == to {
+				if !yield(append(append([]K(nil), path...), succ)) {
+					return
+				}
+				continue
+			}
+
+			onPath[succ] = true
+			stack = append(stack, frame[K]{node: succ})
+			path = append(path, succ)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dag
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestWalkPaths(t *testing.T) {
+	var paths [][]int
+	diamond.WalkPaths(1, 0, func(path []int) bool {
+		paths = append(paths, slices.Clone(path))
+		return true
+	})
+	want := [][]int{{1}, {1, 2}, {1, 2, 4}, {1, 2, 4, 5}, {1, 3}}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected %v, got %v", want, paths)
+	}
+
+	paths = nil
+	diamond.WalkPaths(1, 1, func(path []int) bool {
+		paths = append(paths, slices.Clone(path))
+		return true
+	})
+	if want := [][]int{{1}, {1, 2}, {1, 3}}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected %v within one edge, got %v", want, paths)
+	}
+
+	paths = nil
+	shortcut.WalkPaths(1, 2, func(path []int) bool {
+		paths = append(paths, slices.Clone(path))
+		return true
+	})
+	if want := [][]int{{1}, {1, 2}, {1, 2, 3}, {1, 3}, {1, 3, 4}}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected %v within two edges, got %v", want, paths)
+	}
+
+	paths = nil
+	diamond.WalkPaths(1, 0, func(path []int) bool {
+		paths = append(paths, slices.Clone(path))
+		return len(paths) < 2
+	})
+	if want := [][]int{{1}, {1, 2}}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected the walk to stop after %v, got %v", want, paths)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	want := [][]int{{1, 2, 4, 5}, {1, 3, 4, 5}}
+	if got := slices.Collect(diamond.Branches(1, 0)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := slices.Collect(diamond.Branches(1, 1)); !reflect.DeepEqual(got, [][]int{{1, 2}, {1, 3}}) {
+		t.Errorf("Expected branches cut after one edge, got %v", got)
+	}
+	if got := slices.Collect(diamond.Branches(5, 0)); !reflect.DeepEqual(got, [][]int{{5}}) {
+		t.Errorf("Expected a single branch, got %v", got)
+	}
+	if got := slices.Collect(cyclic.Branches(1, 0)); !reflect.DeepEqual(got, [][]int{{1, 2, 3, 4}}) {
+		t.Errorf("Expected the branch into the cycle to be skipped, got %v", got)
+	}
+	for range diamond.Branches(1, 0) {
+		break
+	}
+}
+
+func TestPaths(t *testing.T) {
+	got := slices.Collect(diamond.Paths(1, 4, 0, nil))
+	want := [][]int{{1, 2, 4}, {1, 3, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	got = slices.Collect(diamond.Paths(1, 4, 0, func(node int) bool { return node != 3 }))
+	if !reflect.DeepEqual(got, [][]int{{1, 2, 4}}) {
+		t.Errorf("Expected the path through 3 to be pruned, got %v", got)
+	}
+
+	if got := slices.Collect(diamond.Paths(1, 5, 2, nil)); got != nil {
+		t.Errorf("Expected no path within two edges, got %v", got)
+	}
+	if got := slices.Collect(diamond.Paths(2, 2, 0, nil)); !reflect.DeepEqual(got, [][]int{{2}}) {
+		t.Errorf("Expected [[2]], got %v", got)
+	}
+
+	for range diamond.Paths(1, 4, 0, nil) {
+		break
+	}
+}
@@ -0,0 +1,216 @@
+// Package graphql serves the resolver's catalog as a GraphQL API, so clients
+// can fetch resources, their dependency edges and computed fields in a single
+// query.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/jjuliano/runner/pkg/resolver"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxQueryDepth bounds the nesting of queries, which could otherwise walk the
+// dependency graph over and over.
+const maxQueryDepth = 12
+
+// maxBodySize bounds the size of a request, and maxResults the number of
+// resources a query returns, counting the resources of every nested list:
+// a query listing transitive edges at every level of its nesting grows
+// exponentially with it.
+const (
+	maxBodySize = 1 << 20
+	maxResults  = 10000
+)
+
+type handler struct {
+	dr         *resolver.DependencyResolver
+	schema     *graphql.Schema
+	maxResults int
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler returns an http.Handler answering GraphQL queries over the
+// resolver's catalog, sent as JSON in the body of a POST request. Every query
+// is answered from a snapshot of the catalog.
+func NewHandler(dr *resolver.DependencyResolver) http.Handler {
+	return &handler{
+		dr:         dr,
+		schema:     graphql.MustParseSchema(schema, &query{}, graphql.MaxDepth(maxQueryDepth)),
+		maxResults: maxResults,
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req request
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request larger than %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), viewKey{}, &view{snapshot: h.dr.Snapshot(), ids: h.dr.IDs, maxResults: h.maxResults})
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.dr.LoggerOrDefault().Error("Failed to encode response", "err", err)
+	}
+}
+
+type viewKey struct{}
+
+// view is the snapshot a query is answered from, with the ID policy its
+// arguments are normalized by, the depths of its resources computed on first
+// use, and the number of resources the query returned so far.
+type view struct {
+	snapshot   *resolver.Snapshot
+	ids        resolver.IDPolicy
+	depthsOnce sync.Once
+	depths     map[string]int
+	maxResults int
+	results    atomic.Int64
+}
+
+func viewOf(ctx context.Context) *view {
+	return ctx.Value(viewKey{}).(*view)
+}
+
+func (v *view) depth(id string) int32 {
+	v.depthsOnce.Do(func() { v.depths = v.snapshot.Depths() })
+	return int32(v.depths[id])
+}
+
+// count adds n resources to the results of the query, failing once they
+// exceed maxResults.
+func (v *view) count(n int) error {
+	if v.results.Add(int64(n)) > int64(v.maxResults) {
+		return fmt.Errorf("the query returns more than %d resources, select fewer or shallower edges", v.maxResults)
+	}
+	return nil
+}
+
+// resources returns the resolvers of the given IDs, skipping the ones
+// missing from the catalog.
+func (v *view) resources(ids []string) ([]*resource, error) {
+	if err := v.count(len(ids)); err != nil {
+		return nil, err
+	}
+	resources := make([]*resource, 0, len(ids))
+	for _, id := range ids {
+		if entry, ok := v.snapshot.Resource(id); ok {
+			resources = append(resources, &resource{entry: entry, view: v})
+		}
+	}
+	return resources, nil
+}
+
+type query struct{}
+
+func (q *query) Resources(ctx context.Context, args struct{ Category *string }) ([]*resource, error) {
+	v := viewOf(ctx)
+	var resources []*resource
+	for _, entry := range v.snapshot.Resources() {
+		if args.Category == nil || entry.Category == *args.Category {
+			resources = append(resources, &resource{entry: entry, view: v})
+		}
+	}
+	if err := v.count(len(resources)); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (q *query) Resource(ctx context.Context, args struct{ Id graphql.ID }) *resource {
+	v := viewOf(ctx)
+	entry, ok := v.snapshot.Resource(v.ids.ID(string(args.Id)))
+	if !ok {
+		return nil
+	}
+	return &resource{entry: entry, view: v}
+}
+
+type resource struct {
+	entry resolver.ResourceNodeEntry
+	view  *view
+}
+
+// edgeArgs selects the direct edges of a resource, or its whole closure when
+// Transitive is set, limited to Depth levels unless it is 0.
+type edgeArgs struct {
+	Transitive bool
+	Depth      int32
+}
+
+func (r *resource) Id() graphql.ID     { return graphql.ID(r.entry.Id) }
+func (r *resource) Name() string       { return r.entry.Name }
+func (r *resource) Desc() string       { return r.entry.Desc }
+func (r *resource) Category() string   { return r.entry.Category }
+func (r *resource) Version() string    { return r.entry.Version }
+func (r *resource) License() string    { return r.entry.License }
+func (r *resource) Owners() []string   { return nonNil(r.entry.Owners) }
+func (r *resource) Aliases() []string  { return nonNil(r.entry.Aliases) }
+func (r *resource) Requires() []string { return nonNil(r.entry.Requires) }
+func (r *resource) Deprecated() bool   { return r.entry.Deprecated }
+
+func (r *resource) ReplacedBy() *string {
+	if r.entry.ReplacedBy == "" {
+		return nil
+	}
+	return &r.entry.ReplacedBy
+}
+
+func (r *resource) Dependencies(args edgeArgs) ([]*resource, error) {
+	if !args.Transitive {
+		return r.view.resources(r.view.snapshot.DirectDependencies(r.entry.Id))
+	}
+	return r.view.resources(r.view.snapshot.DependenciesWithin(r.entry.Id, max(int(args.Depth), 0)))
+}
+
+func (r *resource) Dependents(args edgeArgs) ([]*resource, error) {
+	if !args.Transitive {
+		return r.view.resources(r.view.snapshot.ReverseDependencies(r.entry.Id))
+	}
+	return r.view.resources(r.view.snapshot.DependentsWithin(r.entry.Id, max(int(args.Depth), 0)))
+}
+
+func (r *resource) ClosureSize() int32 {
+	return int32(len(r.view.snapshot.DependenciesWithin(r.entry.Id, 0)))
+}
+
+func (r *resource) Depth() int32 {
+	return r.view.depth(r.entry.Id)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
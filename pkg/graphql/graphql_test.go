@@ -0,0 +1,231 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+)
+
+func setupTestServer(t *testing.T) *httptest.Server {
+	return setupTestServerWith(t, maxResults)
+}
+
+// setupTestServerWith serves the test catalog, answering queries returning at
+// most limit resources.
+func setupTestServerWith(t *testing.T, limit int) *httptest.Server {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.Resources = []resolver.ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs"},
+		{Id: "tag", Name: "Tag", Category: "tools", Requires: []string{"git"}},
+		{Id: "organize", Name: "Organize", Category: "tools", Requires: []string{"tag"}, Deprecated: true, ReplacedBy: "tag"},
+	}
+	dr.BuildDependencies()
+
+	h := NewHandler(dr).(*handler)
+	h.maxResults = limit
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// execute posts a query and decodes the data of the response into v,
+// returning the errors of the response.
+func execute(t *testing.T, server *httptest.Server, query string, v interface{}) []string {
+	body, _ := json.Marshal(request{Query: query})
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("Failed to post query: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	var errs []string
+	for _, e := range result.Errors {
+		errs = append(errs, e.Message)
+	}
+	if v != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, v); err != nil {
+			t.Fatalf("Failed to decode data: %v", err)
+		}
+	}
+	return errs
+}
+
+func TestResources(t *testing.T) {
+	server := setupTestServer(t)
+
+	var data struct {
+		Resources []struct {
+			Id         string
+			ReplacedBy *string
+		}
+	}
+	if errs := execute(t, server, `{ resources(category: "tools") { id replacedBy } }`, &data); errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(data.Resources) != 2 || data.Resources[0].Id != "tag" || data.Resources[0].ReplacedBy != nil ||
+		data.Resources[1].ReplacedBy == nil || *data.Resources[1].ReplacedBy != "tag" {
+		t.Errorf("Unexpected resources: %+v", data.Resources)
+	}
+}
+
+func TestResource_Edges(t *testing.T) {
+	server := setupTestServer(t)
+
+	var data struct {
+		Resource struct {
+			Name         string
+			Dependencies []struct{ Id string }
+			Closure      []struct{ Id string }
+			Dependents   []struct{ Id string }
+			ClosureSize  int
+			Depth        int
+		}
+	}
+	errs := execute(t, server, `{
+		resource(id: "tag") {
+			name
+			dependencies { id }
+			closure: dependencies(transitive: true) { id }
+			dependents { id }
+			closureSize
+			depth
+		}
+	}`, &data)
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	r := data.Resource
+	if r.Name != "Tag" || r.ClosureSize != 1 || r.Depth != 1 {
+		t.Errorf("Unexpected resource: %+v", r)
+	}
+	if !reflect.DeepEqual(r.Dependencies, []struct{ Id string }{{"git"}}) ||
+		!reflect.DeepEqual(r.Closure, []struct{ Id string }{{"git"}}) ||
+		!reflect.DeepEqual(r.Dependents, []struct{ Id string }{{"organize"}}) {
+		t.Errorf("Unexpected edges: %+v", r)
+	}
+}
+
+func TestResource_Transitive(t *testing.T) {
+	server := setupTestServer(t)
+
+	var data struct {
+		Resource struct {
+			All     []struct{ Id string }
+			Limited []struct{ Id string }
+			Depth   int
+		}
+	}
+	errs := execute(t, server, `{
+		resource(id: "organize") {
+			all: dependencies(transitive: true) { id }
+			limited: dependencies(transitive: true, depth: 1) { id }
+			depth
+		}
+	}`, &data)
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(data.Resource.All) != 2 || len(data.Resource.Limited) != 1 || data.Resource.Depth != 2 {
+		t.Errorf("Unexpected resource: %+v", data.Resource)
+	}
+}
+
+func TestResource_NotFound(t *testing.T) {
+	server := setupTestServer(t)
+
+	var data struct {
+		Resource *struct{ Id string }
+	}
+	if errs := execute(t, server, `{ resource(id: "unknown") { id } }`, &data); errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if data.Resource != nil {
+		t.Errorf("Expected no resource, got %+v", data.Resource)
+	}
+}
+
+func TestResource_CaseInsensitive(t *testing.T) {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.IDs.CaseInsensitive = true
+	dr.AddResourceEntry(resolver.ResourceNodeEntry{Id: "DB"})
+	server := httptest.NewServer(NewHandler(dr))
+	t.Cleanup(server.Close)
+
+	var data struct{ Resource *struct{ Id string } }
+	if errs := execute(t, server, `{ resource(id: "DB") { id } }`, &data); errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if data.Resource == nil || data.Resource.Id != "db" {
+		t.Errorf("Expected the resource under its normalized ID, got %+v", data.Resource)
+	}
+}
+
+func TestInvalidQuery(t *testing.T) {
+	server := setupTestServer(t)
+
+	if errs := execute(t, server, `{ resources { unknown } }`, nil); len(errs) == 0 {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestResultLimit(t *testing.T) {
+	server := setupTestServerWith(t, 5)
+
+	if errs := execute(t, server, `{ resources { id dependencies { id } } }`, nil); errs != nil {
+		t.Fatalf("Unexpected errors within the limit: %v", errs)
+	}
+	errs := execute(t, server, `{ resources { id dependents(transitive: true) { id dependencies(transitive: true) { id } } } }`, nil)
+	if len(errs) == 0 || !strings.Contains(errs[0], "more than 5 resources") {
+		t.Errorf("Expected the query to exceed the limit, got %v", errs)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	server := setupTestServer(t)
+
+	body := `{"query": "` + strings.Repeat(" ", maxBodySize) + `{ resources { id } }"}`
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to post query: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+	summaryType = "summary"
+)
+
+// Registry holds a set of metrics and renders them in the Prometheus text
+// exposition format.
+type Registry struct {
+	mu         sync.Mutex
+	metrics    []*Metric
+	collectors []func()
+	runtime    bool
+}
+
+// Metric is a counter, gauge or summary, optionally partitioned by labels.
+type Metric struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+
+	mu      sync.Mutex
+	samples map[string]*sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+	count  uint64
+}
+
+// Default is the registry used by the resolver and served by `runner serve`.
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounter registers a monotonically increasing counter.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Metric {
+	return r.register(name, help, counterType, labelNames)
+}
+
+// NewGauge registers a gauge that can be set to arbitrary values.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Metric {
+	return r.register(name, help, gaugeType, labelNames)
+}
+
+// NewSummary registers a summary tracking the sum and count of observations.
+func (r *Registry) NewSummary(name, help string, labelNames ...string) *Metric {
+	return r.register(name, help, summaryType, labelNames)
+}
+
+func (r *Registry) register(name, help, kind string, labelNames []string) *Metric {
+	m := &Metric{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		samples:    make(map[string]*sample),
+	}
+	r.mu.Lock()
+	r.metrics = append(r.metrics, m)
+	r.mu.Unlock()
+	return m
+}
+
+// Inc increments a counter by one.
+func (m *Metric) Inc(labels ...string) {
+	m.Add(1, labels...)
+}
+
+// Add adds the given value to a counter or gauge.
+func (m *Metric) Add(v float64, labels ...string) {
+	m.update(labels, func(s *sample) { s.value += v })
+}
+
+// Set sets a gauge to the given value.
+func (m *Metric) Set(v float64, labels ...string) {
+	m.update(labels, func(s *sample) { s.value = v })
+}
+
+// Observe records a single observation in a summary.
+func (m *Metric) Observe(v float64, labels ...string) {
+	m.update(labels, func(s *sample) {
+		s.value += v
+		s.count++
+	})
+}
+
+// Value returns the current value of the sample with the given labels. For
+// summaries this is the sum of all observations.
+func (m *Metric) Value(labels ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.samples[strings.Join(labels, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (m *Metric) update(labels []string, fn func(*sample)) {
+	if len(labels) != len(m.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d labels, got %d", m.name, len(m.labelNames), len(labels)))
+	}
+
+	key := strings.Join(labels, "\xff")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.samples[key]
+	if !ok {
+		s = &sample{labels: append([]string(nil), labels...)}
+		m.samples[key] = s
+	}
+	fn(s)
+}
+
+// OnCollect registers a function called every time the registry is written,
+// before writing its metrics, to update metrics sampled on demand.
+func (r *Registry) OnCollect(collect func()) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, collect)
+	r.mu.Unlock()
+}
+
+// WriteText writes every metric of the registry in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]*Metric(nil), r.metrics...)
+	collectors := append([]func(){}, r.collectors...)
+	r.mu.Unlock()
+
+	for _, collect := range collectors {
+		collect()
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler serving the registry's metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		r.WriteText(w)
+	})
+}
+
+func (m *Metric) writeText(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+
+	keys := make([]string, 0, len(m.samples))
+	for key := range m.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := m.samples[key]
+		labels := m.formatLabels(s.labels)
+		if m.kind == summaryType {
+			fmt.Fprintf(w, "%s_sum%s %s\n", m.name, labels, formatValue(s.value))
+			fmt.Fprintf(w, "%s_count%s %d\n", m.name, labels, s.count)
+		} else {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, labels, formatValue(s.value))
+		}
+	}
+}
+
+func (m *Metric) formatLabels(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(values))
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i, value := range values {
+		pairs[i] = m.labelNames[i] + `="` + replacer.Replace(value) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+	runs := registry.NewCounter("runs_total", "Total runs.", "resource")
+	size := registry.NewGauge("graph_resources", "Resources in the graph.")
+	duration := registry.NewSummary("duration_seconds", "Run duration.")
+
+	runs.Inc("b")
+	runs.Inc("a")
+	runs.Add(2, "a")
+	size.Set(26)
+	duration.Observe(0.5)
+	duration.Observe(1.5)
+
+	var output strings.Builder
+	if err := registry.WriteText(&output); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+
+	expectedOutput := `# HELP runs_total Total runs.
+# TYPE runs_total counter
+runs_total{resource="a"} 3
+runs_total{resource="b"} 1
+# HELP graph_resources Resources in the graph.
+# TYPE graph_resources gauge
+graph_resources 26
+# HELP duration_seconds Run duration.
+# TYPE duration_seconds summary
+duration_seconds_sum 2
+duration_seconds_count 2
+`
+	if output.String() != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output.String())
+	}
+	if runs.Value("a") != 3 {
+		t.Errorf("Expected value 3, got %v", runs.Value("a"))
+	}
+}
+
+func TestWriteText_EscapesLabels(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("failures_total", "Failures.", "resource").Inc("a\"b")
+
+	var output strings.Builder
+	registry.WriteText(&output)
+
+	if !strings.Contains(output.String(), `failures_total{resource="a\"b"} 1`) {
+		t.Errorf("Expected escaped label, got:\n%s", output.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewGauge("up", "Whether the server is up.").Set(1)
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(recorder.Body)
+	if !strings.Contains(string(body), "up 1\n") {
+		t.Errorf("Expected metrics output, got:\n%s", body)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Unexpected content type %s", contentType)
+	}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// RegisterRuntime registers gauges sampling the Go runtime when the registry
+// is written: goroutines, heap and memory obtained from the OS, garbage
+// collections and uptime. It lets operators of a long-running server watch
+// the memory growth of a large catalog. Calling it more than once registers
+// them once.
+func (r *Registry) RegisterRuntime() {
+	r.mu.Lock()
+	registered := r.runtime
+	r.runtime = true
+	r.mu.Unlock()
+	if registered {
+		return
+	}
+
+	start := time.Now()
+	goroutines := r.NewGauge("go_goroutines", "Number of goroutines that currently exist.")
+	heapAlloc := r.NewGauge("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.")
+	heapObjects := r.NewGauge("go_memstats_heap_objects", "Number of allocated heap objects.")
+	heapInuse := r.NewGauge("go_memstats_heap_inuse_bytes", "Number of heap bytes in in-use spans.")
+	sys := r.NewGauge("go_memstats_sys_bytes", "Number of bytes obtained from the OS.")
+	gcCycles := r.NewGauge("go_gc_cycles_total", "Number of completed garbage collection cycles.")
+	gcPause := r.NewGauge("go_gc_pause_seconds_total", "Total time spent in garbage collection pauses.")
+	uptime := r.NewGauge("process_uptime_seconds", "Time since the runtime metrics were registered.")
+
+	r.OnCollect(func() {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		goroutines.Set(float64(runtime.NumGoroutine()))
+		heapAlloc.Set(float64(stats.HeapAlloc))
+		heapObjects.Set(float64(stats.HeapObjects))
+		heapInuse.Set(float64(stats.HeapInuse))
+		sys.Set(float64(stats.Sys))
+		gcCycles.Set(float64(stats.NumGC))
+		gcPause.Set(time.Duration(stats.PauseTotalNs).Seconds())
+		uptime.Set(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterRuntime(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRuntime()
+	registry.RegisterRuntime()
+
+	var output strings.Builder
+	if err := registry.WriteText(&output); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	for _, name := range []string{"go_goroutines", "go_memstats_heap_alloc_bytes", "go_memstats_sys_bytes", "go_gc_cycles_total", "process_uptime_seconds"} {
+		if count := strings.Count(output.String(), "# TYPE "+name+" "); count != 1 {
+			t.Errorf("Expected %s to be registered once, got %d", name, count)
+		}
+	}
+	if strings.Contains(output.String(), "go_memstats_heap_alloc_bytes 0\n") {
+		t.Errorf("Expected the runtime to be sampled, got:\n%s", output.String())
+	}
+}
+
+func TestOnCollect(t *testing.T) {
+	registry := NewRegistry()
+	gauge := registry.NewGauge("samples", "Number of samples.")
+	registry.OnCollect(func() { gauge.Add(1) })
+
+	var output strings.Builder
+	registry.WriteText(&output)
+	registry.WriteText(&output)
+	if gauge.Value() != 2 {
+		t.Errorf("Expected the collector to run on every write, got %v", gauge.Value())
+	}
+}
@@ -0,0 +1,177 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/mattn/go-isatty"
+)
+
+// tickInterval is how often the elapsed time of running resources refreshes.
+const tickInterval = 100 * time.Millisecond
+
+type eventMsg resolver.Event
+
+type tickMsg time.Time
+
+// Model is the bubbletea model of the progress view of a run.
+type Model struct {
+	bar      progress.Model
+	total    int
+	done     int
+	running  map[string]time.Time
+	now      time.Time
+	cancel   context.CancelFunc
+	quitting bool
+}
+
+// New creates a progress view calling cancel when the user interrupts it.
+func New(cancel context.CancelFunc) Model {
+	return Model{
+		bar:     progress.New(progress.WithDefaultGradient(), progress.WithWidth(40)),
+		running: make(map[string]time.Time),
+		now:     time.Now(),
+		cancel:  cancel,
+	}
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(tickInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.cancel()
+		}
+	case tickMsg:
+		m.now = time.Time(msg)
+		return m, tick()
+	case eventMsg:
+		return m.handleEvent(resolver.Event(msg))
+	}
+	return m, nil
+}
+
+func (m Model) handleEvent(event resolver.Event) (tea.Model, tea.Cmd) {
+	m.now = event.Time
+	switch event.Type {
+	case resolver.RunStarted:
+		m.total = len(event.Resources)
+	case resolver.ResourceStarted:
+		m.running[event.Resource] = event.Time
+	case resolver.ResourceFinished, resolver.ResourceFailed, resolver.ResourceSkipped:
+		delete(m.running, event.Resource)
+		m.done++
+		return m, tea.Println(FormatEvent(event))
+	case resolver.RunCompleted:
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// View implements tea.Model.
+func (m Model) View() string {
+	if m.quitting || m.total == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	percent := float64(m.done) / float64(m.total)
+	fmt.Fprintf(&b, "%s %d/%d\n", m.bar.ViewAs(percent), m.done, m.total)
+
+	ids := make([]string, 0, len(m.running))
+	for id := range m.running {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(&b, "⏳ %s %s\n", id, m.now.Sub(m.running[id]).Round(time.Second))
+	}
+	return b.String()
+}
+
+// FormatEvent formats an event as a single line, or returns an empty string
+// for events without one.
+func FormatEvent(event resolver.Event) string {
+	elapsed := event.Duration.Round(time.Millisecond)
+	switch event.Type {
+	case resolver.ResourceStarted:
+		return "▶️  " + event.Resource
+	case resolver.ResourceFinished:
+		return fmt.Sprintf("✅ %s (%s)", event.Resource, elapsed)
+	case resolver.ResourceFailed:
+		return fmt.Sprintf("❌ %s (%s): %v", event.Resource, elapsed, event.Err)
+	case resolver.ResourceSkipped:
+		return "⏭️  " + event.Resource + " (skipped)"
+	}
+	return ""
+}
+
+// Run calls fn while displaying the progress of the run of dr on the
+// terminal. When stderr is not a terminal, the progress is logged line by
+// line through the resolver's logger instead. Interrupting the view cancels
+// the context passed to fn. It returns the error of fn, else the error of the
+// progress view.
+func Run(ctx context.Context, dr *resolver.DependencyResolver, fn func(ctx context.Context) error) error {
+	if !isatty.IsTerminal(os.Stderr.Fd()) {
+		unsubscribe := dr.OnEvent(func(event resolver.Event) {
+			if line := FormatEvent(event); line != "" {
+				dr.Logger.Info(line)
+			}
+		})
+		defer unsubscribe()
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	program := tea.NewProgram(New(cancel), tea.WithOutput(os.Stderr), tea.WithContext(ctx))
+	unsubscribe := dr.OnEvent(func(event resolver.Event) {
+		program.Send(eventMsg(event))
+	})
+	defer unsubscribe()
+
+	stepOutput := dr.StepOutput
+	dr.StepOutput = printer{program}
+	defer func() { dr.StepOutput = stepOutput }()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := program.Run()
+		done <- err
+	}()
+
+	err := fn(ctx)
+	program.Quit()
+	if viewErr := <-done; err == nil && viewErr != nil {
+		err = fmt.Errorf("error displaying the progress: %w", viewErr)
+	}
+	return err
+}
+
+// printer writes above the progress view.
+type printer struct {
+	program *tea.Program
+}
+
+func (p printer) Write(data []byte) (int, error) {
+	p.program.Println(strings.TrimSuffix(string(data), "\n"))
+	return len(data), nil
+}
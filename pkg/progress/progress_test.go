@@ -0,0 +1,102 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+)
+
+func send(m tea.Model, events ...resolver.Event) tea.Model {
+	for _, event := range events {
+		m, _ = m.Update(eventMsg(event))
+	}
+	return m
+}
+
+func TestModel_View(t *testing.T) {
+	start := time.Now()
+	m := send(New(func() {}),
+		resolver.Event{Type: resolver.RunStarted, Resources: []string{"git", "tag", "organize"}, Time: start},
+		resolver.Event{Type: resolver.ResourceStarted, Resource: "git", Time: start},
+		resolver.Event{Type: resolver.ResourceFinished, Resource: "git", Time: start.Add(time.Second)},
+		resolver.Event{Type: resolver.ResourceStarted, Resource: "tag", Time: start.Add(time.Second)},
+	)
+	m, _ = m.Update(tickMsg(start.Add(4 * time.Second)))
+
+	view := m.View()
+	for _, expected := range []string{"1/3", "⏳ tag 3s"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in the view, got %q", expected, view)
+		}
+	}
+	if strings.Contains(view, "⏳ git") {
+		t.Errorf("Expected finished resources not to be listed as running, got %q", view)
+	}
+
+	m, cmd := m.Update(eventMsg(resolver.Event{Type: resolver.RunCompleted}))
+	if cmd == nil || m.View() != "" {
+		t.Error("Expected the view to quit once the run completes")
+	}
+}
+
+func TestModel_Interrupt(t *testing.T) {
+	cancelled := false
+	m := New(func() { cancelled = true })
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !cancelled {
+		t.Error("Expected ctrl+c to cancel the run")
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	tests := []struct {
+		event    resolver.Event
+		expected string
+	}{
+		{resolver.Event{Type: resolver.ResourceStarted, Resource: "git"}, "▶️  git"},
+		{resolver.Event{Type: resolver.ResourceFinished, Resource: "git", Duration: 1500 * time.Millisecond}, "✅ git (1.5s)"},
+		{resolver.Event{Type: resolver.ResourceFailed, Resource: "git", Duration: time.Second, Err: errors.New("exit status 1")}, "❌ git (1s): exit status 1"},
+		{resolver.Event{Type: resolver.ResourceSkipped, Resource: "git"}, "⏭️  git (skipped)"},
+		{resolver.Event{Type: resolver.RunCompleted}, ""},
+	}
+	for _, tt := range tests {
+		if got := FormatEvent(tt.event); got != tt.expected {
+			t.Errorf("Expected %q for %s, got %q", tt.expected, tt.event.Type, got)
+		}
+	}
+}
+
+func TestRun_NotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(&buf)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.Resources = []resolver.ResourceNodeEntry{{Id: "git"}}
+	dr.BuildDependencies()
+
+	err = Run(context.Background(), dr, func(ctx context.Context) error {
+		_, err := dr.Execute(ctx, []string{"git"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "✅ git") {
+		t.Errorf("Expected the progress to be logged, got %q", buf.String())
+	}
+
+	// The progress of later runs is not logged once Run returns.
+	dr.Execute(context.Background(), []string{"git"})
+	if count := strings.Count(buf.String(), "✅ git"); count != 1 {
+		t.Errorf("Expected the listener to be removed, got %d progress lines", count)
+	}
+}
@@ -0,0 +1,100 @@
+package report
+
+import "github.com/jjuliano/runner/pkg/resolver"
+
+// Dimensions of the graph view, in pixels.
+const (
+	nodeWidth   = 160
+	nodeHeight  = 32
+	layerGap    = 80
+	nodeGap     = 16
+	graphMargin = 20
+)
+
+// svgGraph is the dependency graph laid out in layers, with the resources
+// without requirements on the left and every resource to the right of its
+// requirements.
+type svgGraph struct {
+	Width, Height int
+	Nodes         []svgNode
+	Edges         []svgEdge
+}
+
+type svgNode struct {
+	Id   string
+	X, Y int
+}
+
+type svgEdge struct {
+	From, To       string
+	X1, Y1, X2, Y2 int
+}
+
+// layoutGraph places every resource in the layer of the longest requirement
+// chain below it. Requirements within a cycle are ignored for the layout.
+func layoutGraph(dr *resolver.DependencyResolver) svgGraph {
+	resources := dr.ListResources()
+	deps := make(map[string][]string, len(resources))
+	for _, entry := range resources {
+		deps[entry.Id] = dr.DirectDependencies(entry.Id)
+	}
+
+	layers := make(map[string]int, len(resources))
+	visiting := make(map[string]bool)
+	var layer func(id string) int
+	layer = func(id string) int {
+		if l, ok := layers[id]; ok {
+			return l
+		}
+		if visiting[id] {
+			return -1
+		}
+		visiting[id] = true
+		l := 0
+		for _, dep := range deps[id] {
+			if _, known := deps[dep]; known {
+				l = max(l, layer(dep)+1)
+			}
+		}
+		visiting[id] = false
+		layers[id] = l
+		return l
+	}
+
+	var graph svgGraph
+	positions := make(map[string]svgNode, len(resources))
+	rows := make(map[int]int)
+	for _, entry := range resources {
+		if _, ok := positions[entry.Id]; ok {
+			continue
+		}
+		l := layer(entry.Id)
+		node := svgNode{
+			Id: entry.Id,
+			X:  graphMargin + l*(nodeWidth+layerGap),
+			Y:  graphMargin + rows[l]*(nodeHeight+nodeGap),
+		}
+		rows[l]++
+		positions[entry.Id] = node
+		graph.Nodes = append(graph.Nodes, node)
+		graph.Width = max(graph.Width, node.X+nodeWidth+graphMargin)
+		graph.Height = max(graph.Height, node.Y+nodeHeight+graphMargin)
+	}
+
+	for _, edge := range dr.Edges() {
+		from, ok := positions[edge.From]
+		to, known := positions[edge.To]
+		if !ok || !known {
+			continue
+		}
+		graph.Edges = append(graph.Edges, svgEdge{
+			From: edge.From,
+			To:   edge.To,
+			X1:   from.X,
+			Y1:   from.Y + nodeHeight/2,
+			X2:   to.X + nodeWidth,
+			Y2:   to.Y + nodeHeight/2,
+		})
+	}
+	return graph
+}
@@ -0,0 +1,25 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/jjuliano/runner/pkg/resolver"
+)
+
+func TestLayoutGraph(t *testing.T) {
+	dr := setupTestResolver(t)
+	dr.AddResourceEntry(resolver.ResourceNodeEntry{Id: "a", Requires: []string{"b"}})
+	dr.AddResourceEntry(resolver.ResourceNodeEntry{Id: "b", Requires: []string{"a"}})
+
+	graph := layoutGraph(dr)
+	x := make(map[string]int)
+	for _, node := range graph.Nodes {
+		x[node.Id] = node.X
+	}
+	if !(x["git"] < x["tools/lint"] && x["tools/lint"] < x["app"]) {
+		t.Errorf("Expected every resource right of its requirements, got %v", x)
+	}
+	if len(graph.Nodes) != 5 || len(graph.Edges) != 5 {
+		t.Errorf("Expected 5 nodes and 5 edges, got %d and %d", len(graph.Nodes), len(graph.Edges))
+	}
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jjuliano/runner/pkg/resolver"
+)
+
+// uncategorized is the section of the resources without a category.
+const uncategorized = "Uncategorized"
+
+// WriteMarkdown writes a Markdown report of the catalog: an inventory table
+// of the resources, then a section per category, sorted, with the details and
+// dependency tree of each of its resources.
+func WriteMarkdown(dr *resolver.DependencyResolver, w io.Writer) error {
+	resources := dr.ListResources()
+	categories := dr.Categories()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Resource catalog\n\n%d resources in %d categories.\n\n", len(resources), len(categories))
+
+	bw.WriteString("## Inventory\n\n")
+	bw.WriteString("| ID | Name | Version | Category | Description |\n")
+	bw.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, entry := range resources {
+		fmt.Fprintf(bw, "| `%s` | %s | %s | %s | %s |\n", tableCell(entry.Id), tableCell(entry.Name),
+			tableCell(entry.Version), tableCell(entry.Category), tableCell(entry.Desc))
+	}
+
+	sections := categories
+	if len(dr.ResourcesByCategory("")) > 0 {
+		sections = append(sections, uncategorized)
+	}
+	for _, category := range sections {
+		fmt.Fprintf(bw, "\n## %s\n", category)
+		if category == uncategorized {
+			category = ""
+		}
+		for _, entry := range dr.ResourcesByCategory(category) {
+			if err := writeMarkdownResource(bw, dr, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownResource(w *bufio.Writer, dr *resolver.DependencyResolver, entry resolver.ResourceNodeEntry) error {
+	fmt.Fprintf(w, "\n### %s\n\n", entry.Id)
+	if entry.Desc != "" {
+		w.WriteString(entry.Desc + "\n\n")
+	}
+	if entry.Name != "" {
+		fmt.Fprintf(w, "- **Name:** %s\n", entry.Name)
+	}
+	if entry.Version != "" {
+		fmt.Fprintf(w, "- **Version:** %s\n", entry.Version)
+	}
+	fmt.Fprintf(w, "- **Requires:** %s\n", codeList(dr.DirectDependencies(entry.Id)))
+	fmt.Fprintf(w, "- **Required by:** %s\n", codeList(dr.ReverseDependencies(entry.Id)))
+
+	if len(dr.DirectDependencies(entry.Id)) == 0 {
+		return nil
+	}
+	w.WriteString("\n```text\n")
+	if err := dr.WriteDependencyTreeText(w, entry.Id, 0); err != nil {
+		return err
+	}
+	w.WriteString("```\n")
+	return nil
+}
+
+// tableCell escapes the pipes and line breaks of a table cell.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "\n", " ")), " ")
+}
+
+// codeList formats the IDs as a comma-separated list of code spans.
+func codeList(ids []string) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+	return "`" + strings.Join(ids, "`, `") + "`"
+}
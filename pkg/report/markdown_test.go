@@ -0,0 +1,32 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jjuliano/runner/pkg/resolver"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	dr := setupTestResolver(t)
+	dr.UpdateResource(resolver.ResourceNodeEntry{Id: "tools/lint", Name: "Lint | check", Category: "tools", Requires: []string{"git"}})
+
+	var output strings.Builder
+	if err := WriteMarkdown(dr, &output); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedOutput := "# Resource catalog\n\n3 resources in 2 categories.\n\n" +
+		"## Inventory\n\n" +
+		"| ID | Name | Version | Category | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `git` | Git |  | vcs | Version <control> |\n" +
+		"| `tools/lint` | Lint \\| check |  | tools |  |\n" +
+		"| `app` |  |  |  |  |\n" +
+		"\n## tools\n\n### tools/lint\n\n- **Name:** Lint | check\n- **Requires:** `git`\n- **Required by:** `app`\n\n```text\ntools/lint\n└── git\n```\n" +
+		"\n## vcs\n\n### git\n\nVersion <control>\n\n- **Name:** Git\n- **Requires:** none\n- **Required by:** `tools/lint`, `app`\n" +
+		"\n## Uncategorized\n\n### app\n\n- **Requires:** `tools/lint`, `git`\n- **Required by:** none\n\n```text\napp\n├── tools/lint\n│   └── git\n└── git\n```\n"
+	if output.String() != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output.String())
+	}
+}
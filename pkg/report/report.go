@@ -0,0 +1,104 @@
+// Package report generates static reports of a catalog, to share it with
+// people who do not use the CLI.
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/url"
+	"path/filepath"
+
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+var htmlTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"page": pageHref,
+}).ParseFS(templates, "templates/*.html"))
+
+// resourcePage is the data of the page of a resource.
+type resourcePage struct {
+	Title      string
+	Root       string
+	Entry      resolver.ResourceNodeEntry
+	Requires   []string
+	Dependents []string
+	Closure    []string
+}
+
+// indexPage is the data of the resource table.
+type indexPage struct {
+	Title     string
+	Root      string
+	Resources []resolver.ResourceNodeEntry
+}
+
+// graphPage is the data of the graph view.
+type graphPage struct {
+	Title string
+	Root  string
+	Graph svgGraph
+}
+
+// WriteHTML writes a static, self-contained HTML report of the catalog to
+// dir: index.html with a searchable table of the resources, graph.html with
+// an interactive view of the dependency graph, and a page per resource with
+// its dependencies and dependents under resources/.
+func WriteHTML(dr *resolver.DependencyResolver, fs afero.Fs, dir string) error {
+	resources := dr.ListResources()
+	if err := fs.MkdirAll(filepath.Join(dir, "resources"), 0755); err != nil {
+		return fmt.Errorf("error creating report directory %s: %w", dir, err)
+	}
+
+	if err := writePage(fs, filepath.Join(dir, "index.html"), "index.html", indexPage{Title: "Resources", Resources: resources}); err != nil {
+		return err
+	}
+	if err := writePage(fs, filepath.Join(dir, "graph.html"), "graph.html", graphPage{Title: "Dependency graph", Graph: layoutGraph(dr)}); err != nil {
+		return err
+	}
+	for _, entry := range resources {
+		page := resourcePage{
+			Title:      entry.Id,
+			Root:       "../",
+			Entry:      entry,
+			Requires:   dr.DirectDependencies(entry.Id),
+			Dependents: dr.ReverseDependencies(entry.Id),
+			Closure:    dr.TransitiveDependencies(entry.Id),
+		}
+		if err := writePage(fs, filepath.Join(dir, "resources", pageFile(entry.Id)), "resource.html", page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePage(fs afero.Fs, path, name string, data interface{}) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", path, err)
+	}
+	err = htmlTemplates.ExecuteTemplate(f, name, data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// pageFile returns the file name of the page of a resource. The ID is
+// escaped, so IDs containing slashes stay in the directory.
+func pageFile(id string) string {
+	return url.PathEscape(id) + ".html"
+}
+
+// pageHref returns the link to the page of a resource, relative to the
+// resources directory.
+func pageHref(id string) string {
+	return url.PathEscape(pageFile(id))
+}
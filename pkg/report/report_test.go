@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+)
+
+func setupTestResolver(t *testing.T) *resolver.DependencyResolver {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(&bytes.Buffer{})), "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	dr.Resources = []resolver.ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs", Desc: "Version <control>"},
+		{Id: "tools/lint", Name: "Lint", Requires: []string{"git"}},
+		{Id: "app", Requires: []string{"tools/lint", "git"}},
+	}
+	dr.BuildDependencies()
+	return dr
+}
+
+func readFile(t *testing.T, fs afero.Fs, path string) string {
+	t.Helper()
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestWriteHTML(t *testing.T) {
+	dr := setupTestResolver(t)
+	if err := WriteHTML(dr, dr.Fs, "out"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	index := readFile(t, dr.Fs, "out/index.html")
+	for _, expected := range []string{
+		`<a class="id" href="resources/git.html">git</a>`,
+		`<a class="id" href="resources/tools%252Flint.html">tools/lint</a>`,
+		`Version &lt;control&gt;`,
+		`id="search"`,
+	} {
+		if !strings.Contains(index, expected) {
+			t.Errorf("Expected the index to contain %s, got:\n%s", expected, index)
+		}
+	}
+	if strings.Contains(index, "<link") || strings.Contains(index, "<script src") {
+		t.Error("Expected the report to be self-contained")
+	}
+
+	page := readFile(t, dr.Fs, "out/resources/tools%2Flint.html")
+	for _, expected := range []string{
+		`<h1>tools/lint</h1>`,
+		`<h2>Dependencies</h2>
+<ul><li><a class="id" href="git.html">git</a></li></ul>`,
+		`<h2>Dependents</h2>
+<ul><li><a class="id" href="app.html">app</a></li></ul>`,
+		`href="../index.html"`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("Expected the resource page to contain %s, got:\n%s", expected, page)
+		}
+	}
+
+	graph := readFile(t, dr.Fs, "out/graph.html")
+	if !strings.Contains(graph, `<line data-from="app" data-to="tools/lint"`) || !strings.Contains(graph, `data-href="resources/app.html"`) {
+		t.Errorf("Expected the graph view to contain the edges and nodes, got:\n%s", graph)
+	}
+}
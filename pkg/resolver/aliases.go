@@ -0,0 +1,78 @@
+package resolver
+
+import "fmt"
+
+// CurrentIDs returns the IDs, with every former ID among the aliases of a
+// resource replaced by the ID of the resource.
+func (dr *DependencyResolver) CurrentIDs(ids []string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	current := make([]string, len(ids))
+	for i, id := range ids {
+		current[i] = dr.currentID(id)
+	}
+	return current
+}
+
+// currentID returns the ID of the resource with the given ID, or with the
+// given former ID among its aliases. The caller must hold the lock.
+func (dr *DependencyResolver) currentID(id string) string {
+	if dr.resourceIndexOf(id) < 0 {
+		if current := dr.aliasOf(id); current != "" {
+			return current
+		}
+	}
+	return id
+}
+
+// aliasWarnings returns a CodeAliasUsed warning for every requirement of the
+// entries naming a resource by one of its aliases.
+func (dr *DependencyResolver) aliasWarnings(entries []ResourceNodeEntry) []Warning {
+	var warnings []Warning
+	dr.aliasedRequirements(entries, func(entry ResourceNodeEntry, req, id string) {
+		warnings = append(warnings, Warning{
+			Code:     CodeAliasUsed,
+			Resource: entry.Id,
+			File:     dr.sources[entry.Id],
+			Message:  fmt.Sprintf("'%s' requires '%s', a former ID of '%s'", entry.Id, req, id),
+		})
+	})
+	return warnings
+}
+
+// aliasedRequirements calls visit with every requirement of entries naming a
+// resource by one of its aliases, and the ID of the resource.
+func (dr *DependencyResolver) aliasedRequirements(entries []ResourceNodeEntry, visit func(entry ResourceNodeEntry, req, id string)) {
+	for _, entry := range entries {
+		for _, req := range append(entry.Requires[:len(entry.Requires):len(entry.Requires)], entry.Optional...) {
+			if dr.resourceIndexOf(req) >= 0 {
+				continue
+			}
+			if id := dr.aliasOf(req); id != "" {
+				visit(entry, req, id)
+			}
+		}
+	}
+}
+
+// warnAliases logs a warning for every target and requirement of a resolved
+// run naming a resource by one of its aliases. The caller must hold the lock.
+func (dr *DependencyResolver) warnAliases(targets, stack []string) {
+	for _, target := range targets {
+		if dr.resourceIndexOf(target) < 0 {
+			if id := dr.aliasOf(target); id != "" {
+				dr.logger().Warn("Target is a former ID", "target", target, "resource", id)
+			}
+		}
+	}
+
+	entries := make([]ResourceNodeEntry, 0, len(stack))
+	for _, id := range stack {
+		if entry, ok := dr.getResource(id); ok {
+			entries = append(entries, entry)
+		}
+	}
+	dr.aliasedRequirements(entries, func(entry ResourceNodeEntry, req, id string) {
+		dr.logger().Warn("Requirement is a former ID", entryFields(entry, "", "requirement", req, "replacement", id)...)
+	})
+}
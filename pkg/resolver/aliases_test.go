@@ -0,0 +1,93 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func setupAliasesResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "postgres", Aliases: []string{"pg", "db"}},
+		{Id: "api", Requires: []string{"pg"}},
+		{Id: "app", Requires: []string{"api"}, Optional: []string{"db"}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestAliases_Resolve(t *testing.T) {
+	resolver := setupAliasesResolver()
+	var logs bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&logs))
+
+	if err := resolver.CheckRequirements(); err != nil {
+		t.Errorf("Expected aliases to satisfy requirements, got %v", err)
+	}
+	if deps := resolver.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"api", "postgres"}) {
+		t.Errorf("Expected the alias to resolve to the resource, got %v", deps)
+	}
+
+	stack, err := resolver.Resolve(context.Background(), []string{"db"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(stack, []string{"postgres"}) {
+		t.Errorf("Expected the aliased target to resolve, got %v", stack)
+	}
+	if !strings.Contains(logs.String(), "Target is a former ID target=db resource=postgres") {
+		t.Errorf("Expected the aliased target to be logged, got %q", logs.String())
+	}
+
+	logs.Reset()
+	resolver.Resolve(context.Background(), []string{"app"})
+	if !strings.Contains(logs.String(), "Requirement is a former ID resource=api requirement=pg replacement=postgres") {
+		t.Errorf("Expected the aliased requirement to be logged, got %q", logs.String())
+	}
+}
+
+func TestAliases_Warnings(t *testing.T) {
+	resolver := setupAliasesResolver()
+
+	var warnings []Warning
+	for _, w := range resolver.Warnings() {
+		if w.Code == CodeAliasUsed {
+			warnings = append(warnings, w)
+		}
+	}
+	if len(warnings) != 2 || warnings[0].Resource != "api" || warnings[1].Message != "'app' requires 'db', a former ID of 'postgres'" {
+		t.Errorf("Unexpected warnings: %+v", warnings)
+	}
+
+	// A resource ID takes precedence over an alias.
+	resolver.AddResourceEntry(ResourceNodeEntry{Id: "pg"})
+	if deps := resolver.DirectDependencies("api"); !reflect.DeepEqual(deps, []string{"pg"}) {
+		t.Errorf("Expected the resource to win over the alias, got %v", deps)
+	}
+}
+
+func TestAliases_Lookups(t *testing.T) {
+	resolver := setupAliasesResolver()
+
+	if entry, ok := resolver.GetResource("pg"); !ok || entry.Id != "postgres" {
+		t.Errorf("Expected the resource of the former ID, got %+v, %v", entry, ok)
+	}
+	if _, ok := resolver.GetResource("mysql"); ok {
+		t.Error("Expected an unknown ID not to be found")
+	}
+	if ids := resolver.CurrentIDs([]string{"db", "api", "mysql"}); !reflect.DeepEqual(ids, []string{"postgres", "api", "mysql"}) {
+		t.Errorf("Expected the former IDs to be replaced, got %v", ids)
+	}
+	paths, err := resolver.Why("pg", "app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := [][]string{{"app", "api", "postgres"}, {"app", "postgres"}}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected the paths to the resource, got %v", paths)
+	}
+}
@@ -0,0 +1,39 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// annotationData escapes the message of a GitHub Actions workflow command.
+var annotationData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// annotationProperty escapes a property of a GitHub Actions workflow command.
+var annotationProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// WriteGitHubAnnotations writes every finding as a GitHub Actions workflow
+// command, '::error' or '::warning', located at its file, line and column
+// when known, and titled with its code, so that it annotates the file in
+// pull requests:
+//
+//	::error file=catalog.yaml,line=3,col=5,title=unknown-field::unknown field resources[0].colour
+func WriteGitHubAnnotations(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		var properties []string
+		if f.File != "" {
+			properties = append(properties, "file="+annotationProperty.Replace(f.File))
+		}
+		if f.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", f.Line))
+		}
+		if f.Column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", f.Column))
+		}
+		properties = append(properties, "title="+annotationProperty.Replace(string(f.Code)))
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", f.Severity, strings.Join(properties, ","), annotationData.Replace(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	findings := []Finding{
+		{Severity: SeverityError, Warning: Warning{Code: CodeUnknownField, File: "catalog.yaml", Line: 3, Column: 5, Message: "unknown field resources[0].colour"}},
+		{Severity: SeverityWarning, Warning: Warning{Code: CodeMissingOptional, File: "dir,1/a:b.yaml", Message: "100% missing\nreally"}},
+		{Severity: SeverityError, Warning: Warning{Code: CodeUnknownType, Message: "unknown type"}},
+	}
+
+	var output strings.Builder
+	if err := WriteGitHubAnnotations(&output, findings); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "::error file=catalog.yaml,line=3,col=5,title=unknown-field::unknown field resources[0].colour\n" +
+		"::warning file=dir%2C1/a%3Ab.yaml,title=missing-optional::100%25 missing%0Areally\n" +
+		"::error title=unknown-type::unknown type\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
+
+func TestHandleValidateCommand_GitHub(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{{Id: "app", Optional: []string{"docs"}}}
+	resolver.BuildDependencies()
+	resolver.OutputFormat = FormatGitHub
+
+	var err error
+	output := captureOutput(func() { err = resolver.HandleValidateCommand(false, true) })
+	if err == nil || output != "::warning title=missing-optional::optional requirement 'docs' of 'app' is not in the catalog\n" {
+		t.Errorf("Expected a failing warning annotation, got %v: %q", err, output)
+	}
+
+	if err := resolver.HandleIndexCommand(); err == nil || !strings.Contains(err.Error(), "only supported by validate") {
+		t.Errorf("Expected listings to reject the github format, got %v", err)
+	}
+}
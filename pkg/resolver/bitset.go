@@ -0,0 +1,31 @@
+package resolver
+
+// bitset is a set of dense indices, one bit per index.
+type bitset []uint64
+
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+// has reports whether i is in the set. Indices past the end are not.
+func (b bitset) has(i int32) bool {
+	word := int(i) / 64
+	return word < len(b) && b[word]&(1<<(uint(i)%64)) != 0
+}
+
+// set adds i to the set, which must be large enough to hold it.
+func (b bitset) set(i int32) {
+	b[int(i)/64] |= 1 << (uint(i) % 64)
+}
+
+// clear removes i from the set, which must be large enough to hold it.
+func (b bitset) clear(i int32) {
+	b[int(i)/64] &^= 1 << (uint(i) % 64)
+}
+
+// or adds every index of other to the set, which must be at least as large.
+func (b bitset) or(other bitset) {
+	for i, word := range other {
+		b[i] |= word
+	}
+}
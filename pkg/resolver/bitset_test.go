@@ -0,0 +1,22 @@
+package resolver
+
+import "testing"
+
+func TestBitset(t *testing.T) {
+	b := newBitset(130)
+	if len(b) != 3 {
+		t.Fatalf("Expected 3 words for 130 bits, got %d", len(b))
+	}
+	for _, i := range []int32{0, 63, 64, 129} {
+		b.set(i)
+	}
+	for i := int32(0); i < 130; i++ {
+		want := i == 0 || i == 63 || i == 64 || i == 129
+		if b.has(i) != want {
+			t.Errorf("has(%d): expected %v", i, want)
+		}
+	}
+	if b.has(1000) {
+		t.Error("Expected indices past the end not to be in the set")
+	}
+}
@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"sync"
+
+	"github.com/jjuliano/runner/pkg/dag"
+)
+
+// closureCache memoizes results derived from ResourceDependencies, such as
+// transitive closures, the reverse dependency map and the latest snapshot. It is reset whenever the
+// dependencies are rebuilt, so entries never outlive a catalog mutation. Updating
+// resources in place only drops the entries they affect, see updateDependencies.
+type closureCache struct {
+	mu         sync.Mutex
+	closures   map[string][]string
+	dependents map[string][]string
+	reverse    dag.Graph[string]
+	ids        *idGraph
+	snapshot   *Snapshot
+}
+
+func (c *closureCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closures = nil
+	c.dependents = nil
+	c.reverse = nil
+	c.ids = nil
+	c.snapshot = nil
+}
+
+// memoize returns the cached value of key in the map selected by field,
+// computing and storing it on a miss.
+func (c *closureCache) memoize(field *map[string][]string, key string, compute func() []string) []string {
+	c.mu.Lock()
+	if value, ok := (*field)[key]; ok {
+		c.mu.Unlock()
+		return value
+	}
+	c.mu.Unlock()
+
+	value := compute()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if *field == nil {
+		*field = make(map[string][]string)
+	}
+	(*field)[key] = value
+	return value
+}
+
+// reverseDependencies returns the cached inverse of ResourceDependencies.
+// Callers must hold the read lock and must not modify the result.
+func (dr *DependencyResolver) reverseDependencies() dag.Graph[string] {
+	dr.cache.mu.Lock()
+	defer dr.cache.mu.Unlock()
+	if dr.cache.reverse == nil {
+		dr.cache.reverse = dr.graph().Reverse(dr.resourceIDs())
+	}
+	return dr.cache.reverse
+}
@@ -0,0 +1,25 @@
+package resolver
+
+// Catalog is the read side of a resolved catalog: its resources and their
+// dependencies. DependencyResolver implements it in memory, and other
+// backends, such as the SQLite store, implement it for catalogs too large to
+// load at once.
+type Catalog interface {
+	// ListResources returns every resource, in catalog order.
+	ListResources() []ResourceNodeEntry
+	// GetResource returns the resource with the given ID.
+	GetResource(id string) (ResourceNodeEntry, bool)
+	// DirectDependencies returns the resources the resource requires.
+	DirectDependencies(id string) []string
+	// ReverseDependencies returns the resources directly requiring the
+	// resource, in catalog order.
+	ReverseDependencies(id string) []string
+	// TransitiveDependencies returns the closure of the resource, excluding
+	// itself, in breadth-first order.
+	TransitiveDependencies(id string) []string
+	// TransitiveDependents returns the resources transitively requiring the
+	// resource, in breadth-first order.
+	TransitiveDependents(id string) []string
+}
+
+var _ Catalog = (*DependencyResolver)(nil)
@@ -0,0 +1,103 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// CheckpointVersion is the format version written to checkpoints.
+const CheckpointVersion = 1
+
+// Checkpoint is the state of a partially completed run, saved to the
+// resolver's CheckpointFile after every resource so the run can be resumed.
+type Checkpoint struct {
+	CheckpointVersion int               `yaml:"checkpointVersion"`
+	Targets           []string          `yaml:"targets"`
+	Completed         []string          `yaml:"completed"`
+	Outputs           map[string]string `yaml:"outputs,omitempty"`
+	Failed            string            `yaml:"failed,omitempty"`
+	Error             string            `yaml:"error,omitempty"`
+}
+
+// LoadCheckpoint reads the checkpoint saved in the resolver's CheckpointFile.
+func (dr *DependencyResolver) LoadCheckpoint() (*Checkpoint, error) {
+	data, err := afero.ReadFile(dr.Fs, dr.CheckpointFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no run to resume: checkpoint %s does not exist", dr.CheckpointFile)
+		}
+		return nil, fmt.Errorf("error reading checkpoint %s: %w", dr.CheckpointFile, err)
+	}
+
+	var checkpoint Checkpoint
+	if err := yaml.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("error unmarshalling checkpoint %s: %w", dr.CheckpointFile, err)
+	}
+	if checkpoint.CheckpointVersion != CheckpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d in %s", checkpoint.CheckpointVersion, dr.CheckpointFile)
+	}
+	return &checkpoint, nil
+}
+
+// resumeCheckpoint returns the checkpoint to resume the run of the targets
+// from, or nil when the run is not resumed.
+func (dr *DependencyResolver) resumeCheckpoint(targets []string) (*Checkpoint, error) {
+	if !dr.Resume {
+		return nil, nil
+	}
+	checkpoint, err := dr.LoadCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(checkpoint.Targets, targets) {
+		return nil, fmt.Errorf("checkpoint %s is for a run of %v, not %v", dr.CheckpointFile, checkpoint.Targets, targets)
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint writes the state of the run to the CheckpointFile, with the
+// resource that failed, if any. It does nothing when CheckpointFile is empty.
+func (dr *DependencyResolver) saveCheckpoint(state *ExecutionState, failed string, failure error) error {
+	if dr.CheckpointFile == "" {
+		return nil
+	}
+
+	checkpoint := Checkpoint{
+		CheckpointVersion: CheckpointVersion,
+		Targets:           state.Targets(),
+		Completed:         state.Completed(),
+		Outputs:           make(map[string]string),
+		Failed:            failed,
+	}
+	for _, id := range checkpoint.Completed {
+		checkpoint.Outputs[id] = state.logs.ResourceOutput(id)
+	}
+	if failure != nil {
+		checkpoint.Error = failure.Error()
+	}
+
+	data, err := yaml.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("error marshalling checkpoint: %w", err)
+	}
+	if err := writeFileAtomic(dr.Fs, dr.CheckpointFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing checkpoint %s: %w", dr.CheckpointFile, err)
+	}
+	return nil
+}
+
+// clearCheckpoint removes the CheckpointFile once a run completed successfully.
+func (dr *DependencyResolver) clearCheckpoint() error {
+	if dr.CheckpointFile == "" {
+		return nil
+	}
+	if err := dr.Fs.Remove(dr.CheckpointFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing checkpoint %s: %w", dr.CheckpointFile, err)
+	}
+	return nil
+}
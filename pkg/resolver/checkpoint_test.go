@@ -0,0 +1,79 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExecute_Resume(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	ready := filepath.Join(dir, "ready")
+
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "build", Run: []RunStep{{Name: "build", Exec: "echo built >> " + runs + "; echo built"}}},
+		{Id: "deploy", Requires: []string{"build"}, Run: []RunStep{{Name: "deploy", Exec: "test -f " + ready}}},
+	}
+	resolver.CheckpointFile = filepath.Join("cache", "projects", "app", "checkpoint.yaml")
+	resolver.BuildDependencies()
+
+	if _, err := resolver.Execute(context.Background(), []string{"deploy"}); err == nil {
+		t.Fatal("Expected 'deploy' to fail")
+	}
+
+	checkpoint, err := resolver.LoadCheckpoint()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(checkpoint.Completed, []string{"build"}) || checkpoint.Failed != "deploy" || checkpoint.Error == "" {
+		t.Errorf("Unexpected checkpoint: %+v", checkpoint)
+	}
+	if !strings.Contains(checkpoint.Outputs["build"], "built") {
+		t.Errorf("Expected the output of 'build' in the checkpoint, got %q", checkpoint.Outputs["build"])
+	}
+
+	if err := os.WriteFile(ready, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolver.Resume = true
+	results, err := resolver.Execute(context.Background(), []string{"deploy"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []ResourceResult{
+		{Id: "build", Status: StatusSucceeded, Resumed: true},
+		{Id: "deploy", Status: StatusSucceeded},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, results)
+	}
+	if data, _ := os.ReadFile(runs); strings.Count(string(data), "built") != 1 {
+		t.Errorf("Expected 'build' to run once, got %q", data)
+	}
+	if exists, _ := afero.Exists(resolver.Fs, resolver.CheckpointFile); exists {
+		t.Error("Expected the checkpoint to be removed after a successful run")
+	}
+}
+
+func TestExecute_ResumeErrors(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.CheckpointFile = "state.yaml"
+	resolver.Resume = true
+
+	if _, err := resolver.Execute(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "no run to resume") {
+		t.Errorf("Expected a missing checkpoint error, got %v", err)
+	}
+
+	afero.WriteFile(resolver.Fs, "state.yaml", []byte("checkpointVersion: 1\ntargets: [b]\ncompleted: []\n"), 0644)
+	if _, err := resolver.Execute(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "is for a run of [b]") {
+		t.Errorf("Expected a target mismatch error, got %v", err)
+	}
+}
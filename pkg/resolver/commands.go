@@ -2,11 +2,16 @@ package resolver
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jjuliano/runner/pkg/expect"
 	"github.com/jjuliano/runner/pkg/runnerexec"
@@ -26,6 +31,8 @@ type RunnerLogs struct {
 	mu      sync.Mutex
 	entries []StepLog
 	closed  bool
+	// out receives the formatted entries, os.Stdout when nil.
+	out io.Writer
 }
 
 // Add adds a new log entry to the RunnerLogs.
@@ -35,7 +42,11 @@ func (m *RunnerLogs) Add(entry StepLog) {
 	if m.closed {
 		return
 	}
-	fmt.Println(FormatLogEntry(entry))
+	out := m.out
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintln(out, FormatLogEntry(entry))
 	m.entries = append(m.entries, entry)
 }
 
@@ -66,6 +77,27 @@ func (m *RunnerLogs) GetAllMessages() []string {
 	return messages
 }
 
+// ResourceOutput returns the messages logged by the steps of a resource.
+func (m *RunnerLogs) ResourceOutput(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var messages []string
+	for _, entry := range m.entries {
+		if entry.targetRes == id {
+			messages = append(messages, entry.message)
+		}
+	}
+	return strings.Join(messages, "\n")
+}
+
+// restore records the output of a resource completed by a previous run,
+// without printing it again.
+func (m *RunnerLogs) restore(id, output string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, StepLog{targetRes: id, id: id, message: output})
+}
+
 // GetAllMessageString retrieves all log messages as a string.
 func (m *RunnerLogs) GetAllMessageString() string {
 	return strings.Join(m.GetAllMessages(), "\n")
@@ -82,75 +114,81 @@ func FormatLogEntry(entry StepLog) string {
 	}, "\n")
 }
 
-func SourceEnvFile(envFilePath string) error {
-	LogDebug(fmt.Sprintf("Sourcing environment file from path: %s", envFilePath))
+// SourceEnvFile sets the environment variables declared in the file, one
+// KEY=value per line.
+func (dr *DependencyResolver) SourceEnvFile(envFilePath string) error {
+	dr.logDebug("Sourcing environment file", "path", envFilePath)
 
 	file, err := os.Open(envFilePath)
 	if err != nil {
-		return LogError(fmt.Sprintf("Failed to open environment file: %s - %v", envFilePath, err), err)
+		return dr.logError("Failed to open environment file", err, "path", envFilePath)
 	}
 	defer func(file *os.File) {
 		err := file.Close()
 		if err != nil {
-			LogError(fmt.Sprintf("Failed to close environment file: %s - %v", envFilePath, err), err)
+			dr.logError("Failed to close environment file", err, "path", envFilePath)
 		} else {
-			LogDebug(fmt.Sprintf("Successfully closed environment file: %s", envFilePath))
+			dr.logDebug("Closed environment file", "path", envFilePath)
 		}
 	}(file)
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
-		LogDebug(fmt.Sprintf("Processing line: %s", line))
+		dr.logDebug("Processing environment file line", "path", envFilePath, "line", line)
 
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
-			return LogError(fmt.Sprintf("Invalid environment variable declaration: %s in file: %s", line, envFilePath), err)
+			return dr.logError("Invalid environment variable declaration", err, "path", envFilePath, "line", line)
 		}
 
 		key := parts[0]
 		value := strings.Trim(parts[1], "\"")
 		if err := os.Setenv(key, value); err != nil {
-			return LogError(fmt.Sprintf("Failed to set environment variable %s: %v - %s", key, err, envFilePath), err)
+			return dr.logError("Failed to set environment variable", err, "path", envFilePath, "name", key)
 		}
-		LogDebug(fmt.Sprintf("Set environment variable %s=%s", key, value))
+		dr.logDebug("Set environment variable", "path", envFilePath, "name", key)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return LogError(fmt.Sprintf("Error reading environment file: %s - %v", envFilePath, err), err)
+		return dr.logError("Failed to read environment file", err, "path", envFilePath)
 	}
 
-	LogDebug(fmt.Sprintf("Successfully sourced environment file: %s", envFilePath))
+	dr.logDebug("Sourced environment file", "path", envFilePath)
 	return nil
 }
 
 // ProcessNodeSteps processes each step by executing the relevant checks.
 func (dr *DependencyResolver) ProcessNodeSteps(steps []interface{}, stepType, resNode string, client *http.Client, logs *RunnerLogs) error {
 	for _, step := range steps {
-		LogInfo(fmt.Sprintf("Processing '%s' step: '%v' - '%s'", stepType, step, resNode))
-		if err := ProcessSingleNodeRule(step, client, logs); err != nil {
-			return LogError(fmt.Sprintf("Error processing step '%v' in '%s' steps: ", step, stepType), err)
+		fields := dr.resourceFields(context.Background(), resNode, "", "rules", stepType, "rule", step)
+		dr.logger().Info("Processing rule", fields...)
+		if err := dr.ProcessSingleNodeRule(step, client, logs); err != nil {
+			return dr.logError("Rule failed", err, fields...)
 		}
 	}
 	return nil
 }
 
 // ProcessSingleNodeRule processes an individual step element based on its type.
-func ProcessSingleNodeRule(element interface{}, client *http.Client, logs *RunnerLogs) error {
+func (dr *DependencyResolver) ProcessSingleNodeRule(element interface{}, client *http.Client, logs *RunnerLogs) error {
 	switch val := element.(type) {
 	case string:
 		if HasValidRulePrefix(val) {
 			return expect.CheckExpectations(logs.GetAllMessageString(), 0, []string{val}, client)
 		} else {
-			LogInfo(fmt.Sprintf("Skipping check condition '%s' unsupported.", val))
+			dr.logger().Info("Skipping unsupported rule", "rule", val)
 		}
 	case map[interface{}]interface{}:
 		if expectVal, exists := val["expect"]; exists {
-			ev := expectVal.([]interface{})
+			ev, ok := expectVal.([]interface{})
+			if !ok {
+				return fmt.Errorf("unsupported expect rule '%v', expected a list", expectVal)
+			}
 			return ProcessResourceNodeRules(ev, client, logs)
 		}
 	default:
-		LogErrorExit(fmt.Sprintf("Unsupported Step: %v", val), nil)
+		return fmt.Errorf("unsupported rule '%v'", val)
 	}
 	return nil
 }
@@ -189,7 +227,7 @@ func (dr *DependencyResolver) ProcessResourceNodeEnvVarDeclarations(envVars []En
 			result, ok = <-resultChan
 
 			if !ok {
-				LogErrorExit(fmt.Sprintf("Failed to set ENV VAR: '%s'", envVar.Exec), nil)
+				return fmt.Errorf("failed to set environment variable '%s': command '%s' did not complete", envVar.Name, envVar.Exec)
 			}
 			value = result.Output
 		} else if envVar.Input != "" {
@@ -197,7 +235,7 @@ func (dr *DependencyResolver) ProcessResourceNodeEnvVarDeclarations(envVars []En
 
 			_, err := fmt.Scanln(&value)
 			if err != nil {
-				LogErrorExit(fmt.Sprintf("Failed to read input for environment variable %s: ", envVar.Name), err)
+				return fmt.Errorf("failed to read the input of environment variable '%s': %w", envVar.Name, err)
 			}
 		} else if envVar.File != "" {
 			// Check if envVar.File starts with a "$" to resolve environment variable
@@ -205,7 +243,7 @@ func (dr *DependencyResolver) ProcessResourceNodeEnvVarDeclarations(envVars []En
 				envVarName := envVar.File[1:] // Remove the "$" prefix
 				filePath := os.Getenv(envVarName)
 				if filePath == "" {
-					LogErrorExit(fmt.Sprintf("Environment variable %s not set or empty", envVarName), nil)
+					return fmt.Errorf("environment variable '%s' is not set or empty", envVarName)
 				}
 				envVar.File = filePath
 			}
@@ -214,24 +252,26 @@ func (dr *DependencyResolver) ProcessResourceNodeEnvVarDeclarations(envVars []En
 		}
 
 		if err := os.Setenv(envVar.Name, value); err != nil {
-			LogErrorExit(fmt.Sprintf("Failed to set environment variable %s: ", envVar.Name), err)
+			return fmt.Errorf("failed to set environment variable '%s': %w", envVar.Name, err)
 		}
 	}
 	return nil
 }
 
-func (dr *DependencyResolver) ExecuteAndLogCommand(step RunStep, resName string, resNode string, logs *RunnerLogs) error {
-	LogInfo(fmt.Sprintf("Executing command: '%s' for resource: '%s', step: '%s'", step.Exec, resName, step.Name))
+func (dr *DependencyResolver) ExecuteAndLogCommand(ctx context.Context, step RunStep, resName string, resNode string, logs *RunnerLogs) error {
+	fields := dr.resourceFields(ctx, resName, phaseOf(ctx), "step", step.Name)
+	dr.logger().Info("Executing command", append(fields, "command", step.Exec)...)
 
 	// Set environment variables
 	if err := dr.ProcessResourceNodeEnvVarDeclarations(step.Env); err != nil {
-		LogErrorExit(fmt.Sprintf("Failed to set environment variables for step: '%s'", step.Name), err)
+		return dr.logError("Failed to set environment variables", err, fields...)
 	}
 
 	var result runnerexec.CommandResult
 	var ok bool
 
-	execResultChan := dr.ShellSession.ExecuteCommand(step.Exec)
+	start := time.Now()
+	execResultChan := dr.ShellSession.ExecuteCommandContext(ctx, step.Exec)
 	result, ok = <-execResultChan
 	logEntry := StepLog{
 		targetRes: resNode,
@@ -243,60 +283,92 @@ func (dr *DependencyResolver) ExecuteAndLogCommand(step RunStep, resName string,
 	logs.Add(logEntry)
 
 	if !ok {
-		LogErrorExit(fmt.Sprintf("Failed to execute command: '%s'", step.Exec), nil)
+		return fmt.Errorf("failed to execute command: '%s'", step.Exec)
 	}
 
+	fields = append(fields, "duration", time.Since(start))
 	if result.Err != nil {
-		LogErrorExit(fmt.Sprintf("Command execution error for '%s' ", step.Name), result.Err)
+		return dr.logError("Command failed", result.Err, fields...)
 	}
+	dr.logger().Debug("Command finished", fields...)
 
 	return nil
 }
 
-// HandleRunCommand handles the 'run' command for the given resources.
+// HandleRunCommand handles the 'run' command for the given resources, and
+// reports the outcome of every resource.
 func (dr *DependencyResolver) HandleRunCommand(resources []string) error {
-	logs := &RunnerLogs{}
-
-	visited := make(map[string]bool)
-	client := &http.Client{}
+	return dr.ReportRun(dr.Execute(context.Background(), resources))
+}
 
-	for _, resName := range resources {
-		stack := dr.Graph.BuildDependencyStack(resName, visited)
-		for _, resNode := range stack {
-			for _, res := range dr.Resources {
-				if res.Id == resNode {
-					dr.ResolveResourceNodeDependency(resNode, res, logs, client)
+// ReportRun prints the results of a run returned by Execute, and returns the
+// error of the run.
+func (dr *DependencyResolver) ReportRun(results []ResourceResult, err error) error {
+	if results == nil {
+		return err
+	}
+	if renderErr := dr.render(nonNil(results), func() Table { return ResultsTable(results) }, resultsPorcelain(results), func() {
+		for _, result := range results {
+			switch result.Status {
+			case StatusSucceeded:
+				switch {
+				case result.RolledBack:
+					Println("↩️ ", styles.id.Render(result.Id), "(rolled back)")
+				case result.Resumed:
+					Println("✅", styles.id.Render(result.Id), "(resumed)")
+				case result.UpToDate:
+					Println("✅", styles.id.Render(result.Id), "(up to date)")
+				default:
+					Println("✅", styles.id.Render(result.Id))
 				}
+			case StatusFailed:
+				Println("❌", styles.id.Render(result.Id)+":", styles.err.Render(result.Error))
+			case StatusSkipped:
+				Println("⏭️ ", styles.id.Render(result.Id), "(skipped)")
 			}
 		}
+	}); renderErr != nil {
+		return renderErr
 	}
+	return err
+}
 
-	// Close the log after all processing is done.
-	logs.Close()
-
-	return nil
+// Run resolves the given targets and executes the run steps of every resource
+// in dependency order, following the resolver's FailurePolicy. Execution stops
+// with the context's error once ctx is done.
+func (dr *DependencyResolver) Run(ctx context.Context, targets []string) error {
+	_, err := dr.Execute(ctx, targets)
+	return err
 }
 
 // ResolveResourceNodeDependency resolves the dependency for a given resource node.
-func (dr *DependencyResolver) ResolveResourceNodeDependency(resNode string, res ResourceNodeEntry, logs *RunnerLogs, client *http.Client) {
-	LogInfo("Resolving dependency " + resNode)
+func (dr *DependencyResolver) ResolveResourceNodeDependency(ctx context.Context, resNode string, res ResourceNodeEntry, logs *RunnerLogs, client *http.Client) error {
+	ctx = withPhase(ctx, phaseRun)
+	dr.logger().Info("Resolving resource", entryFields(res, phaseRun)...)
 	if res.Run == nil {
-		LogInfo("No run steps found for resource " + resNode)
-		return
+		dr.logger().Info("No run steps", entryFields(res, phaseRun)...)
+		return nil
 	}
+	return dr.runPhase(ctx, res, res.Run, logs, client)
+}
 
+// runSteps evaluates the skip rules of the steps, then executes them in order.
+func (dr *DependencyResolver) runSteps(ctx context.Context, steps []RunStep, resNode string, logs *RunnerLogs, client *http.Client) error {
 	skipResults := make(map[StepKey]bool)
 	mu := &sync.Mutex{}
 
-	for _, step := range res.Run {
+	for _, step := range steps {
 		dr.ProcessNodeSkipRules(step, resNode, skipResults, mu, client, logs)
 	}
 
-	skip := dr.BuildNodeSkipMap(res.Run, resNode, skipResults)
+	skip := dr.BuildNodeSkipMap(steps, resNode, skipResults)
 
-	for _, step := range res.Run {
-		dr.HandleResourceNodeStep(step, resNode, skip, logs, client)
+	for _, step := range steps {
+		if err := dr.HandleResourceNodeStep(ctx, step, resNode, skip, logs, client); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // ProcessNodeSkipRules processes skip steps for a given step.
@@ -304,12 +376,12 @@ func (dr *DependencyResolver) ProcessNodeSkipRules(step RunStep, resNode string,
 	if skipSteps, ok := step.Skip.([]interface{}); ok {
 		for _, skipStep := range skipSteps {
 			if skipStr, ok := skipStep.(string); ok && HasValidRulePrefix(skipStr) {
-				if err := ProcessSingleNodeRule(skipStr, client, logs); err == nil {
+				if err := dr.ProcessSingleNodeRule(skipStr, client, logs); err == nil {
 					mu.Lock()
 					skipResults[StepKey{name: step.Name, node: resNode}] = true
 					mu.Unlock()
 
-					LogDebug(fmt.Sprintf("Skipping step '%s' for node '%s' due to skip condition", step.Name, resNode))
+					dr.logger().Debug("Skipping step", dr.resourceFields(context.Background(), resNode, "", "step", step.Name, "rule", skipStr)...)
 
 					return
 				}
@@ -319,7 +391,7 @@ func (dr *DependencyResolver) ProcessNodeSkipRules(step RunStep, resNode string,
 		skipResults[StepKey{name: step.Name, node: resNode}] = false
 		mu.Unlock()
 
-		LogDebug(fmt.Sprintf("Not skipping step '%s' for node '%s'", step.Name, resNode))
+		dr.logger().Debug("Not skipping step", dr.resourceFields(context.Background(), resNode, "", "step", step.Name)...)
 	}
 }
 
@@ -333,119 +405,830 @@ func (dr *DependencyResolver) BuildNodeSkipMap(steps []RunStep, resNode string,
 }
 
 // HandleResourceNodeStep handles the execution and logging of a step.
-func (dr *DependencyResolver) HandleResourceNodeStep(step RunStep, resNode string, skip map[StepKey]bool, logs *RunnerLogs, client *http.Client) {
+func (dr *DependencyResolver) HandleResourceNodeStep(ctx context.Context, step RunStep, resNode string, skip map[StepKey]bool, logs *RunnerLogs, client *http.Client) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	skipKey := StepKey{name: step.Name, node: resNode}
-	LogDebug(fmt.Sprintf("Skip key '%v' = %v", skipKey, skip[skipKey]))
+	fields := dr.resourceFields(ctx, resNode, phaseOf(ctx), "step", step.Name)
 
 	if skip[skipKey] {
 		logs.Add(StepLog{targetRes: resNode, command: step.Exec, id: resNode, name: step.Name, message: "Step skipped."})
-		LogInfo("Step: '" + step.Name + "' skipped for resource: '" + resNode + "'")
-		return
+		dr.logger().Info("Step skipped", fields...)
+		return nil
 	}
 
 	if step.Exec != "" {
-		if err := dr.ExecuteAndLogCommand(step, resNode, resNode, logs); err != nil {
-			LogErrorExit(fmt.Sprintf("Execution failed for step '%s' of resource '%s': ", step.Name, resNode), err)
+		if err := dr.ExecuteAndLogCommand(ctx, step, resNode, resNode, logs); err != nil {
+			return dr.logError("Step failed", err, fields...)
 		}
 	}
 
 	if checkSteps, ok := step.Check.([]interface{}); ok {
 		if err := dr.ProcessNodeSteps(checkSteps, "check", resNode, client, logs); err != nil {
-			LogErrorExit("Check expectation failed for resource '"+resNode+"' step '"+step.Name+"'", err)
+			return dr.logError("Check failed", err, fields...)
 		}
 	}
 
 	if expectSteps, ok := step.Expect.([]interface{}); ok {
-		if err := SourceEnvFile(os.Getenv("RUNNER_ENV")); err != nil {
-			LogErrorExit(fmt.Sprintf("Failed to source environment file for step: '%s'", step.Name), err)
+		if err := dr.SourceEnvFile(os.Getenv("RUNNER_ENV")); err != nil {
+			return dr.logError("Failed to source environment file", err, fields...)
 		}
 
 		expectations := expect.ProcessExpectations(expectSteps)
 		if err := expect.CheckExpectations(logs.GetAllMessageString(), 0, expectations, client); err != nil {
-			LogErrorExit(fmt.Sprintf("Expectation failed for '%s': ", step.Name), err)
+			return dr.logError("Expectation failed", err, fields...)
 		}
 	}
+	return nil
 }
 
 // HandleShowCommand handles the 'show' command for the given resources.
 func (dr *DependencyResolver) HandleShowCommand(resources []string) error {
+	entries := []ResourceNodeEntry{}
 	for _, res := range resources {
-		if err := dr.ShowResourceEntry(res); err != nil {
-			LogErrorExit("Error showing resource entry "+res, err)
+		entry, ok := dr.GetResource(res)
+		if !ok {
+			return &NotFoundError{Id: res}
 		}
+		entries = append(entries, entry)
 	}
-	return nil
+
+	var showErr error
+	err := dr.render(entries, func() Table { return ResourceTable(entries) }, resourcePorcelain(entries), func() {
+		for _, res := range resources {
+			if showErr = dr.ShowResourceEntry(res); showErr != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return showErr
 }
 
-// HandleDependsCommand handles the 'depends' command for the given resources.
+// ClosureRecord is a JSON line of the 'depends' and 'rdepends' commands,
+// pairing a resource with one of its dependencies or dependents.
+type ClosureRecord struct {
+	Resource   string `json:"resource"`
+	Dependency string `json:"dependency,omitempty"`
+	Dependent  string `json:"dependent,omitempty"`
+}
+
+// HandleDependsCommand handles the 'depends' command for the given resources,
+// printed as trees, or as compact paths with CompactPaths. In the JSONL
+// format, the dependencies are streamed as the traversal reaches them.
 func (dr *DependencyResolver) HandleDependsCommand(resources []string) error {
+	if err := dr.CheckLimits(resources, false, dr.MaxDepth); err != nil {
+		return err
+	}
+	if dr.streaming() {
+		return dr.streamClosures(resources, dr.DependenciesSeq, func(res, id string) ClosureRecord {
+			return ClosureRecord{Resource: res, Dependency: id}
+		})
+	}
+
+	dependencies := make(map[string][]string, len(resources))
 	for _, res := range resources {
-		LogDebug("Listing direct dependencies for resource " + res)
-		dr.Graph.ListDirectDependencies(res)
+		dependencies[res] = nonNil(dr.DependenciesWithin(res, dr.MaxDepth))
+		dr.warnTraversed(append([]string{res}, dependencies[res]...))
 	}
-	return nil
+
+	table := func() Table {
+		return pairTable([]string{"RESOURCE", "DEPENDENCY"}, resources, dependencies)
+	}
+	var writeErr error
+	err := dr.render(dependencies, table, pairPorcelain(resources, dependencies), func() {
+		for _, res := range resources {
+			dr.logDebug("Listing dependencies", "resource", res)
+			write := dr.WriteDependencyTree
+			if dr.CompactPaths {
+				write = dr.WriteDependencyPaths
+			}
+			if writeErr = write(dr.stdout(), res, dr.MaxDepth); writeErr != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return writeErr
 }
 
-// HandleRDependsCommand handles the 'rdepends' command for the given resources.
+// HandleRDependsCommand handles the 'rdepends' command for the given resources,
+// printed like the 'depends' command.
 func (dr *DependencyResolver) HandleRDependsCommand(resources []string) error {
+	if err := dr.CheckLimits(resources, true, dr.MaxDepth); err != nil {
+		return err
+	}
+	if dr.streaming() {
+		return dr.streamClosures(resources, dr.DependentsSeq, func(res, id string) ClosureRecord {
+			return ClosureRecord{Resource: res, Dependent: id}
+		})
+	}
+
+	dependents := make(map[string][]string, len(resources))
 	for _, res := range resources {
-		LogDebug("Listing reverse dependencies for resource " + res)
-		dr.Graph.ListReverseDependencies(res)
+		dependents[res] = nonNil(dr.DependentsWithin(res, dr.MaxDepth))
+		dr.warnTraversed(append([]string{res}, dependents[res]...))
 	}
-	return nil
+
+	table := func() Table {
+		return pairTable([]string{"RESOURCE", "DEPENDENT"}, resources, dependents)
+	}
+	var writeErr error
+	err := dr.render(dependents, table, pairPorcelain(resources, dependents), func() {
+		for _, res := range resources {
+			dr.logDebug("Listing dependents", "resource", res)
+			write := dr.WriteDependentTree
+			if dr.CompactPaths {
+				write = dr.WriteDependentPaths
+			}
+			if writeErr = write(dr.stdout(), res, dr.MaxDepth); writeErr != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return writeErr
 }
 
 // HandleSearchCommand handles the 'search' command.
 func (dr *DependencyResolver) HandleSearchCommand(resources []string) error {
+	if len(resources) == 0 {
+		return usageErrorf("a search query is required")
+	}
 	query := resources[0]
 	keys := resources[1:]
-	LogDebug("Performing fuzzy search with query: " + query)
-	return dr.FuzzySearch(query, keys)
+	dr.logDebug("Searching resources", "query", query)
+	if (dr.OutputFormat == "" || dr.OutputFormat == FormatPlain) && dr.Template == "" && dr.Porcelain == "" {
+		return dr.FuzzySearch(query, keys)
+	}
+
+	matches := nonNil(paginate(dr, dr.filterMetadata(dr.Search(query, keys))))
+	return dr.render(matches, func() Table { return ResourceTable(matches) }, resourcePorcelain(matches), nil)
+}
+
+// HandleQueryCommand handles the 'query' command, listing the resources
+// matching the query formed by the arguments.
+func (dr *DependencyResolver) HandleQueryCommand(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("a query is required")
+	}
+	entries, err := dr.QueryResources(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	entries = paginate(dr, dr.filterMetadata(entries))
+	return dr.render(entries, func() Table { return ResourceTable(entries) }, resourcePorcelain(entries), func() {
+		for _, entry := range entries {
+			Println("📦 " + styles.id.Render(entry.Id))
+		}
+	})
 }
 
 // HandleCategoryCommand handles the 'category' command for the given categories.
 func (dr *DependencyResolver) HandleCategoryCommand(resources []string) error {
 	if len(resources) == 0 {
-		LogInfo("No categories provided")
+		dr.logger().Info("No categories provided")
 		Println("Usage: runner category [categories...]")
 		return nil
 	}
-	for _, entry := range dr.Resources {
-		for _, category := range resources {
-			if entry.Category == category {
-				LogDebug("Listing resource in category: " + category)
-				Println("📦 " + entry.Id)
-			}
+
+	entries := paginate(dr, dr.filterMetadata(dr.ResourcesByCategory(resources...)))
+
+	return dr.render(entries, func() Table { return ResourceTable(entries) }, resourcePorcelain(entries), func() {
+		for _, entry := range entries {
+			dr.logDebug("Listing resource", "resource", entry.Id, "category", entry.Category)
+			Println("📦 " + styles.id.Render(entry.Id))
 		}
-	}
-	return nil
+	})
 }
 
 // HandleTreeCommand handles the 'tree' command for the given resources.
 func (dr *DependencyResolver) HandleTreeCommand(resources []string) error {
+	trees := make(map[string][][]string, len(resources))
 	for _, res := range resources {
-		LogDebug("Listing dependency tree for resource " + res)
-		dr.Graph.ListDependencyTree(res)
+		tree, err := dr.DependencyTree(res, dr.MaxDepth)
+		if err != nil {
+			return err
+		}
+		trees[res] = nonNil(tree)
+		dr.warnTraversed(slices.Compact(slices.Sorted(slices.Values(slices.Concat(tree...)))))
 	}
-	return nil
+
+	table := func() Table {
+		table := Table{Header: []string{"RESOURCE", "PATH"}}
+		for _, res := range resources {
+			for _, path := range trees[res] {
+				table.Rows = append(table.Rows, []string{res, strings.Join(path, " <- ")})
+			}
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, res := range resources {
+			for _, path := range trees[res] {
+				records = append(records, []string{res, strings.Join(path, " <- ")})
+			}
+		}
+		return records
+	}}
+	return dr.render(trees, table, porcelain, func() {
+		for _, res := range resources {
+			dr.logDebug("Listing dependency tree", "resource", res)
+			for _, path := range trees[res] {
+				dr.println(styledPath(path, " <- "))
+			}
+		}
+	})
 }
 
 // HandleTreeListCommand handles the 'tree-list' command for the given resources.
 func (dr *DependencyResolver) HandleTreeListCommand(resources []string) error {
+	orders := make(map[string][]string, len(resources))
 	for _, res := range resources {
-		LogDebug("Listing top-down dependency tree for resource " + res)
-		dr.Graph.ListDependencyTreeTopDown(res)
+		order, err := dr.DependencyOrder(res, dr.MaxDepth)
+		if err != nil {
+			return err
+		}
+		orders[res] = nonNil(order)
 	}
-	return nil
+
+	table := func() Table {
+		return pairTable([]string{"RESOURCE", "STEP"}, resources, orders)
+	}
+	return dr.render(orders, table, pairPorcelain(resources, orders), func() {
+		for _, res := range resources {
+			dr.logDebug("Listing top-down dependency tree", "resource", res)
+			for _, step := range orders[res] {
+				dr.println(styles.id.Render(step))
+			}
+		}
+	})
 }
 
 // HandleIndexCommand handles the 'index' command, listing all resources.
 func (dr *DependencyResolver) HandleIndexCommand() error {
-	for _, entry := range dr.Resources {
-		LogDebug("Indexing resource: " + entry.Id)
-		PrintMessage("📦 Id: %s\n📛 Name: %s\n📝 Description: %s\n🏷️  Category: %s\n🔗 Requirements: %v\n",
-			entry.Id, entry.Name, entry.Desc, entry.Category, entry.Requires)
-		fmt.Println()
+	entries := nonNil(paginate(dr, dr.filterMetadata(dr.ListResources())))
+	return dr.render(entries, func() Table { return ResourceTable(entries) }, resourcePorcelain(entries), func() {
+		for _, entry := range entries {
+			dr.logDebug("Indexing resource", "resource", entry.Id, "category", entry.Category)
+			PrintMessage("📦 Id: %s\n📛 Name: %s\n📝 Description: %s\n🏷️  Category: %s\n🔗 Requirements: %v\n",
+				styles.id.Render(entry.Id), styles.name.Render(entry.Name), entry.Desc, styles.category.Render(entry.Category), entry.Requires)
+			fmt.Println()
+		}
+	})
+}
+
+// HandleDiffCommand handles the 'diff' command, comparing two resource files.
+func (dr *DependencyResolver) HandleDiffCommand(files []string) error {
+	if len(files) != 2 {
+		Println("Usage: runner diff [old] [new]")
+		return usageErrorf("expected 2 files, got %d", len(files))
 	}
-	return nil
+
+	catalogs := make([]*DependencyResolver, len(files))
+	for i, file := range files {
+		catalog, err := NewGraphResolver(dr.Fs, dr.Logger, dr.WorkDir, dr.ShellSession)
+		if err != nil {
+			return err
+		}
+		if err := catalog.ReloadResourceEntries(file); err != nil {
+			return err
+		}
+		catalogs[i] = catalog
+	}
+
+	diff := DiffCatalogs(catalogs[0], catalogs[1])
+	table := func() Table {
+		table := Table{Header: []string{"CHANGE", "RESOURCE"}}
+		for _, id := range diff.Added {
+			table.Rows = append(table.Rows, []string{"added", id})
+		}
+		for _, id := range diff.Removed {
+			table.Rows = append(table.Rows, []string{"removed", id})
+		}
+		for _, id := range diff.Changed {
+			table.Rows = append(table.Rows, []string{"changed", id})
+		}
+		for _, edge := range diff.AddedEdges {
+			table.Rows = append(table.Rows, []string{"added edge", edge.From + " -> " + edge.To})
+		}
+		for _, edge := range diff.RemovedEdges {
+			table.Rows = append(table.Rows, []string{"removed edge", edge.From + " -> " + edge.To})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, id := range diff.Added {
+			records = append(records, []string{"added", id})
+		}
+		for _, id := range diff.Removed {
+			records = append(records, []string{"removed", id})
+		}
+		for _, id := range diff.Changed {
+			records = append(records, []string{"changed", id})
+		}
+		for _, edge := range diff.AddedEdges {
+			records = append(records, []string{"added edge", edge.From + " -> " + edge.To})
+		}
+		for _, edge := range diff.RemovedEdges {
+			records = append(records, []string{"removed edge", edge.From + " -> " + edge.To})
+		}
+		return records
+	}}
+	return dr.render(diff, table, porcelain, func() {
+		for _, id := range diff.Added {
+			Println("➕ Added: " + id)
+		}
+		for _, id := range diff.Removed {
+			Println("➖ Removed: " + id)
+		}
+		for _, id := range diff.Changed {
+			Println("✏️  Changed: " + id)
+		}
+		for _, edge := range diff.AddedEdges {
+			Println("➕ Edge: " + edge.From + " -> " + edge.To)
+		}
+		for _, edge := range diff.RemovedEdges {
+			Println("➖ Edge: " + edge.From + " -> " + edge.To)
+		}
+	})
+}
+
+// HandleLockCommand handles the 'lock' command, writing the lockfile of a target to stdout.
+func (dr *DependencyResolver) HandleLockCommand(targets []string) error {
+	if len(targets) != 1 {
+		Println("Usage: runner lock [target]")
+		return usageErrorf("expected 1 target, got %d", len(targets))
+	}
+	return dr.WriteLockfile(targets[0], os.Stdout)
+}
+
+// HandleWhyCommand handles the 'why' command, printing every dependency path
+// from the target to the resource.
+func (dr *DependencyResolver) HandleWhyCommand(resource, target string) error {
+	paths, err := dr.Why(resource, target)
+	if err != nil {
+		return err
+	}
+	paths = nonNil(paths)
+
+	table := func() Table {
+		table := Table{Header: []string{"PATH"}}
+		for _, path := range paths {
+			table.Rows = append(table.Rows, []string{strings.Join(path, " -> ")})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(paths))
+		for _, path := range paths {
+			records = append(records, []string{strings.Join(path, " -> ")})
+		}
+		return records
+	}}
+	return dr.render(paths, table, porcelain, func() {
+		for _, path := range paths {
+			Println(styledPath(path, " -> "))
+		}
+	})
+}
+
+// HandleRedundantCommand handles the 'redundant' command, listing requirements
+// already implied by other requirements.
+func (dr *DependencyResolver) HandleRedundantCommand() error {
+	redundant := nonNil(dr.RedundantEdges())
+	table := func() Table {
+		table := Table{Header: []string{"FROM", "TO", "VIA"}}
+		for _, edge := range redundant {
+			table.Rows = append(table.Rows, []string{edge.From, edge.To, strings.Join(edge.Via, " -> ")})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(redundant))
+		for _, edge := range redundant {
+			records = append(records, []string{edge.From, edge.To, strings.Join(edge.Via, " -> ")})
+		}
+		return records
+	}}
+	return dr.render(redundant, table, porcelain, func() {
+		for _, edge := range redundant {
+			Println(edge.From + " -> " + edge.To + " (via " + strings.Join(edge.Via, " -> ") + ")")
+		}
+	})
+}
+
+// HandleValidateCommand handles the 'validate' command, running every check
+// of the catalog. It lists the errors, and the warnings too when
+// showWarnings or strict is set, or reports the number of valid resources
+// when there is nothing to list. The github format writes the findings as
+// GitHub Actions annotations. It returns a ValidationError when there are
+// errors, or warnings in strict mode.
+func (dr *DependencyResolver) HandleValidateCommand(showWarnings, strict bool) error {
+	all := dr.Validate()
+	findings := nonNil(slices.DeleteFunc(slices.Clone(all), func(f Finding) bool {
+		return f.Severity == SeverityWarning && !showWarnings && !strict
+	}))
+	if dr.OutputFormat == FormatGitHub && dr.Template == "" && dr.Porcelain == "" {
+		if err := WriteGitHubAnnotations(os.Stdout, findings); err != nil {
+			return err
+		}
+		return validationError(all, strict)
+	}
+
+	table := func() Table {
+		table := Table{Header: []string{"SEVERITY", "CODE", "RESOURCE", "LOCATION", "MESSAGE"}}
+		for _, f := range findings {
+			location := f.File
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+			}
+			table.Rows = append(table.Rows, []string{string(f.Severity), string(f.Code), f.Resource, location, f.Message})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(findings))
+		for _, f := range findings {
+			location := f.File
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+			}
+			records = append(records, []string{string(f.Severity), string(f.Code), f.Resource, location, f.Message})
+		}
+		return records
+	}}
+	err := dr.render(findings, table, porcelain, func() {
+		if len(findings) == 0 {
+			PrintMessage("✅ %d resources are valid\n", len(dr.ListResources()))
+		}
+		for _, f := range findings {
+			icon := "⚠️  "
+			if f.Severity == SeverityError {
+				icon = "❌ "
+			}
+			Println(icon + f.String())
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return validationError(all, strict)
+}
+
+// HandleCyclesCommand handles the 'cycles' command, listing every group of
+// resources that depend on each other. It returns a CycleError for each of
+// them.
+func (dr *DependencyResolver) HandleCyclesCommand() error {
+	cycles := nonNil(dr.Cycles())
+	table := func() Table {
+		table := Table{Header: []string{"CYCLE", "RESOURCES"}}
+		for i, cycle := range cycles {
+			table.Rows = append(table.Rows, []string{fmt.Sprint(i + 1), strings.Join(cycle, ", ")})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(cycles))
+		for i, cycle := range cycles {
+			records = append(records, []string{fmt.Sprint(i + 1), strings.Join(cycle, ", ")})
+		}
+		return records
+	}}
+	if err := dr.render(cycles, table, porcelain, func() {
+		for _, cycle := range cycles {
+			Println("🔁 " + strings.Join(cycle, ", "))
+		}
+	}); err != nil {
+		return err
+	}
+	errs := make([]error, len(cycles))
+	for i, cycle := range cycles {
+		errs[i] = &CycleError{Resources: cycle}
+	}
+	return errors.Join(errs...)
+}
+
+// HandleLevelsCommand handles the 'levels' command, printing the execution
+// levels of the given resources.
+func (dr *DependencyResolver) HandleLevelsCommand(resources []string) error {
+	levels := make(map[string][][]string, len(resources))
+	for _, res := range resources {
+		resLevels, err := dr.ExecutionLevels(res)
+		if err != nil {
+			return err
+		}
+		levels[res] = resLevels
+	}
+
+	table := func() Table {
+		table := Table{Header: []string{"RESOURCE", "LEVEL", "RESOURCES"}}
+		for _, res := range resources {
+			for i, level := range levels[res] {
+				table.Rows = append(table.Rows, []string{res, fmt.Sprint(i + 1), strings.Join(level, ", ")})
+			}
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, res := range resources {
+			for i, level := range levels[res] {
+				records = append(records, []string{res, fmt.Sprint(i + 1), strings.Join(level, ", ")})
+			}
+		}
+		return records
+	}}
+	return dr.render(levels, table, porcelain, func() {
+		for _, res := range resources {
+			for i, level := range levels[res] {
+				PrintMessage("%d: %s\n", i+1, strings.Join(level, ", "))
+			}
+		}
+	})
+}
+
+// HandleLeavesCommand handles the 'leaves' command, listing the resources
+// without requirements.
+func (dr *DependencyResolver) HandleLeavesCommand() error {
+	return dr.renderIDs(dr.Leaves())
+}
+
+// HandleRootsCommand handles the 'roots' command, listing the resources no
+// other resource requires.
+func (dr *DependencyResolver) HandleRootsCommand() error {
+	return dr.renderIDs(dr.Roots())
+}
+
+// renderIDs renders a list of resource IDs.
+func (dr *DependencyResolver) renderIDs(ids []string) error {
+	ids = nonNil(paginate(dr, dr.filterMetadataIDs(ids)))
+	table := func() Table {
+		table := Table{Header: []string{"ID"}}
+		for _, id := range ids {
+			table.Rows = append(table.Rows, []string{id})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(ids))
+		for _, id := range ids {
+			records = append(records, []string{id})
+		}
+		return records
+	}}
+	return dr.render(ids, table, porcelain, func() {
+		for _, id := range ids {
+			Println("📦 " + styles.id.Render(id))
+		}
+	})
+}
+
+// HandleOrphansCommand handles the 'orphans' command, listing the resources
+// unreachable from the given targets.
+func (dr *DependencyResolver) HandleOrphansCommand(targets []string) error {
+	orphans, err := dr.Orphans(targets...)
+	if err != nil {
+		return err
+	}
+	return dr.renderIDs(orphans)
+}
+
+// HandleImpactCommand handles the 'impact' command, listing the resources that
+// would break if the given resources were removed.
+func (dr *DependencyResolver) HandleImpactCommand(resources []string) error {
+	impacts := make([]Impact, 0, len(resources))
+	for _, res := range resources {
+		impact, err := dr.ImpactOfRemoval(res)
+		if err != nil {
+			return err
+		}
+		impacts = append(impacts, impact)
+	}
+
+	table := func() Table {
+		table := Table{Header: []string{"RESOURCE", "IMPACT", "DEPENDENT"}}
+		for _, impact := range impacts {
+			for _, id := range impact.Direct {
+				table.Rows = append(table.Rows, []string{impact.Resource, "direct", id})
+			}
+			for _, id := range impact.Transitive {
+				table.Rows = append(table.Rows, []string{impact.Resource, "transitive", id})
+			}
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, impact := range impacts {
+			for _, id := range impact.Direct {
+				records = append(records, []string{impact.Resource, "direct", id})
+			}
+			for _, id := range impact.Transitive {
+				records = append(records, []string{impact.Resource, "transitive", id})
+			}
+		}
+		return records
+	}}
+	return dr.render(impacts, table, porcelain, func() {
+		for _, impact := range impacts {
+			Println("🗑️  Removing " + styles.id.Render(impact.Resource))
+			for _, id := range impact.Direct {
+				Println("  💥 Direct: " + styles.id.Render(id))
+			}
+			for _, id := range impact.Transitive {
+				Println("  ⚠️  Transitive: " + styles.id.Render(id))
+			}
+		}
+	})
+}
+
+// HandleLicensesCommand handles the 'licenses' command, listing the licenses
+// in the closure of the target. It fails when any of them is denied.
+func (dr *DependencyResolver) HandleLicensesCommand(target string, deny []string) error {
+	usages, err := dr.Licenses(target)
+	if err != nil {
+		return err
+	}
+
+	table := func() Table {
+		table := Table{Header: []string{"LICENSE", "RESOURCE"}}
+		for _, usage := range usages {
+			for _, id := range usage.Resources {
+				table.Rows = append(table.Rows, []string{usage.License, id})
+			}
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, usage := range usages {
+			for _, id := range usage.Resources {
+				records = append(records, []string{usage.License, id})
+			}
+		}
+		return records
+	}}
+	err = dr.render(usages, table, porcelain, func() {
+		for _, usage := range usages {
+			ids := make([]string, 0, len(usage.Resources))
+			for _, id := range usage.Resources {
+				ids = append(ids, styles.id.Render(id))
+			}
+			Println("📜 " + usage.License + ": " + strings.Join(ids, ", "))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return CheckLicenses(target, usages, deny)
+}
+
+// HandleOwnersCommand handles the 'owners' command, listing who owns the
+// resources in the closure of the target.
+func (dr *DependencyResolver) HandleOwnersCommand(target string) error {
+	ownerships, err := dr.Owners(target)
+	if err != nil {
+		return err
+	}
+
+	table := func() Table {
+		table := Table{Header: []string{"OWNER", "RESOURCE"}}
+		for _, ownership := range ownerships {
+			for _, id := range ownership.Resources {
+				table.Rows = append(table.Rows, []string{ownership.Owner, id})
+			}
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, ownership := range ownerships {
+			for _, id := range ownership.Resources {
+				records = append(records, []string{ownership.Owner, id})
+			}
+		}
+		return records
+	}}
+	return dr.render(ownerships, table, porcelain, func() {
+		for _, ownership := range ownerships {
+			ids := make([]string, 0, len(ownership.Resources))
+			for _, id := range ownership.Resources {
+				ids = append(ids, styles.id.Render(id))
+			}
+			Println("👥 " + ownership.Owner + ": " + strings.Join(ids, ", "))
+		}
+	})
+}
+
+// HandleOwnedCommand handles the 'owned' command, listing the resources owned
+// by any of the given owners.
+func (dr *DependencyResolver) HandleOwnedCommand(owners []string) error {
+	if len(owners) == 0 {
+		return usageErrorf("at least one owner is required")
+	}
+
+	entries := paginate(dr, dr.filterMetadata(dr.ResourcesOwnedBy(owners...)))
+	return dr.render(entries, func() Table { return ResourceTable(entries) }, resourcePorcelain(entries), func() {
+		for _, entry := range entries {
+			Println("📦 " + styles.id.Render(entry.Id))
+		}
+	})
+}
+
+// HandleDeprecationsCommand handles the 'deprecations' command, listing the
+// deprecated resources in the closure of the target with their replacements.
+func (dr *DependencyResolver) HandleDeprecationsCommand(target string) error {
+	deprecations, err := dr.Deprecations(target)
+	if err != nil {
+		return err
+	}
+
+	table := func() Table {
+		table := Table{Header: []string{"RESOURCE", "REPLACED BY", "REQUIRED BY"}}
+		for _, deprecation := range deprecations {
+			table.Rows = append(table.Rows, []string{deprecation.Resource, deprecation.ReplacedBy, strings.Join(deprecation.RequiredBy, ",")})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(deprecations))
+		for _, deprecation := range deprecations {
+			records = append(records, []string{deprecation.Resource, deprecation.ReplacedBy, strings.Join(deprecation.RequiredBy, ",")})
+		}
+		return records
+	}}
+	return dr.render(deprecations, table, porcelain, func() {
+		for _, deprecation := range deprecations {
+			line := "🚧 " + styles.id.Render(deprecation.Resource)
+			if deprecation.ReplacedBy != "" {
+				line += " → " + styles.id.Render(deprecation.ReplacedBy)
+			}
+			if len(deprecation.RequiredBy) > 0 {
+				line += " (required by " + strings.Join(deprecation.RequiredBy, ", ") + ")"
+			}
+			Println(line)
+		}
+	})
+}
+
+// HandleStatsCommand handles the 'stats' command, summarizing the dependency
+// graph.
+func (dr *DependencyResolver) HandleStatsCommand() error {
+	stats := dr.Stats()
+	table := func() Table {
+		// The resources counted have their count in a column of its own.
+		table := Table{Header: []string{"STATISTIC", "VALUE", "COUNT"}}
+		table.Rows = [][]string{
+			{"resources", fmt.Sprint(stats.Resources)},
+			{"edges", fmt.Sprint(stats.Edges)},
+			{"max_depth", fmt.Sprint(stats.MaxDepth)},
+			{"average_fan_in", fmt.Sprintf("%.2f", stats.AverageFanIn)},
+			{"average_fan_out", fmt.Sprintf("%.2f", stats.AverageFanOut)},
+			{"largest_closure", stats.LargestClosure.Id, fmt.Sprint(stats.LargestClosure.Count)},
+			{"categories", fmt.Sprint(stats.Categories)},
+		}
+		for _, res := range stats.MostDepended {
+			table.Rows = append(table.Rows, []string{"most_depended", res.Id, fmt.Sprint(res.Count)})
+		}
+		return table
+	}
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		// The resources counted have their count in a field of its own.
+		records := [][]string{
+			{"resources", fmt.Sprint(stats.Resources)},
+			{"edges", fmt.Sprint(stats.Edges)},
+			{"max_depth", fmt.Sprint(stats.MaxDepth)},
+			{"average_fan_in", fmt.Sprintf("%.2f", stats.AverageFanIn)},
+			{"average_fan_out", fmt.Sprintf("%.2f", stats.AverageFanOut)},
+			{"largest_closure", stats.LargestClosure.Id, fmt.Sprint(stats.LargestClosure.Count)},
+			{"categories", fmt.Sprint(stats.Categories)},
+		}
+		for _, res := range stats.MostDepended {
+			records = append(records, []string{"most_depended", res.Id, fmt.Sprint(res.Count)})
+		}
+		return records
+	}}
+	return dr.render(stats, table, porcelain, func() {
+		PrintMessage("📦 Resources: %d\n🔗 Edges: %d\n📏 Max depth: %d\n📥 Average fan-in: %.2f\n📤 Average fan-out: %.2f\n🌳 Largest closure: %s (%d)\n🏷️  Categories: %d\n",
+			stats.Resources, stats.Edges, stats.MaxDepth, stats.AverageFanIn, stats.AverageFanOut,
+			styles.id.Render(stats.LargestClosure.Id), stats.LargestClosure.Count, stats.Categories)
+		if len(stats.MostDepended) > 0 {
+			Println("⭐ Most depended upon:")
+		}
+		for _, res := range stats.MostDepended {
+			PrintMessage("  %s (%d)\n", styles.id.Render(res.Id), res.Count)
+		}
+	})
 }
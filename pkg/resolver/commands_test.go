@@ -1,10 +1,14 @@
 package resolver
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/jjuliano/runner/pkg/runnerexec"
@@ -38,7 +42,7 @@ func setupTestRunResolver() *DependencyResolver {
 	}
 	defer session.Close()
 
-	resolver, err := NewGraphResolver(fs, logger, workDir, session)
+	resolver, err := NewGraphResolver(fs, NewCharmLogger(logger), workDir, session)
 	if err != nil {
 		log.Fatalf("Failed to create dependency resolver: %v", err)
 	}
@@ -112,6 +116,7 @@ func TestProcessSteps(t *testing.T) {
 }
 
 func TestProcessElement(t *testing.T) {
+	resolver := setupTestResolver()
 	// Mock HTTP client
 	httpClient := &http.Client{}
 
@@ -137,7 +142,7 @@ func TestProcessElement(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Execute the function with the test case input
-			err := ProcessSingleNodeRule(tc.element, httpClient, &RunnerLogs{})
+			err := resolver.ProcessSingleNodeRule(tc.element, httpClient, &RunnerLogs{})
 
 			// Check if the error matches the expected error
 			if (err == nil && tc.expectedErr != nil) || (err != nil && tc.expectedErr == nil) || (err != nil && tc.expectedErr != nil && err.Error() != tc.expectedErr.Error()) {
@@ -148,10 +153,11 @@ func TestProcessElement(t *testing.T) {
 }
 
 func TestProcessElement_String(t *testing.T) {
+	resolver := setupTestResolver()
 	client := &http.Client{}
 	expectedPrefix := "ENV:HELLO"
 
-	err := ProcessSingleNodeRule(expectedPrefix, client, &RunnerLogs{})
+	err := resolver.ProcessSingleNodeRule(expectedPrefix, client, &RunnerLogs{})
 	expectedError := "expected environment variable 'HELLO' does not exist"
 	if err.Error() != expectedError {
 		t.Errorf("Expected error: %s, got: %v", expectedError, err)
@@ -159,10 +165,11 @@ func TestProcessElement_String(t *testing.T) {
 }
 
 func TestProcessElement_Map(t *testing.T) {
+	resolver := setupTestResolver()
 	client := &http.Client{}
 	expectedExpectations := []interface{}{"unfound value"}
 
-	err := ProcessSingleNodeRule(map[interface{}]interface{}{"expect": expectedExpectations}, client, &RunnerLogs{})
+	err := resolver.ProcessSingleNodeRule(map[interface{}]interface{}{"expect": expectedExpectations}, client, &RunnerLogs{})
 	expectedError := "expected 'unfound value' not found in output"
 	if err.Error() != expectedError {
 		t.Errorf("Expected error: %s, got: %v", expectedError, err)
@@ -262,3 +269,102 @@ func TestAddLogEntry(t *testing.T) {
 	}
 
 }
+
+func TestHandleRunCommand_RecordsMetrics(t *testing.T) {
+	resolver := setupTestRunResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "ok", Name: "Ok", Run: []RunStep{{Name: "succeed", Exec: "true"}}},
+		{Id: "broken", Name: "Broken", Requires: []string{"ok"}, Run: []RunStep{{Name: "fail", Exec: "exit 3"}}},
+	}
+	resolver.BuildDependencies()
+
+	failures := resourceFailures.Value("broken")
+	err := resolver.HandleRunCommand([]string{"broken"})
+	if err == nil {
+		t.Fatalf("Expected the run to fail")
+	}
+
+	if resourceFailures.Value("broken") != failures+1 {
+		t.Errorf("Expected the failure to be counted")
+	}
+	if resourceFailures.Value("ok") != 0 {
+		t.Errorf("Expected no failures for 'ok', got %v", resourceFailures.Value("ok"))
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	resolver := setupTestRunResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "slow", Name: "Slow", Run: []RunStep{{Name: "sleep", Exec: "sleep 5"}}},
+		{Id: "after", Name: "After", Requires: []string{"slow"}, Run: []RunStep{{Name: "echo", Exec: "echo after"}}},
+	}
+	resolver.BuildDependencies()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := resolver.Run(ctx, []string{"after"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the run to be interrupted, took %s", elapsed)
+	}
+}
+
+func TestExecute_EnvVarFailure(t *testing.T) {
+	resolver := setupTestRunResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Run: []RunStep{{Name: "start", Exec: "true", Env: []EnvVar{{Name: "CONFIG", File: "$RUNNER_TEST_UNSET"}}}}},
+	}
+	resolver.BuildDependencies()
+
+	results, err := resolver.Execute(context.Background(), []string{"app"})
+	if ExitCode(err) != ExitExecution {
+		t.Errorf("Expected the run to fail with an execution error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusFailed {
+		t.Errorf("Expected 'app' to be recorded as failed, got %+v", results)
+	}
+}
+
+func TestListingCommands_Deterministic(t *testing.T) {
+	ids, deps := randomGraph(40, 80)
+	outputs := func() string {
+		resolver := setupTestResolver()
+		resolver.OutputFormat = FormatJSON
+		for i, id := range ids {
+			resolver.Resources = append(resolver.Resources, ResourceNodeEntry{
+				Id:       id,
+				Requires: deps[id],
+				Category: fmt.Sprintf("c%d", i%3),
+				Owners:   []string{fmt.Sprintf("o%d", i%4)},
+				License:  fmt.Sprintf("L%d", i%5),
+			})
+		}
+		resolver.BuildDependencies()
+
+		return captureOutput(func() {
+			resolver.HandleIndexCommand()
+			resolver.HandleDependsCommand(ids[:5])
+			resolver.HandleRDependsCommand(ids[:5])
+			resolver.HandleTreeCommand(ids[:2])
+			resolver.HandleCyclesCommand()
+			resolver.HandleLeavesCommand()
+			resolver.HandleRootsCommand()
+			resolver.HandleRedundantCommand()
+			resolver.HandleValidateCommand(true, false)
+			resolver.HandleLicensesCommand(ids[0], nil)
+			resolver.HandleOwnersCommand(ids[0])
+			resolver.HandleStatsCommand()
+		})
+	}
+
+	expected := outputs()
+	for i := 0; i < 5; i++ {
+		if got := outputs(); got != expected {
+			t.Fatalf("Expected the same output on every run, got:\n%s\nthen:\n%s", expected, got)
+		}
+	}
+}
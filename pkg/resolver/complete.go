@@ -0,0 +1,24 @@
+package resolver
+
+import (
+	"sort"
+	"strings"
+)
+
+// CompleteIDs returns the resources whose ID starts with prefix, once
+// normalized following the IDPolicy, sorted by ID. It backs the shell
+// completion of resource IDs.
+func (dr *DependencyResolver) CompleteIDs(prefix string) []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	prefix = dr.IDs.ID(prefix)
+	var matches []ResourceNodeEntry
+	for _, entry := range dr.Resources {
+		if strings.HasPrefix(entry.Id, prefix) {
+			matches = append(matches, entry)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Id < matches[j].Id })
+	return matches
+}
@@ -0,0 +1,33 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompleteIDs(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{{Id: "zookeeper"}, {Id: "api"}, {Id: "zlib"}, {Id: "zlib/dev"}}
+	resolver.BuildDependencies()
+
+	ids := func(entries []ResourceNodeEntry) []string {
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.Id)
+		}
+		return ids
+	}
+	if got := ids(resolver.CompleteIDs("z")); !reflect.DeepEqual(got, []string{"zlib", "zlib/dev", "zookeeper"}) {
+		t.Errorf("Expected the IDs starting with z in order, got %v", got)
+	}
+	if got := ids(resolver.CompleteIDs("Z")); got != nil {
+		t.Errorf("Expected case-sensitive completion, got %v", got)
+	}
+	resolver.IDs.CaseInsensitive = true
+	if got := ids(resolver.CompleteIDs("ZL")); !reflect.DeepEqual(got, []string{"zlib", "zlib/dev"}) {
+		t.Errorf("Expected case-insensitive completion, got %v", got)
+	}
+	if got := ids(resolver.CompleteIDs("")); len(got) != 4 {
+		t.Errorf("Expected every ID, got %v", got)
+	}
+}
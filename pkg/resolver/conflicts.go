@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConflictError is returned when two resources that declare a conflict end up
+// in the same transitive closure.
+type ConflictError struct {
+	Resource     string
+	Conflict     string
+	ResourcePath []string
+	ConflictPath []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("resource '%s' conflicts with '%s' (pulled in by %s and %s)",
+		e.Resource, e.Conflict, strings.Join(e.ResourcePath, " -> "), strings.Join(e.ConflictPath, " -> "))
+}
+
+// CheckConflicts verifies that no two conflicting resources are part of the
+// closure of the given targets. When WarnOnConflict is set, conflicts are
+// logged as warnings instead of being returned.
+func (dr *DependencyResolver) CheckConflicts(ctx context.Context, targets []string) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.checkConflicts(ctx, targets)
+}
+
+// checkConflicts only computes the dependency paths of the targets when a
+// conflict is found, testing membership in their closure with reachability
+// sets otherwise.
+func (dr *DependencyResolver) checkConflicts(ctx context.Context, targets []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	included := dr.inClosure(targets)
+	var paths map[string][]string
+	for _, entry := range dr.Resources {
+		if len(entry.Conflicts) == 0 || !included(entry.Id) {
+			continue
+		}
+		for _, conflict := range entry.Conflicts {
+			if !included(conflict) {
+				continue
+			}
+			if paths == nil {
+				var err error
+				if paths, err = dr.dependencyPaths(ctx, targets); err != nil {
+					return err
+				}
+			}
+			err := &ConflictError{
+				Resource:     entry.Id,
+				Conflict:     conflict,
+				ResourcePath: paths[entry.Id],
+				ConflictPath: paths[conflict],
+			}
+			if !dr.WarnOnConflict {
+				return err
+			}
+			dr.logger().Warn("Conflicting resources", "resource", err.Resource, "conflict", err.Conflict)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func setupConflictResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "mysql", Name: "MySQL", Conflicts: []string{"mariadb"}},
+		{Id: "mariadb", Name: "MariaDB"},
+		{Id: "backend", Name: "Backend", Requires: []string{"mysql"}},
+		{Id: "reports", Name: "Reports", Requires: []string{"mariadb"}},
+		{Id: "app", Name: "App", Requires: []string{"backend", "reports"}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestCheckConflicts(t *testing.T) {
+	resolver := setupConflictResolver()
+
+	err := resolver.CheckConflicts(context.Background(), []string{"app"})
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected a ConflictError, got %v", err)
+	}
+	if conflictErr.Resource != "mysql" || conflictErr.Conflict != "mariadb" {
+		t.Errorf("Expected conflict between 'mysql' and 'mariadb', got '%s' and '%s'", conflictErr.Resource, conflictErr.Conflict)
+	}
+
+	expectedError := "resource 'mysql' conflicts with 'mariadb' (pulled in by app -> backend -> mysql and app -> reports -> mariadb)"
+	if err.Error() != expectedError {
+		t.Errorf("Expected error: %s, got: %v", expectedError, err)
+	}
+}
+
+func TestCheckConflicts_SeparateClosures(t *testing.T) {
+	resolver := setupConflictResolver()
+
+	if err := resolver.CheckConflicts(context.Background(), []string{"backend"}); err != nil {
+		t.Errorf("Expected no conflict, got %v", err)
+	}
+	if err := resolver.CheckConflicts(context.Background(), []string{"backend", "reports"}); err == nil {
+		t.Errorf("Expected a conflict when resolving both targets together")
+	}
+}
+
+func TestCheckConflicts_Warn(t *testing.T) {
+	resolver := setupConflictResolver()
+	resolver.WarnOnConflict = true
+
+	if err := resolver.CheckConflicts(context.Background(), []string{"app"}); err != nil {
+		t.Errorf("Expected conflicts to be reported as warnings, got %v", err)
+	}
+}
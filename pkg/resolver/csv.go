@@ -0,0 +1,74 @@
+package resolver
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// WriteNodesCSV writes the resources as CSV, one row per resource with its
+// ID, name, version, category and description, after a header row. comma is
+// the field separator, such as ',' or '\t'.
+func (dr *DependencyResolver) WriteNodesCSV(w io.Writer, comma rune) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.Write([]string{"id", "name", "version", "category", "desc"})
+	for _, entry := range dr.Resources {
+		cw.Write([]string{entry.Id, entry.Name, entry.Version, entry.Category, entry.Desc})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEdgesCSV writes the dependency edges as CSV, one from,to row per
+// requirement of every resource, after a header row.
+func (dr *DependencyResolver) WriteEdgesCSV(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.Write([]string{"from", "to"})
+	for _, edge := range dr.Edges() {
+		cw.Write([]string{edge.From, edge.To})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes nodes.csv and edges.csv to dir, or nodes.tsv and edges.tsv
+// when comma is a tab. See WriteNodesCSV and WriteEdgesCSV for their
+// columns.
+func (dr *DependencyResolver) WriteCSV(dir string, comma rune) error {
+	ext := ".csv"
+	if comma == '\t' {
+		ext = ".tsv"
+	}
+	if err := dr.Fs.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %w", dir, err)
+	}
+
+	files := []struct {
+		name  string
+		write func(io.Writer, rune) error
+	}{
+		{"nodes" + ext, dr.WriteNodesCSV},
+		{"edges" + ext, dr.WriteEdgesCSV},
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		f, err := dr.Fs.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %w", path, err)
+		}
+		err = file.write(f, comma)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
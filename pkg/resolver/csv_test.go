@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func setupCSVResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "git", Name: "Git", Version: "2.45", Category: "vcs", Desc: "Version control, distributed"},
+		{Id: "tag", Name: `The "tag"`, Requires: []string{"git"}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestWriteNodesCSV(t *testing.T) {
+	resolver := setupCSVResolver()
+
+	var output strings.Builder
+	if err := resolver.WriteNodesCSV(&output, ','); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedOutput := `id,name,version,category,desc
+git,Git,2.45,vcs,"Version control, distributed"
+tag,"The ""tag""",,,
+`
+	if output.String() != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output.String())
+	}
+}
+
+func TestWriteEdgesCSV(t *testing.T) {
+	resolver := setupCSVResolver()
+
+	var output strings.Builder
+	if err := resolver.WriteEdgesCSV(&output, '\t'); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expectedOutput := "from\tto\ntag\tgit\n"; output.String() != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	resolver := setupCSVResolver()
+
+	if err := resolver.WriteCSV("export", '\t'); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	nodes, err := afero.ReadFile(resolver.Fs, "export/nodes.tsv")
+	if err != nil || !strings.HasPrefix(string(nodes), "id\tname\tversion\tcategory\tdesc\ngit\tGit\t2.45\t") {
+		t.Errorf("Expected the nodes as TSV, got %q, %v", nodes, err)
+	}
+	edges, err := afero.ReadFile(resolver.Fs, "export/edges.tsv")
+	if err != nil || string(edges) != "from\tto\ntag\tgit\n" {
+		t.Errorf("Expected the edges as TSV, got %q, %v", edges, err)
+	}
+}
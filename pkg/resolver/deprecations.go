@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Deprecation is a deprecated resource of a closure, with the resources of
+// the closure requiring it.
+type Deprecation struct {
+	Resource   string   `json:"resource" yaml:"resource"`
+	ReplacedBy string   `json:"replacedBy,omitempty" yaml:"replacedBy,omitempty"`
+	RequiredBy []string `json:"requiredBy" yaml:"requiredBy"`
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("resource '%s' is deprecated%s", d.Resource, d.replacement())
+}
+
+// replacement suggests the replacement of the resource, if any.
+func (d Deprecation) replacement() string {
+	if d.ReplacedBy == "" {
+		return ""
+	}
+	return fmt.Sprintf(", use '%s' instead", d.ReplacedBy)
+}
+
+// Deprecations returns the deprecated resources among the target and its
+// closure, in breadth-first order from the target.
+func (dr *DependencyResolver) Deprecations(target string) ([]Deprecation, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if _, ok := dr.getResource(target); !ok {
+		return nil, &NotFoundError{Id: target}
+	}
+	if err := dr.checkLimits([]string{target}, false, 0); err != nil {
+		return nil, err
+	}
+	closure := dr.cache.memoize(&dr.cache.closures, target, func() []string {
+		return dr.closure(target, 0)
+	})
+	return dr.deprecations(append([]string{target}, closure...)), nil
+}
+
+// deprecations returns the deprecated resources among ids, each with the
+// resources among ids requiring it directly.
+func (dr *DependencyResolver) deprecations(ids []string) []Deprecation {
+	deprecations := []Deprecation{}
+	for _, id := range ids {
+		entry, ok := dr.getResource(id)
+		if !ok || !entry.Deprecated {
+			continue
+		}
+		deprecation := Deprecation{Resource: id, ReplacedBy: entry.ReplacedBy, RequiredBy: []string{}}
+		for _, dependent := range ids {
+			if slices.Contains(dr.ResourceDependencies[dependent], id) {
+				deprecation.RequiredBy = append(deprecation.RequiredBy, dependent)
+			}
+		}
+		deprecations = append(deprecations, deprecation)
+	}
+	return deprecations
+}
+
+// warnTraversed logs a warning for every deprecated resource among the
+// resources a traversal visited.
+func (dr *DependencyResolver) warnTraversed(ids []string) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	dr.warnDeprecated(ids)
+}
+
+// warnDeprecated logs a warning for every deprecated resource of a resolved
+// run or a traversal. The caller must hold the lock.
+func (dr *DependencyResolver) warnDeprecated(stack []string) {
+	for _, deprecation := range dr.deprecations(stack) {
+		entry, _ := dr.getResource(deprecation.Resource)
+		fields := entryFields(entry, "", "requiredBy", deprecation.RequiredBy)
+		if deprecation.ReplacedBy != "" {
+			fields = append(fields, "replacedBy", deprecation.ReplacedBy)
+		}
+		dr.logger().Warn("Deprecated resource", fields...)
+	}
+}
@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func setupDeprecationsResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"api", "pg12"}},
+		{Id: "api", Requires: []string{"pg12", "cache"}},
+		{Id: "pg12", Deprecated: true, ReplacedBy: "pg16"},
+		{Id: "pg16"},
+		{Id: "cache", Deprecated: true},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestDeprecations(t *testing.T) {
+	resolver := setupDeprecationsResolver()
+
+	deprecations, err := resolver.Deprecations("app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Deprecation{
+		{Resource: "pg12", ReplacedBy: "pg16", RequiredBy: []string{"app", "api"}},
+		{Resource: "cache", RequiredBy: []string{"api"}},
+	}
+	if !reflect.DeepEqual(deprecations, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, deprecations)
+	}
+	if deprecations, _ := resolver.Deprecations("pg16"); len(deprecations) != 0 {
+		t.Errorf("Expected no deprecations, got %+v", deprecations)
+	}
+	if _, err := resolver.Deprecations("missing"); err == nil {
+		t.Error("Expected an error for a missing target")
+	}
+}
+
+func TestWarnings_Deprecated(t *testing.T) {
+	resolver := setupDeprecationsResolver()
+
+	var messages []string
+	for _, w := range resolver.Warnings() {
+		if w.Code == CodeDeprecatedResource {
+			messages = append(messages, w.Message)
+		}
+	}
+	expected := []string{
+		"'app' requires deprecated resource 'pg12', use 'pg16' instead",
+		"'api' requires deprecated resource 'pg12', use 'pg16' instead",
+		"'api' requires deprecated resource 'cache'",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestResolve_WarnsDeprecated(t *testing.T) {
+	resolver := setupDeprecationsResolver()
+	var logs bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&logs))
+
+	if _, err := resolver.Resolve(context.Background(), []string{"api"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output := logs.String(); !strings.Contains(output, "Deprecated resource resource=pg12 requiredBy=[api] replacedBy=pg16") || !strings.Contains(output, "Deprecated resource resource=cache requiredBy=[api]\n") {
+		t.Errorf("Expected the deprecated resources to be logged, got %q", output)
+	}
+}
+
+func TestTraversals_WarnDeprecated(t *testing.T) {
+	traversals := []struct {
+		name     string
+		handle   func(*DependencyResolver) error
+		expected []string
+	}{
+		{"depends", func(dr *DependencyResolver) error { return dr.HandleDependsCommand([]string{"api"}) },
+			[]string{"resource=pg12 requiredBy=[api] replacedBy=pg16", "resource=cache requiredBy=[api]\n"}},
+		{"tree", func(dr *DependencyResolver) error { return dr.HandleTreeCommand([]string{"api"}) },
+			[]string{"resource=pg12 requiredBy=[api] replacedBy=pg16", "resource=cache requiredBy=[api]\n"}},
+		{"rdepends", func(dr *DependencyResolver) error { return dr.HandleRDependsCommand([]string{"pg12"}) },
+			[]string{`resource=pg12 requiredBy="[app api]" replacedBy=pg16`}},
+	}
+	for _, traversal := range traversals {
+		t.Run(traversal.name, func(t *testing.T) {
+			resolver := setupDeprecationsResolver()
+			var logs bytes.Buffer
+			resolver.Logger = NewCharmLogger(log.New(&logs))
+
+			var err error
+			captureOutput(func() { err = traversal.handle(resolver) })
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range traversal.expected {
+				if !strings.Contains(logs.String(), "Deprecated resource "+expected) {
+					t.Errorf("Expected %q to be logged, got %q", expected, logs.String())
+				}
+			}
+		})
+	}
+
+	// The resources outside the traversal are not reported.
+	resolver := setupDeprecationsResolver()
+	var logs bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&logs))
+	captureOutput(func() { resolver.HandleRDependsCommand([]string{"pg16"}) })
+	if strings.Contains(logs.String(), "Deprecated resource") {
+		t.Errorf("Expected no deprecation to be logged, got %q", logs.String())
+	}
+}
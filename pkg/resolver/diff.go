@@ -0,0 +1,105 @@
+package resolver
+
+import (
+	"bytes"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Edge is a dependency from a resource to one of its requirements.
+type Edge struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// CatalogDiff describes the differences between two catalogs. All lists are
+// sorted.
+type CatalogDiff struct {
+	Added        []string `json:"added" yaml:"added"`
+	Removed      []string `json:"removed" yaml:"removed"`
+	Changed      []string `json:"changed" yaml:"changed"`
+	AddedEdges   []Edge   `json:"addedEdges" yaml:"addedEdges"`
+	RemovedEdges []Edge   `json:"removedEdges" yaml:"removedEdges"`
+}
+
+// Empty reports whether the catalogs are identical.
+func (d CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// DiffCatalogs compares two catalogs, reporting added, removed and changed
+// resources as well as added and removed dependency edges.
+func DiffCatalogs(old, new *DependencyResolver) CatalogDiff {
+	oldResources := resourcesByID(old.ListResources())
+	newResources := resourcesByID(new.ListResources())
+
+	var diff CatalogDiff
+	for id, entry := range newResources {
+		oldEntry, exists := oldResources[id]
+		if !exists {
+			diff.Added = append(diff.Added, id)
+		} else if !sameResource(oldEntry, entry) {
+			diff.Changed = append(diff.Changed, id)
+		}
+	}
+	for id := range oldResources {
+		if _, exists := newResources[id]; !exists {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+
+	oldEdges := edgeSet(old.Edges())
+	newEdges := edgeSet(new.Edges())
+	for edge := range newEdges {
+		if !oldEdges[edge] {
+			diff.AddedEdges = append(diff.AddedEdges, edge)
+		}
+	}
+	for edge := range oldEdges {
+		if !newEdges[edge] {
+			diff.RemovedEdges = append(diff.RemovedEdges, edge)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	sortEdges(diff.AddedEdges)
+	sortEdges(diff.RemovedEdges)
+	return diff
+}
+
+func resourcesByID(resources []ResourceNodeEntry) map[string]ResourceNodeEntry {
+	byID := make(map[string]ResourceNodeEntry, len(resources))
+	for _, entry := range resources {
+		byID[entry.Id] = entry
+	}
+	return byID
+}
+
+// sameResource compares entries by their serialized form, so that an empty
+// and a missing list are considered equal.
+func sameResource(a, b ResourceNodeEntry) bool {
+	aData, aErr := yaml.Marshal(a)
+	bData, bErr := yaml.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+func edgeSet(edges []Edge) map[Edge]bool {
+	set := make(map[Edge]bool, len(edges))
+	for _, edge := range edges {
+		set[edge] = true
+	}
+	return set
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
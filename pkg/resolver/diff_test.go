@@ -0,0 +1,53 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	old := setupTestResolver()
+	old.Resources = []ResourceNodeEntry{
+		{Id: "a", Name: "A"},
+		{Id: "b", Name: "B", Requires: []string{"a"}},
+		{Id: "c", Name: "C", Requires: []string{"b"}},
+	}
+	old.BuildDependencies()
+
+	new := setupTestResolver()
+	new.Resources = []ResourceNodeEntry{
+		{Id: "a", Name: "A", Requires: []string{}},
+		{Id: "b", Name: "B2", Requires: []string{"a"}},
+		{Id: "d", Name: "D", Requires: []string{"a", "b"}},
+	}
+	new.BuildDependencies()
+
+	diff := DiffCatalogs(old, new)
+
+	expected := CatalogDiff{
+		Added:        []string{"d"},
+		Removed:      []string{"c"},
+		Changed:      []string{"b"},
+		AddedEdges:   []Edge{{From: "d", To: "a"}, {From: "d", To: "b"}},
+		RemovedEdges: []Edge{{From: "c", To: "b"}},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected diff %+v, got %+v", expected, diff)
+	}
+	if diff.Empty() {
+		t.Errorf("Expected the diff not to be empty")
+	}
+
+	if diff := DiffCatalogs(old, old); !diff.Empty() {
+		t.Errorf("Expected no differences, got %+v", diff)
+	}
+}
+
+func TestEdges(t *testing.T) {
+	resolver := setupTestResolver()
+
+	edges := resolver.Edges()
+	if len(edges) != 25 || edges[0] != (Edge{From: "b", To: "a"}) {
+		t.Errorf("Expected 25 edges starting with b -> a, got %v", edges)
+	}
+}
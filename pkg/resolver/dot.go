@@ -0,0 +1,31 @@
+package resolver
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the dependency graph in Graphviz DOT format, with an edge
+// from every resource to each of its requirements.
+func (dr *DependencyResolver) WriteDOT(w io.Writer) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph runner {\n")
+	for _, entry := range dr.Resources {
+		bw.WriteString("  " + dotQuote(entry.Id) + " [label=" + dotQuote(entry.Name) + "];\n")
+	}
+	for _, entry := range dr.Resources {
+		for _, dep := range dr.ResourceDependencies[entry.Id] {
+			bw.WriteString("  " + dotQuote(entry.Id) + " -> " + dotQuote(dep) + ";\n")
+		}
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
@@ -0,0 +1,30 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Name: "A"},
+		{Id: "b", Name: `The "B"`, Requires: []string{"a"}},
+	}
+	resolver.BuildDependencies()
+
+	var output strings.Builder
+	if err := resolver.WriteDOT(&output); err != nil {
+		t.Fatalf("Failed to write DOT: %v", err)
+	}
+
+	expectedOutput := `digraph runner {
+  "a" [label="A"];
+  "b" [label="The \"B\""];
+  "b" -> "a";
+}
+`
+	if output.String() != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output.String())
+	}
+}
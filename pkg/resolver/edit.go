@@ -0,0 +1,229 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/afero"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// AddResourceToManifest adds a resource to the catalog like AddResourceEntry,
+// and writes it to the manifest at path: appended to the resources it lists,
+// or to a new manifest named after its ID when path is a directory of
+// manifests. A missing manifest is created. Every requirement of the resource
+// must be in the catalog. It returns the path of the manifest written.
+func (dr *DependencyResolver) AddResourceToManifest(path string, entry ResourceNodeEntry) (string, error) {
+	entry = dr.IDs.entry(entry)
+	if err := dr.checkNewRequirements(entry.Id, entry.Requires); err != nil {
+		return "", err
+	}
+	resource, err := resourceNode(entry)
+	if err != nil {
+		return "", err
+	}
+
+	var m *manifestEdit
+	if info, err := dr.Fs.Stat(path); err == nil && info.IsDir() {
+		path = NewFileStore(dr.Fs, path).path(entry.Id)
+		if _, err := dr.Fs.Stat(path); err == nil {
+			return "", fmt.Errorf("%s: %w", path, os.ErrExist)
+		}
+		data, err := encodeYAMLNode(resource)
+		if err != nil {
+			return "", err
+		}
+		m, err = parseManifestEdit(path, []byte(data), dr.IDs)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		data, err := afero.ReadFile(dr.Fs, path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if m, err = parseManifestEdit(path, data, dr.IDs); err != nil {
+			return "", err
+		}
+		if err := m.AppendResource(resource); err != nil {
+			return "", err
+		}
+	}
+
+	if err := dr.AddResourceEntry(entry); err != nil {
+		return "", err
+	}
+	if err := dr.writeManifestEdit(m, entry.Id); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// AddRequirementsInManifest adds requirements to a loaded resource, and to
+// the manifest defining it. Every requirement must be in the catalog, and
+// not already required.
+func (dr *DependencyResolver) AddRequirementsInManifest(id string, requirements []string) error {
+	id, requirements = dr.IDs.ID(id), dr.IDs.IDs(requirements)
+	return dr.editRequirements(id, func(m *manifestEdit, entry *ResourceNodeEntry) error {
+		if err := dr.checkNewRequirements(id, requirements); err != nil {
+			return err
+		}
+		for _, req := range requirements {
+			if slices.Contains(entry.Requires, req) {
+				return fmt.Errorf("resource '%s' already requires '%s'", id, req)
+			}
+			if err := m.AddRequirement(id, req); err != nil {
+				return err
+			}
+			entry.Requires = append(slices.Clip(entry.Requires), req)
+		}
+		return nil
+	})
+}
+
+// RemoveRequirementsInManifest removes requirements from a loaded resource,
+// and from the manifest defining it.
+func (dr *DependencyResolver) RemoveRequirementsInManifest(id string, requirements []string) error {
+	id, requirements = dr.IDs.ID(id), dr.IDs.IDs(requirements)
+	return dr.editRequirements(id, func(m *manifestEdit, entry *ResourceNodeEntry) error {
+		for _, req := range requirements {
+			if err := m.RemoveRequirement(id, req); err != nil {
+				return err
+			}
+			entry.Requires = slices.DeleteFunc(slices.Clone(entry.Requires), func(r string) bool { return r == req })
+		}
+		return nil
+	})
+}
+
+// RemoveResourceFromManifest removes a resource from the catalog like
+// RemoveResource, and from the manifest defining it. A manifest defining
+// only that resource at the top level is deleted.
+func (dr *DependencyResolver) RemoveResourceFromManifest(id string, force bool) error {
+	id = dr.IDs.ID(id)
+	m, err := dr.openManifestOf(id)
+	if err != nil {
+		return err
+	}
+	if !m.single() {
+		if err := m.RemoveResource(id); err != nil {
+			return err
+		}
+	} else if _, _, err := m.resource(id); err != nil {
+		return err
+	}
+
+	if err := dr.RemoveResource(id, force); err != nil {
+		return err
+	}
+	if m.single() {
+		if err := dr.Fs.Remove(m.path); err != nil {
+			return fmt.Errorf("error removing %s: %w", m.path, err)
+		}
+		return nil
+	}
+	return dr.writeManifestEdit(m, "")
+}
+
+// editRequirements applies edit to the manifest defining the resource id and
+// to a copy of the resource, then updates the resource and writes the
+// manifest.
+func (dr *DependencyResolver) editRequirements(id string, edit func(*manifestEdit, *ResourceNodeEntry) error) error {
+	entry, ok := dr.GetResource(id)
+	if !ok {
+		return &NotFoundError{Id: id}
+	}
+	m, err := dr.openManifestOf(id)
+	if err != nil {
+		return err
+	}
+	if err := edit(m, &entry); err != nil {
+		return err
+	}
+	if err := dr.UpdateResource(entry); err != nil {
+		return err
+	}
+	return dr.writeManifestEdit(m, "")
+}
+
+// openManifestOf parses the local manifest defining the resource id for
+// editing.
+func (dr *DependencyResolver) openManifestOf(id string) (*manifestEdit, error) {
+	dr.mu.RLock()
+	path, ok := dr.sources[id]
+	dr.mu.RUnlock()
+	if !ok || !isManifest(path) {
+		return nil, fmt.Errorf("resource '%s' is not defined in a local manifest", id)
+	}
+	data, err := afero.ReadFile(dr.Fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("resource '%s' is not defined in a local manifest: %w", id, err)
+	}
+	return parseManifestEdit(path, data, dr.IDs)
+}
+
+// writeManifestEdit writes the edited manifest, recording it as the source of
+// the resource id, when set.
+func (dr *DependencyResolver) writeManifestEdit(m *manifestEdit, id string) error {
+	if err := dr.Fs.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(dr.Fs, m.path, m.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", m.path, err)
+	}
+	if id != "" {
+		dr.mu.Lock()
+		dr.sources[id] = m.path
+		dr.mu.Unlock()
+	}
+	return nil
+}
+
+// checkNewRequirements returns an error for the first requirement of the
+// resource id that is neither a loaded resource, a virtual resource nor an
+// alias.
+func (dr *DependencyResolver) checkNewRequirements(id string, requirements []string) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	known := dr.knownResources()
+	for _, req := range requirements {
+		if !dr.exists(known, req) {
+			return fmt.Errorf("missing %w", &DanglingRequirementError{Id: req, RequiredBy: []string{id}})
+		}
+	}
+	return nil
+}
+
+// resourceNode returns the YAML node of the entry, without its empty fields.
+func resourceNode(entry ResourceNodeEntry) (*yamlv3.Node, error) {
+	var node yamlv3.Node
+	if err := node.Encode(entry); err != nil {
+		return nil, fmt.Errorf("error encoding resource '%s': %w", entry.Id, err)
+	}
+	pruneEmpty(&node)
+	return &node, nil
+}
+
+// pruneEmpty removes the fields of the mappings under node set to null, an
+// empty string or an empty collection.
+func pruneEmpty(node *yamlv3.Node) {
+	for _, child := range node.Content {
+		pruneEmpty(child)
+	}
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+	var content []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		switch {
+		case isNull(value), value.Kind == yamlv3.ScalarNode && value.ShortTag() == "!!str" && value.Value == "":
+		case value.Kind != yamlv3.ScalarNode && len(value.Content) == 0:
+		default:
+			content = append(content, node.Content[i], value)
+		}
+	}
+	node.Content = content
+}
@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func setupEditResolver(t *testing.T) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	afero.WriteFile(resolver.Fs, "catalog/main.yaml", []byte("resources:\n  - id: db\n\n  - id: app # the app\n    requires: [db]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/extra/web.yaml", []byte("id: web\nrequires:\n  - app\n"), 0644)
+	if err := resolver.LoadSource("catalog"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestAddResourceToManifest(t *testing.T) {
+	resolver := setupEditResolver(t)
+
+	if _, err := resolver.AddResourceToManifest("catalog/main.yaml", ResourceNodeEntry{Id: "cache", Desc: "Cache", Requires: []string{"db"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, _ := afero.ReadFile(resolver.Fs, "catalog/main.yaml")
+	expected := "resources:\n  - id: db\n\n  - id: app # the app\n    requires: [db]\n\n  - id: cache\n    desc: Cache\n    requires:\n      - db\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+	if deps := resolver.DependenciesWithin("cache", 0); !reflect.DeepEqual(deps, []string{"db"}) {
+		t.Errorf("Expected the resource in the catalog, got %v", deps)
+	}
+
+	if path, err := resolver.AddResourceToManifest("catalog/extra", ResourceNodeEntry{Id: "worker"}); err != nil || path != "catalog/extra/worker.yaml" {
+		t.Fatalf("Expected a new manifest, got %s: %v", path, err)
+	}
+	if data, _ := afero.ReadFile(resolver.Fs, "catalog/extra/worker.yaml"); string(data) != "id: worker\n" {
+		t.Errorf("Expected a manifest for the resource, got %q", data)
+	}
+
+	var dup *DuplicateResourceError
+	if _, err := resolver.AddResourceToManifest("catalog/main.yaml", ResourceNodeEntry{Id: "db"}); !errors.As(err, &dup) {
+		t.Errorf("Expected a duplicate, got %v", err)
+	}
+	var dangling *DanglingRequirementError
+	if _, err := resolver.AddResourceToManifest("catalog/main.yaml", ResourceNodeEntry{Id: "api", Requires: []string{"missing"}}); !errors.As(err, &dangling) {
+		t.Errorf("Expected a missing requirement, got %v", err)
+	}
+	if after, _ := afero.ReadFile(resolver.Fs, "catalog/main.yaml"); string(after) != expected {
+		t.Errorf("Expected the failed additions to leave the manifest unchanged, got %q", after)
+	}
+}
+
+func TestEditRequirementsInManifest(t *testing.T) {
+	resolver := setupEditResolver(t)
+
+	if err := resolver.AddRequirementsInManifest("web", []string{"db"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.AddRequirementsInManifest("db", []string{"app", "web"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.RemoveRequirementsInManifest("app", []string{"db"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, _ := afero.ReadFile(resolver.Fs, "catalog/main.yaml")
+	if expected := "resources:\n  - id: db\n    requires:\n      - app\n      - web\n\n  - id: app # the app\n    requires: []\n"; string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+	if data, _ := afero.ReadFile(resolver.Fs, "catalog/extra/web.yaml"); string(data) != "id: web\nrequires:\n  - app\n  - db\n" {
+		t.Errorf("Expected the requirement in the single resource manifest, got %q", data)
+	}
+	if deps := resolver.DirectDependencies("db"); !reflect.DeepEqual(deps, []string{"app", "web"}) {
+		t.Errorf("Expected the catalog to be updated, got %v", deps)
+	}
+
+	if err := resolver.AddRequirementsInManifest("db", []string{"app"}); err == nil {
+		t.Error("Expected an existing requirement to fail")
+	}
+	if err := resolver.RemoveRequirementsInManifest("app", []string{"db"}); err == nil {
+		t.Error("Expected a missing requirement to fail")
+	}
+	var self *SelfDependencyError
+	if err := resolver.AddRequirementsInManifest("app", []string{"app"}); !errors.As(err, &self) {
+		t.Errorf("Expected a self dependency, got %v", err)
+	}
+}
+
+func TestRemoveResourceFromManifest(t *testing.T) {
+	resolver := setupEditResolver(t)
+
+	var dangling *DanglingRequirementError
+	if err := resolver.RemoveResourceFromManifest("app", false); !errors.As(err, &dangling) {
+		t.Errorf("Expected a dangling requirement, got %v", err)
+	}
+	if err := resolver.RemoveResourceFromManifest("web", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := resolver.Fs.Stat("catalog/extra/web.yaml"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the single resource manifest to be removed, got %v", err)
+	}
+	if err := resolver.RemoveResourceFromManifest("app", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, _ := afero.ReadFile(resolver.Fs, "catalog/main.yaml"); string(data) != "resources:\n  - id: db\n" {
+		t.Errorf("Expected the resource to be removed, got %q", data)
+	}
+	if _, ok := resolver.GetResource("app"); ok {
+		t.Error("Expected the resource to be removed from the catalog")
+	}
+
+	resolver.AddResourceEntry(ResourceNodeEntry{Id: "memory"})
+	if err := resolver.RemoveResourceFromManifest("memory", false); err == nil {
+		t.Error("Expected a resource without manifest to fail")
+	}
+}
@@ -0,0 +1,79 @@
+package resolver
+
+import (
+	"slices"
+	"time"
+)
+
+// EventType identifies what happened in an Event.
+type EventType string
+
+const (
+	// RunStarted is sent once the targets are resolved, with every resource
+	// of the run in execution order.
+	RunStarted EventType = "RunStarted"
+	// ResourceStarted is sent before a resource is executed.
+	ResourceStarted EventType = "ResourceStarted"
+	// ResourceFinished is sent when a resource succeeded, or was completed by
+	// the resumed run.
+	ResourceFinished EventType = "ResourceFinished"
+	// ResourceFailed is sent when a resource failed, with its error.
+	ResourceFailed EventType = "ResourceFailed"
+	// ResourceSkipped is sent when a resource is not executed because of a
+	// failure or because the run was cancelled.
+	ResourceSkipped EventType = "ResourceSkipped"
+	// RunCompleted is sent once the run is over, with the result of every
+	// resource and the error of the run.
+	RunCompleted EventType = "RunCompleted"
+)
+
+// Event describes the progress of a run.
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Resource string
+	// Resources lists the resources of the run, for RunStarted.
+	Resources []string
+	// Duration is the execution time of a resource, or of the whole run for
+	// RunCompleted.
+	Duration time.Duration
+	Err      error
+	// Results holds the result of every resource, for RunCompleted.
+	Results []ResourceResult
+}
+
+// Listener receives the events of every run. Listeners are called in the
+// order they were registered, from the goroutine executing the run, so they
+// must not block.
+type Listener func(event Event)
+
+// OnEvent registers a listener for the events of every run, and returns a
+// function unregistering it.
+func (dr *DependencyResolver) OnEvent(listener Listener) (unsubscribe func()) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	registered := &listener
+	dr.listeners = append(dr.listeners, registered)
+	return func() {
+		dr.mu.Lock()
+		defer dr.mu.Unlock()
+		dr.listeners = slices.DeleteFunc(dr.listeners, func(l *Listener) bool { return l == registered })
+	}
+}
+
+// emit sends an event to the registered listeners and queues it for the
+// Webhooks, stamping it with the current time.
+func (dr *DependencyResolver) emit(event Event) {
+	dr.mu.RLock()
+	listeners := slices.Clone(dr.listeners)
+	webhooks := dr.Webhooks
+	dr.mu.RUnlock()
+
+	event.Time = time.Now()
+	for _, listener := range listeners {
+		(*listener)(event)
+	}
+	if len(webhooks) > 0 {
+		dr.deliveries.send(dr.logger(), webhooks, event)
+	}
+}
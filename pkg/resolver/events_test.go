@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExecute_Events(t *testing.T) {
+	resolver := setupPolicyResolver(SkipDependents)
+
+	var events []Event
+	resolver.OnEvent(func(event Event) {
+		events = append(events, event)
+	})
+
+	results, runErr := resolver.Execute(context.Background(), []string{"all"})
+
+	var got []string
+	for _, event := range events {
+		got = append(got, string(event.Type)+":"+event.Resource)
+		if event.Time.IsZero() {
+			t.Errorf("Expected %s to be timestamped", event.Type)
+		}
+	}
+	expected := []string{
+		"RunStarted:",
+		"ResourceStarted:broken",
+		"ResourceFailed:broken",
+		"ResourceSkipped:app",
+		"ResourceStarted:docs",
+		"ResourceFinished:docs",
+		"ResourceSkipped:all",
+		"RunCompleted:",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected events %v, got %v", expected, got)
+	}
+
+	if !reflect.DeepEqual(events[0].Resources, []string{"broken", "app", "docs", "all"}) {
+		t.Errorf("Expected the run order in RunStarted, got %v", events[0].Resources)
+	}
+	if events[2].Err == nil {
+		t.Error("Expected the error in ResourceFailed")
+	}
+	completed := events[len(events)-1]
+	if completed.Err != runErr || !reflect.DeepEqual(completed.Results, results) {
+		t.Errorf("Expected the results and error of the run in RunCompleted, got %+v", completed)
+	}
+}
+
+func TestOnEvent_Unsubscribe(t *testing.T) {
+	resolver := setupPolicyResolver(SkipDependents)
+
+	var first, second int
+	unsubscribe := resolver.OnEvent(func(event Event) { first++ })
+	resolver.OnEvent(func(event Event) { second++ })
+	unsubscribe()
+	unsubscribe()
+
+	resolver.Execute(context.Background(), []string{"docs"})
+	if first != 0 {
+		t.Errorf("Expected no events after unsubscribing, got %d", first)
+	}
+	if second == 0 {
+		t.Error("Expected the other listener to keep receiving events")
+	}
+}
@@ -0,0 +1,210 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ExecutionState is the state of a run, shared with the hooks of every
+// resource.
+type ExecutionState struct {
+	mu        sync.Mutex
+	targets   []string
+	order     []string
+	completed []string
+	logs      *RunnerLogs
+}
+
+func newExecutionState(targets, order []string, out io.Writer) *ExecutionState {
+	return &ExecutionState{targets: targets, order: order, logs: &RunnerLogs{out: out}}
+}
+
+// Targets returns the resources the run was started for.
+func (s *ExecutionState) Targets() []string {
+	return append([]string(nil), s.targets...)
+}
+
+// Order returns every resource of the run in execution order.
+func (s *ExecutionState) Order() []string {
+	return append([]string(nil), s.order...)
+}
+
+// Completed returns the resources that ran successfully so far, in the order
+// they completed.
+func (s *ExecutionState) Completed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.completed...)
+}
+
+// Output returns the output of every step executed so far.
+func (s *ExecutionState) Output() string {
+	return s.logs.GetAllMessageString()
+}
+
+func (s *ExecutionState) complete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, id)
+}
+
+func (s *ExecutionState) uncomplete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = slices.DeleteFunc(s.completed, func(completed string) bool { return completed == id })
+}
+
+// Hook is a callback run before or after the main action of every resource.
+// Returning an error fails the resource.
+type Hook func(ctx context.Context, res ResourceNodeEntry, state *ExecutionState) error
+
+// OnPreRun registers a hook run before the PreRun steps of every resource.
+func (dr *DependencyResolver) OnPreRun(hook Hook) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.preRunHooks = append(dr.preRunHooks, hook)
+}
+
+// OnPostRun registers a hook run after the PostRun steps of every resource
+// that ran successfully.
+func (dr *DependencyResolver) OnPostRun(hook Hook) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.postRunHooks = append(dr.postRunHooks, hook)
+}
+
+// OnRollback registers a hook run after the Rollback steps of every resource
+// rolled back.
+func (dr *DependencyResolver) OnRollback(hook Hook) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.rollbackHooks = append(dr.rollbackHooks, hook)
+}
+
+func (dr *DependencyResolver) hooks() (pre, post []Hook) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return append([]Hook(nil), dr.preRunHooks...), append([]Hook(nil), dr.postRunHooks...)
+}
+
+// TimeoutError is returned when a resource runs longer than its timeout.
+type TimeoutError struct {
+	Id      string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("resource '%s' timed out after %s", e.Id, e.Timeout)
+}
+
+// timeout returns the timeout of a resource, falling back to the resolver's
+// default.
+func (dr *DependencyResolver) timeout(res ResourceNodeEntry) time.Duration {
+	if res.Timeout > 0 {
+		return res.Timeout
+	}
+	return dr.Timeout
+}
+
+// executeResource runs a resource, killing its commands once its timeout is
+// exceeded.
+func (dr *DependencyResolver) executeResource(ctx context.Context, res ResourceNodeEntry, state *ExecutionState, client *http.Client) error {
+	timeout := dr.timeout(res)
+	if timeout <= 0 {
+		return dr.runResource(ctx, res, state, client)
+	}
+
+	resCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := dr.runResource(resCtx, res, state, client)
+	if err != nil && ctx.Err() == nil && errors.Is(resCtx.Err(), context.DeadlineExceeded) {
+		resourceTimeouts.Inc(res.Id)
+		return &TimeoutError{Id: res.Id, Timeout: timeout}
+	}
+	return err
+}
+
+// runResource runs a resource: the pre-run hooks, the PreRun steps, the
+// Run steps, retried according to its retry policy, then the PostRun steps
+// and the post-run hooks. The steps of a resource declaring a Runner are
+// executed by its plugin.
+func (dr *DependencyResolver) runResource(ctx context.Context, res ResourceNodeEntry, state *ExecutionState, client *http.Client) error {
+	pre, post := dr.hooks()
+
+	start := time.Now()
+	defer func() {
+		resourceExecutionDuration.Observe(time.Since(start).Seconds(), res.Id)
+	}()
+
+	for _, hook := range pre {
+		if err := hook(ctx, res, state); err != nil {
+			return fmt.Errorf("pre-run hook of resource '%s' failed: %w", res.Id, err)
+		}
+	}
+	if err := dr.runPhase(withPhase(ctx, phasePreRun), res, res.PreRun, state.logs, client); err != nil {
+		return err
+	}
+	err := dr.withRetry(ctx, res, func() error {
+		return dr.ResolveResourceNodeDependency(ctx, res.Id, res, state.logs, client)
+	})
+	if err != nil {
+		return err
+	}
+	if err := dr.runPhase(withPhase(ctx, phasePostRun), res, res.PostRun, state.logs, client); err != nil {
+		return err
+	}
+	for _, hook := range post {
+		if err := hook(ctx, res, state); err != nil {
+			return fmt.Errorf("post-run hook of resource '%s' failed: %w", res.Id, err)
+		}
+	}
+
+	state.complete(res.Id)
+	dr.logger().Info("Resource finished", entryFields(res, "", "duration", time.Since(start))...)
+	return nil
+}
+
+// rollback runs the Rollback steps and hooks of the completed resources in
+// the reverse order they completed. It runs even when ctx is done, and keeps
+// going when a rollback fails. It returns the resources rolled back, and the
+// errors of the failed rollbacks.
+func (dr *DependencyResolver) rollback(ctx context.Context, state *ExecutionState, client *http.Client) ([]string, []error) {
+	ctx = context.WithoutCancel(ctx)
+
+	dr.mu.RLock()
+	hooks := append([]Hook(nil), dr.rollbackHooks...)
+	dr.mu.RUnlock()
+
+	var rolledBack []string
+	var errs []error
+	completed := state.Completed()
+	for i := len(completed) - 1; i >= 0; i-- {
+		res, ok := dr.GetResource(completed[i])
+		if !ok {
+			continue
+		}
+
+		dr.logger().Info("Rolling back resource", entryFields(res, phaseRollback)...)
+		err := dr.runPhase(withPhase(ctx, phaseRollback), res, res.Rollback, state.logs, client)
+		for _, hook := range hooks {
+			if err != nil {
+				break
+			}
+			err = hook(ctx, res, state)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback of resource '%s' failed: %w", res.Id, err))
+			continue
+		}
+		state.uncomplete(res.Id)
+		rolledBack = append(rolledBack, res.Id)
+	}
+	return rolledBack, errs
+}
@@ -0,0 +1,192 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_Hooks(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "db", Run: []RunStep{{Name: "start", Exec: "echo db-run"}}},
+		{
+			Id:       "app",
+			Requires: []string{"db"},
+			PreRun:   []RunStep{{Name: "setup", Exec: "echo app-pre"}},
+			Run:      []RunStep{{Name: "start", Exec: "echo app-run"}},
+			PostRun:  []RunStep{{Name: "notify", Exec: "echo app-post"}},
+		},
+	}
+	resolver.BuildDependencies()
+
+	var calls []string
+	resolver.OnPreRun(func(ctx context.Context, res ResourceNodeEntry, state *ExecutionState) error {
+		calls = append(calls, "pre:"+res.Id+":"+strings.Join(state.Completed(), ","))
+		return nil
+	})
+	resolver.OnPostRun(func(ctx context.Context, res ResourceNodeEntry, state *ExecutionState) error {
+		calls = append(calls, "post:"+res.Id+":"+strings.Join(strings.Fields(state.Output()), ","))
+		return nil
+	})
+
+	if err := resolver.Run(context.Background(), []string{"app"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"pre:db:",
+		"post:db:db-run",
+		"pre:app:db",
+		"post:app:db-run,app-pre,app-run,app-post",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+}
+
+func TestRun_HookFailure(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Run: []RunStep{{Name: "start", Exec: "echo app-run"}}},
+	}
+	resolver.BuildDependencies()
+
+	hookErr := errors.New("not ready")
+	resolver.OnPreRun(func(ctx context.Context, res ResourceNodeEntry, state *ExecutionState) error {
+		return hookErr
+	})
+
+	if err := resolver.Run(context.Background(), []string{"app"}); !errors.Is(err, hookErr) {
+		t.Errorf("Expected the hook error, got %v", err)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "slow", Run: []RunStep{{Name: "wait", Exec: "sleep 5"}}, Timeout: 100 * time.Millisecond},
+	}
+	resolver.BuildDependencies()
+
+	start := time.Now()
+	err := resolver.Run(context.Background(), []string{"slow"})
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if timeoutErr.Id != "slow" || timeoutErr.Timeout != 100*time.Millisecond {
+		t.Errorf("Unexpected timeout error: %+v", timeoutErr)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the command to be killed, took %s", elapsed)
+	}
+}
+
+func TestRun_DefaultTimeout(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "fast", Run: []RunStep{{Name: "echo", Exec: "echo done"}}},
+		{Id: "slow", Requires: []string{"fast"}, Run: []RunStep{{Name: "wait", Exec: "sleep 5"}}},
+	}
+	resolver.Timeout = 500 * time.Millisecond
+	resolver.BuildDependencies()
+
+	var timeoutErr *TimeoutError
+	if err := resolver.Run(context.Background(), []string{"slow"}); !errors.As(err, &timeoutErr) || timeoutErr.Id != "slow" {
+		t.Errorf("Expected 'slow' to time out, got %v", err)
+	}
+}
+
+func TestRun_TimeoutNotExceeded(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "broken", Run: []RunStep{{Name: "fail", Exec: "exit 1"}}, Timeout: time.Minute},
+	}
+	resolver.BuildDependencies()
+
+	var timeoutErr *TimeoutError
+	if err := resolver.Run(context.Background(), []string{"broken"}); err == nil || errors.As(err, &timeoutErr) {
+		t.Errorf("Expected an ordinary failure, got %v", err)
+	}
+}
+
+func setupRollbackResolver(log string) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "network", Run: []RunStep{{Name: "create", Exec: "true"}}, Rollback: []RunStep{{Name: "delete", Exec: "echo network >> " + log}}},
+		{Id: "server", Requires: []string{"network"}, Run: []RunStep{{Name: "create", Exec: "true"}}, Rollback: []RunStep{{Name: "delete", Exec: "echo server >> " + log}}},
+		{Id: "dns", Requires: []string{"server"}, Run: []RunStep{{Name: "update", Exec: "exit 1"}}, Rollback: []RunStep{{Name: "revert", Exec: "echo dns >> " + log}}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestExecute_Rollback(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "rollback")
+	resolver := setupRollbackResolver(log)
+	resolver.RollbackOnFailure = true
+
+	var hooked []string
+	resolver.OnRollback(func(ctx context.Context, res ResourceNodeEntry, state *ExecutionState) error {
+		hooked = append(hooked, res.Id)
+		return nil
+	})
+
+	results, err := resolver.Execute(context.Background(), []string{"dns"})
+	if err == nil {
+		t.Fatal("Expected 'dns' to fail")
+	}
+
+	data, _ := os.ReadFile(log)
+	if got := strings.Fields(string(data)); !reflect.DeepEqual(got, []string{"server", "network"}) {
+		t.Errorf("Expected the completed resources to be rolled back in reverse order, got %v", got)
+	}
+	if !reflect.DeepEqual(hooked, []string{"server", "network"}) {
+		t.Errorf("Expected the rollback hooks to run for %v, got %v", []string{"server", "network"}, hooked)
+	}
+	for _, result := range results {
+		if result.RolledBack != (result.Id != "dns") {
+			t.Errorf("Unexpected rollback state for %+v", result)
+		}
+	}
+}
+
+func TestExecute_NoRollback(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "rollback")
+	resolver := setupRollbackResolver(log)
+
+	if _, err := resolver.Execute(context.Background(), []string{"dns"}); err == nil {
+		t.Fatal("Expected 'dns' to fail")
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Error("Expected no rollback unless enabled")
+	}
+}
+
+func TestExecute_RollbackFailure(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "rollback")
+	resolver := setupRollbackResolver(log)
+	resolver.RollbackOnFailure = true
+	resolver.Resources[1].Rollback = []RunStep{{Name: "delete", Exec: "exit 1"}}
+	resolver.BuildDependencies()
+
+	results, err := resolver.Execute(context.Background(), []string{"dns"})
+	if err == nil || !strings.Contains(err.Error(), "rollback of resource 'server' failed") {
+		t.Errorf("Expected the rollback failure to be returned, got %v", err)
+	}
+
+	data, _ := os.ReadFile(log)
+	if got := strings.Fields(string(data)); !reflect.DeepEqual(got, []string{"network"}) {
+		t.Errorf("Expected the rollback to continue after a failure, got %v", got)
+	}
+	if results[1].RolledBack || !results[0].RolledBack {
+		t.Errorf("Unexpected rollback states: %+v", results)
+	}
+}
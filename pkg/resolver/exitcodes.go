@@ -0,0 +1,142 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes of the runner command, by class of error, for scripts to react
+// to failures without parsing the error messages. They never change once
+// released: new classes get new codes.
+const (
+	ExitOK = 0
+	// ExitError is any error without a more specific code.
+	ExitError = 1
+	// ExitUsage is an invalid command line: unknown commands or flags, or
+	// invalid arguments.
+	ExitUsage = 2
+	// ExitNotFound is a resource, or the plugin of a resource, that does not
+	// exist, or a search matching no resource.
+	ExitNotFound = 3
+	// ExitCycle is a dependency or include cycle.
+	ExitCycle = 4
+	// ExitValidation is an invalid catalog: the errors of validate, or the
+	// warnings with --strict, manifests failing to load, conflicting
+	// resources or ambiguous providers.
+	ExitValidation = 5
+	// ExitExecution is a resource failing, or timing out, during a run.
+	ExitExecution = 6
+	// ExitPolicy is a denied license.
+	ExitPolicy = 7
+	// ExitLimit is a closure exceeding the traversal limits.
+	ExitLimit = 8
+)
+
+// UsageError is an invalid command line.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// usageErrorf returns a UsageError with the formatted message.
+func usageErrorf(format string, a ...interface{}) error {
+	return &UsageError{Err: fmt.Errorf(format, a...)}
+}
+
+// NotFoundError is returned for a resource that is not in the catalog.
+type NotFoundError struct {
+	Id string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("resource '%s' not found", e.Id)
+}
+
+// CycleError is returned for resources that depend on each other.
+type CycleError struct {
+	Resources []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle between " + strings.Join(e.Resources, ", ")
+}
+
+// ExecutionError is the failure of a resource during a run.
+type ExecutionError struct {
+	Id  string
+	Err error
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the runner command failing with err,
+// ExitOK when err is nil. A run where a resource failed exits with
+// ExitExecution, whatever the cause of the failure, and a validation with
+// ExitValidation, whatever the errors it found besides dependency cycles,
+// which exit with ExitCycle.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case isError[*UsageError](err), isError[*QuerySyntaxError](err):
+		return ExitUsage
+	case isError[*ExecutionError](err), isError[*TimeoutError](err):
+		return ExitExecution
+	case isError[*CycleError](err), isError[*IncludeCycleError](err):
+		return ExitCycle
+	case isError[*ValidationError](err):
+		return ExitValidation
+	case isError[*NotFoundError](err), isError[*PluginNotFoundError](err), isError[*NoMatchError](err):
+		return ExitNotFound
+	case isError[SchemaErrors](err), isError[*SchemaError](err), isError[*DuplicateResourceError](err),
+		isError[*DanglingRequirementError](err), isError[*SelfDependencyError](err), isError[*InvalidIDError](err),
+		isError[*InvalidRunnerError](err), isError[*UndefinedVariableError](err), isError[*UnknownTypeError](err), isError[*ConflictError](err),
+		isError[*AmbiguousProviderError](err):
+		return ExitValidation
+	case isError[*DeniedLicenseError](err):
+		return ExitPolicy
+	case isError[*TraversalLimitError](err):
+		return ExitLimit
+	}
+	return ExitError
+}
+
+// isError reports whether any error in the tree of err is a T.
+func isError[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
+
+// CheckTargets returns a NotFoundError for the first of the targets that is
+// neither a loaded resource, a virtual resource nor an alias.
+func (dr *DependencyResolver) CheckTargets(targets []string) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	known := dr.knownResources()
+	for _, target := range targets {
+		if !dr.exists(known, target) {
+			return &NotFoundError{Id: target}
+		}
+	}
+	return nil
+}
+
+// exists reports whether id is a loaded resource, given the known ones, a
+// virtual resource or an alias. The caller holds the read lock.
+func (dr *DependencyResolver) exists(known map[string]bool, id string) bool {
+	return known[id] || len(dr.providers(id)) > 0 || dr.aliasOf(id) != ""
+}
@@ -0,0 +1,87 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitOK},
+		{fmt.Errorf("failed"), ExitError},
+		{usageErrorf("a query is required"), ExitUsage},
+		{fmt.Errorf("error resolving: %w", &NotFoundError{Id: "a"}), ExitNotFound},
+		{&NoMatchError{Query: "a"}, ExitNotFound},
+		{&CycleError{Resources: []string{"a", "b"}}, ExitCycle},
+		{errors.Join(&CycleError{Resources: []string{"a"}}, &CycleError{Resources: []string{"b"}}), ExitCycle},
+		{&ValidationError{Errors: 1}, ExitValidation},
+		// A validation finding a dependency cycle fails with the cycle.
+		{&ValidationError{Errors: 1, errs: []error{&CycleError{Resources: []string{"a"}}}}, ExitCycle},
+		{fmt.Errorf("missing %w", &DanglingRequirementError{Id: "a"}), ExitValidation},
+		{fmt.Errorf("error loading: %w", SchemaErrors{{Message: "unknown field"}}), ExitValidation},
+		{&ExecutionError{Id: "a", Err: fmt.Errorf("exit status 1")}, ExitExecution},
+		// A resource failing on a missing plugin fails the run.
+		{&ExecutionError{Id: "a", Err: &PluginNotFoundError{}}, ExitExecution},
+		{&DeniedLicenseError{}, ExitPolicy},
+		{&TraversalLimitError{}, ExitLimit},
+	}
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.expected {
+			t.Errorf("%v: expected exit code %d, got %d", test.err, test.expected, code)
+		}
+	}
+}
+
+func TestCheckTargets(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "postgres", Provides: []string{"database"}, Aliases: []string{"pg"}},
+	}
+	resolver.BuildDependencies()
+
+	if err := resolver.CheckTargets([]string{"postgres", "database", "pg"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	var notFound *NotFoundError
+	if err := resolver.CheckTargets([]string{"postgres", "mysql"}); !errors.As(err, &notFound) || notFound.Id != "mysql" {
+		t.Errorf("Expected a NotFoundError for mysql, got %v", err)
+	}
+}
+
+func TestExitCode_Commands(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b"}},
+		{Id: "b", Requires: []string{"a"}},
+		{Id: "broken", Run: []RunStep{{Name: "fail", Exec: "exit 1"}}},
+	}
+	resolver.BuildDependencies()
+
+	tests := []struct {
+		name     string
+		run      func() error
+		expected int
+	}{
+		{"show", func() error { return resolver.HandleShowCommand([]string{"nope"}) }, ExitNotFound},
+		{"impact", func() error { return resolver.HandleImpactCommand([]string{"nope"}) }, ExitNotFound},
+		{"cycles", resolver.HandleCyclesCommand, ExitCycle},
+		{"levels", func() error { return resolver.HandleLevelsCommand([]string{"a"}) }, ExitCycle},
+		{"run", func() error { return resolver.Run(context.Background(), []string{"broken"}) }, ExitExecution},
+	}
+	for _, test := range tests {
+		var err error
+		captureOutput(func() { err = test.run() })
+		if code := ExitCode(err); code != test.expected {
+			t.Errorf("%s: expected exit code %d, got %d for %v", test.name, test.expected, code, err)
+		}
+	}
+}
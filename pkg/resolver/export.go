@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// exporters maps the formats of Export to the method writing them.
+var exporters = map[string]func(*DependencyResolver, io.Writer) error{
+	"cyclonedx": (*DependencyResolver).WriteCycloneDX,
+	"dot":       (*DependencyResolver).WriteDOT,
+	"graphml":   (*DependencyResolver).ExportGraphML,
+	"spdx":      (*DependencyResolver).WriteSPDX,
+}
+
+// dirExporters maps the formats of ExportDir, written as several files, to
+// the function writing them.
+var dirExporters = map[string]func(*DependencyResolver, string) error{
+	"csv": func(dr *DependencyResolver, dir string) error { return dr.WriteCSV(dir, ',') },
+	"tsv": func(dr *DependencyResolver, dir string) error { return dr.WriteCSV(dir, '\t') },
+}
+
+// ExportFormats lists the formats supported by Export and ExportDir, sorted.
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters)+len(dirExporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	for format := range dirExporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Export writes the catalog and its dependency graph in the given format.
+// Formats written as several files are exported with ExportDir.
+func (dr *DependencyResolver) Export(format string, w io.Writer) error {
+	if _, ok := dirExporters[format]; ok {
+		return fmt.Errorf("the %s export is written to a directory", format)
+	}
+	export, ok := exporters[format]
+	if !ok {
+		return unknownExportFormat(format)
+	}
+	return export(dr, w)
+}
+
+// ExportDir writes the catalog and its dependency graph in the given format
+// to files of the directory, such as the nodes.csv and edges.csv of the csv
+// format.
+func (dr *DependencyResolver) ExportDir(format, dir string) error {
+	export, ok := dirExporters[format]
+	if !ok {
+		if _, ok := exporters[format]; ok {
+			return fmt.Errorf("the %s export is written to the standard output", format)
+		}
+		return unknownExportFormat(format)
+	}
+	return export(dr, dir)
+}
+
+func unknownExportFormat(format string) error {
+	return fmt.Errorf("unknown export format '%s', expected one of: %s", format, strings.Join(ExportFormats(), ", "))
+}
+
+// HandleExportCommand handles the 'export' command, writing the catalog in
+// the given format to the standard output, or to the given directory for
+// formats written as several files, the current one by default.
+func (dr *DependencyResolver) HandleExportCommand(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		Println("Usage: runner export [" + strings.Join(ExportFormats(), "|") + "] [directory]")
+		return fmt.Errorf("expected a format, got %d arguments", len(args))
+	}
+	if _, ok := dirExporters[args[0]]; ok {
+		dir := "."
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		return dr.ExportDir(args[0], dir)
+	}
+	if len(args) == 2 {
+		return fmt.Errorf("the %s export is written to the standard output", args[0])
+	}
+	return dr.Export(args[0], os.Stdout)
+}
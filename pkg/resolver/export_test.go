@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExport(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{{Id: "a", Name: "A"}}
+	resolver.BuildDependencies()
+
+	for _, format := range []string{"cyclonedx", "dot", "graphml", "spdx"} {
+		var output strings.Builder
+		if err := resolver.Export(format, &output); err != nil {
+			t.Errorf("Unexpected error for %s: %v", format, err)
+		}
+		if !strings.Contains(output.String(), "a") {
+			t.Errorf("Expected the %s export to include the resource, got:\n%s", format, output.String())
+		}
+	}
+
+	if err := resolver.Export("svg", &strings.Builder{}); err == nil || !strings.Contains(err.Error(), "csv, cyclonedx, dot, graphml, spdx, tsv") {
+		t.Errorf("Expected an unknown format error listing the formats, got %v", err)
+	}
+	if err := resolver.Export("csv", &strings.Builder{}); err == nil {
+		t.Error("Expected the csv format to require a directory")
+	}
+	if err := resolver.ExportDir("dot", "out"); err == nil {
+		t.Error("Expected the dot format not to be written to a directory")
+	}
+}
+
+func TestExportDir(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{{Id: "a", Name: "A"}}
+	resolver.BuildDependencies()
+
+	for _, format := range []string{"csv", "tsv"} {
+		if err := resolver.ExportDir(format, "out"); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", format, err)
+		}
+		for _, name := range []string{"nodes", "edges"} {
+			if exists, _ := afero.Exists(resolver.Fs, "out/"+name+"."+format); !exists {
+				t.Errorf("Expected out/%s.%s to be written", name, format)
+			}
+		}
+	}
+}
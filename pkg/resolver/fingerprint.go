@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// fingerprint returns the fingerprint of a resource: the SHA-256 digest of
+// its definition and of the content of the files matching its Inputs,
+// changing whenever any of them does.
+func (dr *DependencyResolver) fingerprint(entry ResourceNodeEntry) (string, error) {
+	digest, err := resourceDigest(entry)
+	if err != nil {
+		return "", err
+	}
+	if len(entry.Inputs) == 0 {
+		return digest, nil
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(digest + "\x00"))
+	for _, pattern := range entry.Inputs {
+		matches, err := afero.Glob(dr.Fs, pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid input '%s' of resource '%s': %w", pattern, entry.Id, err)
+		}
+		sort.Strings(matches)
+		// A pattern matching no file still changes the fingerprint once a
+		// file matches it.
+		hash.Write([]byte(pattern + "\x00"))
+		for _, path := range matches {
+			if err := hashFile(hash, dr.Fs, path); err != nil {
+				return "", fmt.Errorf("error reading input '%s' of resource '%s': %w", path, entry.Id, err)
+			}
+		}
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashFile writes the path and content of a file to w. Directories only
+// contribute their path.
+func hashFile(w io.Writer, fs afero.Fs, path string) error {
+	w.Write([]byte(path + "\x00"))
+	info, err := fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	f, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	w.Write([]byte{0})
+	return nil
+}
+
+// upToDate reports whether a resource can be skipped by a run: its last run
+// succeeded, neither its definition nor its inputs changed since, and none of
+// its requirements were executed by the run. It is never the case with Force
+// or without a RecordsFile.
+func (dr *DependencyResolver) upToDate(records *ResourceRecords, entry ResourceNodeEntry, executed map[string]bool) (bool, error) {
+	if dr.Force || dr.RecordsFile == "" {
+		return false, nil
+	}
+	record, ok := records.Resources[entry.Id]
+	if !ok || record.State != StateInstalled {
+		return false, nil
+	}
+	if dependsOnAny(dr.DirectDependencies(entry.Id), executed) {
+		return false, nil
+	}
+	fingerprint, err := dr.fingerprint(entry)
+	if err != nil {
+		return false, err
+	}
+	return fingerprint == record.Fingerprint, nil
+}
@@ -0,0 +1,150 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// upToDateResults returns the IDs of the resources a run found up to date.
+func upToDateResults(results []ResourceResult) []string {
+	var ids []string
+	for _, result := range results {
+		if result.UpToDate {
+			ids = append(ids, result.Id)
+		}
+	}
+	return ids
+}
+
+func TestExecute_UpToDate(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	step := func(id string) []RunStep {
+		return []RunStep{{Name: id, Exec: "echo " + id + " >> " + runs}}
+	}
+
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	resolver.RecordsFile = "status.yaml"
+	if err := afero.WriteFile(resolver.Fs, "src/main.go", []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "deps", Run: step("deps")},
+		{Id: "build", Requires: []string{"deps"}, Inputs: []string{"src/*.go"}, Run: step("build")},
+		{Id: "deploy", Requires: []string{"build"}, Run: step("deploy")},
+	}
+	resolver.BuildDependencies()
+
+	run := func() []ResourceResult {
+		t.Helper()
+		if err := os.Remove(runs); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		results, err := resolver.Execute(context.Background(), []string{"deploy"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return results
+	}
+	ran := func() string {
+		data, _ := os.ReadFile(runs)
+		return strings.Join(strings.Fields(string(data)), ",")
+	}
+
+	run()
+	if got := ran(); got != "deps,build,deploy" {
+		t.Fatalf("Expected every resource to run first, got %q", got)
+	}
+
+	results := run()
+	if got := ran(); got != "" {
+		t.Errorf("Expected nothing to run again, got %q", got)
+	}
+	if got := strings.Join(upToDateResults(results), ","); got != "deps,build,deploy" {
+		t.Errorf("Expected every resource to be up to date, got %q", got)
+	}
+
+	// Changing an input runs the resource and its dependents again.
+	if err := afero.WriteFile(resolver.Fs, "src/main.go", []byte("package main // changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	if got := ran(); got != "build,deploy" {
+		t.Errorf("Expected 'build' and 'deploy' to run after an input change, got %q", got)
+	}
+
+	// A new file matching an input pattern changes the fingerprint too.
+	if err := afero.WriteFile(resolver.Fs, "src/util.go", []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	if got := ran(); got != "build,deploy" {
+		t.Errorf("Expected 'build' and 'deploy' to run after a new input, got %q", got)
+	}
+
+	resolver.Force = true
+	results = run()
+	if got := ran(); got != "deps,build,deploy" {
+		t.Errorf("Expected every resource to run with Force, got %q", got)
+	}
+	if ids := upToDateResults(results); len(ids) != 0 {
+		t.Errorf("Expected no up to date resource with Force, got %v", ids)
+	}
+}
+
+func TestExecute_FailedIsNotUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	ready := filepath.Join(dir, "ready")
+
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	resolver.RecordsFile = "status.yaml"
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "flaky", Run: []RunStep{{Name: "check", Exec: "test -f " + ready}}},
+	}
+	resolver.BuildDependencies()
+
+	if _, err := resolver.Execute(context.Background(), []string{"flaky"}); err == nil {
+		t.Fatal("Expected 'flaky' to fail")
+	}
+	if err := os.WriteFile(ready, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	results, err := resolver.Execute(context.Background(), []string{"flaky"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := upToDateResults(results); len(ids) != 0 {
+		t.Errorf("Expected a failed resource to run again, got up to date %v", ids)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	resolver := setupTestResolver()
+	entry := ResourceNodeEntry{Id: "build", Inputs: []string{"*.txt"}}
+
+	empty, err := resolver.fingerprint(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := afero.WriteFile(resolver.Fs, "a.txt", []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withFile, err := resolver.fingerprint(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	again, _ := resolver.fingerprint(entry)
+	if empty == withFile || withFile != again {
+		t.Errorf("Expected the fingerprint to change with the inputs only, got %s, %s, %s", empty, withFile, again)
+	}
+
+	if _, err := resolver.fingerprint(ResourceNodeEntry{Id: "bad", Inputs: []string{"["}}); err == nil {
+		t.Error("Expected an error for an invalid input pattern")
+	}
+}
@@ -7,14 +7,43 @@ import (
 	"github.com/lithammer/fuzzysearch/fuzzy"
 )
 
+// NoMatchError is returned when a search matches no resource.
+type NoMatchError struct {
+	Query string
+}
+
+func (e *NoMatchError) Error() string {
+	return fmt.Sprintf("no resource matches '%s'", e.Query)
+}
+
+// FuzzySearch prints the resources fuzzy matching the query in the given
+// fields, like Search, or returns a NoMatchError when there are none.
 func (dr *DependencyResolver) FuzzySearch(query string, keys []string) error {
+	matches := dr.filterMetadata(dr.Search(query, keys))
+	if len(matches) == 0 {
+		return &NoMatchError{Query: query}
+	}
+
+	for _, entry := range paginate(dr, matches) {
+		if err := dr.ShowResourceEntry(entry.Id); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// Search returns the resources fuzzy matching the query in the given fields,
+// or in all fields when none are given, in catalog order.
+func (dr *DependencyResolver) Search(query string, keys []string) []ResourceNodeEntry {
 	if len(keys) == 0 {
 		// If no keys are provided, search in all fields
 		keys = []string{"id", "name", "desc", "category"}
 	}
 
-	combinedEntries := make([][2]string, len(dr.Resources))
-	for i, entry := range dr.Resources {
+	resources := dr.ListResources()
+	combinedEntries := make([][2]string, len(resources))
+	for i, entry := range resources {
 		var combined strings.Builder
 		for _, key := range keys {
 			switch key {
@@ -31,24 +60,16 @@ func (dr *DependencyResolver) FuzzySearch(query string, keys []string) error {
 		combinedEntries[i] = [2]string{entry.Id, combined.String()}
 	}
 
-	matches := fuzzy.Find(query, getSecondStrings(combinedEntries))
-	if len(matches) == 0 {
-		LogErrorExit("No matches found for query: "+query, nil)
-	}
-
-	for _, match := range matches {
-		for _, entry := range combinedEntries {
+	var found []ResourceNodeEntry
+	for _, match := range fuzzy.Find(query, getSecondStrings(combinedEntries)) {
+		for i, entry := range combinedEntries {
 			if entry[1] == match {
-				err := dr.ShowResourceEntry(entry[0])
-				if err != nil {
-					LogErrorExit("Failed to show resource entry: "+entry[0], err)
-				}
-				fmt.Println()
+				found = append(found, resources[i])
 				break
 			}
 		}
 	}
-	return nil
+	return found
 }
 
 func getSecondStrings(entries [][2]string) []string {
@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -22,6 +23,15 @@ func (dr *DependencyResolver) MockShowResourceEntry(res string) string {
 	return ""
 }
 
+func TestFuzzySearch_NoMatch(t *testing.T) {
+	resolver := setupTestResolver()
+
+	var noMatch *NoMatchError
+	if err := resolver.FuzzySearch("zzzzzz", nil); !errors.As(err, &noMatch) || ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected a no match error, got %v", err)
+	}
+}
+
 // TestDependencyResolver_FuzzySearch tests the FuzzySearch method.
 func TestDependencyResolver_FuzzySearch(t *testing.T) {
 	logger := log.New(nil)
@@ -30,7 +40,7 @@ func TestDependencyResolver_FuzzySearch(t *testing.T) {
 		logger.Fatalf("Failed to create shell session: %v", err)
 	}
 	defer session.Close()
-	resolver, err := NewGraphResolver(afero.NewMemMapFs(), logger, "", session)
+	resolver, err := NewGraphResolver(afero.NewMemMapFs(), NewCharmLogger(logger), "", session)
 	if err != nil {
 		log.Fatalf("Failed to create dependency resolver: %v", err)
 	}
@@ -0,0 +1,87 @@
+package resolver
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ExportGraphML writes the dependency graph in GraphML format, for graph tools
+// such as yEd or Gephi. Nodes carry the name, category and description of
+// their resource, and edges go from every resource to each of its
+// requirements.
+func (dr *DependencyResolver) ExportGraphML(w io.Writer) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "category", For: "node", AttrName: "category", AttrType: "string"},
+			{ID: "desc", For: "node", AttrName: "desc", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "runner", EdgeDefault: "directed"},
+	}
+	for _, entry := range dr.Resources {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: entry.Id,
+			Data: []graphMLData{
+				{Key: "name", Value: entry.Name},
+				{Key: "category", Value: entry.Category},
+				{Key: "desc", Value: entry.Desc},
+			},
+		})
+	}
+	for _, entry := range dr.Resources {
+		for _, dep := range dr.ResourceDependencies[entry.Id] {
+			doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: entry.Id, Target: dep})
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportGraphML(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Name: "A", Category: "vcs", Desc: "The <first> resource"},
+		{Id: "b", Name: "B", Requires: []string{"a"}},
+	}
+	resolver.BuildDependencies()
+
+	var output strings.Builder
+	if err := resolver.ExportGraphML(&output); err != nil {
+		t.Fatalf("Failed to write GraphML: %v", err)
+	}
+
+	expectedOutput := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="name" for="node" attr.name="name" attr.type="string"></key>
+  <key id="category" for="node" attr.name="category" attr.type="string"></key>
+  <key id="desc" for="node" attr.name="desc" attr.type="string"></key>
+  <graph id="runner" edgedefault="directed">
+    <node id="a">
+      <data key="name">A</data>
+      <data key="category">vcs</data>
+      <data key="desc">The &lt;first&gt; resource</data>
+    </node>
+    <node id="b">
+      <data key="name">B</data>
+      <data key="category"></data>
+      <data key="desc"></data>
+    </node>
+    <edge source="b" target="a"></edge>
+  </graph>
+</graphml>
+`
+	if output.String() != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output.String())
+	}
+}
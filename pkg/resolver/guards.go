@@ -0,0 +1,155 @@
+package resolver
+
+import "fmt"
+
+// Kinds of the limits of a TraversalLimitError.
+const (
+	LimitDepth = "depth"
+	LimitSize  = "size"
+	LimitPaths = "paths"
+)
+
+// TraversalLimitError is returned when the closure of a resource is deeper
+// than MaxTraversalDepth or larger than MaxClosureSize, or when listing it
+// takes more than MaxPaths paths. Dependents is set when the closure is the
+// one of the resources requiring Resource.
+type TraversalLimitError struct {
+	Resource   string
+	Dependents bool
+	Limit      string
+	Max        int
+}
+
+func (e *TraversalLimitError) Error() string {
+	closure := "dependencies"
+	if e.Dependents {
+		closure = "dependents"
+	}
+	switch e.Limit {
+	case LimitDepth:
+		return fmt.Sprintf("the %s of '%s' are more than %d requirements deep, the maximum traversal depth", closure, e.Resource, e.Max)
+	case LimitPaths:
+		return fmt.Sprintf("the %s of '%s' take more than %d paths, the maximum number of paths", closure, e.Resource, e.Max)
+	}
+	return fmt.Sprintf("the %s of '%s' are more than %d resources, the maximum closure size", closure, e.Resource, e.Max)
+}
+
+// exceeds walks the indices reachable from start along edges, down to
+// maxDepth steps away unless it is 0, and returns the kind of the first limit
+// they exceed: more than sizeLimit of them, or a chain of more than
+// depthLimit steps. A limit of 0 is no limit. The size is counted
+// breadth-first and the walk stops as soon as it is exceeded, so it only
+// takes memory for the indices within limits.
+func (g *idGraph) exceeds(start int32, edges [][]int32, maxDepth, depthLimit, sizeLimit int) string {
+	if sizeLimit > 0 {
+		visited := newBitset(len(g.ids))
+		visited.set(start)
+		queue := []int32{start}
+		size := 0
+		for depth := 1; len(queue) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+			var next []int32
+			for _, node := range queue {
+				for _, succ := range edges[node] {
+					if visited.has(succ) {
+						continue
+					}
+					if size++; size > sizeLimit {
+						return LimitSize
+					}
+					visited.set(succ)
+					next = append(next, succ)
+				}
+			}
+			queue = next
+		}
+	}
+	if depthLimit > 0 && (maxDepth == 0 || maxDepth > depthLimit) && g.chainLonger(start, edges, depthLimit) {
+		return LimitDepth
+	}
+	return ""
+}
+
+// chainLonger reports whether a chain of more than limit steps starts at
+// start along edges, ignoring the edges that close a cycle. It measures the
+// longest chain rather than the distance to the indices, since the paths
+// listed from start are as deep as it, and stops as soon as the limit is
+// exceeded.
+func (g *idGraph) chainLonger(start int32, edges [][]int32, limit int) bool {
+	// height is one more than the longest chain known to start at every
+	// index, so that 0 is an index not walked yet.
+	height := make([]int32, len(g.ids))
+	onPath := newBitset(len(g.ids))
+	type frame struct {
+		node int32
+		next int
+	}
+	stack := []frame{{node: start}}
+	onPath.set(start)
+	height[start] = 1
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if len(stack)-1+int(height[top.node])-1 > limit {
+			return true
+		}
+		if top.next == len(edges[top.node]) {
+			node := top.node
+			onPath.clear(node)
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1].node
+				height[parent] = max(height[parent], height[node]+1)
+			}
+			continue
+		}
+
+		succ := edges[top.node][top.next]
+		top.next++
+		switch {
+		case onPath.has(succ):
+		case height[succ] > 0:
+			height[top.node] = max(height[top.node], height[succ]+1)
+		default:
+			onPath.set(succ)
+			height[succ] = 1
+			stack = append(stack, frame{node: succ})
+		}
+	}
+	return false
+}
+
+// CheckLimits returns a TraversalLimitError when the closure of any of the
+// resources, or the closure of their dependents when dependents is set,
+// exceeds MaxTraversalDepth or MaxClosureSize. Only the resources at most
+// maxDepth requirements away are considered unless maxDepth is 0, like the
+// traversals limited by it.
+func (dr *DependencyResolver) CheckLimits(resources []string, dependents bool, maxDepth int) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.checkLimits(resources, dependents, maxDepth)
+}
+
+// checkLimits is CheckLimits for callers holding the read lock.
+func (dr *DependencyResolver) checkLimits(resources []string, dependents bool, maxDepth int) error {
+	if dr.MaxTraversalDepth <= 0 && dr.MaxClosureSize <= 0 {
+		return nil
+	}
+	g := dr.ids()
+	edges := g.deps
+	if dependents {
+		edges = g.dependents
+	}
+	for _, res := range resources {
+		start, ok := g.index[res]
+		if !ok {
+			continue
+		}
+		if limit := g.exceeds(start, edges, maxDepth, dr.MaxTraversalDepth, dr.MaxClosureSize); limit != "" {
+			max := dr.MaxTraversalDepth
+			if limit == LimitSize {
+				max = dr.MaxClosureSize
+			}
+			return &TraversalLimitError{Resource: res, Dependents: dependents, Limit: limit, Max: max}
+		}
+	}
+	return nil
+}
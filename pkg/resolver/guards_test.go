@@ -0,0 +1,140 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckLimits(t *testing.T) {
+	resolver := setupChainResolver(10)
+	if err := resolver.CheckLimits([]string{"n9"}, false, 0); err != nil {
+		t.Fatalf("Expected no limits by default, got %v", err)
+	}
+
+	tests := []struct {
+		depth, size, maxDepth int
+		resource              string
+		dependents            bool
+		expected              *TraversalLimitError
+	}{
+		{depth: 9, resource: "n9"},
+		{size: 9, resource: "n9"},
+		{depth: 8, resource: "n9", expected: &TraversalLimitError{Resource: "n9", Limit: LimitDepth, Max: 8}},
+		{size: 8, resource: "n9", expected: &TraversalLimitError{Resource: "n9", Limit: LimitSize, Max: 8}},
+		{depth: 3, resource: "n0", dependents: true, expected: &TraversalLimitError{Resource: "n0", Dependents: true, Limit: LimitDepth, Max: 3}},
+		{depth: 3, size: 3, maxDepth: 3, resource: "n9"},
+		{depth: 3, resource: "missing"},
+	}
+	for _, test := range tests {
+		resolver.MaxTraversalDepth, resolver.MaxClosureSize = test.depth, test.size
+		err := resolver.CheckLimits([]string{test.resource}, test.dependents, test.maxDepth)
+		var limitErr *TraversalLimitError
+		switch {
+		case test.expected == nil && err != nil:
+			t.Errorf("With %+v, unexpected error: %v", test, err)
+		case test.expected != nil && (!errors.As(err, &limitErr) || *limitErr != *test.expected):
+			t.Errorf("With %+v, expected %v, got %v", test, test.expected, err)
+		}
+	}
+
+	err := &TraversalLimitError{Resource: "app", Dependents: true, Limit: LimitSize, Max: 10}
+	if expected := "the dependents of 'app' are more than 10 resources, the maximum closure size"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestCheckLimits_Cycle(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b"}},
+		{Id: "b", Requires: []string{"c"}},
+		{Id: "c", Requires: []string{"a"}},
+	}
+	resolver.BuildDependencies()
+	resolver.MaxClosureSize = 1
+
+	var limitErr *TraversalLimitError
+	if _, err := resolver.Resolve(context.Background(), []string{"a"}); !errors.As(err, &limitErr) {
+		t.Errorf("Expected the resolution to fail, got %v", err)
+	}
+	for name, call := range map[string]func() error{
+		"levels":       func() error { _, err := resolver.ExecutionLevels("a"); return err },
+		"licenses":     func() error { _, err := resolver.Licenses("a"); return err },
+		"deprecations": func() error { _, err := resolver.Deprecations("a"); return err },
+		"orphans":      func() error { _, err := resolver.Orphans("a"); return err },
+		"impact":       func() error { _, err := resolver.ImpactOfRemoval("a"); return err },
+		"depends":      func() error { return resolver.HandleDependsCommand([]string{"a"}) },
+		"rdepends":     func() error { return resolver.HandleRDependsCommand([]string{"a"}) },
+	} {
+		if err := call(); !errors.As(err, &limitErr) {
+			t.Errorf("Expected %s to fail, got %v", name, err)
+		}
+	}
+
+	var cycleErr *CycleError
+	resolver.MaxClosureSize = 2
+	if _, err := resolver.Resolve(context.Background(), []string{"a"}); !errors.As(err, &cycleErr) {
+		t.Errorf("Expected the cycle to fail the resolution, got %v", err)
+	}
+}
+
+// setupLadderResolver returns a resolver with a ladder of n resources, every
+// resource requiring the next two, whose paths double with every step.
+func setupLadderResolver(n int) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	for i := 0; i < n; i++ {
+		entry := ResourceNodeEntry{Id: fmt.Sprintf("n%d", i)}
+		for j := i + 1; j < n && j <= i+2; j++ {
+			entry.Requires = append(entry.Requires, fmt.Sprintf("n%d", j))
+		}
+		resolver.Resources = append(resolver.Resources, entry)
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestCheckLimits_LongestChain(t *testing.T) {
+	resolver := setupLadderResolver(40)
+	resolver.MaxTraversalDepth, resolver.MaxClosureSize = 25, 100
+
+	var limitErr *TraversalLimitError
+	if _, err := resolver.DependencyTree("n0", 0); !errors.As(err, &limitErr) || limitErr.Limit != LimitDepth {
+		t.Errorf("Expected the longest chain to exceed the depth, got %v", err)
+	}
+	if _, err := resolver.DependencyOrder("n0", 0); !errors.As(err, &limitErr) || limitErr.Limit != LimitDepth {
+		t.Errorf("Expected the order to fail too, got %v", err)
+	}
+	if err := resolver.CheckLimits([]string{"n0"}, false, 20); err != nil {
+		t.Errorf("Expected the chains within the depth to pass, got %v", err)
+	}
+}
+
+func TestMaxPaths(t *testing.T) {
+	resolver := setupLadderResolver(20)
+	resolver.MaxPaths = 100
+
+	var limitErr *TraversalLimitError
+	if _, err := resolver.DependencyTree("n0", 0); !errors.As(err, &limitErr) || *limitErr != (TraversalLimitError{Resource: "n0", Limit: LimitPaths, Max: 100}) {
+		t.Errorf("Expected too many paths, got %v", err)
+	}
+	if tree, err := resolver.DependencyTree("n15", 0); err != nil || len(tree) != 5 {
+		t.Errorf("Expected the 5 paths of n15, got %v, %v", tree, err)
+	}
+	if expected := "the dependencies of 'n0' take more than 100 paths, the maximum number of paths"; limitErr.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, limitErr.Error())
+	}
+
+	resolver.MaxPaths = 5
+	var buf bytes.Buffer
+	if err := resolver.WriteDependentPaths(&buf, "n19", 0); !errors.As(err, &limitErr) || !limitErr.Dependents {
+		t.Errorf("Expected the dependent paths to stop, got %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 5 {
+		t.Errorf("Expected 5 paths written, got %d", lines)
+	}
+}
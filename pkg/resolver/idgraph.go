@@ -0,0 +1,166 @@
+package resolver
+
+import (
+	"slices"
+	"sort"
+)
+
+// idGraph is ResourceDependencies with every resource ID interned to a dense
+// int32 index, and adjacency kept as slices of indices in both directions.
+// Closures are computed over it with a bitset of visited indices, instead of
+// hashing strings at every step, which matters on catalogs of hundreds of
+// thousands of resources. The loaded resources are interned first, in catalog
+// order, so sorting indices sorts resources in catalog order; requirements
+// missing from the catalog come after them.
+type idGraph struct {
+	ids   []string
+	index map[string]int32
+	// resources is the number of loaded resources, at indices [0, resources).
+	resources int
+	deps      [][]int32
+	// dependents lists the loaded resources requiring every index, in
+	// catalog order.
+	dependents [][]int32
+	// reach and reachedBy cache the reachability sets of indices along deps
+	// and along dependents, see reachable. reachBytes is their size.
+	reach, reachedBy []bitset
+	reachBytes       int
+}
+
+// newIDGraph interns the given resources, in order, and the dependencies
+// between them.
+func newIDGraph(resources []string, dependencies map[string][]string) *idGraph {
+	g := &idGraph{index: make(map[string]int32, len(resources))}
+	for _, id := range resources {
+		g.intern(id)
+	}
+	g.resources = len(g.ids)
+
+	// Keys of ResourceDependencies without a loaded resource still have
+	// dependencies; intern them in a stable order.
+	var others []string
+	for id := range dependencies {
+		if _, ok := g.index[id]; !ok {
+			others = append(others, id)
+		}
+	}
+	sort.Strings(others)
+	for _, id := range others {
+		g.intern(id)
+	}
+
+	for id, deps := range dependencies {
+		i := g.index[id]
+		g.deps[i] = g.internAll(deps)
+	}
+	for i := 0; i < g.resources; i++ {
+		for _, dep := range g.deps[i] {
+			// Like dag.Graph.Reverse, repeated requirements count once.
+			if n := len(g.dependents[dep]); n > 0 && g.dependents[dep][n-1] == int32(i) {
+				continue
+			}
+			g.dependents[dep] = append(g.dependents[dep], int32(i))
+		}
+	}
+	return g
+}
+
+// intern returns the index of id, adding it when missing.
+func (g *idGraph) intern(id string) int32 {
+	if i, ok := g.index[id]; ok {
+		return i
+	}
+	i := int32(len(g.ids))
+	g.ids = append(g.ids, id)
+	g.index[id] = i
+	g.deps = append(g.deps, nil)
+	g.dependents = append(g.dependents, nil)
+	return i
+}
+
+func (g *idGraph) internAll(ids []string) []int32 {
+	if len(ids) == 0 {
+		return nil
+	}
+	indices := make([]int32, len(ids))
+	for j, id := range ids {
+		indices[j] = g.intern(id)
+	}
+	return indices
+}
+
+// closure returns the IDs reachable from root along edges, excluding root, in
+// breadth-first order, like dag.Graph.Closure.
+func (g *idGraph) closure(root string, edges [][]int32, maxDepth int) []string {
+	start, ok := g.index[root]
+	if !ok {
+		return nil
+	}
+	visited := newBitset(len(g.ids))
+	visited.set(start)
+	queue := []int32{start}
+	var closure []string
+	for depth := 1; len(queue) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		var next []int32
+		for _, node := range queue {
+			for _, succ := range edges[node] {
+				if visited.has(succ) {
+					continue
+				}
+				visited.set(succ)
+				closure = append(closure, g.ids[succ])
+				next = append(next, succ)
+			}
+		}
+		queue = next
+	}
+	return closure
+}
+
+// setDeps replaces the dependencies of the loaded resource id, keeping the
+// dependents in catalog order.
+func (g *idGraph) setDeps(id string, deps []string) {
+	i := g.intern(id)
+	g.dropReach(i)
+	defer g.reachAfter(i)
+	for _, dep := range g.deps[i] {
+		g.dependents[dep] = slices.DeleteFunc(g.dependents[dep], func(d int32) bool { return d == i })
+	}
+	g.deps[i] = g.internAll(deps)
+	if int(i) >= g.resources {
+		return
+	}
+	for _, dep := range g.deps[i] {
+		pos, found := slices.BinarySearch(g.dependents[dep], i)
+		if !found {
+			g.dependents[dep] = slices.Insert(g.dependents[dep], pos, i)
+		}
+	}
+}
+
+// ids returns the interned graph of ResourceDependencies, building it on
+// first use. Callers must hold the read lock and must not modify the result.
+func (dr *DependencyResolver) ids() *idGraph {
+	dr.cache.mu.Lock()
+	defer dr.cache.mu.Unlock()
+	if dr.cache.ids == nil {
+		dr.cache.ids = newIDGraph(dr.resourceIDs(), dr.ResourceDependencies)
+	}
+	return dr.cache.ids
+}
+
+// closure returns the resources in the closure of id, excluding id, in
+// breadth-first order, down to maxDepth requirements away unless maxDepth is
+// 0. Callers must hold the read lock.
+func (dr *DependencyResolver) closure(id string, maxDepth int) []string {
+	g := dr.ids()
+	return g.closure(id, g.deps, maxDepth)
+}
+
+// dependentClosure returns the resources transitively requiring id, in
+// breadth-first order, down to maxDepth requirements away unless maxDepth is
+// 0. Callers must hold the read lock.
+func (dr *DependencyResolver) dependentClosure(id string, maxDepth int) []string {
+	g := dr.ids()
+	return g.closure(id, g.dependents, maxDepth)
+}
@@ -0,0 +1,103 @@
+package resolver
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jjuliano/runner/pkg/dag"
+)
+
+// randomGraph returns the IDs and dependencies of a random graph with cycles,
+// repeated requirements and requirements missing from the catalog.
+func randomGraph(nodes, edges int) ([]string, map[string][]string) {
+	rng := rand.New(rand.NewSource(1))
+	ids := make([]string, nodes)
+	deps := make(map[string][]string, nodes)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("r%d", i)
+		deps[ids[i]] = nil
+	}
+	for j := 0; j < edges; j++ {
+		from := ids[rng.Intn(nodes)]
+		to := fmt.Sprintf("r%d", rng.Intn(nodes+nodes/10))
+		deps[from] = append(deps[from], to)
+	}
+	return ids, deps
+}
+
+func TestIDGraph_Closure(t *testing.T) {
+	ids, deps := randomGraph(300, 900)
+	g := newIDGraph(ids, deps)
+	forward := dag.Graph[string](deps)
+	reverse := forward.Reverse(ids)
+
+	for _, id := range append(ids, "r310", "unknown") {
+		for _, maxDepth := range []int{0, 1, 3} {
+			if got, want := g.closure(id, g.deps, maxDepth), forward.Closure(id, maxDepth); !reflect.DeepEqual(got, want) {
+				t.Fatalf("closure(%s, %d): expected %v, got %v", id, maxDepth, want, got)
+			}
+			if got, want := g.closure(id, g.dependents, maxDepth), reverse.Closure(id, maxDepth); !reflect.DeepEqual(got, want) {
+				t.Fatalf("dependents(%s, %d): expected %v, got %v", id, maxDepth, want, got)
+			}
+		}
+	}
+}
+
+func TestIDGraph_SetDeps(t *testing.T) {
+	ids, deps := randomGraph(100, 300)
+	g := newIDGraph(ids, deps)
+
+	deps["r5"] = []string{"r1", "r99", "missing", "r1"}
+	deps["r50"] = nil
+	g.setDeps("r5", deps["r5"])
+	g.setDeps("r50", nil)
+
+	want := newIDGraph(ids, deps)
+	for _, id := range append(ids, "missing") {
+		if got, want := g.closure(id, g.deps, 0), want.closure(id, want.deps, 0); !reflect.DeepEqual(got, want) {
+			t.Errorf("closure(%s): expected %v, got %v", id, want, got)
+		}
+		if got, want := g.closure(id, g.dependents, 0), want.closure(id, want.dependents, 0); !reflect.DeepEqual(got, want) {
+			t.Errorf("dependents(%s): expected %v, got %v", id, want, got)
+		}
+	}
+}
+
+func BenchmarkClosure_Chain100k(b *testing.B) {
+	resolver := setupChainResolver(100000)
+	last := "n99999"
+	b.Run("dag", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resolver.graph().Closure(last, 0)
+		}
+	})
+	b.Run("interned", func(b *testing.B) {
+		resolver.ids()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			resolver.closure(last, 0)
+		}
+	})
+}
+
+func BenchmarkClosure_Random100k(b *testing.B) {
+	ids, deps := randomGraph(100000, 300000)
+	forward := dag.Graph[string](deps)
+	g := newIDGraph(ids, deps)
+	b.Run("dag", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			forward.Closure(ids[i%len(ids)], 0)
+		}
+	})
+	b.Run("interned", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.closure(ids[i%len(ids)], g.deps, 0)
+		}
+	})
+}
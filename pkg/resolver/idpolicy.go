@@ -0,0 +1,214 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultIDPattern is the grammar of resource IDs unless an IDPolicy sets
+// another one: a letter or digit, followed by letters, digits, dots,
+// underscores, dashes and slashes. IDs matching it can be used as they are in
+// DOT graphs, while slashes, which namespace IDs such as `tools/lint`, must
+// be escaped in URL paths and file names, as HTTPStore and FileStore do.
+const DefaultIDPattern = `^[\p{L}\p{N}][\p{L}\p{N}._/-]*$`
+
+// DefaultMaxIDLength is the maximum length of resource IDs, in characters,
+// unless an IDPolicy sets another one.
+const DefaultMaxIDLength = 128
+
+// idPatterns caches the compiled patterns of the ID policies.
+var idPatterns sync.Map
+
+// IDPolicy controls how resource IDs, and the references to them, are
+// normalized and validated on load. The zero policy keeps IDs exactly as
+// written, so IDs differing only in case or Unicode encoding name different
+// resources, and checks them against DefaultIDPattern and
+// DefaultMaxIDLength.
+type IDPolicy struct {
+	// CaseInsensitive lowercases IDs, so that IDs differing only in case
+	// name the same resource.
+	CaseInsensitive bool
+	// Normalize converts IDs to Unicode NFC, so that IDs differing only in
+	// how their characters are encoded name the same resource.
+	Normalize bool
+	// Pattern is the regular expression IDs must match, DefaultIDPattern
+	// when empty.
+	Pattern string
+	// MaxLength is the maximum length of IDs in characters,
+	// DefaultMaxIDLength when 0.
+	MaxLength int
+	// Reserved lists the IDs no resource can use, such as the names of
+	// downstream tools' keywords.
+	Reserved []string
+	// Legacy accepts any non-empty ID, for catalogs predating the
+	// validation of IDs.
+	Legacy bool
+}
+
+// InvalidIDError is returned when a resource ID breaks the IDPolicy, with
+// the file defining the resource, if any.
+type InvalidIDError struct {
+	Id     string
+	Source string
+	Reason string
+}
+
+func (e *InvalidIDError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("invalid resource ID '%s': %s", e.Id, e.Reason)
+	}
+	return fmt.Sprintf("invalid resource ID '%s' in %s: %s", e.Id, e.Source, e.Reason)
+}
+
+// ValidateIDPolicy returns an error when the pattern or the maximum length
+// of the policy are invalid.
+func ValidateIDPolicy(p IDPolicy) error {
+	if p.MaxLength < 0 {
+		return fmt.Errorf("invalid maximum ID length %d, expected a positive number or 0 for the default", p.MaxLength)
+	}
+	_, err := p.pattern()
+	return err
+}
+
+// pattern returns the compiled Pattern of the policy.
+func (p IDPolicy) pattern() (*regexp.Regexp, error) {
+	source := p.Pattern
+	if source == "" {
+		source = DefaultIDPattern
+	}
+	if re, ok := idPatterns.Load(source); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID pattern: %w", err)
+	}
+	idPatterns.Store(source, re)
+	return re, nil
+}
+
+// Check returns an InvalidIDError unless id follows the policy. Legacy
+// policies only reject empty IDs.
+func (p IDPolicy) Check(id string) error {
+	if id == "" {
+		return &InvalidIDError{Id: id, Reason: "IDs cannot be empty"}
+	}
+	if p.Legacy {
+		return nil
+	}
+	maxLength := p.MaxLength
+	if maxLength == 0 {
+		maxLength = DefaultMaxIDLength
+	}
+	if length := utf8.RuneCountInString(id); length > maxLength {
+		return &InvalidIDError{Id: id, Reason: fmt.Sprintf("%d characters long, the maximum is %d", length, maxLength)}
+	}
+	if slices.Contains(p.Reserved, id) {
+		return &InvalidIDError{Id: id, Reason: "the ID is reserved"}
+	}
+	re, err := p.pattern()
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(id) {
+		return &InvalidIDError{Id: id, Reason: "IDs must match " + re.String()}
+	}
+	return nil
+}
+
+// checkEntry checks the ID and the aliases of the entry, naming source in
+// the error.
+func (p IDPolicy) checkEntry(entry ResourceNodeEntry, source string) error {
+	for _, id := range append([]string{entry.Id}, entry.Aliases...) {
+		if err := p.Check(id); err != nil {
+			var invalid *InvalidIDError
+			if errors.As(err, &invalid) {
+				invalid.Source = source
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ID returns the normalized form of id.
+func (p IDPolicy) ID(id string) string {
+	if p.Normalize {
+		id = norm.NFC.String(id)
+	}
+	if p.CaseInsensitive {
+		id = strings.ToLower(id)
+	}
+	return id
+}
+
+// IDs returns the normalized form of every ID, in a new slice. A nil slice
+// stays nil.
+func (p IDPolicy) IDs(ids []string) []string {
+	if ids == nil {
+		return nil
+	}
+	normalized := make([]string, len(ids))
+	for i, id := range ids {
+		normalized[i] = p.ID(id)
+	}
+	return normalized
+}
+
+// entry returns the entry with its ID and every reference to a resource
+// normalized.
+func (p IDPolicy) entry(entry ResourceNodeEntry) ResourceNodeEntry {
+	if !p.CaseInsensitive && !p.Normalize {
+		return entry
+	}
+	entry.Id = p.ID(entry.Id)
+	entry.Aliases = p.IDs(entry.Aliases)
+	entry.ReplacedBy = p.ID(entry.ReplacedBy)
+	entry.Requires = p.IDs(entry.Requires)
+	entry.Optional = p.IDs(entry.Optional)
+	entry.Conflicts = p.IDs(entry.Conflicts)
+	entry.Provides = p.IDs(entry.Provides)
+	return entry
+}
+
+// overlay returns the overlay with its target and every reference to a
+// resource normalized.
+func (p IDPolicy) overlay(o ResourceOverlay) ResourceOverlay {
+	if !p.CaseInsensitive && !p.Normalize {
+		return o
+	}
+	o.Id = p.ID(o.Id)
+	o.ReplacedBy = p.ID(o.ReplacedBy)
+	o.AddRequires, o.RemoveRequires = p.IDs(o.AddRequires), p.IDs(o.RemoveRequires)
+	o.AddOptional, o.RemoveOptional = p.IDs(o.AddOptional), p.IDs(o.RemoveOptional)
+	o.AddConflicts, o.RemoveConflicts = p.IDs(o.AddConflicts), p.IDs(o.RemoveConflicts)
+	o.AddProvides, o.RemoveProvides = p.IDs(o.AddProvides), p.IDs(o.RemoveProvides)
+	o.AddAliases, o.RemoveAliases = p.IDs(o.AddAliases), p.IDs(o.RemoveAliases)
+	return o
+}
+
+// caseConflicts returns, for every resource whose ID only differs in case or
+// encoding from the ID of an earlier resource, the ID of that resource. They
+// are most likely typos creating a parallel resource, unless the IDPolicy
+// merges them.
+func (dr *DependencyResolver) caseConflicts() map[string]string {
+	folding := IDPolicy{CaseInsensitive: true, Normalize: true}
+	first := make(map[string]string, len(dr.Resources))
+	conflicts := make(map[string]string)
+	for _, entry := range dr.Resources {
+		folded := folding.ID(entry.Id)
+		if id, ok := first[folded]; !ok {
+			first[folded] = entry.Id
+		} else if id != entry.Id {
+			conflicts[entry.Id] = id
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,163 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestIDPolicy_ID(t *testing.T) {
+	decomposed, composed := "cafe\u0301/DB", "caf\u00e9/DB"
+	tests := []struct {
+		policy   IDPolicy
+		expected string
+	}{
+		{IDPolicy{}, decomposed},
+		{IDPolicy{Normalize: true}, composed},
+		{IDPolicy{CaseInsensitive: true}, "cafe\u0301/db"},
+		{IDPolicy{CaseInsensitive: true, Normalize: true}, "caf\u00e9/db"},
+	}
+	for _, test := range tests {
+		if got := test.policy.ID(decomposed); got != test.expected {
+			t.Errorf("With %+v, expected %q, got %q", test.policy, test.expected, got)
+		}
+	}
+	if got := (IDPolicy{CaseInsensitive: true}).IDs(nil); got != nil {
+		t.Errorf("Expected nil IDs to stay nil, got %v", got)
+	}
+}
+
+func setupIDPolicyResolver(policy IDPolicy) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	resolver.IDs = policy
+
+	afero.WriteFile(resolver.Fs, "catalog/a.yaml", []byte("resources:\n  - id: DB\n  - id: App\n    requires: [db]\n    optional: [Cache]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/b.yaml", []byte("id: Db\ndesc: Second definition\n"), 0644)
+	return resolver
+}
+
+func TestIDPolicy_CaseSensitive(t *testing.T) {
+	resolver := setupIDPolicyResolver(IDPolicy{})
+	resolver.LoadMode = LoadLenient
+	resolver.Resources = append(resolver.Resources, ResourceNodeEntry{Id: "db"})
+	if err := resolver.LoadSource("catalog"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ids := resolver.resourceIDs(); !reflect.DeepEqual(ids, []string{"db", "DB", "App", "Db"}) {
+		t.Errorf("Expected parallel resources, got %v", ids)
+	}
+	var conflicts []string
+	for _, warning := range resolver.Warnings() {
+		if warning.Code == CodeCaseConflict {
+			conflicts = append(conflicts, warning.Message)
+		}
+	}
+	expected := []string{
+		"resource 'DB' only differs in case or encoding from 'db'",
+		"resource 'Db' only differs in case or encoding from 'db'",
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("Expected %v, got %v", expected, conflicts)
+	}
+}
+
+func TestIDPolicy_CaseInsensitive(t *testing.T) {
+	resolver := setupIDPolicyResolver(IDPolicy{CaseInsensitive: true})
+
+	var dup *DuplicateResourceError
+	if err := resolver.LoadSource("catalog"); !errors.As(err, &dup) || dup.Id != "db" {
+		t.Fatalf("Expected the IDs differing in case to be duplicates, got %v", err)
+	}
+
+	if err := resolver.LoadSource("catalog/a.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.CheckRequirements(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	app, _ := resolver.GetResource("app")
+	if !reflect.DeepEqual(app.Requires, []string{"db"}) || !reflect.DeepEqual(app.Optional, []string{"cache"}) {
+		t.Errorf("Expected normalized references, got %+v", app)
+	}
+	if deps := resolver.DependenciesWithin("app", 0); !reflect.DeepEqual(deps, []string{"db"}) {
+		t.Errorf("Expected a single 'db' dependency, got %v", deps)
+	}
+
+	if err := resolver.ApplyOverlays([]ResourceOverlay{{Id: "APP", RemoveRequires: []string{"DB"}}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if app, _ := resolver.GetResource("app"); len(app.Requires) != 0 {
+		t.Errorf("Expected the overlay to remove the requirement, got %+v", app)
+	}
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "dB"}); !errors.As(err, &dup) {
+		t.Errorf("Expected a duplicate, got %v", err)
+	}
+}
+
+func TestIDPolicy_Check(t *testing.T) {
+	tests := []struct {
+		policy IDPolicy
+		id     string
+		valid  bool
+	}{
+		{IDPolicy{}, "tools/lint-2.0_x", true},
+		{IDPolicy{}, "café", true},
+		{IDPolicy{}, "", false},
+		{IDPolicy{}, "-flag", false},
+		{IDPolicy{}, "with space", false},
+		{IDPolicy{}, "a\"b", false},
+		{IDPolicy{}, strings.Repeat("a", DefaultMaxIDLength+1), false},
+		{IDPolicy{MaxLength: 3}, "abcd", false},
+		{IDPolicy{Reserved: []string{"all"}}, "all", false},
+		{IDPolicy{Pattern: `^[a-z]+$`}, "db2", false},
+		{IDPolicy{Legacy: true}, "with space", true},
+		{IDPolicy{Legacy: true}, "", false},
+	}
+	for _, test := range tests {
+		err := test.policy.Check(test.id)
+		var invalid *InvalidIDError
+		if test.valid && err != nil || !test.valid && !errors.As(err, &invalid) {
+			t.Errorf("With %+v, unexpected result for %q: %v", test.policy, test.id, err)
+		}
+	}
+
+	if err := ValidateIDPolicy(IDPolicy{Pattern: "("}); err == nil {
+		t.Error("Expected an invalid pattern to fail")
+	}
+	if err := ValidateIDPolicy(IDPolicy{MaxLength: -1}); err == nil {
+		t.Error("Expected a negative maximum length to fail")
+	}
+}
+
+func TestIDPolicy_InvalidIDs(t *testing.T) {
+	resolver := setupIDPolicyResolver(IDPolicy{})
+	afero.WriteFile(resolver.Fs, "catalog/c.yaml", []byte("resources:\n  - id: web\n    aliases: [old web]\n"), 0644)
+
+	var invalid *InvalidIDError
+	if err := resolver.LoadSource("catalog/c.yaml"); !errors.As(err, &invalid) || invalid.Id != "old web" || invalid.Source != "catalog/c.yaml" {
+		t.Errorf("Expected the invalid alias to fail, got %v", err)
+	}
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "a:b"}); !errors.As(err, &invalid) {
+		t.Errorf("Expected the invalid ID to fail, got %v", err)
+	}
+
+	resolver.LoadMode = LoadLenient
+	if err := resolver.LoadSource("catalog/c.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Warning{{Code: CodeInvalidID, Resource: "web", File: "catalog/c.yaml", Message: "invalid resource ID 'old web' in catalog/c.yaml: IDs must match " + DefaultIDPattern}}
+	if warnings := resolver.LoadWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected %v, got %v", expected, warnings)
+	}
+
+	resolver.IDs.Legacy = true
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "a:b"}); err != nil {
+		t.Errorf("Expected legacy IDs to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package resolver
+
+// Impact lists the resources that can no longer be resolved once a resource
+// is removed.
+type Impact struct {
+	Resource   string   `json:"resource" yaml:"resource"`
+	Direct     []string `json:"direct" yaml:"direct"`
+	Transitive []string `json:"transitive" yaml:"transitive"`
+}
+
+// ImpactOfRemoval returns the resources whose closures would become
+// unsatisfiable without the given resource. Direct lists the resources with a
+// requirement nothing else satisfies, in catalog order; Transitive lists the
+// resources depending on those, in breadth-first order.
+func (dr *DependencyResolver) ImpactOfRemoval(id string) (Impact, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if _, ok := dr.getResource(id); !ok {
+		return Impact{}, &NotFoundError{Id: id}
+	}
+	if err := dr.checkLimits([]string{id}, true, 0); err != nil {
+		return Impact{}, err
+	}
+
+	impact := Impact{Resource: id, Direct: nonNil(dr.requiredBy(id)), Transitive: []string{}}
+	seen := map[string]bool{id: true}
+	for _, direct := range impact.Direct {
+		seen[direct] = true
+	}
+
+	reverse := dr.reverseDependencies()
+	queue := append([]string(nil), impact.Direct...)
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dependent := range reverse[node] {
+			if seen[dependent] {
+				continue
+			}
+			seen[dependent] = true
+			impact.Transitive = append(impact.Transitive, dependent)
+			queue = append(queue, dependent)
+		}
+	}
+	return impact, nil
+}
@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImpactOfRemoval(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "base"},
+		{Id: "postgres", Provides: []string{"database"}, Requires: []string{"base"}},
+		{Id: "mysql", Provides: []string{"database"}},
+		{Id: "lib", Requires: []string{"base"}},
+		{Id: "api", Requires: []string{"lib", "database"}},
+		{Id: "app", Requires: []string{"api"}},
+		{Id: "tool", Optional: []string{"base"}},
+	}
+	resolver.ProviderPreferences["database"] = "postgres"
+	resolver.BuildDependencies()
+
+	impact, err := resolver.ImpactOfRemoval("base")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := Impact{Resource: "base", Direct: []string{"postgres", "lib"}, Transitive: []string{"api", "app"}}
+	if !reflect.DeepEqual(impact, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, impact)
+	}
+
+	impact, err = resolver.ImpactOfRemoval("postgres")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = Impact{Resource: "postgres", Direct: []string{}, Transitive: []string{}}
+	if !reflect.DeepEqual(impact, expected) {
+		t.Errorf("Expected no impact with another database provider, got %+v", impact)
+	}
+
+	if _, err := resolver.ImpactOfRemoval("missing"); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+}
@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFile is the resources defined by a manifest file.
+type manifestFile struct {
+	Path     string
+	Entries  []ResourceNodeEntry
+	Includes []string
+	Warnings []Warning
+	// data is the content of the manifest, to locate issues in.
+	data []byte
+}
+
+// IncludeCycleError is returned when a manifest includes itself, directly or
+// through other manifests.
+type IncludeCycleError struct {
+	// Chain is the includes leading back to the first manifest of the cycle.
+	Chain []string
+}
+
+func (e *IncludeCycleError) Error() string {
+	return "include cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// includePath resolves an include of the manifest at from: URLs and absolute
+// paths are kept, and relative paths are relative to the manifest.
+func includePath(from, include string) (string, error) {
+	if isURL(include) || filepath.IsAbs(include) {
+		return include, nil
+	}
+	if isURL(from) {
+		base, err := url.Parse(from)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(include)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	return filepath.Join(filepath.Dir(from), include), nil
+}
+
+// readManifestFiles reads the manifest and the manifests it includes, in
+// include order, skipping the manifests in read and adding the ones it reads.
+// Sharing read across the files of a load reads a manifest included several
+// times, or both included and loaded, only once.
+func (dr *DependencyResolver) readManifestFiles(filePath string, read map[string]bool) ([]manifestFile, error) {
+	var files []manifestFile
+
+	var visit func(path string, chain []string) error
+	visit = func(path string, chain []string) error {
+		for i, parent := range chain {
+			if parent == path {
+				return &IncludeCycleError{Chain: append(append([]string(nil), chain[i:]...), path)}
+			}
+		}
+		if read[path] {
+			return nil
+		}
+		read[path] = true
+		chain = append(chain, path)
+
+		manifest, err := dr.readResourceEntries(path)
+		if err != nil {
+			if len(chain) > 1 {
+				return fmt.Errorf("%w (included by %s)", err, strings.Join(chain[:len(chain)-1], " -> "))
+			}
+			return err
+		}
+		files = append(files, manifest)
+
+		for _, include := range manifest.Includes {
+			included, err := includePath(path, include)
+			if err != nil {
+				return fmt.Errorf("invalid include '%s' in %s: %w", include, path, err)
+			}
+			if err := visit(included, chain); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := visit(filePath, nil); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestLoadResourceEntries_Include(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "catalog/main.yaml", []byte("include: [tools/git.yaml, tools/tag.yaml]\nresources:\n  - id: app\n    requires: [tag]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/tools/git.yaml", []byte("id: git\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/tools/tag.yaml", []byte("include: [git.yaml]\nid: tag\nrequires: [git]\n"), 0644)
+
+	if err := resolver.LoadSource("catalog/main.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"app", "git", "tag"}) {
+		t.Errorf("Expected the included resources to be loaded once, got %v", ids)
+	}
+	if deps := resolver.TransitiveDependencies("app"); len(deps) != 2 {
+		t.Errorf("Expected the dependencies across files to resolve, got %v", deps)
+	}
+
+	afero.WriteFile(resolver.Fs, "other.yaml", []byte("include: [catalog/tools/git.yaml]\n"), 0644)
+	var dup *DuplicateResourceError
+	err := resolver.LoadSource("other.yaml")
+	if !errors.As(err, &dup) || dup.FirstSource != "catalog/tools/git.yaml" {
+		t.Errorf("Expected a duplicate naming the included file, got %v", err)
+	}
+}
+
+func TestLoadResourceEntries_IncludeSibling(t *testing.T) {
+	for _, source := range []string{"catalog", "catalog/*.yaml"} {
+		resolver := setupTestResolver()
+		resolver.Resources = nil
+		resolver.BuildDependencies()
+
+		afero.WriteFile(resolver.Fs, "catalog/app.yaml", []byte("include: [git.yaml]\nid: app\nrequires: [git]\n"), 0644)
+		afero.WriteFile(resolver.Fs, "catalog/git.yaml", []byte("id: git\n"), 0644)
+		afero.WriteFile(resolver.Fs, "catalog/tag.yaml", []byte("include: [git.yaml]\nid: tag\n"), 0644)
+
+		if err := resolver.LoadSource(source); err != nil {
+			t.Fatalf("%s: unexpected error: %v", source, err)
+		}
+		if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"app", "git", "tag"}) {
+			t.Errorf("%s: expected the manifests both loaded and included to be loaded once, got %v", source, ids)
+		}
+	}
+}
+
+func TestLoadResourceEntries_IncludeErrors(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "a.yaml", []byte("include: [b.yaml]\nid: a\n"), 0644)
+	afero.WriteFile(resolver.Fs, "b.yaml", []byte("include: [c.yaml]\nid: b\n"), 0644)
+	afero.WriteFile(resolver.Fs, "c.yaml", []byte("include: [b.yaml]\nid: c\n"), 0644)
+
+	var cycle *IncludeCycleError
+	err := resolver.LoadSource("a.yaml")
+	if !errors.As(err, &cycle) || !reflect.DeepEqual(cycle.Chain, []string{"b.yaml", "c.yaml", "b.yaml"}) {
+		t.Errorf("Expected an include cycle through b and c, got %v", err)
+	}
+	if len(resolver.ListResources()) != 0 {
+		t.Error("Expected nothing to be loaded on error")
+	}
+
+	afero.WriteFile(resolver.Fs, "c.yaml", []byte("include: [missing.yaml]\nid: c\n"), 0644)
+	err = resolver.LoadSource("a.yaml")
+	if err == nil || !strings.Contains(err.Error(), "missing.yaml") || !strings.Contains(err.Error(), "included by a.yaml -> b.yaml -> c.yaml") {
+		t.Errorf("Expected the error to show the include chain, got %v", err)
+	}
+}
+
+func TestLoadResourceEntries_IncludeURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/catalog/main.yaml":
+			w.Write([]byte("include: [tools.yaml]\nid: app\nrequires: [git]\n"))
+		case "/catalog/tools.yaml":
+			w.Write([]byte("id: git\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	if err := resolver.LoadSource(srv.URL + "/catalog/main.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"app", "git"}) {
+		t.Errorf("Expected the include to resolve against the URL, got %v", ids)
+	}
+}
+
+func TestIncludePath(t *testing.T) {
+	tests := []struct {
+		from, include, expected string
+	}{
+		{"catalog/main.yaml", "tools/git.yaml", "catalog/tools/git.yaml"},
+		{"catalog/main.yaml", "../shared.yaml", "shared.yaml"},
+		{"catalog/main.yaml", "/etc/runner/base.yaml", "/etc/runner/base.yaml"},
+		{"catalog/main.yaml", "https://example.com/a.yaml", "https://example.com/a.yaml"},
+		{"https://example.com/catalog/main.yaml", "../b.yaml", "https://example.com/b.yaml"},
+	}
+	for _, test := range tests {
+		path, err := includePath(test.from, test.include)
+		if err != nil || path != test.expected {
+			t.Errorf("Expected %s from %s to be %s, got %s (%v)", test.include, test.from, test.expected, path, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package resolver
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// updateDependencies is buildDependencies for a catalog in which only the
+// resources at the given positions of Resources were replaced, previous
+// holding their former definitions. It recomputes the requirements of the
+// replaced resources and only invalidates the cached results they affect, so
+// editing one resource of a large catalog doesn't recompute every closure.
+//
+// It falls back to buildDependencies when the change may affect the
+// requirements of other resources: an ID, alias or provided name changed, or
+// the catalog defines an ID more than once. The caller must hold the write
+// lock.
+func (dr *DependencyResolver) updateDependencies(previous map[int]ResourceNodeEntry) {
+	index := dr.currentIndex()
+	if index == nil || len(index.byID) != len(dr.Resources) {
+		dr.buildDependencies()
+		return
+	}
+	positions := make([]int, 0, len(previous))
+	for i, old := range previous {
+		if !sameIdentity(old, dr.Resources[i]) {
+			dr.buildDependencies()
+			return
+		}
+		positions = append(positions, i)
+	}
+	sort.Ints(positions)
+
+	dr.cache.mu.Lock()
+	defer dr.cache.mu.Unlock()
+	// Snapshots copy the resources, so any change invalidates them.
+	dr.cache.snapshot = nil
+
+	edges := 0
+	for _, i := range positions {
+		entry := dr.Resources[i]
+		index.update(i, previous[i], entry)
+
+		oldDeps := dr.ResourceDependencies[entry.Id]
+		newDeps := dr.entryDependencies(entry, dr.knownRequirements(entry))
+		if slices.Equal(oldDeps, newDeps) {
+			continue
+		}
+		edges += len(newDeps) - len(oldDeps)
+
+		// The closures containing the resource go through its requirements,
+		// and the resources it reached have it among their dependents.
+		for id, closure := range dr.cache.closures {
+			if id == entry.Id || slices.Contains(closure, entry.Id) {
+				delete(dr.cache.closures, id)
+			}
+		}
+		for id, dependents := range dr.cache.dependents {
+			if slices.Contains(dependents, entry.Id) {
+				delete(dr.cache.dependents, id)
+			}
+		}
+		if dr.cache.reverse != nil {
+			dr.updateReverse(index, i, entry.Id, oldDeps, newDeps)
+		}
+		if dr.cache.ids != nil {
+			dr.cache.ids.setDeps(entry.Id, newDeps)
+		}
+
+		dr.ResourceDependencies[entry.Id] = newDeps
+		// The resources it reaches now have it among their dependents too.
+		for _, id := range dr.graph().Closure(entry.Id, 0) {
+			delete(dr.cache.dependents, id)
+		}
+	}
+	graphEdges.Add(float64(edges))
+}
+
+// sameIdentity reports whether two definitions of a resource are satisfied by
+// the same requirements of other resources.
+func sameIdentity(old, entry ResourceNodeEntry) bool {
+	return old.Id == entry.Id && slices.Equal(old.Aliases, entry.Aliases) && slices.Equal(old.Provides, entry.Provides)
+}
+
+// knownRequirements returns which requirements of the entry, and which of the
+// resources they resolve to, are loaded resources, like knownResources
+// restricted to them. entryDependencies looks up the resolved providers of
+// optional requirements.
+func (dr *DependencyResolver) knownRequirements(entry ResourceNodeEntry) map[string]bool {
+	known := make(map[string]bool, 2*(len(entry.Requires)+len(entry.Optional)))
+	for _, names := range [][]string{entry.Requires, entry.Optional} {
+		for _, name := range names {
+			known[name] = dr.resourceIndexOf(name) >= 0
+			if provider := dr.providerFor(name, known); provider != name {
+				known[provider] = dr.resourceIndexOf(provider) >= 0
+			}
+		}
+	}
+	return known
+}
+
+// updateReverse moves the resource at position i of Resources from the
+// cached reverse dependencies of its former requirements to the ones of its
+// new requirements, keeping them in catalog order.
+func (dr *DependencyResolver) updateReverse(index *resourceIndex, i int, id string, oldDeps, newDeps []string) {
+	reverse := dr.cache.reverse
+	for _, dep := range oldDeps {
+		reverse[dep] = slices.DeleteFunc(reverse[dep], func(dependent string) bool { return dependent == id })
+		if len(reverse[dep]) == 0 {
+			delete(reverse, dep)
+		}
+	}
+	for _, dep := range newDeps {
+		dependents := reverse[dep]
+		if slices.Contains(dependents, id) {
+			continue
+		}
+		pos := sort.Search(len(dependents), func(j int) bool { return index.byID[dependents[j]] > i })
+		reverse[dep] = slices.Insert(dependents, pos, id)
+	}
+}
+
+// update updates the index for the resource at position i, replaced by
+// entry.
+func (index *resourceIndex) update(i int, old, entry ResourceNodeEntry) {
+	if old.Name != entry.Name {
+		removePosition(index.byName, old.Name, i)
+		insertPosition(index.byName, entry.Name, i)
+	}
+	if old.Category != entry.Category {
+		removePosition(index.byCategory, old.Category, i)
+		insertPosition(index.byCategory, entry.Category, i)
+	}
+	if !slices.Equal(old.Owners, entry.Owners) {
+		for _, owner := range old.Owners {
+			removePosition(index.byOwner, owner, i)
+		}
+		for _, owner := range entry.Owners {
+			insertPosition(index.byOwner, owner, i)
+		}
+	}
+}
+
+// removePosition removes position i from the positions of key.
+func removePosition(positions map[string][]int, key string, i int) {
+	positions[key] = slices.DeleteFunc(positions[key], func(p int) bool { return p == i })
+	if len(positions[key]) == 0 {
+		delete(positions, key)
+	}
+}
+
+// insertPosition adds position i to the sorted positions of key.
+func insertPosition(positions map[string][]int, key string, i int) {
+	pos, _ := slices.BinarySearch(positions[key], i)
+	positions[key] = slices.Insert(positions[key], pos, i)
+}
+
+// changedResources returns the positions of the resources of Resources that
+// differ in the given catalog, or false when the catalogs don't list the same
+// IDs in the same order.
+func (dr *DependencyResolver) changedResources(resources []ResourceNodeEntry) ([]int, bool) {
+	if len(resources) != len(dr.Resources) {
+		return nil, false
+	}
+	var changed []int
+	for i, entry := range resources {
+		if entry.Id != dr.Resources[i].Id {
+			return nil, false
+		}
+		if !reflect.DeepEqual(entry, dr.Resources[i]) {
+			changed = append(changed, i)
+		}
+	}
+	return changed, true
+}
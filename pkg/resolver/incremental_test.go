@@ -0,0 +1,173 @@
+package resolver
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// incrementalCatalog is a catalog with two independent components.
+func incrementalCatalog() []ResourceNodeEntry {
+	return []ResourceNodeEntry{
+		{Id: "a", Name: "A", Category: "app", Requires: []string{"b"}},
+		{Id: "b", Name: "B", Category: "lib", Owners: []string{"team-b"}, Requires: []string{"c"}},
+		{Id: "c", Name: "C", Category: "lib", Requires: []string{}},
+		{Id: "d", Name: "D", Category: "app", Requires: []string{"c"}},
+		{Id: "e", Name: "E", Category: "lib", Requires: []string{}},
+		{Id: "f", Name: "F", Category: "app", Requires: []string{"e"}},
+	}
+}
+
+// warmCache queries the closures and reverse dependencies of every resource.
+func warmCache(dr *DependencyResolver) {
+	for _, entry := range dr.ListResources() {
+		dr.TransitiveDependencies(entry.Id)
+		dr.TransitiveDependents(entry.Id)
+		dr.ReverseDependencies(entry.Id)
+	}
+}
+
+// cachedKeys returns the sorted keys of a cache map.
+func cachedKeys(cache map[string][]string) []string {
+	keys := make([]string, 0, len(cache))
+	for key := range cache {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// assertSameGraph fails unless got answers every query like a resolver
+// built from scratch with the same resources.
+func assertSameGraph(t *testing.T, got *DependencyResolver) {
+	t.Helper()
+	want := setupTestResolver()
+	want.Resources = got.ListResources()
+	want.BuildDependencies()
+
+	for _, entry := range want.Resources {
+		id := entry.Id
+		checks := map[string][2]interface{}{
+			"DirectDependencies":     {got.DirectDependencies(id), want.DirectDependencies(id)},
+			"TransitiveDependencies": {got.TransitiveDependencies(id), want.TransitiveDependencies(id)},
+			"TransitiveDependents":   {got.TransitiveDependents(id), want.TransitiveDependents(id)},
+			"ReverseDependencies":    {got.ReverseDependencies(id), want.ReverseDependencies(id)},
+			"ResourcesByName":        {got.ResourcesByName(entry.Name), want.ResourcesByName(entry.Name)},
+			"ResourcesByCategory":    {got.ResourcesByCategory(entry.Category), want.ResourcesByCategory(entry.Category)},
+		}
+		for name, check := range checks {
+			if !reflect.DeepEqual(check[0], check[1]) {
+				t.Errorf("%s(%s): expected %v, got %v", name, id, check[1], check[0])
+			}
+		}
+	}
+	if g, w := got.Roots(), want.Roots(); !reflect.DeepEqual(g, w) {
+		t.Errorf("Roots: expected %v, got %v", w, g)
+	}
+	if g, w := got.ResourcesOwnedBy("team-b"), want.ResourcesOwnedBy("team-b"); !reflect.DeepEqual(g, w) {
+		t.Errorf("ResourcesOwnedBy: expected %v, got %v", w, g)
+	}
+}
+
+func TestUpdateResource_Incremental(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = incrementalCatalog()
+	resolver.BuildDependencies()
+	warmCache(resolver)
+
+	// Move 'b' from the first component to the second one.
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "b", Name: "Bee", Category: "app", Requires: []string{"e"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Only the closures through 'b' and the dependents of what it reached or
+	// reaches are invalidated.
+	if keys := cachedKeys(resolver.cache.closures); !reflect.DeepEqual(keys, []string{"c", "d", "e", "f"}) {
+		t.Errorf("Expected the closures of a and b to be invalidated, got %v cached", keys)
+	}
+	if keys := cachedKeys(resolver.cache.dependents); !reflect.DeepEqual(keys, []string{"a", "b", "d", "f"}) {
+		t.Errorf("Expected the dependents of c and e to be invalidated, got %v cached", keys)
+	}
+	if resolver.cache.reverse == nil {
+		t.Error("Expected the reverse dependencies to be updated in place")
+	}
+	assertSameGraph(t, resolver)
+}
+
+func TestUpdateResource_SameRequirements(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = incrementalCatalog()
+	resolver.BuildDependencies()
+	warmCache(resolver)
+
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "b", Name: "B", Desc: "Changed", Requires: []string{"c"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resolver.cache.closures) != 6 || len(resolver.cache.dependents) != 6 {
+		t.Errorf("Expected every closure to stay cached, got %d closures and %d dependents", len(resolver.cache.closures), len(resolver.cache.dependents))
+	}
+	if entry, _ := resolver.GetResource("b"); entry.Desc != "Changed" {
+		t.Errorf("Expected the resource to be updated, got %+v", entry)
+	}
+	assertSameGraph(t, resolver)
+}
+
+func TestUpdateResource_ProvidesRebuilds(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = incrementalCatalog()
+	resolver.BuildDependencies()
+	warmCache(resolver)
+
+	// A new provided name may satisfy the requirements of other resources.
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "e", Provides: []string{"storage"}, Requires: []string{}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resolver.cache.closures) != 0 {
+		t.Errorf("Expected the dependencies to be rebuilt, got %v cached", cachedKeys(resolver.cache.closures))
+	}
+	assertSameGraph(t, resolver)
+}
+
+func TestUpdateResource_OptionalVirtualRequirement(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Name: "App", Optional: []string{"storage"}},
+		{Id: "e", Name: "E", Provides: []string{"storage"}},
+	}
+	resolver.BuildDependencies()
+	if deps := resolver.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"e"}) {
+		t.Fatalf("Expected the provider of the optional requirement, got %v", deps)
+	}
+
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "app", Name: "App", Desc: "Changed", Optional: []string{"storage"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"e"}) {
+		t.Errorf("Expected the provider to be kept after the update, got %v", deps)
+	}
+	assertSameGraph(t, resolver)
+}
+
+func TestReload_Incremental(t *testing.T) {
+	resolver := setupWatchResolver(t)
+	afero.WriteFile(resolver.Fs, "catalog/app.yaml", []byte("id: app\nrequires: [git]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/tool.yaml", []byte("id: tool\n"), 0644)
+	if err := resolver.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	warmCache(resolver)
+
+	afero.WriteFile(resolver.Fs, "catalog/app.yaml", []byte("id: app\nrequires: [git, tool]\n"), 0644)
+	if err := resolver.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"git", "tool"}) {
+		t.Errorf("Expected the changed requirements, got %v", deps)
+	}
+	if keys := cachedKeys(resolver.cache.closures); !reflect.DeepEqual(keys, []string{"git", "tool"}) {
+		t.Errorf("Expected only the closure of 'app' to be invalidated, got %v cached", keys)
+	}
+	assertSameGraph(t, resolver)
+}
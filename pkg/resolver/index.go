@@ -0,0 +1,186 @@
+package resolver
+
+import (
+	"slices"
+	"sort"
+)
+
+// resourceIndex maps the IDs, aliases, names, categories and owners of the
+// loaded resources to their positions in Resources. It is rebuilt with the
+// dependencies, so every mutation through the resolver keeps it in sync. It remembers the Resources
+// slice it was built from: lookups scan Resources instead when the field was
+// replaced without rebuilding the dependencies.
+type resourceIndex struct {
+	first      *ResourceNodeEntry
+	size       int
+	byID       map[string]int
+	byName     map[string][]int
+	byCategory map[string][]int
+	byOwner    map[string][]int
+	byAlias    map[string]int
+}
+
+// buildIndex rebuilds the index of Resources. The caller must hold the write
+// lock.
+func (dr *DependencyResolver) buildIndex() {
+	index := &resourceIndex{
+		size:       len(dr.Resources),
+		byID:       make(map[string]int, len(dr.Resources)),
+		byName:     make(map[string][]int),
+		byCategory: make(map[string][]int),
+		byOwner:    make(map[string][]int),
+		byAlias:    make(map[string]int),
+	}
+	if len(dr.Resources) > 0 {
+		index.first = &dr.Resources[0]
+	}
+	for i, entry := range dr.Resources {
+		// The first definition wins, as when scanning.
+		if _, ok := index.byID[entry.Id]; !ok {
+			index.byID[entry.Id] = i
+		}
+		index.byName[entry.Name] = append(index.byName[entry.Name], i)
+		index.byCategory[entry.Category] = append(index.byCategory[entry.Category], i)
+		for _, alias := range entry.Aliases {
+			if _, ok := index.byAlias[alias]; !ok {
+				index.byAlias[alias] = i
+			}
+		}
+		for _, owner := range entry.Owners {
+			index.byOwner[owner] = append(index.byOwner[owner], i)
+		}
+	}
+	dr.index = index
+}
+
+// currentIndex returns the index of Resources, or nil when Resources changed
+// since it was built.
+func (dr *DependencyResolver) currentIndex() *resourceIndex {
+	index := dr.index
+	if index == nil || index.size != len(dr.Resources) {
+		return nil
+	}
+	if index.size > 0 && index.first != &dr.Resources[0] {
+		return nil
+	}
+	return index
+}
+
+// resourceIndexOf returns the position of the resource in Resources, or -1.
+func (dr *DependencyResolver) resourceIndexOf(id string) int {
+	if index := dr.currentIndex(); index != nil {
+		if i, ok := index.byID[id]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, entry := range dr.Resources {
+		if entry.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// aliasOf returns the ID of the resource with the given alias, or "" when no
+// resource has it. The first resource declaring an alias wins.
+func (dr *DependencyResolver) aliasOf(alias string) string {
+	if index := dr.currentIndex(); index != nil {
+		if i, ok := index.byAlias[alias]; ok {
+			return dr.Resources[i].Id
+		}
+		return ""
+	}
+	for _, entry := range dr.Resources {
+		if slices.Contains(entry.Aliases, alias) {
+			return entry.Id
+		}
+	}
+	return ""
+}
+
+// ResourcesByName returns the resources with the given display name, in
+// catalog order.
+func (dr *DependencyResolver) ResourcesByName(name string) []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.lookup(func(index *resourceIndex) map[string][]int { return index.byName },
+		func(entry ResourceNodeEntry, name string) bool { return entry.Name == name }, []string{name})
+}
+
+// ResourcesByCategory returns the resources of any of the given categories,
+// in catalog order. The Store is queried for the resources loaded from it.
+func (dr *DependencyResolver) ResourcesByCategory(categories ...string) []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	entries := dr.lookup(func(index *resourceIndex) map[string][]int { return index.byCategory },
+		func(entry ResourceNodeEntry, category string) bool { return entry.Category == category }, categories)
+	if dr.Store == nil || len(dr.storeIDs) == 0 {
+		return entries
+	}
+	return dr.storeCategories(entries, categories)
+}
+
+// ResourcesOwnedBy returns the resources owned by any of the given owners, in
+// catalog order.
+func (dr *DependencyResolver) ResourcesOwnedBy(owners ...string) []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.lookup(func(index *resourceIndex) map[string][]int { return index.byOwner },
+		func(entry ResourceNodeEntry, owner string) bool { return slices.Contains(entry.Owners, owner) }, owners)
+}
+
+// Categories returns the categories of the loaded resources, sorted.
+func (dr *DependencyResolver) Categories() []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	if index := dr.currentIndex(); index != nil {
+		for category := range index.byCategory {
+			seen[category] = true
+		}
+	} else {
+		for _, entry := range dr.Resources {
+			seen[entry.Category] = true
+		}
+	}
+
+	categories := make([]string, 0, len(seen))
+	for category := range seen {
+		if category != "" {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// lookup returns the resources matching any of keys, as reported by match,
+// using the index map selected by field when the index is current. A
+// resource matching several keys is returned once.
+func (dr *DependencyResolver) lookup(field func(*resourceIndex) map[string][]int, match func(ResourceNodeEntry, string) bool, keys []string) []ResourceNodeEntry {
+	var positions []int
+	if index := dr.currentIndex(); index != nil {
+		for _, k := range keys {
+			positions = append(positions, field(index)[k]...)
+		}
+		sort.Ints(positions)
+		positions = slices.Compact(positions)
+	} else {
+		for i, entry := range dr.Resources {
+			for _, k := range keys {
+				if match(entry, k) {
+					positions = append(positions, i)
+					break
+				}
+			}
+		}
+	}
+
+	entries := make([]ResourceNodeEntry, 0, len(positions))
+	for _, i := range positions {
+		entries = append(entries, dr.Resources[i])
+	}
+	return entries
+}
@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func entryIds(entries []ResourceNodeEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+	return ids
+}
+
+func setupIndexResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs"},
+		{Id: "go", Name: "Go", Category: "language"},
+		{Id: "hg", Name: "Mercurial", Category: "vcs"},
+		{Id: "golang", Name: "Go", Category: "language"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestResourceIndex(t *testing.T) {
+	resolver := setupIndexResolver()
+
+	if entry, ok := resolver.GetResource("hg"); !ok || entry.Name != "Mercurial" {
+		t.Errorf("Expected hg, got %+v", entry)
+	}
+	if ids := entryIds(resolver.ResourcesByName("Go")); !reflect.DeepEqual(ids, []string{"go", "golang"}) {
+		t.Errorf("Expected the resources named Go, got %v", ids)
+	}
+	if ids := entryIds(resolver.ResourcesByCategory("language", "vcs")); !reflect.DeepEqual(ids, []string{"git", "go", "hg", "golang"}) {
+		t.Errorf("Expected the resources in catalog order, got %v", ids)
+	}
+	if ids := entryIds(resolver.ResourcesByCategory("missing")); len(ids) != 0 {
+		t.Errorf("Expected no resources, got %v", ids)
+	}
+	if categories := resolver.Categories(); !reflect.DeepEqual(categories, []string{"language", "vcs"}) {
+		t.Errorf("Expected the sorted categories, got %v", categories)
+	}
+}
+
+func TestResourceIndex_Mutations(t *testing.T) {
+	resolver := setupIndexResolver()
+
+	resolver.AddResourceEntry(ResourceNodeEntry{Id: "svn", Name: "Subversion", Category: "vcs"})
+	resolver.UpdateResource(ResourceNodeEntry{Id: "golang", Name: "Golang", Category: "language"})
+	resolver.RemoveResource("git", false)
+
+	if ids := entryIds(resolver.ResourcesByCategory("vcs")); !reflect.DeepEqual(ids, []string{"hg", "svn"}) {
+		t.Errorf("Expected the index to follow the mutations, got %v", ids)
+	}
+	if ids := entryIds(resolver.ResourcesByName("Go")); !reflect.DeepEqual(ids, []string{"go"}) {
+		t.Errorf("Expected the renamed resource to be reindexed, got %v", ids)
+	}
+	if _, ok := resolver.GetResource("git"); ok {
+		t.Error("Expected the removed resource not to be found")
+	}
+	if entry, ok := resolver.GetResource("svn"); !ok || entry.Name != "Subversion" {
+		t.Errorf("Expected the added resource, got %+v", entry)
+	}
+}
+
+func TestResourceIndex_StaleResources(t *testing.T) {
+	resolver := setupIndexResolver()
+
+	// Replacing Resources without rebuilding falls back to scanning.
+	resolver.Resources = []ResourceNodeEntry{{Id: "svn", Category: "vcs"}}
+	if _, ok := resolver.GetResource("svn"); !ok {
+		t.Error("Expected the new resource to be found")
+	}
+	if _, ok := resolver.GetResource("git"); ok {
+		t.Error("Expected the replaced resource not to be found")
+	}
+	if ids := entryIds(resolver.ResourcesByCategory("vcs")); !reflect.DeepEqual(ids, []string{"svn"}) {
+		t.Errorf("Expected the new resources, got %v", ids)
+	}
+}
@@ -0,0 +1,154 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UndefinedVariableError is returned in strict mode when a manifest refers to
+// an environment variable that is not set and has no default.
+type UndefinedVariableError struct {
+	Name     string
+	Resource string
+	Source   string
+}
+
+func (e *UndefinedVariableError) Error() string {
+	return fmt.Sprintf("undefined environment variable '%s' in resource '%s' of %s", e.Name, e.Resource, e.Source)
+}
+
+// lookupEnv returns the LookupEnv of the resolver, or os.LookupEnv when unset.
+func (dr *DependencyResolver) lookupEnv(name string) (string, bool) {
+	if dr.LookupEnv != nil {
+		return dr.LookupEnv(name)
+	}
+	return os.LookupEnv(name)
+}
+
+// expandEnv replaces ${VAR} and ${VAR:-default} in s with the value of the
+// environment variable, or the default when it is unset or empty. $${ is
+// kept as a literal ${. An undefined variable without default is replaced by
+// an empty string, and its name returned in undefined.
+func expandEnv(s string, lookup func(string) (string, bool)) (expanded string, undefined []string, err error) {
+	if !strings.Contains(s, "${") {
+		return s, nil, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		b.WriteString(s[:i])
+
+		name, fallback, hasDefault := strings.Cut(s[i+2:i+end], ":-")
+		if name == "" {
+			return "", nil, fmt.Errorf("empty variable reference in %q", s)
+		}
+		value, ok := lookup(name)
+		switch {
+		case ok && value != "":
+			b.WriteString(value)
+		case hasDefault:
+			b.WriteString(fallback)
+		case !ok:
+			undefined = append(undefined, name)
+		}
+		s = s[i+end+1:]
+	}
+	return b.String(), undefined, nil
+}
+
+// interpolateEntries expands the environment variables referenced by the
+// fields of the entries read from source. Exec commands are left to the
+// shell, which expands variables when they run. In StrictEnv mode, a
+// variable that is not set and has no default is an error.
+func (dr *DependencyResolver) interpolateEntries(entries []ResourceNodeEntry, source string) error {
+	for i := range entries {
+		entry := &entries[i]
+		var err error
+		expand := func(s *string) {
+			if err != nil {
+				return
+			}
+			expanded, undefined, expandErr := expandEnv(*s, dr.lookupEnv)
+			switch {
+			case expandErr != nil:
+				err = fmt.Errorf("resource '%s' in %s: %w", entry.Id, source, expandErr)
+			case dr.StrictEnv && len(undefined) > 0:
+				err = &UndefinedVariableError{Name: undefined[0], Resource: entry.Id, Source: source}
+			default:
+				*s = expanded
+			}
+		}
+		expandAll := func(values []string) {
+			for j := range values {
+				expand(&values[j])
+			}
+		}
+		expandValue := func(v *interface{}) {
+			switch value := (*v).(type) {
+			case string:
+				expand(&value)
+				*v = value
+			case []interface{}:
+				for j := range value {
+					if s, ok := value[j].(string); ok {
+						expand(&s)
+						value[j] = s
+					}
+				}
+			}
+		}
+
+		expand(&entry.Name)
+		expand(&entry.Version)
+		expand(&entry.Desc)
+		expand(&entry.Category)
+		expand(&entry.License)
+		expand(&entry.Runner)
+		expand(&entry.Type)
+		expandAll(entry.Requires)
+		expandAll(entry.Optional)
+		expandAll(entry.Conflicts)
+		expandAll(entry.Provides)
+		expandAll(entry.Inputs)
+		expandAll(entry.Owners)
+		expandAll(entry.Aliases)
+		for key, value := range entry.Metadata {
+			expand(&value)
+			entry.Metadata[key] = value
+		}
+		expand(&entry.ReplacedBy)
+		for _, steps := range [][]RunStep{entry.PreRun, entry.Run, entry.PostRun, entry.Rollback} {
+			for j := range steps {
+				step := &steps[j]
+				expand(&step.Name)
+				expandValue(&step.Skip)
+				expandValue(&step.Check)
+				expandValue(&step.Expect)
+				for k := range step.Env {
+					expand(&step.Env[k].Value)
+					expand(&step.Env[k].File)
+					expand(&step.Env[k].Input)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"HOST": "db.internal", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input     string
+		expected  string
+		undefined []string
+	}{
+		{"plain", "plain", nil},
+		{"$HOST", "$HOST", nil},
+		{"https://${HOST}/api", "https://db.internal/api", nil},
+		{"${HOST:-localhost}", "db.internal", nil},
+		{"${MISSING:-localhost}", "localhost", nil},
+		{"${EMPTY:-fallback}", "fallback", nil},
+		{"${EMPTY}", "", nil},
+		{"${MISSING}-${HOST}", "-db.internal", []string{"MISSING"}},
+		{"$${HOST}", "${HOST}", nil},
+	}
+	for _, test := range tests {
+		expanded, undefined, err := expandEnv(test.input, lookup)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.input, err)
+		}
+		if expanded != test.expected || !reflect.DeepEqual(undefined, test.undefined) {
+			t.Errorf("Expected %q to expand to %q with undefined %v, got %q with %v", test.input, test.expected, test.undefined, expanded, undefined)
+		}
+	}
+
+	for _, input := range []string{"${HOST", "${}", "${:-x}"} {
+		if _, _, err := expandEnv(input, lookup); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestInterpolateEntries(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	env := map[string]string{"ENV": "staging", "DB": "postgres"}
+	resolver.LookupEnv = func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	afero.WriteFile(resolver.Fs, "catalog.yaml", []byte(`resources:
+  - id: app
+    name: App (${ENV})
+    requires: ["${DB}"]
+    run:
+      - name: deploy to ${ENV}
+        exec: echo ${ENV}
+        check: ["FILE:/etc/${ENV}/app.conf"]
+        env:
+          - name: TOKEN
+            value: ${TOKEN:-none}
+  - id: postgres
+`), 0644)
+	if err := resolver.LoadResourceEntries("catalog.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	app, _ := resolver.GetResource("app")
+	if app.Name != "App (staging)" || !reflect.DeepEqual(app.Requires, []string{"postgres"}) {
+		t.Errorf("Expected the fields to be expanded, got %+v", app)
+	}
+	step := app.Run[0]
+	if step.Name != "deploy to staging" || !reflect.DeepEqual(step.Check, []interface{}{"FILE:/etc/staging/app.conf"}) || step.Env[0].Value != "none" {
+		t.Errorf("Expected the step to be expanded, got %+v", step)
+	}
+	if step.Exec != "echo ${ENV}" {
+		t.Errorf("Expected the command to be left to the shell, got %q", step.Exec)
+	}
+	if deps := resolver.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"postgres"}) {
+		t.Errorf("Expected the expanded requirement to resolve, got %v", deps)
+	}
+}
+
+func TestInterpolateEntries_Strict(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	resolver.LookupEnv = func(name string) (string, bool) { return "", false }
+
+	afero.WriteFile(resolver.Fs, "catalog.yaml", []byte("id: app\ndesc: ${REGION:-eu} ${ZONE}\n"), 0644)
+	if err := resolver.ReloadResourceEntries("catalog.yaml"); err != nil {
+		t.Fatalf("Expected undefined variables to be allowed by default, got %v", err)
+	}
+
+	resolver.StrictEnv = true
+	var undefined *UndefinedVariableError
+	err := resolver.ReloadResourceEntries("catalog.yaml")
+	if !errors.As(err, &undefined) || undefined.Name != "ZONE" || undefined.Resource != "app" || undefined.Source != "catalog.yaml" {
+		t.Errorf("Expected an undefined variable error for ZONE, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package resolver
+
+import (
+	"iter"
+
+	"github.com/jjuliano/runner/pkg/dag"
+)
+
+// DFS returns an iterator over the closure of root, root first, in
+// depth-first order. Every resource is visited once. The dependencies of a
+// resource are read when the iteration reaches it, without holding the lock
+// of the resolver in between, so breaking out of the loop early skips the
+// rest of the graph.
+func (dr *DependencyResolver) DFS(root string) iter.Seq[string] {
+	return dag.DFS(root, dr.DirectDependencies)
+}
+
+// BFS returns an iterator over the closure of root, root first, in
+// breadth-first order. Like DFS, it reads the graph as it goes.
+func (dr *DependencyResolver) BFS(root string) iter.Seq[string] {
+	return dag.BFS(root, dr.DirectDependencies)
+}
+
+// DependenciesSeq returns an iterator over the resources DependenciesWithin
+// returns, in the same order, read from the graph as it goes like BFS. It
+// lets callers stream the closure of a large resource without holding it in
+// memory.
+func (dr *DependencyResolver) DependenciesSeq(id string, maxDepth int) iter.Seq[string] {
+	return skipRoot(dag.BFSWithin(id, maxDepth, dr.DirectDependencies))
+}
+
+// DependentsSeq returns an iterator over the resources DependentsWithin
+// returns, in the same order, like DependenciesSeq.
+func (dr *DependencyResolver) DependentsSeq(id string, maxDepth int) iter.Seq[string] {
+	return skipRoot(dag.BFSWithin(id, maxDepth, dr.ReverseDependencies))
+}
+
+// skipRoot returns seq without its first element.
+func skipRoot(seq iter.Seq[string]) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		root := true
+		for id := range seq {
+			if !root && !yield(id) {
+				return
+			}
+			root = false
+		}
+	}
+}
+
+// TopoOrder returns an iterator over every resource, each after its
+// dependencies, in the order a run of all of them would execute them. Edges
+// closing a cycle are ignored, and requirements missing from the catalog are
+// listed like resources, as in Resolve.
+func (dr *DependencyResolver) TopoOrder() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		dr.mu.RLock()
+		ids := dr.resourceIDs()
+		dr.mu.RUnlock()
+
+		for id := range dag.TopoOrder(ids, dr.DirectDependencies) {
+			if !yield(id) {
+				return
+			}
+		}
+	}
+}
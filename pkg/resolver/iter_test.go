@@ -0,0 +1,99 @@
+package resolver
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func setupIterResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"api", "web"}},
+		{Id: "api", Requires: []string{"db", "cache"}},
+		{Id: "web", Requires: []string{"cdn"}},
+		{Id: "db", Requires: []string{"disk"}},
+		{Id: "cache"},
+		{Id: "cdn"},
+		{Id: "disk", Requires: []string{"db"}},
+		{Id: "cli"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestDFS(t *testing.T) {
+	resolver := setupIterResolver()
+
+	expected := []string{"app", "api", "db", "disk", "cache", "web", "cdn"}
+	if order := slices.Collect(resolver.DFS("app")); !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+
+	var visited []string
+	for id := range resolver.DFS("app") {
+		visited = append(visited, id)
+		if id == "db" {
+			break
+		}
+	}
+	if !reflect.DeepEqual(visited, []string{"app", "api", "db"}) {
+		t.Errorf("Expected the iteration to stop early, got %v", visited)
+	}
+}
+
+func TestBFS(t *testing.T) {
+	resolver := setupIterResolver()
+
+	expected := []string{"app", "api", "web", "db", "cache", "cdn", "disk"}
+	if order := slices.Collect(resolver.BFS("app")); !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+
+	var visited []string
+	for id := range resolver.BFS("app") {
+		if id == "db" {
+			break
+		}
+		visited = append(visited, id)
+	}
+	if !reflect.DeepEqual(visited, []string{"app", "api", "web"}) {
+		t.Errorf("Expected the iteration to stop early, got %v", visited)
+	}
+}
+
+func TestDependenciesSeq(t *testing.T) {
+	resolver := setupIterResolver()
+
+	for _, id := range []string{"app", "db", "cli", "unknown"} {
+		for _, maxDepth := range []int{0, 1, 2} {
+			if got, want := slices.Collect(resolver.DependenciesSeq(id, maxDepth)), resolver.DependenciesWithin(id, maxDepth); !reflect.DeepEqual(got, want) {
+				t.Errorf("DependenciesSeq(%s, %d): expected %v, got %v", id, maxDepth, want, got)
+			}
+			if got, want := slices.Collect(resolver.DependentsSeq(id, maxDepth)), resolver.DependentsWithin(id, maxDepth); !reflect.DeepEqual(got, want) {
+				t.Errorf("DependentsSeq(%s, %d): expected %v, got %v", id, maxDepth, want, got)
+			}
+		}
+	}
+}
+
+func TestTopoOrder(t *testing.T) {
+	resolver := setupIterResolver()
+
+	order := slices.Collect(resolver.TopoOrder())
+	expected := []string{"disk", "db", "cache", "api", "cdn", "web", "app", "cli"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+
+	var visited []string
+	for id := range resolver.TopoOrder() {
+		visited = append(visited, id)
+		if len(visited) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(visited, []string{"disk", "db"}) {
+		t.Errorf("Expected the iteration to stop early, got %v", visited)
+	}
+}
@@ -0,0 +1,39 @@
+package resolver
+
+// ExecutionLevels partitions the closure of target into levels, where every
+// resource only depends on resources of earlier levels. Resources of the same
+// level are independent of each other and can run in parallel. Within a level,
+// resources are listed in execution order.
+func (dr *DependencyResolver) ExecutionLevels(target string) ([][]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if _, ok := dr.getResource(target); !ok {
+		return nil, &NotFoundError{Id: target}
+	}
+	if err := dr.checkLimits([]string{target}, false, 0); err != nil {
+		return nil, err
+	}
+
+	order := dr.graph().PostOrder(target, make(map[string]bool), 0)
+	levelOf := make(map[string]int, len(order))
+	var levels [][]string
+	for _, node := range order {
+		level := 0
+		for _, dep := range dr.ResourceDependencies[node] {
+			depLevel, ok := levelOf[dep]
+			if !ok {
+				// Dependencies come first in execution order, unless they
+				// lead back to the resource.
+				return nil, &CycleError{Resources: []string{node, dep}}
+			}
+			level = max(level, depLevel+1)
+		}
+		levelOf[node] = level
+		if level == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], node)
+	}
+	return levels, nil
+}
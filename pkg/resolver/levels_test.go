@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExecutionLevels(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"api", "web"}},
+		{Id: "api", Requires: []string{"db", "cache"}},
+		{Id: "web", Requires: []string{"cache"}},
+		{Id: "db"},
+		{Id: "cache"},
+		{Id: "unrelated"},
+	}
+	resolver.BuildDependencies()
+
+	levels, err := resolver.ExecutionLevels("app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{{"db", "cache"}, {"api", "web"}, {"app"}}
+	if !reflect.DeepEqual(levels, expected) {
+		t.Errorf("Expected %v, got %v", expected, levels)
+	}
+
+	if _, err := resolver.ExecutionLevels("missing"); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+}
+
+func TestExecutionLevels_Cycle(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b"}},
+		{Id: "b", Requires: []string{"a"}},
+	}
+	resolver.BuildDependencies()
+
+	if _, err := resolver.ExecutionLevels("a"); err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NoLicense is the license the resources declaring none are grouped under.
+const NoLicense = "(none)"
+
+// LicenseUsage lists the resources under a license.
+type LicenseUsage struct {
+	License   string   `json:"license" yaml:"license"`
+	Resources []string `json:"resources" yaml:"resources"`
+}
+
+// DeniedLicenseError is returned when the closure of a target includes
+// resources under a denied license.
+type DeniedLicenseError struct {
+	Target string
+	Denied []LicenseUsage
+}
+
+func (e *DeniedLicenseError) Error() string {
+	groups := make([]string, 0, len(e.Denied))
+	for _, usage := range e.Denied {
+		groups = append(groups, fmt.Sprintf("%s (%s)", usage.License, strings.Join(usage.Resources, ", ")))
+	}
+	return fmt.Sprintf("resource '%s' depends on denied licenses: %s", e.Target, strings.Join(groups, "; "))
+}
+
+// Licenses returns the licenses of the target and of every resource in its
+// closure, sorted by license, with the resources declaring none last under
+// NoLicense. The resources of a license are in breadth-first order from the
+// target.
+func (dr *DependencyResolver) Licenses(target string) ([]LicenseUsage, error) {
+	keys, groups, err := dr.groupClosure(target, NoLicense, func(entry ResourceNodeEntry) []string {
+		if entry.License == "" {
+			return nil
+		}
+		return []string{entry.License}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]LicenseUsage, 0, len(keys))
+	for _, license := range keys {
+		usages = append(usages, LicenseUsage{License: license, Resources: groups[license]})
+	}
+	return usages, nil
+}
+
+// groupClosure groups the target and the resources of its closure by the
+// keys returned for them, in breadth-first order from the target. It returns
+// the sorted keys, with none, the key of the resources without any, last.
+func (dr *DependencyResolver) groupClosure(target, none string, keys func(ResourceNodeEntry) []string) ([]string, map[string][]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if _, ok := dr.getResource(target); !ok {
+		return nil, nil, &NotFoundError{Id: target}
+	}
+	if err := dr.checkLimits([]string{target}, false, 0); err != nil {
+		return nil, nil, err
+	}
+	closure := dr.cache.memoize(&dr.cache.closures, target, func() []string {
+		return dr.closure(target, 0)
+	})
+
+	groups := make(map[string][]string)
+	for _, id := range append([]string{target}, closure...) {
+		entry, ok := dr.getResource(id)
+		if !ok {
+			continue
+		}
+		entryKeys := keys(entry)
+		if len(entryKeys) == 0 {
+			entryKeys = []string{none}
+		}
+		for _, key := range entryKeys {
+			groups[key] = append(groups[key], id)
+		}
+	}
+
+	sorted := make([]string, 0, len(groups))
+	for key := range groups {
+		sorted = append(sorted, key)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if (sorted[i] == none) != (sorted[j] == none) {
+			return sorted[j] == none
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted, groups, nil
+}
+
+// CheckLicenses returns a DeniedLicenseError when the closure of the target
+// includes a license of the deny-list. Licenses are compared ignoring case,
+// and NoLicense can be denied to require every resource to declare one.
+func CheckLicenses(target string, usages []LicenseUsage, deny []string) error {
+	var denied []LicenseUsage
+	for _, usage := range usages {
+		for _, license := range deny {
+			if strings.EqualFold(usage.License, license) {
+				denied = append(denied, usage)
+				break
+			}
+		}
+	}
+	if len(denied) > 0 {
+		return &DeniedLicenseError{Target: target, Denied: denied}
+	}
+	return nil
+}
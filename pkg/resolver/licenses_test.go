@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupLicensesResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", License: "MIT", Requires: []string{"lib", "db"}},
+		{Id: "lib", License: "Apache-2.0", Requires: []string{"util"}},
+		{Id: "db", License: "GPL-3.0-only"},
+		{Id: "util", License: "MIT"},
+		{Id: "tool"},
+		{Id: "unused", License: "AGPL-3.0-only"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestLicenses(t *testing.T) {
+	resolver := setupLicensesResolver()
+
+	usages, err := resolver.Licenses("app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []LicenseUsage{
+		{License: "Apache-2.0", Resources: []string{"lib"}},
+		{License: "GPL-3.0-only", Resources: []string{"db"}},
+		{License: "MIT", Resources: []string{"app", "util"}},
+	}
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, usages)
+	}
+
+	resolver.UpdateResource(ResourceNodeEntry{Id: "util", Requires: []string{"tool"}})
+	usages, _ = resolver.Licenses("lib")
+	expected = []LicenseUsage{
+		{License: "Apache-2.0", Resources: []string{"lib"}},
+		{License: NoLicense, Resources: []string{"util", "tool"}},
+	}
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("Expected the unlicensed resources last, got %+v", usages)
+	}
+
+	if _, err := resolver.Licenses("missing"); err == nil {
+		t.Error("Expected an error for a missing target")
+	}
+}
+
+func TestCheckLicenses(t *testing.T) {
+	resolver := setupLicensesResolver()
+	usages, _ := resolver.Licenses("app")
+
+	if err := CheckLicenses("app", usages, []string{"AGPL-3.0-only"}); err != nil {
+		t.Errorf("Expected licenses outside the closure to be allowed, got %v", err)
+	}
+
+	err := CheckLicenses("app", usages, []string{"gpl-3.0-only", "MIT"})
+	var deniedErr *DeniedLicenseError
+	if !errors.As(err, &deniedErr) {
+		t.Fatalf("Expected a DeniedLicenseError, got %v", err)
+	}
+	if len(deniedErr.Denied) != 2 || deniedErr.Denied[0].License != "GPL-3.0-only" || deniedErr.Denied[1].License != "MIT" {
+		t.Errorf("Unexpected denied licenses: %+v", deniedErr.Denied)
+	}
+	if !strings.Contains(err.Error(), "GPL-3.0-only (db)") || !strings.Contains(err.Error(), "MIT (app, util)") {
+		t.Errorf("Expected the error to list the denied resources, got %v", err)
+	}
+}
+
+func TestHandleLicensesCommand(t *testing.T) {
+	resolver := setupLicensesResolver()
+	resolver.OutputFormat = FormatTable
+
+	var err error
+	output := captureOutput(func() {
+		err = resolver.HandleLicensesCommand("lib", []string{"MIT"})
+	})
+	if !strings.Contains(output, "Apache-2.0  lib") || !strings.Contains(output, "MIT         util") {
+		t.Errorf("Expected the licenses to be listed, got %q", output)
+	}
+	if err == nil {
+		t.Error("Expected the denied license to fail the command")
+	}
+}
@@ -0,0 +1,156 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// manifestExtensions lists the extensions of the files loaded from
+// directories.
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+func isManifest(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, manifestExt := range manifestExtensions {
+		if ext == manifestExt {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadResourcesFromDir loads every YAML or JSON file under dir, walking its
+// subdirectories but .git. Each file defines either a single resource, or a list of
+// resources under `resources`. Nothing is loaded when any file fails, and the
+// errors name the file at fault.
+func (dr *DependencyResolver) LoadResourcesFromDir(dir string) error {
+	var files []string
+	err := afero.Walk(dr.Fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && isManifest(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error walking directory %s: %w", dir, err)
+	}
+	return dr.loadResourceFiles(files)
+}
+
+// loadResourceFiles adds the resources of the files, and of the manifests they
+// include, to the catalog. The files are read before the catalog is locked,
+// and the catalog is left unchanged when any of them fails to load.
+func (dr *DependencyResolver) loadResourceFiles(files []string) error {
+	var manifests []manifestFile
+	read := make(map[string]bool)
+	for _, file := range files {
+		fileManifests, err := dr.readManifestFiles(file, read)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, fileManifests...)
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	sources := dr.resourceSources()
+	entries, warnings, err := dr.addManifests(sources, manifests)
+	if err != nil {
+		return err
+	}
+
+	if err := dr.replaceResources(append(dr.Resources, entries...), sources, dr.lenient()); err != nil {
+		return err
+	}
+	dr.warn(warnings...)
+	return nil
+}
+
+// LoadResourcesGlob loads the YAML or JSON files matching the pattern, such
+// as `resources/**/*.yaml`. Besides the filepath.Match syntax, a `**` path
+// segment matches any number of directories. It is an error for the pattern
+// to match no file.
+func (dr *DependencyResolver) LoadResourcesGlob(pattern string) error {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+
+	var files []string
+	root := globRoot(pattern)
+	err := afero.Walk(dr.Fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && globMatch(strings.Split(pattern, "/"), strings.Split(filepath.ToSlash(path), "/")) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error walking directory %s: %w", root, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no files match %s", pattern)
+	}
+	return dr.loadResourceFiles(files)
+}
+
+// IsGlob reports whether the path contains glob metacharacters.
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// globRoot returns the directory holding every file the pattern can match:
+// its leading segments without metacharacters.
+func globRoot(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var root []string
+	for _, segment := range segments[:len(segments)-1] {
+		if IsGlob(segment) {
+			break
+		}
+		root = append(root, segment)
+	}
+	if len(root) == 0 {
+		return "."
+	}
+	if root[0] == "" {
+		return "/" + strings.Join(root[1:], "/")
+	}
+	return strings.Join(root, "/")
+}
+
+// globMatch matches the segments of a path against the segments of a
+// pattern, where a `**` segment matches zero or more path segments.
+func globMatch(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if globMatch(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestLoadResourcesFromDir(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "catalog/git.yaml", []byte("id: git\nname: Git\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/tools/tag.json", []byte(`{"id": "tag", "requires": ["git"]}`), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/tools/more.yml", []byte("resources:\n  - id: organize\n    requires: [tag]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/README.md", []byte("# not a manifest"), 0644)
+
+	if err := resolver.LoadResourcesFromDir("catalog"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var ids []string
+	for _, entry := range resolver.ListResources() {
+		ids = append(ids, entry.Id)
+	}
+	if expected := []string{"git", "organize", "tag"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+	if deps := resolver.DirectDependencies("organize"); !reflect.DeepEqual(deps, []string{"tag"}) {
+		t.Errorf("Expected the requirements to be resolved, got %v", deps)
+	}
+}
+
+func TestLoadResourcesFromDir_Errors(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "catalog/a.yaml", []byte("id: git\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/b.yaml", []byte("id: git\n"), 0644)
+
+	var dup *DuplicateResourceError
+	err := resolver.LoadResourcesFromDir("catalog")
+	if !errors.As(err, &dup) || dup.FirstSource != "catalog/a.yaml" || dup.SecondSource != "catalog/b.yaml" {
+		t.Errorf("Expected a duplicate naming both files, got %v", err)
+	}
+	if len(resolver.ListResources()) != 0 {
+		t.Error("Expected nothing to be loaded on error")
+	}
+
+	afero.WriteFile(resolver.Fs, "invalid/c.yaml", []byte("resources:\n  - name: Nameless\n"), 0644)
+	if err := resolver.LoadResourcesFromDir("invalid"); err == nil || !strings.Contains(err.Error(), "invalid/c.yaml") {
+		t.Errorf("Expected the error to name the file, got %v", err)
+	}
+
+	if err := resolver.LoadResourcesFromDir("missing"); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestLoadResourcesGlob(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "resources/git.yaml", []byte("id: git\n"), 0644)
+	afero.WriteFile(resolver.Fs, "resources/team/a/tag.yaml", []byte("id: tag\nrequires: [git]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "resources/team/b/organize.yml", []byte("id: organize\n"), 0644)
+	afero.WriteFile(resolver.Fs, "other/skip.yaml", []byte("id: skip\n"), 0644)
+
+	if err := resolver.LoadResourcesGlob("resources/**/*.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var ids []string
+	for _, entry := range resolver.ListResources() {
+		ids = append(ids, entry.Id)
+	}
+	if expected := []string{"git", "tag"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+
+	if err := resolver.LoadResourcesGlob("resources/*.json"); err == nil || !strings.Contains(err.Error(), "no files match") {
+		t.Errorf("Expected an error when nothing matches, got %v", err)
+	}
+	if err := resolver.LoadResourcesGlob("resources/[.yaml"); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		expected      bool
+	}{
+		{"a/*.yaml", "a/b.yaml", true},
+		{"a/*.yaml", "a/b/c.yaml", false},
+		{"a/**/*.yaml", "a/b.yaml", true},
+		{"a/**/*.yaml", "a/b/c/d.yaml", true},
+		{"a/**", "a/b/c", true},
+		{"**/c.yaml", "a/b/c.yaml", true},
+		{"a/**/c/*.yaml", "a/c/d.yaml", true},
+		{"a/**/c/*.yaml", "a/b/d.yaml", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/")); got != tt.expected {
+			t.Errorf("globMatch(%s, %s) = %v, expected %v", tt.pattern, tt.path, got, tt.expected)
+		}
+	}
+	if root := globRoot("resources/**/*.yaml"); root != "resources" {
+		t.Errorf("Expected the root 'resources', got %s", root)
+	}
+}
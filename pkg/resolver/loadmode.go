@@ -0,0 +1,284 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LoadMode controls which issues in the loaded catalog are errors.
+type LoadMode string
+
+const (
+	// LoadStrict fails on unknown fields, duplicate resources and dangling
+	// requirements.
+	LoadStrict LoadMode = "strict"
+	// LoadLenient records those issues as warnings, available from
+	// Warnings, and keeps loading. The first definition of a duplicate
+	// resource wins.
+	LoadLenient LoadMode = "lenient"
+)
+
+// LoadModes lists the supported load modes.
+var LoadModes = []string{string(LoadStrict), string(LoadLenient)}
+
+// ValidateLoadMode returns an error for an unsupported load mode. An empty
+// mode is LoadStrict.
+func ValidateLoadMode(mode LoadMode) error {
+	switch mode {
+	case "", LoadStrict, LoadLenient:
+		return nil
+	}
+	return fmt.Errorf("unknown load mode '%s', expected one of: %s", mode, strings.Join(LoadModes, ", "))
+}
+
+// FindingCode identifies the kind of a Warning, or of a Finding of Validate,
+// which has the codes of the Warnings and its own ones, such as
+// CodeUnknownType.
+type FindingCode string
+
+// Codes of the Warnings.
+
+const (
+	// CodeUnknownField is an unknown manifest field, in lenient mode.
+	CodeUnknownField FindingCode = "unknown-field"
+	// CodeDuplicateResource is a resource defined twice, in lenient mode.
+	CodeDuplicateResource FindingCode = "duplicate-resource"
+	// CodeInvalidID is a resource ID breaking the IDPolicy, in lenient mode.
+	CodeInvalidID FindingCode = "invalid-id"
+	// CodeSelfDependency is a resource requiring itself, in lenient mode.
+	CodeSelfDependency FindingCode = "self-dependency"
+	// CodeMissingRequirement is a requirement no resource satisfies, in
+	// lenient mode.
+	CodeMissingRequirement FindingCode = "missing-requirement"
+	// CodeMissingOptional is an optional requirement no resource satisfies.
+	CodeMissingOptional FindingCode = "missing-optional"
+	// CodeRedundantRequirement is a requirement already implied by another
+	// requirement of the same resource.
+	CodeRedundantRequirement FindingCode = "redundant-requirement"
+	// CodeDeprecatedResource is a requirement on a deprecated resource.
+	CodeDeprecatedResource FindingCode = "deprecated-resource"
+	// CodeAliasUsed is a requirement naming a resource by a former ID.
+	CodeAliasUsed FindingCode = "alias-used"
+	// CodeDependencyCycle is a group of resources depending on each other.
+	CodeDependencyCycle FindingCode = "dependency-cycle"
+	// CodeCaseConflict is a resource ID only differing in case or Unicode
+	// encoding from the ID of another resource.
+	CodeCaseConflict FindingCode = "case-conflict"
+)
+
+// Warning is a non-fatal issue of the catalog. Resource is the resource it
+// concerns, and File, Line and Column locate it, when known.
+type Warning struct {
+	Code     FindingCode `json:"code" yaml:"code"`
+	Resource string      `json:"resource,omitempty" yaml:"resource,omitempty"`
+	File     string      `json:"file,omitempty" yaml:"file,omitempty"`
+	Line     int         `json:"line,omitempty" yaml:"line,omitempty"`
+	Column   int         `json:"column,omitempty" yaml:"column,omitempty"`
+	Message  string      `json:"message" yaml:"message"`
+}
+
+func (w Warning) String() string {
+	switch {
+	case w.File == "":
+		return w.Message
+	case w.Line == 0:
+		return fmt.Sprintf("%s: %s", w.File, w.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", w.File, w.Line, w.Column, w.Message)
+}
+
+// LoadWarnings returns the issues found while loading the catalog in lenient
+// mode, which strict mode reports as errors.
+func (dr *DependencyResolver) LoadWarnings() []Warning {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return append([]Warning(nil), dr.warnings...)
+}
+
+// Warnings returns every non-fatal issue of the catalog: the LoadWarnings,
+// followed by the missing optional requirements, the redundant requirements
+// the requirements on deprecated resources and the requirements naming a
+// resource by an alias of the current catalog, and the IDs only differing in
+// case or Unicode encoding from an earlier one, followed by the dependency
+// cycles, in the order of Cycles. The checks run concurrently
+// over chunks of the catalog, see Workers.
+func (dr *DependencyResolver) Warnings() []Warning {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	known := dr.knownResources()
+	missingOptional := func(_ int, entry ResourceNodeEntry) []Warning {
+		var warnings []Warning
+		for _, opt := range entry.Optional {
+			if !known[opt] && len(dr.providers(opt)) == 0 && dr.aliasOf(opt) == "" {
+				warnings = append(warnings, Warning{
+					Code:     CodeMissingOptional,
+					Resource: entry.Id,
+					File:     dr.sources[entry.Id],
+					Message:  fmt.Sprintf("optional requirement '%s' of '%s' is not in the catalog", opt, entry.Id),
+				})
+			}
+		}
+		return warnings
+	}
+	redundant := func(i int, entry ResourceNodeEntry) []Warning {
+		var warnings []Warning
+		for _, edge := range dr.redundantEdges(i, entry) {
+			warnings = append(warnings, Warning{
+				Code:     CodeRedundantRequirement,
+				Resource: edge.From,
+				File:     dr.sources[edge.From],
+				Message:  fmt.Sprintf("requirement '%s' of '%s' is already implied via %s", edge.To, edge.From, strings.Join(edge.Via, " -> ")),
+			})
+		}
+		return warnings
+	}
+	deprecated := func(_ int, entry ResourceNodeEntry) []Warning {
+		var warnings []Warning
+		for _, deprecation := range dr.deprecations(dr.ResourceDependencies[entry.Id]) {
+			warnings = append(warnings, Warning{
+				Code:     CodeDeprecatedResource,
+				Resource: entry.Id,
+				File:     dr.sources[entry.Id],
+				Message:  fmt.Sprintf("'%s' requires deprecated resource '%s'%s", entry.Id, deprecation.Resource, deprecation.replacement()),
+			})
+		}
+		return warnings
+	}
+	aliases := func(_ int, entry ResourceNodeEntry) []Warning {
+		return dr.aliasWarnings([]ResourceNodeEntry{entry})
+	}
+
+	conflicts := dr.caseConflicts()
+	caseConflict := func(_ int, entry ResourceNodeEntry) []Warning {
+		id, ok := conflicts[entry.Id]
+		if !ok {
+			return nil
+		}
+		return []Warning{{
+			Code:     CodeCaseConflict,
+			Resource: entry.Id,
+			File:     dr.sources[entry.Id],
+			Message:  fmt.Sprintf("resource '%s' only differs in case or encoding from '%s'", entry.Id, id),
+		}}
+	}
+
+	warnings := append([]Warning(nil), dr.warnings...)
+	warnings = append(warnings, runPasses(dr, missingOptional, redundant, deprecated, aliases, caseConflict)...)
+	for _, component := range dr.graph().Components(dr.resourceIDs()) {
+		if len(component) > 1 || slices.Contains(dr.ResourceDependencies[component[0]], component[0]) {
+			warnings = append(warnings, Warning{
+				Code:     CodeDependencyCycle,
+				Resource: component[0],
+				File:     dr.sources[component[0]],
+				Message:  "dependency cycle between " + strings.Join(component, ", "),
+			})
+		}
+	}
+	return warnings
+}
+
+// lenient reports whether load issues are warnings rather than errors.
+func (dr *DependencyResolver) lenient() bool {
+	return dr.LoadMode == LoadLenient
+}
+
+// warn records the warnings that are not recorded yet. The caller must hold
+// the write lock.
+func (dr *DependencyResolver) warn(warnings ...Warning) {
+	for _, w := range warnings {
+		if !slices.Contains(dr.warnings, w) {
+			dr.warnings = append(dr.warnings, w)
+		}
+	}
+}
+
+// addManifests records the source of the resources of the manifests, and
+// returns the resources to add to the catalog with the warnings to record.
+// IDs are normalized following the IDPolicy first, so a resource already
+// present in sources under another spelling of its ID is a duplicate too. A
+// duplicate resource is a DuplicateResourceError, or a warning leaving it
+// out in lenient mode. An ID breaking the IDPolicy is an InvalidIDError, or
+// a warning keeping the resource in lenient mode, and a resource requiring
+// itself a SelfDependencyError, or a warning dropping the requirement.
+func (dr *DependencyResolver) addManifests(sources map[string]string, manifests []manifestFile) ([]ResourceNodeEntry, []Warning, error) {
+	var entries []ResourceNodeEntry
+	var warnings []Warning
+	for _, manifest := range manifests {
+		warnings = append(warnings, manifest.Warnings...)
+		for _, entry := range manifest.Entries {
+			entry = dr.IDs.entry(entry)
+			if err := dr.IDs.checkEntry(entry, manifest.Path); err != nil {
+				var invalid *InvalidIDError
+				if !dr.lenient() || !errors.As(err, &invalid) {
+					return nil, nil, err
+				}
+				warnings = append(warnings, Warning{Code: CodeInvalidID, Resource: entry.Id, File: manifest.Path, Message: err.Error()})
+			}
+			if err := checkRunner(entry, manifest.Path); err != nil {
+				return nil, nil, err
+			}
+			if requiresItself(entry) {
+				err := dr.selfDependency(manifest, entry)
+				if !dr.lenient() {
+					return nil, nil, err
+				}
+				warnings = append(warnings, Warning{Code: CodeSelfDependency, Resource: entry.Id, File: err.File, Line: err.Line, Column: err.Column, Message: fmt.Sprintf("resource '%s' requires itself, ignoring the requirement", entry.Id)})
+				entry = withoutSelf(entry)
+			}
+			err := addSources(sources, []ResourceNodeEntry{entry}, manifest.Path)
+			if err == nil {
+				entries = append(entries, entry)
+				continue
+			}
+			if !dr.lenient() {
+				return nil, nil, err
+			}
+			warnings = append(warnings, Warning{Code: CodeDuplicateResource, Resource: entry.Id, File: manifest.Path, Message: err.Error() + ", keeping the first definition"})
+		}
+	}
+	return entries, warnings, nil
+}
+
+// CheckRequirements checks that every requirement of the catalog is a loaded
+// resource or provided by one. Since catalogs can be loaded from several
+// sources, it is meant to be called once they are all loaded. Dangling
+// requirements are DanglingRequirementErrors, or warnings in lenient mode.
+func (dr *DependencyResolver) CheckRequirements() error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	return dr.checkRequirements()
+}
+
+// checkRequirements is CheckRequirements for callers holding the write lock.
+func (dr *DependencyResolver) checkRequirements() error {
+	known := dr.knownResources()
+	requiredBy := make(map[string][]string)
+	for _, entry := range dr.Resources {
+		for _, req := range entry.Requires {
+			if !known[req] && len(dr.providers(req)) == 0 && dr.aliasOf(req) == "" {
+				requiredBy[req] = append(requiredBy[req], entry.Id)
+			}
+		}
+	}
+
+	missing := make([]string, 0, len(requiredBy))
+	for id := range requiredBy {
+		missing = append(missing, id)
+	}
+	sort.Strings(missing)
+
+	var errs []error
+	for _, id := range missing {
+		err := &DanglingRequirementError{Id: id, RequiredBy: requiredBy[id]}
+		if dr.lenient() {
+			dr.warn(Warning{Code: CodeMissingRequirement, Resource: requiredBy[id][0], File: dr.sources[requiredBy[id][0]], Message: "missing " + err.Error()})
+		} else {
+			errs = append(errs, fmt.Errorf("missing %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,171 @@
+package resolver
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func setupLoadModeResolver(mode LoadMode) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	resolver.LoadMode = mode
+
+	afero.WriteFile(resolver.Fs, "catalog/a.yaml", []byte("resources:\n  - id: git\n    colour: blue\n  - id: tag\n    requires: [git, missing]\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/b.yaml", []byte("id: git\ndesc: Second definition\n"), 0644)
+	return resolver
+}
+
+func TestLoadMode_Strict(t *testing.T) {
+	resolver := setupLoadModeResolver(LoadStrict)
+
+	var schemaErrs SchemaErrors
+	if err := resolver.LoadSource("catalog/a.yaml"); !errors.As(err, &schemaErrs) {
+		t.Errorf("Expected the unknown field to fail, got %v", err)
+	}
+
+	afero.WriteFile(resolver.Fs, "catalog/a.yaml", []byte("resources:\n  - id: git\n  - id: tag\n    requires: [git, missing]\n"), 0644)
+	var dup *DuplicateResourceError
+	if err := resolver.LoadSource("catalog"); !errors.As(err, &dup) {
+		t.Errorf("Expected the duplicate to fail, got %v", err)
+	}
+
+	if err := resolver.LoadSource("catalog/a.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var dangling *DanglingRequirementError
+	err := resolver.CheckRequirements()
+	if !errors.As(err, &dangling) || dangling.Id != "missing" || !reflect.DeepEqual(dangling.RequiredBy, []string{"tag"}) {
+		t.Errorf("Expected the missing requirement to fail, got %v", err)
+	}
+	if warnings := resolver.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings in strict mode, got %v", warnings)
+	}
+}
+
+func TestLoadMode_Lenient(t *testing.T) {
+	resolver := setupLoadModeResolver(LoadLenient)
+
+	if err := resolver.LoadSource("catalog"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.CheckRequirements(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry, _ := resolver.GetResource("git"); entry.Desc != "" {
+		t.Errorf("Expected the first definition to win, got %+v", entry)
+	}
+
+	expected := []Warning{
+		{Code: CodeUnknownField, File: "catalog/a.yaml", Line: 3, Column: 5, Message: "unknown field resources[0].colour"},
+		{Code: CodeDuplicateResource, Resource: "git", File: "catalog/b.yaml", Message: "resource 'git' is defined in both catalog/a.yaml and catalog/b.yaml, keeping the first definition"},
+		{Code: CodeMissingRequirement, Resource: "tag", File: "catalog/a.yaml", Message: "missing resource 'missing' is required by tag"},
+	}
+	if warnings := resolver.Warnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected %v, got %v", expected, warnings)
+	}
+
+	// Checking again does not repeat the warnings.
+	resolver.CheckRequirements()
+	if warnings := resolver.Warnings(); len(warnings) != len(expected) {
+		t.Errorf("Expected %d warnings, got %v", len(expected), warnings)
+	}
+
+	// Other schema violations still fail.
+	afero.WriteFile(resolver.Fs, "invalid.yaml", []byte("id: broken\nrequires: git\n"), 0644)
+	if err := resolver.LoadSource("invalid.yaml"); err == nil {
+		t.Error("Expected the invalid field to fail")
+	}
+}
+
+func TestValidateLoadMode(t *testing.T) {
+	for _, mode := range []LoadMode{"", LoadStrict, LoadLenient} {
+		if err := ValidateLoadMode(mode); err != nil {
+			t.Errorf("Unexpected error for %q: %v", mode, err)
+		}
+	}
+	if err := ValidateLoadMode("loose"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestWarningString(t *testing.T) {
+	tests := map[string]Warning{
+		"message":             {Message: "message"},
+		"a.yaml: message":     {File: "a.yaml", Message: "message"},
+		"a.yaml:3:5: message": {File: "a.yaml", Line: 3, Column: 5, Message: "message"},
+	}
+	for expected, warning := range tests {
+		if warning.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, warning.String())
+		}
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "catalog.yaml", []byte(`resources:
+  - id: git
+  - id: tag
+    requires: [git]
+  - id: app
+    requires: [git, tag]
+    optional: [docs]
+`), 0644)
+	if err := resolver.LoadSource("catalog.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Warning{
+		{Code: CodeMissingOptional, Resource: "app", File: "catalog.yaml", Message: "optional requirement 'docs' of 'app' is not in the catalog"},
+		{Code: CodeRedundantRequirement, Resource: "app", File: "catalog.yaml", Message: "requirement 'git' of 'app' is already implied via app -> tag -> git"},
+	}
+	if warnings := resolver.Warnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected %v, got %v", expected, warnings)
+	}
+	if warnings := resolver.LoadWarnings(); len(warnings) != 0 {
+		t.Errorf("Expected no load warnings, got %v", warnings)
+	}
+}
+
+func TestHandleValidateCommand(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{{Id: "app", Optional: []string{"docs"}}}
+	resolver.BuildDependencies()
+
+	capture := func(showWarnings bool) string {
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+		err := resolver.HandleValidateCommand(showWarnings, false)
+		w.Close()
+		os.Stdout = old
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var output strings.Builder
+		io.Copy(&output, r)
+		return output.String()
+	}
+
+	if output := capture(false); output != "✅ 1 resources are valid\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if output := capture(true); output != "⚠️  optional requirement 'docs' of 'app' is not in the catalog [missing-optional]\n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+
+	resolver.OutputFormat = FormatJSON
+	if output := capture(true); !strings.Contains(output, `"code": "missing-optional"`) || !strings.Contains(output, `"resource": "app"`) {
+		t.Errorf("Expected the warnings as JSON, got %s", output)
+	}
+}
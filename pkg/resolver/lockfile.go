@@ -0,0 +1,140 @@
+package resolver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// LockfileVersion is the format version written to lockfiles.
+const LockfileVersion = 1
+
+// Lockfile pins the fully expanded closure of a target.
+type Lockfile struct {
+	LockfileVersion int              `yaml:"lockfileVersion"`
+	Target          string           `yaml:"target"`
+	Resources       []LockedResource `yaml:"resources"`
+}
+
+// LockedResource is a resource of a lockfile, with its resolved requirements
+// and a digest of its definition.
+type LockedResource struct {
+	Id       string            `yaml:"id"`
+	Version  string            `yaml:"version,omitempty"`
+	Digest   string            `yaml:"digest"`
+	Requires []string          `yaml:"requires"`
+	Resource ResourceNodeEntry `yaml:"resource"`
+}
+
+// WriteLockfile writes a lockfile listing every resource in the closure of
+// the target in execution order. The output only depends on the catalog, so
+// the same catalog always produces the same lockfile.
+func (dr *DependencyResolver) WriteLockfile(target string, w io.Writer) error {
+	stack, err := dr.Resolve(context.Background(), []string{target})
+	if err != nil {
+		return err
+	}
+
+	dr.mu.RLock()
+	lockfile := Lockfile{LockfileVersion: LockfileVersion, Target: stack[len(stack)-1]}
+	for _, id := range stack {
+		entry, ok := dr.getResource(id)
+		if !ok {
+			dr.mu.RUnlock()
+			return fmt.Errorf("resource '%s' required by '%s' is not in the catalog", id, target)
+		}
+		digest, err := resourceDigest(entry)
+		if err != nil {
+			dr.mu.RUnlock()
+			return err
+		}
+		lockfile.Resources = append(lockfile.Resources, LockedResource{
+			Id:       id,
+			Version:  entry.Version,
+			Digest:   digest,
+			Requires: append([]string{}, dr.ResourceDependencies[id]...),
+			Resource: entry,
+		})
+	}
+	dr.mu.RUnlock()
+
+	data, err := yaml.Marshal(lockfile)
+	if err != nil {
+		return fmt.Errorf("error marshalling lockfile: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadLockfile replaces the catalog with the resources pinned in a lockfile.
+// Every digest is verified and every requirement must be part of the
+// lockfile, so resolution happens strictly from its contents.
+func (dr *DependencyResolver) LoadLockfile(filePath string) error {
+	data, err := afero.ReadFile(dr.Fs, filePath)
+	if err != nil {
+		return fmt.Errorf("error reading lockfile %s: %w", filePath, err)
+	}
+
+	var lockfile Lockfile
+	if err := yaml.Unmarshal(data, &lockfile); err != nil {
+		return fmt.Errorf("error unmarshalling lockfile %s: %w", filePath, err)
+	}
+	if lockfile.LockfileVersion != LockfileVersion {
+		return fmt.Errorf("unsupported lockfile version %d in %s", lockfile.LockfileVersion, filePath)
+	}
+
+	pinned := make(map[string]bool, len(lockfile.Resources))
+	for _, locked := range lockfile.Resources {
+		pinned[locked.Id] = true
+	}
+
+	resources := make([]ResourceNodeEntry, 0, len(lockfile.Resources))
+	locked := make(map[string][]string, len(lockfile.Resources))
+	sources := make(map[string]string, len(lockfile.Resources))
+	for _, res := range lockfile.Resources {
+		if err := addSources(sources, []ResourceNodeEntry{res.Resource}, filePath); err != nil {
+			return err
+		}
+		if res.Resource.Id != res.Id {
+			return fmt.Errorf("locked resource '%s' defines resource '%s'", res.Id, res.Resource.Id)
+		}
+		digest, err := resourceDigest(res.Resource)
+		if err != nil {
+			return err
+		}
+		if digest != res.Digest {
+			return fmt.Errorf("digest mismatch for locked resource '%s': expected %s, got %s", res.Id, res.Digest, digest)
+		}
+		for _, req := range res.Requires {
+			if !pinned[req] {
+				return fmt.Errorf("locked resource '%s' requires '%s', which is not in the lockfile", res.Id, req)
+			}
+		}
+		resources = append(resources, res.Resource)
+		locked[res.Id] = append([]string{}, res.Requires...)
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	dr.Resources = resources
+	dr.locked = locked
+	dr.sources = sources
+	dr.buildDependencies()
+	return nil
+}
+
+// resourceDigest returns the SHA-256 digest of the serialized entry.
+func resourceDigest(entry ResourceNodeEntry) (string, error) {
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling resource '%s': %w", entry.Id, err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
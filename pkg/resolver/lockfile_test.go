@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func setupLockResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Name: "A", Version: "1.0.0"},
+		{Id: "b", Name: "B", Requires: []string{"a"}},
+		{Id: "c", Name: "C", Requires: []string{"b", "a"}},
+		{Id: "d", Name: "D"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestWriteLockfile(t *testing.T) {
+	resolver := setupLockResolver()
+
+	var first, second bytes.Buffer
+	if err := resolver.WriteLockfile("c", &first); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.WriteLockfile("c", &second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("Expected identical lockfiles, got:\n%s\n%s", first.String(), second.String())
+	}
+	for _, expected := range []string{"lockfileVersion: 1", "target: c", "- id: a\n  version: 1.0.0\n  digest: sha256:"} {
+		if !strings.Contains(first.String(), expected) {
+			t.Errorf("Expected lockfile to contain %q, got:\n%s", expected, first.String())
+		}
+	}
+	if strings.Contains(first.String(), "id: d") {
+		t.Errorf("Expected lockfile to only contain the closure of c, got:\n%s", first.String())
+	}
+
+	if err := resolver.WriteLockfile("unknown", &first); err == nil {
+		t.Errorf("Expected an error for a resource missing from the catalog")
+	}
+}
+
+func TestLoadLockfile(t *testing.T) {
+	resolver := setupLockResolver()
+
+	var lockfile bytes.Buffer
+	if err := resolver.WriteLockfile("c", &lockfile); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	afero.WriteFile(resolver.Fs, "runner.lock", lockfile.Bytes(), 0644)
+
+	locked := setupTestResolver()
+	locked.Fs = resolver.Fs
+	if err := locked.LoadLockfile("runner.lock"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stack, err := locked.Resolve(context.Background(), []string{"c"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(stack, expected) {
+		t.Errorf("Expected %v, got %v", expected, stack)
+	}
+	if _, ok := locked.GetResource("d"); ok {
+		t.Errorf("Expected resources outside of the lockfile not to be loaded")
+	}
+}
+
+func TestLoadLockfile_Errors(t *testing.T) {
+	resolver := setupLockResolver()
+
+	var buf bytes.Buffer
+	if err := resolver.WriteLockfile("c", &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lockfile := buf.String()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"modified resource", strings.Replace(lockfile, "name: B", "name: Modified", 1), "digest mismatch for locked resource 'b'"},
+		{"missing requirement", strings.Replace(lockfile, "  - a\n", "  - x\n", 1), "requires 'x', which is not in the lockfile"},
+		{"unsupported version", strings.Replace(lockfile, "lockfileVersion: 1", "lockfileVersion: 2", 1), "unsupported lockfile version 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			afero.WriteFile(resolver.Fs, "runner.lock", []byte(tt.content), 0644)
+			locked := setupTestResolver()
+			locked.Fs = resolver.Fs
+			err := locked.LoadLockfile("runner.lock")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
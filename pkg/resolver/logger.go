@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"log/slog"
+
+	"github.com/charmbracelet/log"
+)
+
+// Logger is what the resolver logs to. keyvals are alternating keys and
+// values, as in log/slog, so any structured logger can be plugged in: wrap a
+// charmbracelet logger with NewCharmLogger, or a log/slog one with
+// NewSlogLogger.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// charmLogger adapts a charmbracelet logger to Logger.
+type charmLogger struct {
+	l *log.Logger
+}
+
+// NewCharmLogger returns a Logger writing to a charmbracelet logger, or to
+// the default one when l is nil.
+func NewCharmLogger(l *log.Logger) Logger {
+	if l == nil {
+		l = logger
+	}
+	return &charmLogger{l: l}
+}
+
+func (c *charmLogger) Debug(msg string, keyvals ...interface{}) { c.l.Debug(msg, keyvals...) }
+func (c *charmLogger) Info(msg string, keyvals ...interface{})  { c.l.Info(msg, keyvals...) }
+func (c *charmLogger) Warn(msg string, keyvals ...interface{})  { c.l.Warn(msg, keyvals...) }
+func (c *charmLogger) Error(msg string, keyvals ...interface{}) { c.l.Error(msg, keyvals...) }
+
+// NewSlogLogger returns a Logger writing to a log/slog logger, or to
+// slog.Default() when l is nil.
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
+
+// charmLoggerOf returns the charmbracelet logger behind l, for the libraries
+// requiring one, or the default logger when l doesn't wrap one.
+func charmLoggerOf(l Logger) *log.Logger {
+	if c, ok := l.(*charmLogger); ok {
+		return c.l
+	}
+	return logger
+}
@@ -0,0 +1,61 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestNewCharmLogger(t *testing.T) {
+	var buf bytes.Buffer
+	charm := log.New(&buf)
+	l := NewCharmLogger(charm)
+
+	l.Warn("retrying", "resource", "db", "attempt", 2)
+	if output := buf.String(); !strings.Contains(output, "retrying") || !strings.Contains(output, "resource=db") || !strings.Contains(output, "attempt=2") {
+		t.Errorf("Expected the message and its fields, got %q", output)
+	}
+	if charmLoggerOf(l) != charm {
+		t.Errorf("Expected the wrapped logger to be handed to the graph")
+	}
+	if charmLoggerOf(NewCharmLogger(nil)) != logger {
+		t.Errorf("Expected the default logger for a nil logger")
+	}
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	l.Error("failed", "resource", "db")
+	if output := buf.String(); !strings.Contains(output, "level=ERROR") || !strings.Contains(output, "msg=failed resource=db") {
+		t.Errorf("Expected the message and its fields, got %q", output)
+	}
+	if NewSlogLogger(nil) != Logger(slog.Default()) {
+		t.Errorf("Expected the default slog logger for a nil logger")
+	}
+	if charmLoggerOf(l) != logger {
+		t.Errorf("Expected the default logger to be handed to the graph")
+	}
+}
+
+func TestResolve_SlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	dr, err := NewGraphResolver(setupTestResolver().Fs, NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))), "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	dr.Resources = []ResourceNodeEntry{{Id: "app", Requires: []string{"old"}}, {Id: "old", Deprecated: true}}
+	dr.BuildDependencies()
+
+	if _, err := dr.Resolve(context.Background(), []string{"app"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "level=WARN") || !strings.Contains(output, `msg="Deprecated resource" resource=old requiredBy=[app]`) {
+		t.Errorf("Expected the deprecation to be logged through slog, got %q", output)
+	}
+}
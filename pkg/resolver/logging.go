@@ -1,8 +1,12 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/log"
 )
@@ -12,34 +16,43 @@ var (
 	verbose = os.Getenv("VERBOSE")
 )
 
+// LogLevels are the levels accepted by SetLogLevel, from the most verbose.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
 func shouldLog() bool {
-	return verbose != ""
+	return verbose != "" || logger.GetLevel() <= log.DebugLevel
 }
 
-func LogErrorExit(message string, err error) {
-	if shouldLog() {
-		msg := fmt.Sprintf("❌ %s: %s", message, err)
-		logger.Errorf(msg)
+// SetLogLevel sets the level of the default logger, which drops the messages
+// below it. The debug level also logs the messages otherwise only logged when
+// VERBOSE is set.
+func SetLogLevel(level string) error {
+	if !slices.Contains(LogLevels, level) {
+		return fmt.Errorf("unknown log level '%s', expected one of: %s", level, strings.Join(LogLevels, ", "))
 	}
-	os.Exit(1)
+	parsed, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(parsed)
+	return nil
 }
 
-func LogError(message string, err error) error {
+func LogError(message string, err error, keyvals ...interface{}) error {
 	if shouldLog() {
-		msg := fmt.Sprintf("❌ %s: %s", message, err)
-		logger.Errorf(msg)
-		return fmt.Errorf(msg)
+		logger.Error("❌ "+message, errorFields(err, keyvals)...)
+		return fmt.Errorf("❌ %s%s: %w", message, formatFields(keyvals), err)
 	}
 	return err
 }
 
-func LogInfo(message string) {
-	logger.Info(message)
+func LogInfo(message string, keyvals ...interface{}) {
+	logger.Info(message, keyvals...)
 }
 
-func LogDebug(message string) {
+func LogDebug(message string, keyvals ...interface{}) {
 	if shouldLog() {
-		logger.Debug(message)
+		logger.Debug(message, keyvals...)
 	}
 }
 
@@ -52,15 +65,155 @@ func Println(a ...interface{}) {
 }
 
 func PrintError(message string, err error) {
-	fmt.Printf("%s: %v\n", message, err)
+	fmt.Printf("%s: %v\n", styles.err.Render(message), err)
 }
 
-func LogWarn(message string) {
+func LogWarn(message string, keyvals ...interface{}) {
 	if shouldLog() {
-		logger.Warn(message)
+		logger.Warn(message, keyvals...)
 	}
 }
 
 func GetLogger() *log.Logger {
 	return logger
 }
+
+// logger returns the logger of the resolver, or the default one when unset.
+func (dr *DependencyResolver) logger() Logger {
+	if dr.Logger != nil {
+		return dr.Logger
+	}
+	return NewCharmLogger(logger)
+}
+
+// LoggerOrDefault returns the logger of the resolver, or the default one
+// when unset, for the packages serving the resolver.
+func (dr *DependencyResolver) LoggerOrDefault() Logger {
+	return dr.logger()
+}
+
+// stdout returns where the per-resource output of traversals and runs goes:
+// os.Stdout, or nowhere when Quiet is set.
+func (dr *DependencyResolver) stdout() io.Writer {
+	if dr.Quiet {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// println prints a line of per-resource output, unless Quiet is set.
+func (dr *DependencyResolver) println(a ...interface{}) {
+	fmt.Fprintln(dr.stdout(), a...)
+}
+
+// stepOutput returns where the output of the steps of a run goes, or nil for
+// os.Stdout.
+func (dr *DependencyResolver) stepOutput() io.Writer {
+	if dr.StepOutput == nil && dr.Quiet {
+		return io.Discard
+	}
+	return dr.StepOutput
+}
+
+// logError logs a failure like LogError, but to the logger of the resolver.
+func (dr *DependencyResolver) logError(message string, err error, keyvals ...interface{}) error {
+	if shouldLog() {
+		dr.logger().Error("❌ "+message, errorFields(err, keyvals)...)
+		return fmt.Errorf("❌ %s%s: %w", message, formatFields(keyvals), err)
+	}
+	return err
+}
+
+// logDebug logs a message like LogDebug, but to the logger of the resolver.
+func (dr *DependencyResolver) logDebug(message string, keyvals ...interface{}) {
+	if shouldLog() {
+		dr.logger().Debug(message, keyvals...)
+	}
+}
+
+// logWarn logs a message like LogWarn, but to the logger of the resolver.
+func (dr *DependencyResolver) logWarn(message string, keyvals ...interface{}) {
+	if shouldLog() {
+		dr.logger().Warn(message, keyvals...)
+	}
+}
+
+// errorFields appends err to the fields of a log line, when set.
+func errorFields(err error, keyvals []interface{}) []interface{} {
+	if err == nil {
+		return keyvals
+	}
+	return append(keyvals[:len(keyvals):len(keyvals)], "err", err)
+}
+
+// formatFields formats the fields of a log line as " (key=value ...)", for
+// the errors returned along with it.
+func formatFields(keyvals []interface{}) string {
+	if len(keyvals) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", keyvals[i], keyvals[i+1]))
+	}
+	return " (" + strings.Join(pairs, " ") + ")"
+}
+
+// The phases of a resource run, logged with the lines of its steps.
+const (
+	phasePreRun   = "preRun"
+	phaseRun      = "run"
+	phasePostRun  = "postRun"
+	phaseRollback = "rollback"
+)
+
+type phaseKey struct{}
+
+// withPhase returns a context logging the steps run with it under phase.
+func withPhase(ctx context.Context, phase string) context.Context {
+	return context.WithValue(ctx, phaseKey{}, phase)
+}
+
+// phaseOf returns the phase of the steps run with ctx, if any.
+func phaseOf(ctx context.Context) string {
+	phase, _ := ctx.Value(phaseKey{}).(string)
+	return phase
+}
+
+type entryKey struct{}
+
+// withEntry returns a context logging the steps run with it as steps of the
+// entry, as loaded by the executor.
+func withEntry(ctx context.Context, entry ResourceNodeEntry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// resourceFields returns the fields identifying a resource in log lines, like
+// entryFields, for the entry the steps run with ctx belong to, or else the
+// resource as loaded in the catalog. The Store is never queried, as the
+// fields are built for every log line.
+func (dr *DependencyResolver) resourceFields(ctx context.Context, id, phase string, keyvals ...interface{}) []interface{} {
+	entry, ok := ctx.Value(entryKey{}).(ResourceNodeEntry)
+	if !ok || entry.Id != id {
+		dr.mu.RLock()
+		entry, ok = dr.getResource(id)
+		dr.mu.RUnlock()
+		if !ok {
+			entry = ResourceNodeEntry{Id: id}
+		}
+	}
+	return entryFields(entry, phase, keyvals...)
+}
+
+// entryFields returns the fields identifying a resource in log lines: its ID,
+// its category and the phase of its run, when known, followed by keyvals.
+func entryFields(entry ResourceNodeEntry, phase string, keyvals ...interface{}) []interface{} {
+	fields := []interface{}{"resource", entry.Id}
+	if entry.Category != "" {
+		fields = append(fields, "category", entry.Category)
+	}
+	if phase != "" {
+		fields = append(fields, "phase", phase)
+	}
+	return append(fields, keyvals...)
+}
@@ -0,0 +1,184 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestEntryFields(t *testing.T) {
+	fields := entryFields(ResourceNodeEntry{Id: "db", Category: "storage"}, phaseRun, "step", "start")
+	expected := []interface{}{"resource", "db", "category", "storage", "phase", "run", "step", "start"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, fields)
+	}
+
+	fields = entryFields(ResourceNodeEntry{Id: "db"}, "")
+	if !reflect.DeepEqual(fields, []interface{}{"resource", "db"}) {
+		t.Errorf("Expected only the resource, got %v", fields)
+	}
+}
+
+func TestPhaseOf(t *testing.T) {
+	if phase := phaseOf(context.Background()); phase != "" {
+		t.Errorf("Expected no phase, got %q", phase)
+	}
+	if phase := phaseOf(withPhase(context.Background(), phaseRollback)); phase != phaseRollback {
+		t.Errorf("Expected %q, got %q", phaseRollback, phase)
+	}
+}
+
+func TestLogError_Fields(t *testing.T) {
+	defer func(previous string) { verbose = previous }(verbose)
+	verbose = ""
+	cause := errors.New("exit status 1")
+	if err := LogError("Step failed", cause, "resource", "db"); err != cause {
+		t.Errorf("Expected the error to be returned as is, got %v", err)
+	}
+
+	verbose = "1"
+	resolver := setupTestResolver()
+	var buf bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&buf))
+	err := resolver.logError("Step failed", cause, "resource", "db", "step", "start")
+	if !errors.Is(err, cause) || err.Error() != "❌ Step failed (resource=db step=start): exit status 1" {
+		t.Errorf("Expected the fields in the error, got %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, `Step failed resource=db step=start err="exit status 1"`) {
+		t.Errorf("Expected the fields to be logged, got %q", output)
+	}
+}
+
+func TestSourceEnvFile_Logger(t *testing.T) {
+	defer func(previous string) { verbose = previous }(verbose)
+	verbose = "1"
+	resolver := setupTestResolver()
+	var buf bytes.Buffer
+	logger := log.New(&buf)
+	logger.SetLevel(log.DebugLevel)
+	resolver.Logger = NewCharmLogger(logger)
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(envFile, []byte("RUNNER_TEST_SOURCED=yes\n"), 0644)
+	defer os.Unsetenv("RUNNER_TEST_SOURCED")
+	if err := resolver.SourceEnvFile(envFile); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "Sourced environment file") {
+		t.Errorf("Expected the logger of the resolver to be used, got %q", output)
+	}
+}
+
+func TestRun_LogsResourceFields(t *testing.T) {
+	resolver := setupTestResolver()
+	var buf bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&buf))
+	resolver.Resources = []ResourceNodeEntry{{
+		Id:       "db",
+		Category: "storage",
+		PreRun:   []RunStep{{Name: "prepare", Exec: "true"}},
+		Run:      []RunStep{{Name: "start", Exec: "true"}},
+	}}
+	resolver.BuildDependencies()
+
+	if err := resolver.Run(context.Background(), []string{"db"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{
+		"Executing command resource=db category=storage phase=preRun step=prepare",
+		"Executing command resource=db category=storage phase=run step=start",
+		"Resource finished resource=db category=storage duration=",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q to be logged, got %q", expected, output)
+		}
+	}
+}
+
+// countingStore is a MemoryStore counting the resources read with Get.
+type countingStore struct {
+	*MemoryStore
+	gets int
+}
+
+func (s *countingStore) Get(id string) (ResourceNodeEntry, bool, error) {
+	s.gets++
+	return s.MemoryStore.Get(id)
+}
+
+func TestRun_LogsWithoutStoreQueries(t *testing.T) {
+	gets := func(steps int) int {
+		resolver := setupTestResolver()
+		resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+		resolver.Resources = nil
+		entry := ResourceNodeEntry{Id: "db", Category: "storage"}
+		for i := 0; i < steps; i++ {
+			entry.Run = append(entry.Run, RunStep{Name: "start", Exec: "true"})
+		}
+		store := &countingStore{MemoryStore: NewMemoryStore(entry)}
+		resolver.UseStore(store)
+		if err := resolver.LoadStore(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := resolver.Run(context.Background(), []string{"db"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return store.gets
+	}
+	if one, many := gets(1), gets(5); one != many {
+		t.Errorf("Expected the store reads not to grow with the logged steps, got %d for 1 step and %d for 5", one, many)
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer logger.SetLevel(logger.GetLevel())
+	defer func(previous string) { verbose = previous }(verbose)
+	verbose = ""
+
+	if err := SetLogLevel("warn"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if logger.GetLevel() != log.WarnLevel || shouldLog() {
+		t.Errorf("Expected the warn level without verbose logging, got %v", logger.GetLevel())
+	}
+	if err := SetLogLevel("debug"); err != nil || !shouldLog() {
+		t.Errorf("Expected the debug level to log verbosely, got %v", err)
+	}
+	if err := SetLogLevel("fatal"); err == nil || !strings.Contains(err.Error(), "unknown log level 'fatal'") {
+		t.Errorf("Expected an error for an unsupported level, got %v", err)
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"db"}, Run: []RunStep{{Name: "start", Exec: "echo started"}}},
+		{Id: "db"},
+	}
+	resolver.BuildDependencies()
+	resolver.Quiet = true
+
+	output := captureOutput(func() {
+		resolver.ListDirectDependencies("app", 0)
+		resolver.ListDependencyTree("app", 0)
+		resolver.ListDependencyTreeTopDown("app", 0)
+		if _, err := resolver.Execute(context.Background(), []string{"app"}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+	if output != "" {
+		t.Errorf("Expected no output, got %q", output)
+	}
+	if tree, _ := resolver.DependencyTree("app", 0); !reflect.DeepEqual(tree, [][]string{{"app", "db"}}) {
+		t.Errorf("Expected the tree to be returned, got %v", tree)
+	}
+}
@@ -0,0 +1,472 @@
+package resolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// manifestEdit is a manifest being edited. YAML manifests are edited line by
+// line, so that the comments and formatting of the lines left untouched are
+// kept as they are. JSON manifests, which have no comments, are edited as a
+// tree and written back indented with two spaces.
+type manifestEdit struct {
+	path  string
+	json  bool
+	lines []string
+	doc   *yamlv3.Node
+	// ids normalizes the IDs of the manifest before comparing them.
+	ids IDPolicy
+}
+
+// parseManifestEdit parses the manifest at path for editing. Empty data
+// starts a new manifest.
+func parseManifestEdit(path string, data []byte, ids IDPolicy) (*manifestEdit, error) {
+	m := &manifestEdit{path: path, json: strings.EqualFold(filepath.Ext(path), ".json"), ids: ids}
+	if len(bytes.TrimSpace(data)) == 0 {
+		if m.json {
+			data = []byte("{}")
+		} else {
+			data = nil
+		}
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yamlv3.MappingNode {
+		return nil, fmt.Errorf("%s: expected a mapping of resources", path)
+	}
+	m.doc = &doc
+	if !m.json && len(data) > 0 {
+		m.lines = strings.SplitAfter(string(data), "\n")
+		if last := m.lines[len(m.lines)-1]; last == "" {
+			m.lines = m.lines[:len(m.lines)-1]
+		} else {
+			m.lines[len(m.lines)-1] = last + "\n"
+		}
+	}
+	return m, nil
+}
+
+// Bytes returns the content of the edited manifest.
+func (m *manifestEdit) Bytes() []byte {
+	if m.json {
+		var b strings.Builder
+		writeJSONNode(&b, m.doc.Content[0], "")
+		b.WriteString("\n")
+		return []byte(b.String())
+	}
+	return []byte(strings.Join(m.lines, ""))
+}
+
+// reparse parses the lines of a YAML manifest again after a successful edit,
+// so that the next edit locates the nodes at their new lines.
+func (m *manifestEdit) reparse(err *error) {
+	if *err != nil || m.json {
+		return
+	}
+	var doc yamlv3.Node
+	if *err = yamlv3.Unmarshal(m.Bytes(), &doc); *err != nil {
+		*err = fmt.Errorf("error parsing the edited %s: %w", m.path, *err)
+		return
+	}
+	m.doc = &doc
+}
+
+func (m *manifestEdit) root() *yamlv3.Node {
+	return m.doc.Content[0]
+}
+
+// single reports whether the manifest defines a single resource at the top
+// level.
+func (m *manifestEdit) single() bool {
+	return mappingValue(m.root(), "id") != nil
+}
+
+// resource returns the mapping of the resource id, and its index in the
+// resources of the manifest, -1 for a single resource.
+func (m *manifestEdit) resource(id string) (*yamlv3.Node, int, error) {
+	root := m.root()
+	if m.single() {
+		if m.ids.ID(mappingValue(root, "id").Value) == id {
+			return root, -1, nil
+		}
+	} else if list := mappingValue(root, "resources"); list != nil {
+		for i, resource := range list.Content {
+			if idNode := mappingValue(resource, "id"); idNode != nil && m.ids.ID(idNode.Value) == id {
+				return resource, i, nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("resource '%s' not found in %s", id, m.path)
+}
+
+// AppendResource appends the resource to the resources of the manifest.
+func (m *manifestEdit) AppendResource(resource *yamlv3.Node) (err error) {
+	defer m.reparse(&err)
+	if m.single() {
+		return fmt.Errorf("%s defines a single resource, add the resource to another manifest", m.path)
+	}
+	root := m.root()
+	key, list := mappingPair(root, "resources")
+	if m.json {
+		if list == nil || list.Kind != yamlv3.SequenceNode {
+			list = &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+			setMappingValue(root, "resources", list)
+		}
+		list.Content = append(list.Content, resource)
+		return nil
+	}
+
+	text, err := encodeYAMLNode(resource)
+	if err != nil {
+		return err
+	}
+	switch {
+	case list == nil:
+		m.insert(len(m.lines), "resources:\n")
+		m.insert(len(m.lines), sequenceItem(text, 2)...)
+	case list.Kind == yamlv3.SequenceNode && list.Style&yamlv3.FlowStyle == 0 && len(list.Content) > 0:
+		m.appendItem(list, text)
+	case isNull(list) || list.Kind == yamlv3.SequenceNode && len(list.Content) == 0:
+		if err := m.setInline(key, list, ""); err != nil {
+			return err
+		}
+		m.insert(key.Line, sequenceItem(text, key.Column+1)...)
+	default:
+		return fmt.Errorf("%s: cannot append to the resources at line %d, expected a block sequence", m.path, list.Line)
+	}
+	return nil
+}
+
+// RemoveResource removes the resource id from the resources of the manifest.
+func (m *manifestEdit) RemoveResource(id string) (err error) {
+	defer m.reparse(&err)
+	_, index, err := m.resource(id)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		return fmt.Errorf("%s defines a single resource, remove the file instead", m.path)
+	}
+	key, list := mappingPair(m.root(), "resources")
+	if m.json {
+		list.Content = slices.Delete(list.Content, index, index+1)
+		return nil
+	}
+	if list.Style&yamlv3.FlowStyle != 0 {
+		return fmt.Errorf("%s: cannot remove from the resources at line %d, expected a block sequence", m.path, list.Line)
+	}
+	m.removeItem(list, index)
+	if len(list.Content) == 1 {
+		return m.setFlow(key, list, nil)
+	}
+	return nil
+}
+
+// AddRequirement appends the requirement to the requirements of the
+// resource id.
+func (m *manifestEdit) AddRequirement(id, requirement string) (err error) {
+	defer m.reparse(&err)
+	resource, _, err := m.resource(id)
+	if err != nil {
+		return err
+	}
+	key, requires := mappingPair(resource, "requires")
+	item := &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: requirement}
+	if m.json {
+		if requires == nil || requires.Kind != yamlv3.SequenceNode {
+			requires = &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+			setMappingValue(resource, "requires", requires)
+		}
+		requires.Content = append(requires.Content, item)
+		return nil
+	}
+
+	text, err := encodeYAMLNode(item)
+	if err != nil {
+		return err
+	}
+	switch {
+	case requires == nil:
+		_, end, _ := m.nodeLines(resource)
+		indent := resource.Content[0].Column - 1
+		m.insert(end, strings.Repeat(" ", indent)+"requires:\n")
+		m.insert(end+1, sequenceItem(text, indent+2)...)
+	case requires.Kind == yamlv3.SequenceNode && requires.Style&yamlv3.FlowStyle == 0 && len(requires.Content) > 0:
+		m.appendItem(requires, text)
+	default:
+		return m.setFlow(key, requires, append(sequenceValues(requires), requirement))
+	}
+	return nil
+}
+
+// RemoveRequirement removes the requirement from the requirements of the
+// resource id.
+func (m *manifestEdit) RemoveRequirement(id, requirement string) (err error) {
+	defer m.reparse(&err)
+	resource, _, err := m.resource(id)
+	if err != nil {
+		return err
+	}
+	key, requires := mappingPair(resource, "requires")
+	index := -1
+	if requires != nil {
+		index = slices.IndexFunc(requires.Content, func(item *yamlv3.Node) bool { return m.ids.ID(item.Value) == requirement })
+	}
+	if index < 0 {
+		return fmt.Errorf("resource '%s' does not require '%s' in %s", id, requirement, m.path)
+	}
+	if m.json {
+		requires.Content = slices.Delete(requires.Content, index, index+1)
+		return nil
+	}
+	if requires.Style&yamlv3.FlowStyle != 0 {
+		values := sequenceValues(requires)
+		return m.setFlow(key, requires, slices.Delete(values, index, index+1))
+	}
+	m.removeItem(requires, index)
+	if len(requires.Content) == 1 {
+		return m.setFlow(key, requires, nil)
+	}
+	return nil
+}
+
+// insert inserts lines before the line at index i, counting from 0.
+func (m *manifestEdit) insert(i int, lines ...string) {
+	m.lines = slices.Insert(m.lines, i, lines...)
+}
+
+// appendItem appends an item, given as YAML text, after the last item of a
+// block sequence, with the same indentation. A blank line separates it from
+// the last item when the items are separated by blank lines.
+func (m *manifestEdit) appendItem(list *yamlv3.Node, text string) {
+	start, end, indent := m.nodeLines(list.Content[len(list.Content)-1])
+	lines := sequenceItem(text, indent)
+	if len(list.Content) > 1 && start > 0 && isBlank(m.lines[start-1]) {
+		lines = append([]string{"\n"}, lines...)
+	}
+	m.insert(end, lines...)
+}
+
+// removeItem removes the lines of an item of a block sequence, with the
+// comments above it. The blank lines separating it from the next item, or
+// from the previous one for the last item, go with it.
+func (m *manifestEdit) removeItem(list *yamlv3.Node, index int) {
+	start, end, indent := m.nodeLines(list.Content[index])
+	start = m.commentsAbove(start, indent)
+	switch {
+	case index+1 < len(list.Content):
+		next, _, nextIndent := m.nodeLines(list.Content[index+1])
+		end = m.commentsAbove(next, nextIndent)
+	case index > 0:
+		_, start, _ = m.nodeLines(list.Content[index-1])
+	}
+	m.lines = slices.Delete(m.lines, start, end)
+}
+
+// nodeLines returns the range of lines of a node, counting from 0, and the
+// indentation of its first line. The node spans the lines indented more than
+// its first line, trailing blank lines excluded. The first line of an item
+// of a block sequence is the line of its dash.
+func (m *manifestEdit) nodeLines(node *yamlv3.Node) (int, int, int) {
+	start := node.Line - 1
+	if node.Kind != yamlv3.ScalarNode && start > 0 && !strings.HasPrefix(strings.TrimSpace(m.lines[start]), "-") && strings.TrimSpace(m.lines[start-1]) == "-" {
+		start--
+	}
+	if node == m.root() {
+		start = 0
+	}
+	indent := indentation(m.lines[start])
+	if node == m.root() {
+		indent = -1
+	}
+	end := start + 1
+	for i := start + 1; i < len(m.lines); i++ {
+		if isBlank(m.lines[i]) {
+			continue
+		}
+		if indentation(m.lines[i]) <= indent {
+			break
+		}
+		end = i + 1
+	}
+	return start, end, indent
+}
+
+// commentsAbove returns the index of the first line of the comments right
+// above the line at index i with the given indentation, or i.
+func (m *manifestEdit) commentsAbove(i, indent int) int {
+	for i > 0 && indentation(m.lines[i-1]) == indent && strings.HasPrefix(strings.TrimSpace(m.lines[i-1]), "#") {
+		i--
+	}
+	return i
+}
+
+// setFlow replaces the value of key with a flow sequence of the values.
+func (m *manifestEdit) setFlow(key, value *yamlv3.Node, values []string) error {
+	items := make([]string, len(values))
+	for i, v := range values {
+		text, err := encodeYAMLNode(&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: v})
+		if err != nil {
+			return err
+		}
+		items[i] = strings.TrimSuffix(text, "\n")
+	}
+	return m.setInline(key, value, "["+strings.Join(items, ", ")+"]")
+}
+
+// setInline replaces the value of key with text on the line of the key,
+// keeping the comment of the line. The value is either written on that line,
+// such as null or a flow sequence, or a block sequence whose lines are
+// already removed.
+func (m *manifestEdit) setInline(key, value *yamlv3.Node, text string) error {
+	flow := value.Kind != yamlv3.ScalarNode && value.Style&yamlv3.FlowStyle != 0
+	if flow && value.Line != key.Line {
+		return fmt.Errorf("%s: cannot edit the multi-line value of %s at line %d", m.path, key.Value, key.Line)
+	}
+	line := strings.TrimSuffix(m.lines[key.Line-1], "\n")
+	colon := key.Column - 1 + len(key.Value)
+	colon += strings.Index(line[colon:], ":")
+	rest, comment := line[colon+1:], ""
+	if flow {
+		comment = rest[strings.LastIndex(rest, "]")+1:]
+	} else if i := strings.Index(rest, " #"); i >= 0 {
+		comment = rest[i:]
+	}
+	if text != "" {
+		text = " " + text
+	}
+	m.lines[key.Line-1] = line[:colon+1] + text + comment + "\n"
+	return nil
+}
+
+// mappingPair returns the key and value nodes of the key in a mapping node,
+// or nils.
+func mappingPair(node *yamlv3.Node, key string) (*yamlv3.Node, *yamlv3.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// setMappingValue sets the value of the key in a mapping node, appending the
+// key when missing.
+func setMappingValue(node *yamlv3.Node, key string, value *yamlv3.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func sequenceValues(node *yamlv3.Node) []string {
+	var values []string
+	if node.Kind == yamlv3.SequenceNode {
+		for _, item := range node.Content {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+func isNull(node *yamlv3.Node) bool {
+	return node.Kind == yamlv3.ScalarNode && node.ShortTag() == "!!null"
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentation returns the number of spaces starting the line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// sequenceItem returns the lines of an item of a block sequence, given as
+// YAML text, indented by indent spaces.
+func sequenceItem(text string, indent int) []string {
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		prefix := "  "
+		if i == 0 {
+			prefix = "- "
+		}
+		lines[i] = strings.Repeat(" ", indent) + prefix + line
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// encodeYAMLNode returns the YAML text of the node, indented with two
+// spaces.
+func encodeYAMLNode(node *yamlv3.Node) (string, error) {
+	var b strings.Builder
+	encoder := yamlv3.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// writeJSONNode writes the node as JSON, indented with two spaces, keeping
+// the order of the keys of mappings.
+func writeJSONNode(b *strings.Builder, node *yamlv3.Node, indent string) {
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		writeJSONNode(b, node.Content[0], indent)
+	case yamlv3.AliasNode:
+		writeJSONNode(b, node.Alias, indent)
+	case yamlv3.MappingNode, yamlv3.SequenceNode:
+		open, closing, step := "[", "]", 1
+		if node.Kind == yamlv3.MappingNode {
+			open, closing, step = "{", "}", 2
+		}
+		if len(node.Content) == 0 {
+			b.WriteString(open + closing)
+			return
+		}
+		b.WriteString(open + "\n")
+		for i := 0; i < len(node.Content); i += step {
+			b.WriteString(indent + "  ")
+			if step == 2 {
+				key, _ := json.Marshal(node.Content[i].Value)
+				b.Write(key)
+				b.WriteString(": ")
+			}
+			writeJSONNode(b, node.Content[i+step-1], indent+"  ")
+			if i+step < len(node.Content) {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + closing)
+	default:
+		switch node.ShortTag() {
+		case "!!null":
+			b.WriteString("null")
+		case "!!bool", "!!int", "!!float":
+			b.WriteString(node.Value)
+		default:
+			value, _ := json.Marshal(node.Value)
+			b.Write(value)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package resolver
+
+import "testing"
+
+const editedManifest = `# Services of the team.
+resources:
+  # The database.
+  - id: db
+    name: "Database" # managed
+
+  - id: app
+    requires:
+      - db   # first
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+
+  - id: web
+    requires: [app] # flow
+include:
+  - common.yaml
+`
+
+func TestManifestEdit(t *testing.T) {
+	cache, _ := resourceNode(ResourceNodeEntry{Id: "cache", Requires: []string{"db"}, Run: []RunStep{{Name: "start", Exec: "redis-server"}}})
+	tests := []struct {
+		name     string
+		edit     func(*manifestEdit) error
+		expected string
+	}{
+		{
+			name: "append resource",
+			edit: func(m *manifestEdit) error { return m.AppendResource(cache) },
+			expected: `# Services of the team.
+resources:
+  # The database.
+  - id: db
+    name: "Database" # managed
+
+  - id: app
+    requires:
+      - db   # first
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+
+  - id: web
+    requires: [app] # flow
+
+  - id: cache
+    requires:
+      - db
+    run:
+      - name: start
+        exec: redis-server
+include:
+  - common.yaml
+`,
+		},
+		{
+			name: "remove first resource",
+			edit: func(m *manifestEdit) error { return m.RemoveResource("db") },
+			expected: `# Services of the team.
+resources:
+  - id: app
+    requires:
+      - db   # first
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+
+  - id: web
+    requires: [app] # flow
+include:
+  - common.yaml
+`,
+		},
+		{
+			name: "remove last resource",
+			edit: func(m *manifestEdit) error { return m.RemoveResource("web") },
+			expected: `# Services of the team.
+resources:
+  # The database.
+  - id: db
+    name: "Database" # managed
+
+  - id: app
+    requires:
+      - db   # first
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+include:
+  - common.yaml
+`,
+		},
+		{
+			name: "add block requirement",
+			edit: func(m *manifestEdit) error { return m.AddRequirement("app", "cache") },
+			expected: `# Services of the team.
+resources:
+  # The database.
+  - id: db
+    name: "Database" # managed
+
+  - id: app
+    requires:
+      - db   # first
+      - cache
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+
+  - id: web
+    requires: [app] # flow
+include:
+  - common.yaml
+`,
+		},
+		{
+			name: "add flow and new requirements",
+			edit: func(m *manifestEdit) error {
+				if err := m.AddRequirement("web", "db"); err != nil {
+					return err
+				}
+				return m.AddRequirement("db", "with space")
+			},
+			expected: `# Services of the team.
+resources:
+  # The database.
+  - id: db
+    name: "Database" # managed
+    requires:
+      - with space
+
+  - id: app
+    requires:
+      - db   # first
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+
+  - id: web
+    requires: [app, db] # flow
+include:
+  - common.yaml
+`,
+		},
+		{
+			name: "remove requirements",
+			edit: func(m *manifestEdit) error {
+				if err := m.RemoveRequirement("web", "app"); err != nil {
+					return err
+				}
+				return m.RemoveRequirement("app", "db")
+			},
+			expected: `# Services of the team.
+resources:
+  # The database.
+  - id: db
+    name: "Database" # managed
+
+  - id: app
+    requires: []
+    run:
+      - name: build
+        exec: |
+          make
+          make install
+
+  - id: web
+    requires: [] # flow
+include:
+  - common.yaml
+`,
+		},
+	}
+	for _, test := range tests {
+		// Every edit parses the manifest again, like the resolver does.
+		m, err := parseManifestEdit("catalog.yaml", []byte(editedManifest), IDPolicy{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := test.edit(m); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got := string(m.Bytes()); got != test.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestManifestEdit_Empty(t *testing.T) {
+	cache, _ := resourceNode(ResourceNodeEntry{Id: "cache"})
+	tests := []struct {
+		path, data, expected string
+	}{
+		{"new.yaml", "", "resources:\n  - id: cache\n"},
+		{"empty.yaml", "resources: [] # none yet\n", "resources: # none yet\n  - id: cache\n"},
+		{"includes.yaml", "include: [a.yaml]", "include: [a.yaml]\nresources:\n  - id: cache\n"},
+		{"new.json", "", "{\n  \"resources\": [\n    {\n      \"id\": \"cache\"\n    }\n  ]\n}\n"},
+	}
+	for _, test := range tests {
+		m, err := parseManifestEdit(test.path, []byte(test.data), IDPolicy{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := m.AppendResource(cache); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := string(m.Bytes()); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.path, test.expected, got)
+		}
+	}
+
+	m, _ := parseManifestEdit("single.yaml", []byte("id: db\nname: Database\n"), IDPolicy{})
+	if err := m.AppendResource(cache); err == nil {
+		t.Error("Expected appending to a single resource manifest to fail")
+	}
+	if err := m.AddRequirement("db", "cache"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := string(m.Bytes()); got != "id: db\nname: Database\nrequires:\n  - cache\n" {
+		t.Errorf("Expected the requirement to be added, got %q", got)
+	}
+
+	m, _ = parseManifestEdit("only.yaml", []byte("resources:\n  - id: db\n    requires: [x]\n"), IDPolicy{})
+	if err := m.RemoveResource("db"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := string(m.Bytes()); got != "resources: []\n" {
+		t.Errorf("Expected no resources left, got %q", got)
+	}
+}
+
+func TestManifestEdit_JSON(t *testing.T) {
+	data := `{"resources": [{"id": "db", "deprecated": false, "requires": ["x", "y"]}, {"id": "app"}]}`
+	m, err := parseManifestEdit("catalog.json", []byte(data), IDPolicy{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.RemoveRequirement("db", "x"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.AddRequirement("app", "db"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{
+  "resources": [
+    {
+      "id": "db",
+      "deprecated": false,
+      "requires": [
+        "y"
+      ]
+    },
+    {
+      "id": "app",
+      "requires": [
+        "db"
+      ]
+    }
+  ]
+}
+`
+	if got := string(m.Bytes()); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestPruneEmpty(t *testing.T) {
+	node, err := resourceNode(ResourceNodeEntry{Id: "db", Run: []RunStep{{Name: "start"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text, _ := encodeYAMLNode(node)
+	if expected := "id: db\nrun:\n  - name: start\n"; text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
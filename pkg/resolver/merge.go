@@ -0,0 +1,161 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MergePolicy controls how MergeCatalog resolves resources defined in both
+// catalogs.
+type MergePolicy string
+
+const (
+	// MergeError fails the merge on the first resource defined in both.
+	MergeError MergePolicy = "error"
+	// PreferLeft keeps the resource of the catalog merged into.
+	PreferLeft MergePolicy = "prefer-left"
+	// PreferRight replaces the resource with the one of the merged catalog.
+	PreferRight MergePolicy = "prefer-right"
+	// MergeFields merges both resources field by field: the non-empty fields
+	// of the merged catalog override the others, and lists of resources are
+	// combined.
+	MergeFields MergePolicy = "merge-fields"
+)
+
+// MergePolicies lists the supported merge policies.
+var MergePolicies = []string{string(MergeError), string(PreferLeft), string(PreferRight), string(MergeFields)}
+
+// MergeCatalog adds the resources of other to the catalog, resolving the
+// resources defined in both according to policy. The catalog is left
+// unchanged when the merge fails.
+func (dr *DependencyResolver) MergeCatalog(other *DependencyResolver, policy MergePolicy) error {
+	if other == dr {
+		return fmt.Errorf("cannot merge a catalog into itself")
+	}
+	switch policy {
+	case MergeError, PreferLeft, PreferRight, MergeFields:
+	default:
+		return fmt.Errorf("unknown merge policy '%s', expected one of: %s", policy, strings.Join(MergePolicies, ", "))
+	}
+
+	other.mu.RLock()
+	incoming := append([]ResourceNodeEntry(nil), other.Resources...)
+	incomingSources := other.resourceSources()
+	other.mu.RUnlock()
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	sources := dr.resourceSources()
+	resources := append([]ResourceNodeEntry(nil), dr.Resources...)
+	index := make(map[string]int, len(resources))
+	for i, entry := range resources {
+		index[entry.Id] = i
+	}
+
+	for _, entry := range incoming {
+		i, exists := index[entry.Id]
+		if !exists {
+			index[entry.Id] = len(resources)
+			resources = append(resources, entry)
+			sources[entry.Id] = incomingSources[entry.Id]
+			continue
+		}
+
+		switch policy {
+		case MergeError:
+			return &DuplicateResourceError{Id: entry.Id, FirstSource: sources[entry.Id], SecondSource: incomingSources[entry.Id]}
+		case PreferLeft:
+		case PreferRight:
+			resources[i] = entry
+			sources[entry.Id] = incomingSources[entry.Id]
+		case MergeFields:
+			resources[i] = mergeEntries(resources[i], entry)
+		}
+	}
+
+	dr.Resources = resources
+	dr.sources = sources
+	dr.buildDependencies()
+	return nil
+}
+
+// mergeEntries merges two definitions of a resource: the non-empty fields of
+// right override the ones of left, and the lists of resources are combined.
+func mergeEntries(left, right ResourceNodeEntry) ResourceNodeEntry {
+	merged := left
+	overrideString(&merged.Name, right.Name)
+	overrideString(&merged.Version, right.Version)
+	overrideString(&merged.Desc, right.Desc)
+	overrideString(&merged.Category, right.Category)
+	overrideString(&merged.License, right.License)
+	overrideString(&merged.Runner, right.Runner)
+	overrideString(&merged.Type, right.Type)
+	overrideString(&merged.ReplacedBy, right.ReplacedBy)
+	merged.Deprecated = left.Deprecated || right.Deprecated
+
+	merged.Requires = union(left.Requires, right.Requires)
+	merged.Optional = union(left.Optional, right.Optional)
+	merged.Conflicts = union(left.Conflicts, right.Conflicts)
+	merged.Provides = union(left.Provides, right.Provides)
+	merged.Inputs = union(left.Inputs, right.Inputs)
+	merged.Owners = union(left.Owners, right.Owners)
+	merged.Aliases = union(left.Aliases, right.Aliases)
+	merged.Metadata = mergeMetadata(left.Metadata, right.Metadata)
+
+	if len(right.PreRun) > 0 {
+		merged.PreRun = right.PreRun
+	}
+	if len(right.Run) > 0 {
+		merged.Run = right.Run
+	}
+	if len(right.PostRun) > 0 {
+		merged.PostRun = right.PostRun
+	}
+	if len(right.Rollback) > 0 {
+		merged.Rollback = right.Rollback
+	}
+	if right.Retry != nil {
+		merged.Retry = right.Retry
+	}
+	if right.Timeout > 0 {
+		merged.Timeout = right.Timeout
+	}
+	return merged
+}
+
+func overrideString(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+// mergeMetadata returns the metadata of base with the keys of override set.
+func mergeMetadata(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+	return merged
+}
+
+// union returns the elements of a followed by the ones of b missing from a.
+func union(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	merged := append([]string(nil), a...)
+	for _, s := range b {
+		if !slices.Contains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,74 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func setupMergeCatalogs() (*DependencyResolver, *DependencyResolver) {
+	base := setupTestResolver()
+	base.Resources = []ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs"},
+		{Id: "app", Name: "App", Desc: "The application", Requires: []string{"git"}, Run: []RunStep{{Name: "build", Exec: "make"}}},
+	}
+	base.BuildDependencies()
+
+	team := setupTestResolver()
+	team.Resources = []ResourceNodeEntry{
+		{Id: "app", Category: "team", Requires: []string{"git", "redis"}},
+		{Id: "redis", Name: "Redis"},
+	}
+	team.BuildDependencies()
+	return base, team
+}
+
+func TestMergeCatalog(t *testing.T) {
+	tests := []struct {
+		policy   MergePolicy
+		expected ResourceNodeEntry
+	}{
+		{PreferLeft, ResourceNodeEntry{Id: "app", Name: "App", Desc: "The application", Requires: []string{"git"}, Run: []RunStep{{Name: "build", Exec: "make"}}}},
+		{PreferRight, ResourceNodeEntry{Id: "app", Category: "team", Requires: []string{"git", "redis"}}},
+		{MergeFields, ResourceNodeEntry{Id: "app", Name: "App", Desc: "The application", Category: "team", Requires: []string{"git", "redis"}, Run: []RunStep{{Name: "build", Exec: "make"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			base, team := setupMergeCatalogs()
+			if err := base.MergeCatalog(team, tt.policy); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			app, _ := base.GetResource("app")
+			if !reflect.DeepEqual(app, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, app)
+			}
+			if _, ok := base.GetResource("redis"); !ok {
+				t.Error("Expected the resources only in the merged catalog to be added")
+			}
+			if deps := base.DirectDependencies("app"); !reflect.DeepEqual(deps, tt.expected.Requires) {
+				t.Errorf("Expected the dependencies to be rebuilt, got %v", deps)
+			}
+		})
+	}
+}
+
+func TestMergeCatalog_Errors(t *testing.T) {
+	base, team := setupMergeCatalogs()
+
+	var dup *DuplicateResourceError
+	if err := base.MergeCatalog(team, MergeError); !errors.As(err, &dup) || dup.Id != "app" {
+		t.Errorf("Expected a duplicate error for 'app', got %v", err)
+	}
+	if _, ok := base.GetResource("redis"); ok {
+		t.Error("Expected the catalog to be unchanged after a failed merge")
+	}
+
+	if err := base.MergeCatalog(team, "prefer-both"); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+	if err := base.MergeCatalog(base, PreferLeft); err == nil {
+		t.Error("Expected an error when merging a catalog into itself")
+	}
+}
@@ -0,0 +1,58 @@
+package resolver
+
+// hasMetadata reports whether the entry holds every key of the filter, with
+// the same value.
+func hasMetadata(entry ResourceNodeEntry, filter map[string]string) bool {
+	for key, value := range filter {
+		if actual, ok := entry.Metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ResourcesWithMetadata returns the resources holding every key of the
+// filter, with the same value, in catalog order.
+func (dr *DependencyResolver) ResourcesWithMetadata(filter map[string]string) []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.withMetadata(dr.Resources, filter)
+}
+
+func (dr *DependencyResolver) withMetadata(entries []ResourceNodeEntry, filter map[string]string) []ResourceNodeEntry {
+	matches := []ResourceNodeEntry{}
+	for _, entry := range entries {
+		if hasMetadata(entry, filter) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// filterMetadata returns the entries matching the MetadataFilter of the
+// listing commands.
+func (dr *DependencyResolver) filterMetadata(entries []ResourceNodeEntry) []ResourceNodeEntry {
+	if len(dr.MetadataFilter) == 0 {
+		return entries
+	}
+	return dr.withMetadata(entries, dr.MetadataFilter)
+}
+
+// filterMetadataIDs returns the IDs of the resources matching the
+// MetadataFilter of the listing commands.
+func (dr *DependencyResolver) filterMetadataIDs(ids []string) []string {
+	if len(dr.MetadataFilter) == 0 {
+		return ids
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	matches := []string{}
+	for _, id := range ids {
+		if entry, ok := dr.getResource(id); ok && hasMetadata(entry, dr.MetadataFilter) {
+			matches = append(matches, id)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,61 @@
+package resolver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupMetadataResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "billing", Metadata: map[string]string{"team": "payments", "tier": "1"}},
+		{Id: "ledger", Metadata: map[string]string{"team": "payments", "tier": "2"}, Requires: []string{"billing"}},
+		{Id: "search", Metadata: map[string]string{"team": "discovery"}},
+		{Id: "docs"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestResourcesWithMetadata(t *testing.T) {
+	resolver := setupMetadataResolver()
+
+	if ids := entryIds(resolver.ResourcesWithMetadata(map[string]string{"team": "payments"})); !reflect.DeepEqual(ids, []string{"billing", "ledger"}) {
+		t.Errorf("Expected the payments resources, got %v", ids)
+	}
+	if ids := entryIds(resolver.ResourcesWithMetadata(map[string]string{"team": "payments", "tier": "2"})); !reflect.DeepEqual(ids, []string{"ledger"}) {
+		t.Errorf("Expected every key to match, got %v", ids)
+	}
+	if ids := entryIds(resolver.ResourcesWithMetadata(nil)); len(ids) != 4 {
+		t.Errorf("Expected an empty filter to match everything, got %v", ids)
+	}
+}
+
+func TestMetadataFilter(t *testing.T) {
+	resolver := setupMetadataResolver()
+	resolver.MetadataFilter = map[string]string{"team": "payments"}
+
+	output := captureOutput(func() { resolver.HandleRootsCommand() })
+	if !strings.Contains(output, "ledger") || strings.Contains(output, "search") || strings.Contains(output, "docs") {
+		t.Errorf("Expected the roots of the payments team, got %q", output)
+	}
+	output = captureOutput(func() { resolver.HandleIndexCommand() })
+	if !strings.Contains(output, "billing") || strings.Contains(output, "search") {
+		t.Errorf("Expected the index of the payments team, got %q", output)
+	}
+}
+
+func TestMetadata_SaveAndLoad(t *testing.T) {
+	resolver := setupMetadataResolver()
+	resolver.SaveResourceEntries("catalog.yaml")
+
+	loaded := setupTestResolver()
+	loaded.Fs = resolver.Fs
+	if err := loaded.ReloadResourceEntries("catalog.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry, _ := loaded.GetResource("billing"); !reflect.DeepEqual(entry.Metadata, map[string]string{"team": "payments", "tier": "1"}) {
+		t.Errorf("Expected the metadata to be preserved, got %v", entry.Metadata)
+	}
+}
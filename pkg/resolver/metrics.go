@@ -0,0 +1,20 @@
+package resolver
+
+import "github.com/jjuliano/runner/pkg/metrics"
+
+var (
+	resolutionDuration = metrics.Default.NewSummary("runner_resolution_duration_seconds",
+		"Time spent resolving the execution order of targets.")
+	graphResources = metrics.Default.NewGauge("runner_graph_resources",
+		"Number of resources in the dependency graph.")
+	graphEdges = metrics.Default.NewGauge("runner_graph_edges",
+		"Number of dependency edges in the dependency graph.")
+	resourceExecutionDuration = metrics.Default.NewSummary("runner_resource_execution_duration_seconds",
+		"Time spent executing the run steps of a resource.", "resource")
+	resourceFailures = metrics.Default.NewCounter("runner_resource_failures_total",
+		"Number of failed resource executions.", "resource")
+	resourceRetries = metrics.Default.NewCounter("runner_resource_retries_total",
+		"Number of retried resource executions.", "resource")
+	resourceTimeouts = metrics.Default.NewCounter("runner_resource_timeouts_total",
+		"Number of resource executions killed after their timeout.", "resource")
+)
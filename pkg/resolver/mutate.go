@@ -0,0 +1,196 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DanglingRequirementError is returned when removing a resource would leave
+// other resources with a requirement that is no longer in the catalog.
+type DanglingRequirementError struct {
+	Id         string
+	RequiredBy []string
+}
+
+func (e *DanglingRequirementError) Error() string {
+	return fmt.Sprintf("resource '%s' is required by %s", e.Id, strings.Join(e.RequiredBy, ", "))
+}
+
+// AddResourceEntry adds a resource to the catalog and the Store, and rebuilds
+// the dependencies. Adding an ID that is already loaded, once normalized
+// following the IDPolicy, returns a DuplicateResourceError, and an ID or
+// alias breaking it an InvalidIDError. A resource requiring itself, even
+// through one of its aliases or a name it provides, returns a
+// SelfDependencyError.
+func (dr *DependencyResolver) AddResourceEntry(entry ResourceNodeEntry) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	entry = dr.IDs.entry(entry)
+	if err := dr.IDs.checkEntry(entry, ""); err != nil {
+		return err
+	}
+	if err := checkRunner(entry, ""); err != nil {
+		return err
+	}
+	if requiresItself(entry) {
+		return &SelfDependencyError{Id: entry.Id}
+	}
+
+	sources := dr.resourceSources()
+	if err := addSources(sources, []ResourceNodeEntry{entry}, "the catalog"); err != nil {
+		return err
+	}
+	previous, previousSources := dr.Resources, dr.sources
+	if self := dr.replaceResources(append(dr.Resources, entry), sources, false); self != nil {
+		return &SelfDependencyError{Id: self.Id}
+	}
+	if err := dr.storePut(entry); err != nil {
+		dr.Resources, dr.sources = previous, previousSources
+		dr.buildDependencies()
+		return err
+	}
+	return nil
+}
+
+// UpdateResource replaces the loaded resource with the same ID, in the Store
+// too, and updates the dependencies. Only the cached closures the change
+// affects are recomputed. An alias breaking the IDPolicy returns an
+// InvalidIDError, and a requirement resolving to itself a
+// SelfDependencyError.
+func (dr *DependencyResolver) UpdateResource(entry ResourceNodeEntry) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	entry = dr.IDs.entry(entry)
+
+	i := dr.resourceIndexOf(entry.Id)
+	if i < 0 {
+		return &NotFoundError{Id: entry.Id}
+	}
+	if err := dr.IDs.checkEntry(entry, ""); err != nil {
+		return err
+	}
+	if err := checkRunner(entry, ""); err != nil {
+		return err
+	}
+	if requiresItself(entry) {
+		return &SelfDependencyError{Id: entry.Id}
+	}
+
+	previous := dr.Resources[i]
+	locked, wasLocked := dr.locked[entry.Id]
+	dr.Resources[i] = entry
+	delete(dr.locked, entry.Id)
+	dr.updateDependencies(map[int]ResourceNodeEntry{i: previous})
+
+	var err error
+	if self := dr.checkSelfDependencies(false); self != nil {
+		err = &SelfDependencyError{Id: self.Id}
+	} else {
+		err = dr.storePut(entry)
+	}
+	if err != nil {
+		dr.Resources[i] = previous
+		if wasLocked {
+			dr.locked[entry.Id] = locked
+		}
+		dr.updateDependencies(map[int]ResourceNodeEntry{i: entry})
+		return err
+	}
+	return nil
+}
+
+// RemoveResource removes a resource from the catalog and the Store, and
+// rebuilds the dependencies. Unless force is set, removing a resource still required by
+// others returns a DanglingRequirementError and leaves the catalog unchanged.
+func (dr *DependencyResolver) RemoveResource(id string, force bool) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	id = dr.IDs.ID(id)
+	index := dr.resourceIndexOf(id)
+	if index < 0 {
+		return &NotFoundError{Id: id}
+	}
+
+	if !force {
+		if requiredBy := dr.requiredBy(id); len(requiredBy) > 0 {
+			return &DanglingRequirementError{Id: id, RequiredBy: requiredBy}
+		}
+	}
+
+	if dr.Store != nil {
+		storeID := id
+		if loaded, ok := dr.storeIDs[id]; ok {
+			storeID = loaded
+		}
+		if err := dr.Store.Delete(storeID); err != nil {
+			return fmt.Errorf("error deleting resource '%s' from the store: %w", id, err)
+		}
+	}
+
+	dr.Resources = append(dr.Resources[:index:index], dr.Resources[index+1:]...)
+	delete(dr.sources, id)
+	delete(dr.storeIDs, id)
+	delete(dr.locked, id)
+	dr.buildDependencies()
+	return nil
+}
+
+// storePut writes the resource to the Store, when set, which then holds it
+// under its normalized ID. A copy loaded from the Store under another ID,
+// before normalizing it, is deleted so the Store holds the resource once.
+func (dr *DependencyResolver) storePut(entry ResourceNodeEntry) error {
+	if dr.Store == nil {
+		return nil
+	}
+	if err := dr.Store.Put(entry); err != nil {
+		return fmt.Errorf("error storing resource '%s': %w", entry.Id, err)
+	}
+	if storeID, ok := dr.storeIDs[entry.Id]; ok && storeID != entry.Id {
+		if err := dr.Store.Delete(storeID); err != nil {
+			return fmt.Errorf("error deleting resource '%s' from the store: %w", storeID, err)
+		}
+	}
+	if dr.storeIDs == nil {
+		dr.storeIDs = make(map[string]string)
+	}
+	dr.storeIDs[entry.Id] = entry.Id
+	return nil
+}
+
+// requiredBy returns the resources with a mandatory requirement that only the
+// given resource satisfies, in catalog order. Virtual requirements with
+// another provider are not affected by the removal of one of them.
+func (dr *DependencyResolver) requiredBy(id string) []string {
+	known := dr.knownResources()
+
+	var requiredBy []string
+	for _, entry := range dr.Resources {
+		if entry.Id == id {
+			continue
+		}
+		for _, req := range entry.Requires {
+			if dr.providerFor(req, known) == id && !dr.hasOtherProvider(req, id) {
+				requiredBy = append(requiredBy, entry.Id)
+				break
+			}
+		}
+	}
+	return requiredBy
+}
+
+// hasOtherProvider reports whether a virtual requirement is provided by a
+// resource other than id.
+func (dr *DependencyResolver) hasOtherProvider(req, id string) bool {
+	if req == id {
+		return false
+	}
+	for _, provider := range dr.providers(req) {
+		if provider != id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAddResourceEntry(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "a"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "b", Requires: []string{"a"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.TransitiveDependencies("b"); !reflect.DeepEqual(deps, []string{"a"}) {
+		t.Errorf("Expected b to depend on a, got %v", deps)
+	}
+
+	var duplicate *DuplicateResourceError
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "a"}); !errors.As(err, &duplicate) {
+		t.Errorf("Expected a DuplicateResourceError, got %v", err)
+	}
+}
+
+func TestUpdateResource(t *testing.T) {
+	resolver := setupChainResolver(3)
+
+	// Warm up the closure cache before mutating the catalog.
+	resolver.TransitiveDependencies("n2")
+
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "n1", Name: "updated"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.TransitiveDependencies("n2"); !reflect.DeepEqual(deps, []string{"n1"}) {
+		t.Errorf("Expected the cached closure to be invalidated, got %v", deps)
+	}
+	if entry, _ := resolver.GetResource("n1"); entry.Name != "updated" {
+		t.Errorf("Expected the entry to be updated, got %+v", entry)
+	}
+
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "missing"}); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+}
+
+func TestRemoveResource(t *testing.T) {
+	resolver := setupChainResolver(3)
+
+	err := resolver.RemoveResource("n0", false)
+	var dangling *DanglingRequirementError
+	if !errors.As(err, &dangling) || !reflect.DeepEqual(dangling.RequiredBy, []string{"n1"}) {
+		t.Fatalf("Expected n0 to be required by n1, got %v", err)
+	}
+	if _, ok := resolver.GetResource("n0"); !ok {
+		t.Errorf("Expected the catalog to be unchanged")
+	}
+
+	if err := resolver.RemoveResource("n2", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := resolver.GetResource("n2"); ok {
+		t.Errorf("Expected n2 to be removed")
+	}
+	if deps := resolver.ReverseDependencies("n1"); len(deps) != 0 {
+		t.Errorf("Expected no dependents of n1, got %v", deps)
+	}
+
+	if err := resolver.RemoveResource("n0", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.DirectDependencies("n1"); !reflect.DeepEqual(deps, []string{"n0"}) {
+		t.Errorf("Expected n1 to keep its dangling requirement, got %v", deps)
+	}
+
+	if err := resolver.RemoveResource("missing", false); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+}
@@ -0,0 +1,39 @@
+package resolver
+
+import "fmt"
+
+// Orphans returns the resources that are not reachable from any of the given
+// targets, in catalog order.
+func (dr *DependencyResolver) Orphans(targets ...string) ([]string, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("at least one target is required")
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if err := dr.checkLimits(targets, false, 0); err != nil {
+		return nil, err
+	}
+
+	reachable := make(map[string]bool)
+	for _, target := range targets {
+		if _, ok := dr.getResource(target); !ok {
+			return nil, &NotFoundError{Id: target}
+		}
+		reachable[target] = true
+		for _, dep := range dr.cache.memoize(&dr.cache.closures, target, func() []string {
+			return dr.closure(target, 0)
+		}) {
+			reachable[dep] = true
+		}
+	}
+
+	var orphans []string
+	for _, id := range dr.resourceIDs() {
+		if !reachable[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	return orphans, nil
+}
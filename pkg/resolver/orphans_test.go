@@ -0,0 +1,45 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrphans(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"lib"}},
+		{Id: "lib"},
+		{Id: "cli", Requires: []string{"lib", "old-lib"}},
+		{Id: "old-lib"},
+		{Id: "dead"},
+		{Id: "dead-dep"},
+		{Id: "dead-top", Requires: []string{"dead-dep"}},
+	}
+	resolver.BuildDependencies()
+
+	orphans, err := resolver.Orphans("app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"cli", "old-lib", "dead", "dead-dep", "dead-top"}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("Expected %v, got %v", expected, orphans)
+	}
+
+	orphans, err = resolver.Orphans("app", "cli")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = []string{"dead", "dead-dep", "dead-top"}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("Expected %v, got %v", expected, orphans)
+	}
+
+	if _, err := resolver.Orphans(); err == nil {
+		t.Errorf("Expected an error without targets")
+	}
+	if _, err := resolver.Orphans("missing"); err == nil {
+		t.Errorf("Expected an error for an unknown target")
+	}
+}
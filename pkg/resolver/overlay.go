@@ -0,0 +1,156 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ResourceOverlay patches some fields of an existing resource. Empty fields
+// leave the resource unchanged.
+type ResourceOverlay struct {
+	Id       string `yaml:"id"`
+	Name     string `yaml:"name,omitempty"`
+	Version  string `yaml:"version,omitempty"`
+	Desc     string `yaml:"desc,omitempty"`
+	Category string `yaml:"category,omitempty"`
+	License  string `yaml:"license,omitempty"`
+	Runner   string `yaml:"runner,omitempty"`
+	Type     string `yaml:"type,omitempty"`
+
+	// Deprecated marks or unmarks the resource as deprecated when set.
+	Deprecated *bool  `yaml:"deprecated,omitempty"`
+	ReplacedBy string `yaml:"replacedBy,omitempty"`
+
+	AddRequires     []string `yaml:"addRequires,omitempty"`
+	RemoveRequires  []string `yaml:"removeRequires,omitempty"`
+	AddOptional     []string `yaml:"addOptional,omitempty"`
+	RemoveOptional  []string `yaml:"removeOptional,omitempty"`
+	AddConflicts    []string `yaml:"addConflicts,omitempty"`
+	RemoveConflicts []string `yaml:"removeConflicts,omitempty"`
+	AddProvides     []string `yaml:"addProvides,omitempty"`
+	RemoveProvides  []string `yaml:"removeProvides,omitempty"`
+	AddInputs       []string `yaml:"addInputs,omitempty"`
+	RemoveInputs    []string `yaml:"removeInputs,omitempty"`
+	AddOwners       []string `yaml:"addOwners,omitempty"`
+	RemoveOwners    []string `yaml:"removeOwners,omitempty"`
+	AddAliases      []string `yaml:"addAliases,omitempty"`
+	RemoveAliases   []string `yaml:"removeAliases,omitempty"`
+
+	// Metadata sets the given metadata keys, keeping the others.
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+
+	// Run replaces the run steps of the resource when set.
+	Run     []RunStep     `yaml:"run,omitempty"`
+	Retry   *RetryPolicy  `yaml:"retry,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// apply returns the entry patched by the overlay.
+func (o ResourceOverlay) apply(entry ResourceNodeEntry) ResourceNodeEntry {
+	overrideString(&entry.Name, o.Name)
+	overrideString(&entry.Version, o.Version)
+	overrideString(&entry.Desc, o.Desc)
+	overrideString(&entry.Category, o.Category)
+	overrideString(&entry.License, o.License)
+	overrideString(&entry.Runner, o.Runner)
+	overrideString(&entry.Type, o.Type)
+	overrideString(&entry.ReplacedBy, o.ReplacedBy)
+	if o.Deprecated != nil {
+		entry.Deprecated = *o.Deprecated
+	}
+
+	entry.Requires = patch(entry.Requires, o.AddRequires, o.RemoveRequires)
+	entry.Optional = patch(entry.Optional, o.AddOptional, o.RemoveOptional)
+	entry.Conflicts = patch(entry.Conflicts, o.AddConflicts, o.RemoveConflicts)
+	entry.Provides = patch(entry.Provides, o.AddProvides, o.RemoveProvides)
+	entry.Inputs = patch(entry.Inputs, o.AddInputs, o.RemoveInputs)
+	entry.Owners = patch(entry.Owners, o.AddOwners, o.RemoveOwners)
+	entry.Aliases = patch(entry.Aliases, o.AddAliases, o.RemoveAliases)
+	entry.Metadata = mergeMetadata(entry.Metadata, o.Metadata)
+
+	if len(o.Run) > 0 {
+		entry.Run = o.Run
+	}
+	if o.Retry != nil {
+		entry.Retry = o.Retry
+	}
+	if o.Timeout > 0 {
+		entry.Timeout = o.Timeout
+	}
+	return entry
+}
+
+// patch returns ids without the removed ones, followed by the added ones
+// missing from it.
+func patch(ids, add, remove []string) []string {
+	if len(add) == 0 && len(remove) == 0 {
+		return ids
+	}
+	patched := slices.DeleteFunc(append([]string(nil), ids...), func(id string) bool {
+		return slices.Contains(remove, id)
+	})
+	return union(patched, add)
+}
+
+// ApplyOverlays patches the loaded resources with the overlays, in order.
+// The catalog is left unchanged when an overlay targets an unknown resource.
+func (dr *DependencyResolver) ApplyOverlays(overlays []ResourceOverlay) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	return dr.applyOverlays(overlays, "")
+}
+
+// LoadOverlays reads the overlays listed under `overlays` in a local file or
+// URL, and applies them to the loaded resources.
+func (dr *DependencyResolver) LoadOverlays(filePath string) error {
+	data, err := dr.readManifest(filePath)
+	if err != nil {
+		return err
+	}
+
+	var file struct {
+		Overlays []ResourceOverlay `yaml:"overlays"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error unmarshalling YAML data from file %s: %w", filePath, err)
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if err := dr.applyOverlays(file.Overlays, filePath); err != nil {
+		return err
+	}
+	dr.loadedOverlays = append(dr.loadedOverlays, filePath)
+	return nil
+}
+
+// applyOverlays is ApplyOverlays for callers holding the write lock, naming
+// the source of the overlays in errors unless it is empty.
+func (dr *DependencyResolver) applyOverlays(overlays []ResourceOverlay, source string) error {
+	resources := append([]ResourceNodeEntry(nil), dr.Resources...)
+	for _, overlay := range overlays {
+		overlay = dr.IDs.overlay(overlay)
+		i := slices.IndexFunc(resources, func(entry ResourceNodeEntry) bool { return entry.Id == overlay.Id })
+		if i < 0 {
+			if source != "" {
+				return fmt.Errorf("overlay in %s targets unknown resource '%s'", source, overlay.Id)
+			}
+			return fmt.Errorf("overlay targets unknown resource '%s'", overlay.Id)
+		}
+		resources[i] = overlay.apply(resources[i])
+		if requiresItself(resources[i]) {
+			return &SelfDependencyError{Id: overlay.Id, File: source}
+		}
+	}
+
+	if self := dr.replaceResources(resources, dr.sources, false); self != nil {
+		return &SelfDependencyError{Id: self.Id, File: source}
+	}
+	for _, overlay := range overlays {
+		delete(dr.storeIDs, dr.IDs.overlay(overlay).Id)
+	}
+	return nil
+}
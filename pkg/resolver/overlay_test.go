@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestLoadOverlays(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "git", Name: "Git"},
+		{Id: "redis", Name: "Redis"},
+		{Id: "kafka", Name: "Kafka"},
+		{Id: "app", Name: "App", Category: "prod", Requires: []string{"git", "kafka"}},
+	}
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "staging.yaml", []byte(`overlays:
+  - id: app
+    category: staging
+    addRequires: [redis]
+    removeRequires: [kafka]
+    timeout: 1m
+`), 0644)
+
+	if err := resolver.LoadOverlays("staging.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	app, _ := resolver.GetResource("app")
+	expected := ResourceNodeEntry{Id: "app", Name: "App", Category: "staging", Requires: []string{"git", "redis"}, Timeout: time.Minute}
+	if !reflect.DeepEqual(app, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, app)
+	}
+	if deps := resolver.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"git", "redis"}) {
+		t.Errorf("Expected the dependencies to be rebuilt, got %v", deps)
+	}
+}
+
+func TestApplyOverlays_UnknownResource(t *testing.T) {
+	resolver := setupTestResolver()
+
+	err := resolver.ApplyOverlays([]ResourceOverlay{
+		{Id: "a", Category: "patched"},
+		{Id: "missing", Category: "patched"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown resource 'missing'") {
+		t.Errorf("Expected an unknown resource error, got %v", err)
+	}
+	if a, _ := resolver.GetResource("a"); a.Category == "patched" {
+		t.Error("Expected the catalog to be unchanged after a failed overlay")
+	}
+
+	afero.WriteFile(resolver.Fs, "bad.yaml", []byte("overlays:\n  - id: missing\n"), 0644)
+	if err := resolver.LoadOverlays("bad.yaml"); err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("Expected the error to name the file, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package resolver
+
+// NoOwner is the owner the resources declaring none are grouped under.
+const NoOwner = "(none)"
+
+// Ownership lists the resources owned by an owner.
+type Ownership struct {
+	Owner     string   `json:"owner" yaml:"owner"`
+	Resources []string `json:"resources" yaml:"resources"`
+}
+
+// Owners returns the owners of the target and of every resource in its
+// closure, sorted by owner, with the resources declaring none last under
+// NoOwner. A resource with several owners is listed under each of them, and
+// the resources of an owner are in breadth-first order from the target.
+func (dr *DependencyResolver) Owners(target string) ([]Ownership, error) {
+	keys, groups, err := dr.groupClosure(target, NoOwner, func(entry ResourceNodeEntry) []string {
+		return entry.Owners
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ownerships := make([]Ownership, 0, len(keys))
+	for _, owner := range keys {
+		ownerships = append(ownerships, Ownership{Owner: owner, Resources: groups[owner]})
+	}
+	return ownerships, nil
+}
@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupOwnersResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Owners: []string{"team-web"}, Requires: []string{"api", "db"}},
+		{Id: "api", Owners: []string{"team-web", "team-platform"}, Requires: []string{"db"}},
+		{Id: "db", Owners: []string{"team-data"}},
+		{Id: "tool"},
+		{Id: "cli", Owners: []string{"team-platform"}, Requires: []string{"tool"}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestOwners(t *testing.T) {
+	resolver := setupOwnersResolver()
+
+	ownerships, err := resolver.Owners("app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Ownership{
+		{Owner: "team-data", Resources: []string{"db"}},
+		{Owner: "team-platform", Resources: []string{"api"}},
+		{Owner: "team-web", Resources: []string{"app", "api"}},
+	}
+	if !reflect.DeepEqual(ownerships, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, ownerships)
+	}
+
+	ownerships, _ = resolver.Owners("cli")
+	expected = []Ownership{
+		{Owner: "team-platform", Resources: []string{"cli"}},
+		{Owner: NoOwner, Resources: []string{"tool"}},
+	}
+	if !reflect.DeepEqual(ownerships, expected) {
+		t.Errorf("Expected the unowned resources last, got %+v", ownerships)
+	}
+
+	if _, err := resolver.Owners("missing"); err == nil {
+		t.Error("Expected an error for a missing target")
+	}
+}
+
+func TestResourcesOwnedBy(t *testing.T) {
+	resolver := setupOwnersResolver()
+
+	if ids := entryIds(resolver.ResourcesOwnedBy("team-platform", "team-web")); !reflect.DeepEqual(ids, []string{"app", "api", "cli"}) {
+		t.Errorf("Expected each resource once, in catalog order, got %v", ids)
+	}
+
+	// Replacing Resources without rebuilding falls back to scanning.
+	resolver.Resources = []ResourceNodeEntry{{Id: "web", Owners: []string{"team-web", "team-platform"}}}
+	if ids := entryIds(resolver.ResourcesOwnedBy("team-platform", "team-web")); !reflect.DeepEqual(ids, []string{"web"}) {
+		t.Errorf("Expected the new resources, got %v", ids)
+	}
+}
+
+func TestHandleOwnedCommand(t *testing.T) {
+	resolver := setupOwnersResolver()
+
+	output := captureOutput(func() {
+		if err := resolver.HandleOwnedCommand([]string{"team-data"}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "db") || strings.Contains(output, "app") {
+		t.Errorf("Expected the resources of team-data, got %q", output)
+	}
+	if err := resolver.HandleOwnedCommand(nil); err == nil {
+		t.Error("Expected an error without owners")
+	}
+}
@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"fmt"
+	"math"
+)
+
+// Page selects a window of a listing: at most Limit items, after skipping the
+// first Offset ones. A Limit of 0 means no limit.
+type Page struct {
+	Offset int `json:"offset" yaml:"offset"`
+	Limit  int `json:"limit" yaml:"limit"`
+}
+
+// NewPage returns the page with the given 1-based number, of limit items
+// each. A page past the first one needs a limit, and its offset must fit in
+// an int.
+func NewPage(number, limit int) (Page, error) {
+	switch {
+	case limit < 0:
+		return Page{}, fmt.Errorf("invalid limit %d, expected a positive number or 0 for no limit", limit)
+	case number < 1:
+		return Page{}, fmt.Errorf("invalid page %d, pages start at 1", number)
+	case number > 1 && limit == 0:
+		return Page{}, fmt.Errorf("page %d requires a limit", number)
+	case limit > 0 && number-1 > math.MaxInt/limit:
+		return Page{}, fmt.Errorf("invalid page %d, too large for a limit of %d", number, limit)
+	}
+	return Page{Offset: (number - 1) * limit, Limit: limit}, nil
+}
+
+// Paginate returns the items of the page. An Offset past the end returns no
+// item.
+func Paginate[T any](items []T, page Page) []T {
+	start := min(max(page.Offset, 0), len(items))
+	end := len(items)
+	if page.Limit > 0 && page.Limit < end-start {
+		end = start + page.Limit
+	}
+	return items[start:end]
+}
+
+// ListResourcesPage returns the resources of a page of the catalog, in
+// catalog order, and the total number of resources. Only the resources of
+// the page are copied.
+func (dr *DependencyResolver) ListResourcesPage(page Page) ([]ResourceNodeEntry, int) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return append([]ResourceNodeEntry(nil), Paginate(dr.Resources, page)...), len(dr.Resources)
+}
+
+// paginate returns the items of the Page of the listing commands.
+func paginate[T any](dr *DependencyResolver, items []T) []T {
+	return Paginate(items, dr.Page)
+}
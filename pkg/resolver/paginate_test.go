@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		number, limit int
+		expected      Page
+		wantErr       bool
+	}{
+		{1, 0, Page{}, false},
+		{1, 10, Page{Limit: 10}, false},
+		{3, 10, Page{Offset: 20, Limit: 10}, false},
+		{2, 0, Page{}, true},
+		{0, 10, Page{}, true},
+		{1, -1, Page{}, true},
+		{math.MaxInt/10 + 1, 10, Page{Offset: math.MaxInt / 10 * 10, Limit: 10}, false},
+		{math.MaxInt/10 + 2, 10, Page{}, true},
+		{math.MaxInt, 2, Page{}, true},
+	}
+	for _, test := range tests {
+		page, err := NewPage(test.number, test.limit)
+		if (err != nil) != test.wantErr {
+			t.Errorf("NewPage(%d, %d): expected error %v, got %v", test.number, test.limit, test.wantErr, err)
+		}
+		if err == nil && page != test.expected {
+			t.Errorf("NewPage(%d, %d): expected %+v, got %+v", test.number, test.limit, test.expected, page)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		page     Page
+		expected []int
+	}{
+		{Page{}, []int{1, 2, 3, 4, 5}},
+		{Page{Limit: 2}, []int{1, 2}},
+		{Page{Offset: 2, Limit: 2}, []int{3, 4}},
+		{Page{Offset: 4, Limit: 2}, []int{5}},
+		{Page{Offset: 3}, []int{4, 5}},
+		{Page{Offset: 9, Limit: 2}, []int{}},
+		{Page{Offset: 2, Limit: math.MaxInt}, []int{3, 4, 5}},
+	}
+	for _, test := range tests {
+		if got := Paginate(items, test.page); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Paginate(%+v): expected %v, got %v", test.page, test.expected, got)
+		}
+	}
+}
+
+func TestListResourcesPage(t *testing.T) {
+	resolver := setupChainResolver(5)
+
+	entries, total := resolver.ListResourcesPage(Page{Offset: 1, Limit: 2})
+	if total != 5 || len(entries) != 2 || entries[0].Id != "n1" || entries[1].Id != "n2" {
+		t.Errorf("Expected n1 and n2 out of 5, got %+v out of %d", entries, total)
+	}
+	entries[0].Id = "changed"
+	if entry, _ := resolver.GetResource("n1"); entry.Id != "n1" {
+		t.Error("Expected the page to be a copy")
+	}
+	if entries, _ := resolver.Snapshot().ResourcesPage(Page{Offset: 4}); len(entries) != 1 || entries[0].Id != "n4" {
+		t.Errorf("Expected the last resource, got %+v", entries)
+	}
+}
+
+func TestHandleRootsCommand_Page(t *testing.T) {
+	resolver := setupIterResolver()
+	resolver.Page = Page{Offset: 1, Limit: 1}
+
+	if output := captureOutput(func() { resolver.HandleRootsCommand() }); output != "📦 cli\n" {
+		t.Errorf("Expected the second root, got %q", output)
+	}
+}
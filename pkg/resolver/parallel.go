@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"runtime"
+	"sync"
+)
+
+// chunksPerWorker splits passes over resources into more chunks than
+// workers, so a chunk of expensive resources doesn't keep one worker busy
+// while the others are idle.
+const chunksPerWorker = 4
+
+// workers returns the number of goroutines running the validation and
+// analysis passes: Workers, or GOMAXPROCS when it is 0 or less.
+func (dr *DependencyResolver) workers() int {
+	if dr.Workers > 0 {
+		return dr.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallel runs the tasks over at most workers goroutines, and returns their
+// results in the order of the tasks.
+func parallel[T any](workers int, tasks []func() T) []T {
+	results := make([]T, len(tasks))
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+	if workers <= 1 {
+		for i, task := range tasks {
+			results[i] = task()
+		}
+		return results
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = tasks[i]()
+			}
+		}()
+	}
+	for i := range tasks {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// resourcePass checks the resource at position i of Resources.
+type resourcePass[T any] func(i int, entry ResourceNodeEntry) []T
+
+// runPasses runs every pass over every resource, concurrently over chunks of
+// Resources, and returns the findings of the first pass followed by the ones
+// of the next passes, each in catalog order. Passes must only read the
+// resolver. Callers must hold the read lock.
+func runPasses[T any](dr *DependencyResolver, passes ...resourcePass[T]) []T {
+	workers := dr.workers()
+	size := (len(dr.Resources) + workers*chunksPerWorker - 1) / (workers * chunksPerWorker)
+	if size == 0 {
+		size = 1
+	}
+
+	var tasks []func() []T
+	for _, pass := range passes {
+		for start := 0; start < len(dr.Resources); start += size {
+			end := min(start+size, len(dr.Resources))
+			tasks = append(tasks, func() []T {
+				var findings []T
+				for i := start; i < end; i++ {
+					findings = append(findings, pass(i, dr.Resources[i])...)
+				}
+				return findings
+			})
+		}
+	}
+
+	var findings []T
+	for _, chunk := range parallel(workers, tasks) {
+		findings = append(findings, chunk...)
+	}
+	return findings
+}
@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	tasks := make([]func() int, 100)
+	for i := range tasks {
+		tasks[i] = func() int { return i * i }
+	}
+	for _, workers := range []int{0, 1, 7, 200} {
+		results := parallel(workers, tasks)
+		for i, result := range results {
+			if result != i*i {
+				t.Fatalf("With %d workers, expected result %d to be %d, got %d", workers, i, i*i, result)
+			}
+		}
+	}
+}
+
+// analysisCatalog returns a catalog with every kind of warning of the
+// analysis passes.
+func analysisCatalog(size int) []ResourceNodeEntry {
+	resources := make([]ResourceNodeEntry, size)
+	for i := range resources {
+		entry := ResourceNodeEntry{Id: fmt.Sprintf("r%d", i), Deprecated: i%7 == 0}
+		if i > 1 {
+			entry.Requires = []string{fmt.Sprintf("r%d", i-1), fmt.Sprintf("r%d", i-2)}
+		}
+		if i%5 == 0 {
+			entry.Optional = []string{fmt.Sprintf("missing%d", i)}
+		}
+		if i%11 == 0 {
+			entry.Type = "unknown"
+		}
+		resources[i] = entry
+	}
+	return resources
+}
+
+func TestRunPasses_Deterministic(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = analysisCatalog(200)
+	resolver.BuildDependencies()
+
+	resolver.Workers = 1
+	warnings := resolver.Warnings()
+	typeErr := resolver.ValidateTypes()
+	if len(warnings) == 0 || typeErr == nil {
+		t.Fatalf("Expected warnings and type errors, got %d warnings and %v", len(warnings), typeErr)
+	}
+	for _, workers := range []int{0, 3, 16} {
+		resolver.Workers = workers
+		if got := resolver.Warnings(); !reflect.DeepEqual(got, warnings) {
+			t.Errorf("With %d workers, expected the warnings of a single worker, got %v", workers, got)
+		}
+		if err := resolver.ValidateTypes(); err.Error() != typeErr.Error() {
+			t.Errorf("With %d workers, expected %v, got %v", workers, typeErr, err)
+		}
+	}
+
+	// Findings are grouped by check, in catalog order.
+	codes := []FindingCode{CodeMissingOptional, CodeRedundantRequirement, CodeDeprecatedResource}
+	for i, j := 0, 0; i < len(warnings); i++ {
+		for warnings[i].Code != codes[j] {
+			j++
+			if j == len(codes) {
+				t.Fatalf("Unexpected order of the warnings: %v", warnings)
+			}
+		}
+	}
+}
+
+func BenchmarkWarnings_10k(b *testing.B) {
+	resolver := setupTestResolver()
+	resolver.Resources = analysisCatalog(10000)
+	resolver.BuildDependencies()
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			resolver.Workers = workers
+			for i := 0; i < b.N; i++ {
+				resolver.Warnings()
+			}
+		})
+	}
+}
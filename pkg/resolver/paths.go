@@ -0,0 +1,80 @@
+package resolver
+
+import "fmt"
+
+// Why returns every dependency path from target to resource, explaining why
+// the resource is part of the closure of target. Paths are listed in
+// depth-first order and never revisit a resource. Either may be named by a
+// former ID among its aliases.
+func (dr *DependencyResolver) Why(resource, target string) ([][]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	resource, target = dr.currentID(resource), dr.currentID(target)
+	if _, ok := dr.getResource(target); !ok {
+		return nil, &NotFoundError{Id: target}
+	}
+	if _, ok := dr.getResource(resource); !ok {
+		return nil, &NotFoundError{Id: resource}
+	}
+
+	reaches := dr.reaching(resource)
+	if !reaches(target) {
+		return nil, fmt.Errorf("resource '%s' is not required by '%s'", resource, target)
+	}
+	return dr.allPaths(target, resource, 0, reaches)
+}
+
+// AllPaths returns every distinct dependency path from one resource to
+// another, in depth-first order. Paths longer than maxDepth requirements are
+// skipped unless maxDepth is 0. When MaxPaths is set and more paths exist, the
+// first MaxPaths paths are returned along with an error.
+func (dr *DependencyResolver) AllPaths(from, to string, maxDepth int) ([][]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if _, ok := dr.getResource(from); !ok {
+		return nil, &NotFoundError{Id: from}
+	}
+	if _, ok := dr.getResource(to); !ok {
+		return nil, &NotFoundError{Id: to}
+	}
+
+	reaches := dr.reaching(to)
+	if !reaches(from) {
+		return nil, nil
+	}
+	return dr.allPaths(from, to, maxDepth, reaches)
+}
+
+// allPaths enumerates the simple paths from one resource to another, only
+// descending into the resources reaching it.
+func (dr *DependencyResolver) allPaths(from, to string, maxDepth int, reaches func(string) bool) ([][]string, error) {
+	var paths [][]string
+	for path := range dr.graph().Paths(from, to, maxDepth, reaches) {
+		if dr.MaxPaths > 0 && len(paths) == dr.MaxPaths {
+			return paths, fmt.Errorf("more than %d dependency paths from '%s' to '%s'", dr.MaxPaths, from, to)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ShortestPath returns the shortest dependency path from one resource to
+// another, following requirements breadth-first.
+func (dr *DependencyResolver) ShortestPath(from, to string) ([]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if _, ok := dr.getResource(from); !ok {
+		return nil, &NotFoundError{Id: from}
+	}
+	if _, ok := dr.getResource(to); !ok {
+		return nil, &NotFoundError{Id: to}
+	}
+
+	if path := dr.graph().ShortestPath(from, to); path != nil {
+		return path, nil
+	}
+	return nil, fmt.Errorf("no dependency path from '%s' to '%s'", from, to)
+}
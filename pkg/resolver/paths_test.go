@@ -0,0 +1,124 @@
+package resolver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWhy(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	paths, err := resolver.Why("D", "A")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{{"A", "B", "D"}, {"A", "C", "D"}}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	if paths, err := resolver.Why("A", "A"); err != nil || !reflect.DeepEqual(paths, [][]string{{"A"}}) {
+		t.Errorf("Expected the target itself, got %v (%v)", paths, err)
+	}
+
+	if _, err := resolver.Why("A", "D"); err == nil || !strings.Contains(err.Error(), "is not required by") {
+		t.Errorf("Expected an error for a resource outside of the closure, got %v", err)
+	}
+	if _, err := resolver.Why("X", "A"); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+}
+
+func TestWhy_Cycle(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b"}},
+		{Id: "b", Requires: []string{"a", "c"}},
+		{Id: "c"},
+	}
+	resolver.BuildDependencies()
+
+	paths, err := resolver.Why("c", "a")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := [][]string{{"a", "b", "c"}}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b", "e"}},
+		{Id: "b", Requires: []string{"c"}},
+		{Id: "c", Requires: []string{"d"}},
+		{Id: "d"},
+		{Id: "e", Requires: []string{"d"}},
+	}
+	resolver.BuildDependencies()
+
+	path, err := resolver.ShortestPath("a", "d")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"a", "e", "d"}; !reflect.DeepEqual(path, expected) {
+		t.Errorf("Expected %v, got %v", expected, path)
+	}
+
+	if path, err := resolver.ShortestPath("a", "a"); err != nil || !reflect.DeepEqual(path, []string{"a"}) {
+		t.Errorf("Expected the resource itself, got %v (%v)", path, err)
+	}
+	if _, err := resolver.ShortestPath("d", "a"); err == nil || !strings.Contains(err.Error(), "no dependency path") {
+		t.Errorf("Expected an error for unconnected resources, got %v", err)
+	}
+	if _, err := resolver.ShortestPath("a", "x"); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+}
+
+func TestAllPaths(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b", "c", "d"}},
+		{Id: "b", Requires: []string{"c"}},
+		{Id: "c", Requires: []string{"d"}},
+		{Id: "d"},
+	}
+	resolver.BuildDependencies()
+
+	tests := []struct {
+		maxDepth int
+		expected [][]string
+	}{
+		{0, [][]string{{"a", "b", "c", "d"}, {"a", "c", "d"}, {"a", "d"}}},
+		{2, [][]string{{"a", "c", "d"}, {"a", "d"}}},
+		{1, [][]string{{"a", "d"}}},
+	}
+	for _, tt := range tests {
+		paths, err := resolver.AllPaths("a", "d", tt.maxDepth)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(paths, tt.expected) {
+			t.Errorf("maxDepth %d: expected %v, got %v", tt.maxDepth, tt.expected, paths)
+		}
+	}
+
+	if paths, err := resolver.AllPaths("d", "a", 0); err != nil || len(paths) != 0 {
+		t.Errorf("Expected no paths, got %v (%v)", paths, err)
+	}
+	if _, err := resolver.AllPaths("a", "x", 0); err == nil {
+		t.Errorf("Expected an error for an unknown resource")
+	}
+
+	resolver.MaxPaths = 2
+	paths, err := resolver.AllPaths("a", "d", 0)
+	if err == nil || !strings.Contains(err.Error(), "more than 2 dependency paths") {
+		t.Errorf("Expected a path limit error, got %v", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("Expected the first 2 paths, got %v", paths)
+	}
+}
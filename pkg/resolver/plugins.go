@@ -0,0 +1,197 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pluginPrefix starts the name of the executable of a runner plugin, as in
+// runner-terraform for resources declaring 'runner: terraform'.
+const pluginPrefix = "runner-"
+
+// PluginProtocolVersion is the version of the protocol spoken with runner
+// plugins, sent in every PluginRequest.
+const PluginProtocolVersion = 1
+
+// PluginRequest is sent as JSON on the standard input of a runner plugin,
+// which executes the steps of one phase of a resource: preRun, run, postRun
+// or rollback. The plugin runs in the current directory, like shell steps,
+// reports success by exiting with status 0, and its output is logged like the
+// output of a step.
+type PluginRequest struct {
+	Version  int               `json:"version"`
+	Phase    string            `json:"phase"`
+	Resource ResourceNodeEntry `json:"resource"`
+	Steps    []PluginStep      `json:"steps"`
+	WorkDir  string            `json:"workDir,omitempty"`
+}
+
+// PluginStep is a step of a resource handed to a runner plugin, with the
+// environment variables declared with a value.
+type PluginStep struct {
+	Name string            `json:"name"`
+	Exec string            `json:"exec,omitempty"`
+	Env  map[string]string `json:"env,omitempty"`
+}
+
+// PluginNotFoundError is returned when no executable is found for the runner
+// plugin of a resource.
+type PluginNotFoundError struct {
+	Resource string
+	Runner   string
+}
+
+func (e *PluginNotFoundError) Error() string {
+	return fmt.Sprintf("runner plugin '%s' of resource '%s' not found: no %s%s executable in the plugin path or PATH", e.Runner, e.Resource, pluginPrefix, e.Runner)
+}
+
+// runnerPattern is what the names of runner plugins must match, so that they
+// name an executable of the plugin path rather than a path.
+var runnerPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// InvalidRunnerError is returned when loading or adding a resource whose
+// runner is not a plain plugin name.
+type InvalidRunnerError struct {
+	Resource string
+	Runner   string
+	Source   string
+}
+
+func (e *InvalidRunnerError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("invalid runner '%s' of resource '%s': runner names must match %s", e.Runner, e.Resource, runnerPattern)
+	}
+	return fmt.Sprintf("invalid runner '%s' of resource '%s' in %s: runner names must match %s", e.Runner, e.Resource, e.Source, runnerPattern)
+}
+
+// checkRunner returns an InvalidRunnerError when the entry, read from source
+// when known, declares a runner that is not a plain plugin name.
+func checkRunner(entry ResourceNodeEntry, source string) error {
+	if entry.Runner != "" && !runnerPattern.MatchString(entry.Runner) {
+		return &InvalidRunnerError{Resource: entry.Id, Runner: entry.Runner, Source: source}
+	}
+	return nil
+}
+
+// findPlugin returns the path of the executable of the named runner plugin,
+// searching PluginPath before PATH. Names that are not plain plugin names are
+// never found.
+func (dr *DependencyResolver) findPlugin(runner string) (string, bool) {
+	if !runnerPattern.MatchString(runner) {
+		return "", false
+	}
+	name := pluginPrefix + runner
+	for _, dir := range dr.PluginPath {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return path, true
+		}
+	}
+	path, err := exec.LookPath(name)
+	return path, err == nil
+}
+
+// checkPlugins returns an error for the first resource of the stack whose
+// runner plugin cannot be found, so a run fails before executing anything.
+func (dr *DependencyResolver) checkPlugins(stack []string) error {
+	for _, id := range stack {
+		res, ok := dr.GetResource(id)
+		if !ok || res.Runner == "" {
+			continue
+		}
+		if _, found := dr.findPlugin(res.Runner); !found {
+			return &PluginNotFoundError{Resource: id, Runner: res.Runner}
+		}
+	}
+	return nil
+}
+
+// runPhase executes the steps of a phase of a resource with its runner
+// plugin when it declares a Runner, or with the handler of its Type. The
+// phase is read from ctx.
+func (dr *DependencyResolver) runPhase(ctx context.Context, res ResourceNodeEntry, steps []RunStep, logs *RunnerLogs, client *http.Client) error {
+	ctx = withEntry(ctx, res)
+	if res.Runner != "" {
+		if len(steps) == 0 {
+			return nil
+		}
+		return dr.runPlugin(ctx, res, steps, logs)
+	}
+
+	handler, ok := dr.typeHandler(res.Type)
+	if !ok {
+		dr.mu.RLock()
+		known := dr.typeNames()
+		dr.mu.RUnlock()
+		return &UnknownTypeError{Resource: res.Id, Type: res.Type, Known: known}
+	}
+	if handler == nil {
+		return dr.runSteps(ctx, steps, res.Id, logs, client)
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	return dr.runType(ctx, handler, res, steps, logs, client)
+}
+
+// runPlugin hands the steps of a phase of a resource to its runner plugin.
+func (dr *DependencyResolver) runPlugin(ctx context.Context, res ResourceNodeEntry, steps []RunStep, logs *RunnerLogs) error {
+	path, found := dr.findPlugin(res.Runner)
+	if !found {
+		return &PluginNotFoundError{Resource: res.Id, Runner: res.Runner}
+	}
+
+	phase := phaseOf(ctx)
+	request := PluginRequest{
+		Version:  PluginProtocolVersion,
+		Phase:    phase,
+		Resource: res,
+		WorkDir:  dr.WorkDir,
+	}
+	for _, step := range steps {
+		pluginStep := PluginStep{Name: step.Name, Exec: step.Exec}
+		for _, env := range step.Env {
+			if env.Value != "" {
+				if pluginStep.Env == nil {
+					pluginStep.Env = make(map[string]string)
+				}
+				pluginStep.Env[env.Name] = env.Value
+			}
+		}
+		request.Steps = append(request.Steps, pluginStep)
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	fields := entryFields(res, phase, "runner", res.Runner)
+	dr.logger().Info("Running plugin", fields...)
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, phase)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+
+	logs.Add(StepLog{
+		targetRes: res.Id,
+		command:   path + " " + phase,
+		id:        res.Id,
+		name:      pluginPrefix + res.Runner,
+		message:   strings.TrimRight(output.String(), "\n"),
+	})
+	if err != nil {
+		return dr.logError("Plugin failed", fmt.Errorf("runner plugin '%s' failed: %w", res.Runner, err), fields...)
+	}
+	return nil
+}
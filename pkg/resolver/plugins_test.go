@@ -0,0 +1,151 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// writePlugin writes an executable runner plugin running script, and returns
+// its directory.
+func writePlugin(t *testing.T, name, script string) string {
+	dir := t.TempDir()
+	path := filepath.Join(dir, pluginPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	return dir
+}
+
+func TestPlugin_Run(t *testing.T) {
+	requests := filepath.Join(t.TempDir(), "requests")
+	dir := writePlugin(t, "fake", `cat >> `+requests+`; echo >> `+requests+`; echo "ran $1"`)
+
+	resolver := setupTestResolver()
+	resolver.PluginPath = []string{dir}
+	resolver.StepOutput = &strings.Builder{}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "infra", Runner: "fake",
+			PreRun: []RunStep{{Name: "init", Exec: "init"}},
+			Run:    []RunStep{{Name: "apply", Exec: "apply -auto-approve", Env: []EnvVar{{Name: "TF_VAR_env", Value: "prod"}}}}},
+		{Id: "app", Requires: []string{"infra"}, Run: []RunStep{{Name: "start", Exec: "echo app"}}},
+	}
+	resolver.BuildDependencies()
+
+	results, err := resolver.Execute(context.Background(), []string{"app"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := statuses(results); got["infra"] != StatusSucceeded || got["app"] != StatusSucceeded {
+		t.Errorf("Expected both resources to succeed, got %v", got)
+	}
+
+	data, err := os.ReadFile(requests)
+	if err != nil {
+		t.Fatalf("Failed to read requests: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a request for preRun and run, got %d", len(lines))
+	}
+	var request PluginRequest
+	if err := json.Unmarshal([]byte(lines[1]), &request); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	if request.Version != PluginProtocolVersion || request.Phase != "run" || request.Resource.Id != "infra" {
+		t.Errorf("Unexpected request: %+v", request)
+	}
+	if len(request.Steps) != 1 || request.Steps[0].Exec != "apply -auto-approve" || request.Steps[0].Env["TF_VAR_env"] != "prod" {
+		t.Errorf("Unexpected steps: %+v", request.Steps)
+	}
+	if output := resolver.StepOutput.(*strings.Builder).String(); !strings.Contains(output, "ran run") {
+		t.Errorf("Expected the output of the plugin to be logged, got %q", output)
+	}
+}
+
+func TestPlugin_Failure(t *testing.T) {
+	dir := writePlugin(t, "broken", `echo "no state"; exit 3`)
+
+	resolver := setupTestResolver()
+	resolver.PluginPath = []string{dir}
+	resolver.StepOutput = &strings.Builder{}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "infra", Runner: "broken", Run: []RunStep{{Name: "apply"}}},
+	}
+	resolver.BuildDependencies()
+
+	results, err := resolver.Execute(context.Background(), []string{"infra"})
+	if err == nil || !strings.Contains(err.Error(), "runner plugin 'broken' failed") {
+		t.Errorf("Expected the plugin to fail, got %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusFailed {
+		t.Errorf("Expected infra to fail, got %+v", results)
+	}
+}
+
+func TestPlugin_Retry(t *testing.T) {
+	attempts := filepath.Join(t.TempDir(), "attempts")
+	dir := writePlugin(t, "flaky", `echo x >> `+attempts+`; [ $(wc -l < `+attempts+`) -ge 2 ] || exit 75`)
+
+	resolver := setupTestResolver()
+	resolver.PluginPath = []string{dir}
+	resolver.StepOutput = &strings.Builder{}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "infra", Runner: "flaky", Run: []RunStep{{Name: "apply"}}, Retry: &RetryPolicy{Attempts: 3, ExitCodes: []int{75}}},
+	}
+	resolver.BuildDependencies()
+
+	if _, err := resolver.Execute(context.Background(), []string{"infra"}); err != nil {
+		t.Errorf("Expected the retry to succeed, got %v", err)
+	}
+}
+
+func TestPlugin_NotFound(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.PluginPath = []string{t.TempDir()}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "first", Run: []RunStep{{Name: "run", Exec: "echo first"}}},
+		{Id: "infra", Runner: "missing", Requires: []string{"first"}, Run: []RunStep{{Name: "apply"}}},
+	}
+	resolver.BuildDependencies()
+
+	results, err := resolver.Execute(context.Background(), []string{"infra"})
+	var notFound *PluginNotFoundError
+	if !errors.As(err, &notFound) || notFound.Resource != "infra" || notFound.Runner != "missing" {
+		t.Fatalf("Expected a PluginNotFoundError, got %v", err)
+	}
+	if results != nil {
+		t.Errorf("Expected nothing to run, got %+v", results)
+	}
+}
+
+func TestPlugin_InvalidRunner(t *testing.T) {
+	for _, runner := range []string{"/../../../tmp/x", "../x", "Terraform", "-x", "a/b", "${RUNNER}"} {
+		resolver := setupTestResolver()
+		resolver.LookupEnv = func(name string) (string, bool) { return "/tmp/x", name == "RUNNER" }
+		afero.WriteFile(resolver.Fs, "catalog.yaml", []byte("resources:\n  - id: infra\n    runner: \""+runner+"\"\n"), 0644)
+
+		var invalid *InvalidRunnerError
+		if err := resolver.LoadResourceEntries("catalog.yaml"); !errors.As(err, &invalid) || invalid.Resource != "infra" {
+			t.Errorf("%s: expected an InvalidRunnerError, got %v", runner, err)
+		}
+		if _, found := resolver.findPlugin(runner); found {
+			t.Errorf("%s: expected the plugin not to be found", runner)
+		}
+		if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "added", Runner: runner}); !errors.As(err, &invalid) {
+			t.Errorf("%s: expected an InvalidRunnerError, got %v", runner, err)
+		}
+	}
+
+	resolver := setupTestResolver()
+	afero.WriteFile(resolver.Fs, "catalog.yaml", []byte("resources:\n  - id: infra\n    runner: terraform_1-x\n"), 0644)
+	if err := resolver.LoadResourceEntries("catalog.yaml"); err != nil {
+		t.Errorf("Expected a plain runner name to load, got %v", err)
+	}
+}
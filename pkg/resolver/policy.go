@@ -0,0 +1,295 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FailurePolicy controls how a run continues after a resource fails.
+type FailurePolicy string
+
+const (
+	// FailFast stops the run at the first failure.
+	FailFast FailurePolicy = "fail-fast"
+	// SkipDependents skips the resources depending on a failed resource, and
+	// keeps running the independent ones.
+	SkipDependents FailurePolicy = "skip-dependents"
+	// ContinueAll runs every resource regardless of failures.
+	ContinueAll FailurePolicy = "continue"
+)
+
+// FailurePolicies lists the supported failure policies.
+var FailurePolicies = []string{string(FailFast), string(SkipDependents), string(ContinueAll)}
+
+// ValidateFailurePolicy returns an error for an unsupported failure policy.
+// An empty policy is FailFast.
+func ValidateFailurePolicy(policy FailurePolicy) error {
+	switch policy {
+	case "", FailFast, SkipDependents, ContinueAll:
+		return nil
+	}
+	return fmt.Errorf("unknown failure policy '%s', expected one of: %s", policy, strings.Join(FailurePolicies, ", "))
+}
+
+// ResourceStatus is the outcome of a resource in a run.
+type ResourceStatus string
+
+const (
+	StatusSucceeded ResourceStatus = "succeeded"
+	StatusFailed    ResourceStatus = "failed"
+	StatusSkipped   ResourceStatus = "skipped"
+)
+
+// ResourceResult is the outcome of a resource in a run, with the error of a
+// failed resource.
+type ResourceResult struct {
+	Id     string         `json:"id" yaml:"id"`
+	Status ResourceStatus `json:"status" yaml:"status"`
+	Error  string         `json:"error,omitempty" yaml:"error,omitempty"`
+	// Resumed is set for resources completed by the resumed run.
+	Resumed bool `json:"resumed,omitempty" yaml:"resumed,omitempty"`
+	// RolledBack is set for completed resources rolled back after a failure.
+	RolledBack bool `json:"rolledBack,omitempty" yaml:"rolledBack,omitempty"`
+	// UpToDate is set for resources not executed because they did not change
+	// since their last successful run.
+	UpToDate bool `json:"upToDate,omitempty" yaml:"upToDate,omitempty"`
+}
+
+// Execute resolves the given targets and executes every resource in
+// dependency order, following the resolver's FailurePolicy. It returns the
+// result of every resource, in execution order, and the errors of the failed
+// resources. Resources not run because of a failure or because ctx is done are
+// reported as skipped.
+//
+// When CheckpointFile is set, the state of the run is saved to it after every
+// resource, and removed once the run succeeds. With Resume, the resources
+// completed by the saved run are not executed again.
+//
+// When RecordsFile is set, the outcome of every executed resource is recorded
+// to it, with the fingerprint of its definition and inputs, for Status. A
+// resource whose fingerprint matches its last successful run is not executed
+// again, unless one of its requirements is, or Force is set.
+//
+// With RollbackOnFailure, the completed resources are rolled back in reverse
+// order when any resource fails.
+//
+// Resources declaring a Runner are executed by their runner plugin, found in
+// PluginPath or PATH, and the others by the handler of their Type. The run
+// fails before executing anything when a plugin or a handler is missing.
+//
+// The progress of the run is sent to the listeners registered with OnEvent,
+// and to the Webhooks.
+func (dr *DependencyResolver) Execute(ctx context.Context, targets []string) (results []ResourceResult, err error) {
+	if err := ValidateFailurePolicy(dr.FailurePolicy); err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracer.Start(ctx, "run", trace.WithAttributes(attrTargets.StringSlice(targets)))
+	defer func() { endSpan(span, err) }()
+
+	stack, err := dr.Resolve(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+	if err := dr.checkStackTypes(stack); err != nil {
+		return nil, err
+	}
+	if err := dr.checkPlugins(stack); err != nil {
+		return nil, err
+	}
+	checkpoint, err := dr.resumeCheckpoint(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	dr.emit(Event{Type: RunStarted, Resources: append([]string(nil), stack...)})
+	results, err = dr.executeStack(ctx, targets, stack, checkpoint)
+	dr.emit(Event{Type: RunCompleted, Duration: time.Since(start), Err: err, Results: results})
+	// Deliver the events of the run before returning, as the program may
+	// exit right after.
+	dr.deliveries.wait()
+	return results, err
+}
+
+// executeStack executes the resources of a resolved run, resuming from the
+// checkpoint unless it is nil.
+func (dr *DependencyResolver) executeStack(ctx context.Context, targets, stack []string, checkpoint *Checkpoint) ([]ResourceResult, error) {
+	records, err := dr.LoadRecords()
+	if err != nil {
+		return nil, err
+	}
+	state := newExecutionState(targets, stack, dr.stepOutput())
+	// Close the log after all processing is done.
+	defer state.logs.Close()
+
+	client := &http.Client{}
+	var results []ResourceResult
+	var errs []error
+	// unsuccessful holds the resources that failed or were skipped.
+	unsuccessful := make(map[string]bool)
+	halted := false
+	// failed is the last resource that failed, with its error.
+	var failed string
+	var failure error
+	// executed holds the resources executed by the run, or by the resumed
+	// one, whose dependents are never up to date.
+	executed := make(map[string]bool)
+	waves := dr.waves(stack)
+
+	for _, resNode := range stack {
+		res, ok := dr.GetResource(resNode)
+		if !ok {
+			continue
+		}
+
+		if !halted {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+				halted = true
+			}
+		}
+		if checkpoint != nil && slices.Contains(checkpoint.Completed, resNode) {
+			state.logs.restore(resNode, checkpoint.Outputs[resNode])
+			state.complete(resNode)
+			executed[resNode] = true
+			results = append(results, ResourceResult{Id: resNode, Status: StatusSucceeded, Resumed: true})
+			_, span := startResourceSpan(ctx, res, waves[resNode])
+			span.SetAttributes(attrResult.String(string(StatusSucceeded)), attrResumed.Bool(true))
+			span.End()
+			dr.emit(Event{Type: ResourceFinished, Resource: resNode})
+			continue
+		}
+		if halted || (dr.FailurePolicy == SkipDependents && dependsOnAny(dr.DirectDependencies(resNode), unsuccessful)) {
+			unsuccessful[resNode] = true
+			results = append(results, ResourceResult{Id: resNode, Status: StatusSkipped})
+			_, span := startResourceSpan(ctx, res, waves[resNode])
+			span.SetAttributes(attrResult.String(string(StatusSkipped)))
+			span.End()
+			dr.emit(Event{Type: ResourceSkipped, Resource: resNode})
+			continue
+		}
+		upToDate, err := dr.upToDate(records, res, executed)
+		if err != nil {
+			return results, err
+		}
+		if upToDate {
+			results = append(results, ResourceResult{Id: resNode, Status: StatusSucceeded, UpToDate: true})
+			_, span := startResourceSpan(ctx, res, waves[resNode])
+			span.SetAttributes(attrResult.String(string(StatusSucceeded)), attrUpToDate.Bool(true))
+			span.End()
+			dr.emit(Event{Type: ResourceFinished, Resource: resNode})
+			continue
+		}
+		executed[resNode] = true
+
+		dr.emit(Event{Type: ResourceStarted, Resource: resNode})
+		start := time.Now()
+		resCtx, span := startResourceSpan(ctx, res, waves[resNode])
+		err = dr.executeResource(resCtx, res, state, client)
+		span.SetAttributes(attrResult.String(string(resultStatus(err))))
+		endSpan(span, err)
+		if err != nil {
+			resourceFailures.Inc(resNode)
+			unsuccessful[resNode] = true
+			if ctx.Err() != nil {
+				// The run was interrupted, rather than failed by the
+				// resource.
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, &ExecutionError{Id: resNode, Err: err})
+			}
+			results = append(results, ResourceResult{Id: resNode, Status: StatusFailed, Error: err.Error()})
+			halted = dr.FailurePolicy == "" || dr.FailurePolicy == FailFast
+			failed, failure = resNode, err
+			dr.emit(Event{Type: ResourceFailed, Resource: resNode, Duration: time.Since(start), Err: err})
+		} else {
+			results = append(results, ResourceResult{Id: resNode, Status: StatusSucceeded})
+			dr.emit(Event{Type: ResourceFinished, Resource: resNode, Duration: time.Since(start)})
+		}
+		if err := dr.recordResult(records, res, err); err != nil {
+			return results, err
+		}
+		if err := dr.saveCheckpoint(state, failed, failure); err != nil {
+			return results, err
+		}
+	}
+
+	if len(errs) == 0 {
+		return results, dr.clearCheckpoint()
+	}
+
+	if dr.RollbackOnFailure {
+		rolledBack, rollbackErrs := dr.rollback(ctx, state, client)
+		for i := range results {
+			results[i].RolledBack = slices.Contains(rolledBack, results[i].Id)
+		}
+		errs = append(errs, rollbackErrs...)
+		if err := dr.saveCheckpoint(state, failed, failure); err != nil {
+			return results, err
+		}
+	}
+
+	if len(errs) == 1 {
+		return results, errs[0]
+	}
+	return results, errors.Join(errs...)
+}
+
+// resultStatus returns the status of a resource executed with the given error.
+func resultStatus(err error) ResourceStatus {
+	if err != nil {
+		return StatusFailed
+	}
+	return StatusSucceeded
+}
+
+func dependsOnAny(deps []string, ids map[string]bool) bool {
+	for _, dep := range deps {
+		if ids[dep] {
+			return true
+		}
+	}
+	return false
+}
+
+// ResultsTable returns the results of a run as a table.
+func ResultsTable(results []ResourceResult) Table {
+	table := Table{Header: []string{"RESOURCE", "STATUS", "ERROR"}}
+	for _, result := range results {
+		status := string(result.Status)
+		if result.RolledBack {
+			status += " (rolled back)"
+		}
+		if result.UpToDate {
+			status += " (up to date)"
+		}
+		table.Rows = append(table.Rows, []string{result.Id, status, result.Error})
+	}
+	return table
+}
+
+// resultsPorcelain returns the porcelain records of the results of a run.
+func resultsPorcelain(results []ResourceResult) Porcelain {
+	return Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(results))
+		for _, result := range results {
+			status := string(result.Status)
+			if result.RolledBack {
+				status += " (rolled back)"
+			}
+			if result.UpToDate {
+				status += " (up to date)"
+			}
+			records = append(records, []string{result.Id, status, result.Error})
+		}
+		return records
+	}}
+}
@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupPolicyResolver returns a resolver where 'broken' fails, 'app' depends
+// on it, and 'docs' is independent of both.
+func setupPolicyResolver(policy FailurePolicy) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "broken", Run: []RunStep{{Name: "fail", Exec: "exit 1"}}},
+		{Id: "app", Requires: []string{"broken"}, Run: []RunStep{{Name: "start", Exec: "echo app"}}},
+		{Id: "docs", Run: []RunStep{{Name: "build", Exec: "echo docs"}}},
+		{Id: "all", Requires: []string{"app", "docs"}},
+	}
+	resolver.FailurePolicy = policy
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func statuses(results []ResourceResult) map[string]ResourceStatus {
+	byId := make(map[string]ResourceStatus, len(results))
+	for _, result := range results {
+		byId[result.Id] = result.Status
+	}
+	return byId
+}
+
+func TestExecute_Policies(t *testing.T) {
+	tests := []struct {
+		policy   FailurePolicy
+		expected map[string]ResourceStatus
+	}{
+		{FailFast, map[string]ResourceStatus{"broken": StatusFailed, "app": StatusSkipped, "docs": StatusSkipped, "all": StatusSkipped}},
+		{SkipDependents, map[string]ResourceStatus{"broken": StatusFailed, "app": StatusSkipped, "docs": StatusSucceeded, "all": StatusSkipped}},
+		{ContinueAll, map[string]ResourceStatus{"broken": StatusFailed, "app": StatusSucceeded, "docs": StatusSucceeded, "all": StatusSucceeded}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			resolver := setupPolicyResolver(tt.policy)
+			results, err := resolver.Execute(context.Background(), []string{"all"})
+			if err == nil {
+				t.Error("Expected the failure of 'broken' to be returned")
+			}
+			if got := statuses(results); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExecute_FailedError(t *testing.T) {
+	resolver := setupPolicyResolver(ContinueAll)
+	results, _ := resolver.Execute(context.Background(), []string{"broken"})
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("Expected the error of the failed resource in its result, got %+v", results)
+	}
+}
+
+func TestExecute_UnknownPolicy(t *testing.T) {
+	resolver := setupPolicyResolver("retry-forever")
+	if _, err := resolver.Execute(context.Background(), []string{"all"}); err == nil || !strings.Contains(err.Error(), "unknown failure policy") {
+		t.Errorf("Expected an unknown policy error, got %v", err)
+	}
+}
+
+func TestHandleRunCommand_Report(t *testing.T) {
+	resolver := setupPolicyResolver(SkipDependents)
+	output := captureOutput(func() {
+		if err := resolver.HandleRunCommand([]string{"all"}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	for _, expected := range []string{"❌ broken:", "⏭️  app (skipped)", "✅ docs"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in the report, got %q", expected, output)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// PorcelainV1 is the first version of the porcelain output.
+const PorcelainV1 = "v1"
+
+// PorcelainVersions lists the supported versions of the porcelain output.
+// A version, once released, never changes: new fields are only appended to
+// its records, and any other change makes a new version.
+var PorcelainVersions = []string{PorcelainV1}
+
+// ValidatePorcelain returns an error for unsupported porcelain versions.
+func ValidatePorcelain(version string) error {
+	for _, supported := range PorcelainVersions {
+		if version == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported porcelain version '%s', expected one of %s", version, strings.Join(PorcelainVersions, ", "))
+}
+
+// porcelainEscaper escapes the separators of the porcelain records in their
+// fields.
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// WritePorcelainRecord writes the fields as a porcelain record: a line of
+// tab-separated fields, escaping backslashes, tabs and line breaks.
+func WritePorcelainRecord(w io.Writer, fields ...string) error {
+	escaped := make([]string, len(fields))
+	for i, field := range fields {
+		escaped[i] = porcelainEscaper.Replace(field)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(escaped, "\t"))
+	return err
+}
+
+// Porcelain holds the builders of the porcelain records of a command, keyed
+// by the version of the porcelain output. The records are built apart from
+// the table of the command, which stays free to change between releases.
+type Porcelain map[string]func() [][]string
+
+// RenderPorcelain writes the records built for the given version as porcelain
+// records.
+func RenderPorcelain(w io.Writer, porcelain Porcelain, version string) error {
+	if err := ValidatePorcelain(version); err != nil {
+		return err
+	}
+	records, ok := porcelain[version]
+	if !ok {
+		return fmt.Errorf("porcelain version '%s' is not supported by this command", version)
+	}
+	for _, record := range records() {
+		if err := WritePorcelainRecord(w, record...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourcePorcelain returns the porcelain records of the given resources.
+func resourcePorcelain(entries []ResourceNodeEntry) Porcelain {
+	return Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(entries))
+		for _, entry := range entries {
+			records = append(records, []string{entry.Id, entry.Name, entry.Category, strings.Join(entry.Requires, ","), entry.Desc})
+		}
+		return records
+	}}
+}
+
+// pairPorcelain returns the porcelain records pairing every resource, in the
+// given order, with each of its values.
+func pairPorcelain(resources []string, values map[string][]string) Porcelain {
+	return Porcelain{PorcelainV1: func() [][]string {
+		var records [][]string
+		for _, res := range resources {
+			for _, value := range values[res] {
+				records = append(records, []string{res, value})
+			}
+		}
+		return records
+	}}
+}
+
+// PrintStatus reports the outcome of a command changing files: the message,
+// formatted like PrintMessage, or the record with Porcelain set.
+func (dr *DependencyResolver) PrintStatus(record []string, format string, a ...interface{}) {
+	if dr.Porcelain != "" {
+		WritePorcelainRecord(os.Stdout, record...)
+		return
+	}
+	PrintMessage(format, a...)
+}
@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestValidatePorcelain(t *testing.T) {
+	if err := ValidatePorcelain(PorcelainV1); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := ValidatePorcelain("v0"); err == nil {
+		t.Error("Expected an unsupported version to fail")
+	}
+}
+
+func TestWritePorcelainRecord(t *testing.T) {
+	var buf bytes.Buffer
+	WritePorcelainRecord(&buf, "a", "", "tab\there", "two\nlines", `back\slash`)
+	if expected := "a\t\ttab\\there\ttwo\\nlines\tback\\\\slash\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestRenderPorcelain(t *testing.T) {
+	porcelain := Porcelain{PorcelainV1: func() [][]string {
+		return [][]string{{"app", "db"}, {"app", "cache"}}
+	}}
+	var buf bytes.Buffer
+	if err := RenderPorcelain(&buf, porcelain, PorcelainV1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "app\tdb\napp\tcache\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+	if err := RenderPorcelain(&buf, Porcelain{}, PorcelainV1); err == nil {
+		t.Error("Expected a version without records to fail")
+	}
+	if err := RenderPorcelain(&buf, porcelain, "v0"); err == nil {
+		t.Error("Expected an unsupported version to fail")
+	}
+}
+
+// TestPorcelainV1 pins the records of the porcelain output: once released,
+// the fields of a version only ever get new fields appended.
+func TestPorcelainV1(t *testing.T) {
+	resolver := setupDiamondResolver()
+	resolver.Resources[0].Name = "App"
+	resolver.Resources[0].Desc = "Serves\tthe app"
+	resolver.Resources[0].Category = "web"
+	resolver.Resources[3].Owners = []string{"team-db"}
+	resolver.Resources[3].License = "MIT"
+	resolver.BuildDependencies()
+	resolver.Porcelain = PorcelainV1
+	resolver.OutputFormat = FormatJSON // Porcelain takes precedence.
+
+	tests := []struct {
+		name     string
+		run      func() error
+		expected string
+	}{
+		{"index", resolver.HandleIndexCommand, "A\tApp\tweb\tB,C\tServes\\tthe app\nB\t\t\tD\t\nC\t\t\tD\t\nD\t\t\t\t\n"},
+		{"show", func() error { return resolver.HandleShowCommand([]string{"D"}) }, "D\t\t\t\t\n"},
+		{"depends", func() error { return resolver.HandleDependsCommand([]string{"A"}) }, "A\tB\nA\tC\nA\tD\n"},
+		{"rdepends", func() error { return resolver.HandleRDependsCommand([]string{"D"}) }, "D\tB\nD\tC\nD\tA\n"},
+		{"tree", func() error { return resolver.HandleTreeCommand([]string{"B"}) }, "B\tB <- D\n"},
+		{"levels", func() error { return resolver.HandleLevelsCommand([]string{"A"}) }, "A\t1\tD\nA\t2\tB, C\nA\t3\tA\n"},
+		{"why", func() error { return resolver.HandleWhyCommand("D", "A") }, "A -> B -> D\nA -> C -> D\n"},
+		{"roots", resolver.HandleRootsCommand, "A\n"},
+		{"impact", func() error { return resolver.HandleImpactCommand([]string{"B"}) }, "B\tdirect\tA\n"},
+		{"owners", func() error { return resolver.HandleOwnersCommand("A") }, "team-db\tD\n(none)\tA\n(none)\tB\n(none)\tC\n"},
+		{"licenses", func() error { return resolver.HandleLicensesCommand("A", nil) }, "MIT\tD\n(none)\tA\n(none)\tB\n(none)\tC\n"},
+		{"search", func() error { return resolver.HandleSearchCommand([]string{"App"}) }, "A\tApp\tweb\tB,C\tServes\\tthe app\n"},
+		{"cycles", resolver.HandleCyclesCommand, ""},
+		{"stats", resolver.HandleStatsCommand, "resources\t4\nedges\t4\nmax_depth\t2\naverage_fan_in\t1.00\naverage_fan_out\t1.00\nlargest_closure\tA\t3\ncategories\t1\nmost_depended\tD\t3\nmost_depended\tB\t1\nmost_depended\tC\t1\n"},
+	}
+	for _, test := range tests {
+		var err error
+		output := captureOutput(func() { err = test.run() })
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if output != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, output)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AmbiguousProviderError is returned when a virtual resource has more than one
+// provider and no preference has been configured.
+type AmbiguousProviderError struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *AmbiguousProviderError) Error() string {
+	return fmt.Sprintf("virtual resource '%s' has multiple providers: %s", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// Providers returns the sorted IDs of the resources that provide the given name.
+func (dr *DependencyResolver) Providers(name string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.providers(name)
+}
+
+func (dr *DependencyResolver) providers(name string) []string {
+	var candidates []string
+	for _, entry := range dr.Resources {
+		for _, provided := range entry.Provides {
+			if provided == name {
+				candidates = append(candidates, entry.Id)
+				break
+			}
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// ResolveProvider maps a requirement to the resource that satisfies it. Concrete
+// resources resolve to themselves, and aliases to the resource declaring them.
+// For virtual resources, ProviderPreferences is consulted first, then the
+// SelectProvider hook. When several providers remain, the first one in sorted
+// order is returned along with an AmbiguousProviderError.
+// The SelectProvider hook runs under the resolver's read lock and must not call
+// back into the resolver.
+func (dr *DependencyResolver) ResolveProvider(name string) (string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.resolveProvider(name)
+}
+
+func (dr *DependencyResolver) resolveProvider(name string) (string, error) {
+	if dr.resourceIndexOf(name) >= 0 {
+		return name, nil
+	}
+	if id := dr.aliasOf(name); id != "" {
+		return id, nil
+	}
+
+	candidates := dr.providers(name)
+	if len(candidates) == 0 {
+		return name, nil
+	}
+
+	if preferred, ok := dr.ProviderPreferences[name]; ok {
+		for _, candidate := range candidates {
+			if candidate == preferred {
+				return preferred, nil
+			}
+		}
+		return name, fmt.Errorf("preferred provider '%s' does not provide '%s'", preferred, name)
+	}
+
+	if dr.SelectProvider != nil {
+		return dr.SelectProvider(name, candidates)
+	}
+
+	if len(candidates) > 1 {
+		return candidates[0], &AmbiguousProviderError{Name: name, Candidates: candidates}
+	}
+	return candidates[0], nil
+}
+
+// providerFor is like ResolveProvider but never fails, so the graph can still be
+// built while a virtual resource is ambiguous.
+func (dr *DependencyResolver) providerFor(name string, known map[string]bool) string {
+	if known[name] {
+		return name
+	}
+	provider, _ := dr.resolveProvider(name)
+	return provider
+}
+
+// checkProviders verifies that every virtual requirement in the closure of the
+// targets maps to exactly one provider.
+func (dr *DependencyResolver) checkProviders(ctx context.Context, targets []string) error {
+	paths, err := dr.dependencyPaths(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range dr.Resources {
+		if _, included := paths[entry.Id]; !included {
+			continue
+		}
+		for _, req := range append(entry.Requires[:len(entry.Requires):len(entry.Requires)], entry.Optional...) {
+			if _, err := dr.resolveProvider(req); err != nil {
+				return fmt.Errorf("resolving requirement of '%s': %w", entry.Id, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func setupProvidersResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "postgres", Name: "PostgreSQL", Provides: []string{"database"}},
+		{Id: "mysql", Name: "MySQL", Provides: []string{"database"}},
+		{Id: "redis", Name: "Redis", Provides: []string{"cache"}},
+		{Id: "backend", Name: "Backend", Requires: []string{"database", "cache"}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestProviders(t *testing.T) {
+	resolver := setupProvidersResolver()
+
+	candidates := resolver.Providers("database")
+	if strings.Join(candidates, ",") != "mysql,postgres" {
+		t.Errorf("Expected providers [mysql postgres], got %v", candidates)
+	}
+	if candidates := resolver.Providers("unknown"); len(candidates) != 0 {
+		t.Errorf("Expected no providers, got %v", candidates)
+	}
+}
+
+func TestResolveProvider(t *testing.T) {
+	resolver := setupProvidersResolver()
+
+	if provider, err := resolver.ResolveProvider("cache"); err != nil || provider != "redis" {
+		t.Errorf("Expected provider 'redis', got '%s' (%v)", provider, err)
+	}
+	if provider, err := resolver.ResolveProvider("postgres"); err != nil || provider != "postgres" {
+		t.Errorf("Expected concrete resource to resolve to itself, got '%s' (%v)", provider, err)
+	}
+
+	_, err := resolver.ResolveProvider("database")
+	var ambiguousErr *AmbiguousProviderError
+	if !errors.As(err, &ambiguousErr) {
+		t.Fatalf("Expected an AmbiguousProviderError, got %v", err)
+	}
+	if len(ambiguousErr.Candidates) != 2 {
+		t.Errorf("Expected 2 candidates, got %v", ambiguousErr.Candidates)
+	}
+
+	resolver.ProviderPreferences["database"] = "postgres"
+	if provider, err := resolver.ResolveProvider("database"); err != nil || provider != "postgres" {
+		t.Errorf("Expected preferred provider 'postgres', got '%s' (%v)", provider, err)
+	}
+
+	resolver.ProviderPreferences["database"] = "redis"
+	if _, err := resolver.ResolveProvider("database"); err == nil {
+		t.Errorf("Expected an error for a preference that does not provide 'database'")
+	}
+}
+
+func TestResolveProvider_SelectHook(t *testing.T) {
+	resolver := setupProvidersResolver()
+	resolver.SelectProvider = func(name string, candidates []string) (string, error) {
+		return candidates[len(candidates)-1], nil
+	}
+
+	if provider, err := resolver.ResolveProvider("database"); err != nil || provider != "postgres" {
+		t.Errorf("Expected hook to select 'postgres', got '%s' (%v)", provider, err)
+	}
+}
+
+func TestResolve_VirtualResources(t *testing.T) {
+	resolver := setupProvidersResolver()
+
+	if deps := resolver.ResourceDependencies["backend"]; strings.Join(deps, ",") != "mysql,redis" {
+		t.Errorf("Expected dependencies [mysql redis], got %v", deps)
+	}
+
+	if _, err := resolver.Resolve(context.Background(), []string{"backend"}); err == nil {
+		t.Errorf("Expected ambiguous provider error")
+	}
+
+	resolver.ProviderPreferences["database"] = "postgres"
+	resolver.BuildDependencies()
+
+	stack, err := resolver.Resolve(context.Background(), []string{"backend"})
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if strings.Join(stack, ",") != "postgres,redis,backend" {
+		t.Errorf("Expected order [postgres redis backend], got %v", stack)
+	}
+}
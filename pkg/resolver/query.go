@@ -0,0 +1,157 @@
+package resolver
+
+// ListResources returns a copy of the loaded resource entries.
+func (dr *DependencyResolver) ListResources() []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return append([]ResourceNodeEntry(nil), dr.Resources...)
+}
+
+// GetResource returns the resource entry with the given ID, normalized
+// following the IDPolicy, or with the given former ID among its aliases, from
+// the Store when it was loaded from it.
+func (dr *DependencyResolver) GetResource(id string) (ResourceNodeEntry, bool) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	id = dr.currentID(dr.IDs.ID(id))
+	if storeID, ok := dr.storeIDs[id]; ok && dr.Store != nil {
+		return dr.storeResource(id, storeID)
+	}
+	return dr.getResource(id)
+}
+
+func (dr *DependencyResolver) getResource(id string) (ResourceNodeEntry, bool) {
+	if i := dr.resourceIndexOf(id); i >= 0 {
+		return dr.Resources[i], true
+	}
+	return ResourceNodeEntry{}, false
+}
+
+// DirectDependencies returns the resources the given resource requires.
+func (dr *DependencyResolver) DirectDependencies(id string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return append([]string(nil), dr.ResourceDependencies[id]...)
+}
+
+// Edges returns every dependency edge of the graph, in catalog order.
+func (dr *DependencyResolver) Edges() []Edge {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	var edges []Edge
+	seen := make(map[string]bool, len(dr.Resources))
+	for _, entry := range dr.Resources {
+		if seen[entry.Id] {
+			continue
+		}
+		seen[entry.Id] = true
+		for _, dep := range dr.ResourceDependencies[entry.Id] {
+			edges = append(edges, Edge{From: entry.Id, To: dep})
+		}
+	}
+	return edges
+}
+
+// TransitiveDependencies returns every resource in the closure of the given
+// resource, excluding the resource itself, in breadth-first order. Closures
+// are cached until the catalog changes.
+func (dr *DependencyResolver) TransitiveDependencies(id string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	closure := dr.cache.memoize(&dr.cache.closures, id, func() []string {
+		return dr.closure(id, 0)
+	})
+	return append([]string(nil), closure...)
+}
+
+// ReverseDependencies returns the resources that directly require the given
+// resource, in catalog order.
+func (dr *DependencyResolver) ReverseDependencies(id string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return append([]string(nil), dr.reverseDependencies()[id]...)
+}
+
+// TransitiveDependents returns every resource that transitively requires the
+// given resource, in breadth-first order. Results are cached until the
+// catalog changes.
+func (dr *DependencyResolver) TransitiveDependents(id string) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	dependents := dr.cache.memoize(&dr.cache.dependents, id, func() []string {
+		return dr.dependentClosure(id, 0)
+	})
+	return append([]string(nil), dependents...)
+}
+
+// DependenciesWithin returns the resources at most maxDepth requirements away
+// from the given resource, in breadth-first order. A maxDepth of 0 returns the
+// whole closure, like TransitiveDependencies.
+func (dr *DependencyResolver) DependenciesWithin(id string, maxDepth int) []string {
+	if maxDepth == 0 {
+		return dr.TransitiveDependencies(id)
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.closure(id, maxDepth)
+}
+
+// DependentsWithin returns the resources requiring the given resource through
+// at most maxDepth requirements, in breadth-first order. A maxDepth of 0
+// returns every dependent, like TransitiveDependents.
+func (dr *DependencyResolver) DependentsWithin(id string, maxDepth int) []string {
+	if maxDepth == 0 {
+		return dr.TransitiveDependents(id)
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.dependentClosure(id, maxDepth)
+}
+
+// Leaves returns the resources without requirements, in catalog order.
+func (dr *DependencyResolver) Leaves() []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	var leaves []string
+	for _, id := range dr.resourceIDs() {
+		if len(dr.ResourceDependencies[id]) == 0 {
+			leaves = append(leaves, id)
+		}
+	}
+	return leaves
+}
+
+// Roots returns the resources no other resource requires, in catalog order.
+func (dr *DependencyResolver) Roots() []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	reverse := dr.reverseDependencies()
+	var roots []string
+	for _, id := range dr.resourceIDs() {
+		if len(reverse[id]) == 0 {
+			roots = append(roots, id)
+		}
+	}
+	return roots
+}
+
+// resourceIDs returns the IDs of the loaded resources in catalog order,
+// without duplicates.
+func (dr *DependencyResolver) resourceIDs() []string {
+	ids := make([]string, 0, len(dr.Resources))
+	seen := make(map[string]bool, len(dr.Resources))
+	for _, entry := range dr.Resources {
+		if !seen[entry.Id] {
+			seen[entry.Id] = true
+			ids = append(ids, entry.Id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetResource(t *testing.T) {
+	resolver := setupTestResolver()
+
+	entry, ok := resolver.GetResource("b")
+	if !ok || entry.Name != "B" {
+		t.Errorf("Expected resource 'b', got %+v", entry)
+	}
+	if _, ok := resolver.GetResource("unknown"); ok {
+		t.Errorf("Expected resource 'unknown' to be missing")
+	}
+}
+
+func TestDirectDependencies(t *testing.T) {
+	resolver := setupTestResolver()
+
+	deps := resolver.DirectDependencies("c")
+	if strings.Join(deps, ",") != "b" {
+		t.Errorf("Expected dependencies [b], got %v", deps)
+	}
+}
+
+func TestTransitiveDependencies(t *testing.T) {
+	resolver := setupTestResolver()
+
+	deps := resolver.TransitiveDependencies("d")
+	if strings.Join(deps, ",") != "c,b,a" {
+		t.Errorf("Expected dependencies [c b a], got %v", deps)
+	}
+	if deps := resolver.TransitiveDependencies("a"); len(deps) != 0 {
+		t.Errorf("Expected no dependencies, got %v", deps)
+	}
+}
+
+func TestReverseDependencies(t *testing.T) {
+	resolver := setupTestResolver()
+
+	deps := resolver.ReverseDependencies("y")
+	if strings.Join(deps, ",") != "z" {
+		t.Errorf("Expected dependents [z], got %v", deps)
+	}
+	if deps := resolver.ReverseDependencies("z"); len(deps) != 0 {
+		t.Errorf("Expected no dependents, got %v", deps)
+	}
+}
+
+func TestTransitiveDependents(t *testing.T) {
+	resolver := setupTestResolver()
+
+	deps := resolver.TransitiveDependents("w")
+	if strings.Join(deps, ",") != "x,y,z" {
+		t.Errorf("Expected dependents [x y z], got %v", deps)
+	}
+}
+
+func TestTransitiveDependencies_CacheInvalidation(t *testing.T) {
+	resolver := setupTestResolver()
+
+	if deps := resolver.TransitiveDependencies("c"); strings.Join(deps, ",") != "b,a" {
+		t.Fatalf("Expected dependencies [b a], got %v", deps)
+	}
+	if _, cached := resolver.cache.closures["c"]; !cached {
+		t.Errorf("Expected the closure of 'c' to be cached")
+	}
+
+	deps := resolver.TransitiveDependencies("c")
+	deps[0] = "modified"
+	if deps := resolver.TransitiveDependencies("c"); deps[0] != "b" {
+		t.Errorf("Expected callers not to be able to modify the cache, got %v", deps)
+	}
+
+	resolver.Resources[1].Requires = nil
+	resolver.BuildDependencies()
+
+	if deps := resolver.TransitiveDependencies("c"); strings.Join(deps, ",") != "b" {
+		t.Errorf("Expected dependencies [b] after the catalog changed, got %v", deps)
+	}
+	if deps := resolver.TransitiveDependents("a"); len(deps) != 0 {
+		t.Errorf("Expected no dependents after the catalog changed, got %v", deps)
+	}
+}
+
+func TestDependenciesWithin(t *testing.T) {
+	resolver := setupChainResolver(5)
+
+	if deps := resolver.DependenciesWithin("n4", 2); !reflect.DeepEqual(deps, []string{"n3", "n2"}) {
+		t.Errorf("Expected [n3 n2], got %v", deps)
+	}
+	if deps := resolver.DependenciesWithin("n4", 0); len(deps) != 4 {
+		t.Errorf("Expected the whole closure, got %v", deps)
+	}
+	if deps := resolver.DependentsWithin("n0", 1); !reflect.DeepEqual(deps, []string{"n1"}) {
+		t.Errorf("Expected [n1], got %v", deps)
+	}
+}
+
+func TestLeavesAndRoots(t *testing.T) {
+	resolver := setupDiamondResolver()
+	resolver.Resources = append(resolver.Resources, ResourceNodeEntry{Id: "E"})
+	resolver.BuildDependencies()
+
+	if leaves := resolver.Leaves(); !reflect.DeepEqual(leaves, []string{"D", "E"}) {
+		t.Errorf("Expected leaves [D E], got %v", leaves)
+	}
+	if roots := resolver.Roots(); !reflect.DeepEqual(roots, []string{"A", "E"}) {
+		t.Errorf("Expected roots [A E], got %v", roots)
+	}
+}
@@ -0,0 +1,567 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QuerySyntaxError is an invalid query. Column is the 1-based position of the
+// offending token in the query.
+type QuerySyntaxError struct {
+	Query   string
+	Column  int
+	Message string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at column %d: %s", e.Column, e.Message)
+}
+
+// queryType is the type of a query expression.
+type queryType int
+
+const (
+	queryString queryType = iota
+	queryInt
+	queryBool
+	queryList
+)
+
+func (t queryType) String() string {
+	switch t {
+	case queryString:
+		return "a string"
+	case queryInt:
+		return "an integer"
+	case queryBool:
+		return "a boolean"
+	}
+	return "a list"
+}
+
+// queryEnv is what a query is evaluated against: a resource, and the graph
+// it belongs to. The caller holds the read lock of the resolver.
+type queryEnv struct {
+	dr    *DependencyResolver
+	entry ResourceNodeEntry
+	// depths holds the chain depths of the graph, and of the reversed graph,
+	// computed on first use.
+	depths map[bool]map[string]int
+}
+
+// depth returns the length of the longest chain of dependencies, or of
+// dependents when reverse is set, starting at the resource.
+func (env *queryEnv) depth(reverse bool) int {
+	if env.depths[reverse] == nil {
+		graph := env.dr.graph()
+		if reverse {
+			graph = env.dr.reverseDependencies()
+		}
+		env.depths[reverse] = graph.Depths(env.dr.resourceIDs())
+	}
+	return env.depths[reverse][env.entry.Id]
+}
+
+// queryExpr is a typed query expression. Eval returns a string, an int, a
+// bool or a []string, according to its type.
+type queryExpr struct {
+	typ  queryType
+	eval func(env *queryEnv) interface{}
+}
+
+// queryFields are the fields of a resource a query can refer to.
+var queryFields = map[string]queryExpr{
+	"id":         {queryString, func(env *queryEnv) interface{} { return env.entry.Id }},
+	"name":       {queryString, func(env *queryEnv) interface{} { return env.entry.Name }},
+	"version":    {queryString, func(env *queryEnv) interface{} { return env.entry.Version }},
+	"desc":       {queryString, func(env *queryEnv) interface{} { return env.entry.Desc }},
+	"category":   {queryString, func(env *queryEnv) interface{} { return env.entry.Category }},
+	"license":    {queryString, func(env *queryEnv) interface{} { return env.entry.License }},
+	"replacedBy": {queryString, func(env *queryEnv) interface{} { return env.entry.ReplacedBy }},
+	"deprecated": {queryBool, func(env *queryEnv) interface{} { return env.entry.Deprecated }},
+	"requires":   {queryList, func(env *queryEnv) interface{} { return env.entry.Requires }},
+	"optional":   {queryList, func(env *queryEnv) interface{} { return env.entry.Optional }},
+	"conflicts":  {queryList, func(env *queryEnv) interface{} { return env.entry.Conflicts }},
+	"provides":   {queryList, func(env *queryEnv) interface{} { return env.entry.Provides }},
+	"owners":     {queryList, func(env *queryEnv) interface{} { return env.entry.Owners }},
+	"aliases":    {queryList, func(env *queryEnv) interface{} { return env.entry.Aliases }},
+	"deps": {queryList, func(env *queryEnv) interface{} {
+		return env.dr.ResourceDependencies[env.entry.Id]
+	}},
+	"rdeps": {queryList, func(env *queryEnv) interface{} {
+		return env.dr.reverseDependencies()[env.entry.Id]
+	}},
+}
+
+// Query is a compiled resource query.
+type Query struct {
+	source string
+	expr   queryExpr
+}
+
+func (q *Query) String() string {
+	return q.source
+}
+
+// ParseQuery compiles a query selecting resources. A query is a boolean
+// expression over the fields of a resource:
+//
+//	category == "database" && depth(deps) > 3 && has(owners, "team-data")
+//
+// Strings, lists and the deprecated boolean hold the fields of the resource
+// of the same name; deps and rdeps are its resolved dependencies and direct
+// dependents, and meta.key, or meta("key"), the value of a metadata key.
+// Values compare with ==, !=, <, <=, > and >=, and conditions combine with
+// &&, || and !. The functions are len(list or string), has(list, string),
+// which also finds a substring in a string, matches(string, "regexp"), and
+// depth(deps) or depth(rdeps), the length of the longest chain of
+// dependencies or dependents of the resource.
+func ParseQuery(source string) (*Query, error) {
+	tokens, err := lexQuery(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{source: source, tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	if expr.typ != queryBool {
+		return nil, &QuerySyntaxError{Query: source, Column: 1, Message: fmt.Sprintf("the query must be a condition, got %s", expr.typ)}
+	}
+	return &Query{source: source, expr: expr}, nil
+}
+
+// Select returns the resources matching the query, in catalog order.
+func (dr *DependencyResolver) Select(q *Query) []ResourceNodeEntry {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	env := &queryEnv{dr: dr, depths: make(map[bool]map[string]int)}
+	matches := []ResourceNodeEntry{}
+	for _, entry := range dr.Resources {
+		env.entry = entry
+		if q.expr.eval(env).(bool) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// QueryResources compiles the query and returns the resources matching it,
+// in catalog order.
+func (dr *DependencyResolver) QueryResources(source string) ([]ResourceNodeEntry, error) {
+	q, err := ParseQuery(source)
+	if err != nil {
+		return nil, err
+	}
+	return dr.Select(q), nil
+}
+
+type queryTokenKind int
+
+const (
+	tokEOF queryTokenKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokOp
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	// value is the unquoted value of a string token.
+	value string
+	pos   int
+}
+
+func (t queryToken) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+// queryOperators are the operators and punctuation of the language, longest
+// first.
+var queryOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", ",", "."}
+
+func lexQuery(source string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(source) && source[end] != '"' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, &QuerySyntaxError{Query: source, Column: i + 1, Message: "unterminated string"}
+			}
+			value, err := strconv.Unquote(source[i : end+1])
+			if err != nil {
+				return nil, &QuerySyntaxError{Query: source, Column: i + 1, Message: "invalid string " + source[i:end+1]}
+			}
+			tokens = append(tokens, queryToken{kind: tokString, text: source[i : end+1], value: value, pos: i})
+			i = end + 1
+		case isDigit(c):
+			end := i
+			for end < len(source) && isDigit(source[end]) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: tokInt, text: source[i:end], pos: i})
+			i = end
+		case isIdentStart(c):
+			end := i
+			for end < len(source) && (isIdentStart(source[end]) || isDigit(source[end])) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: tokIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range queryOperators {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &QuerySyntaxError{Query: source, Column: i + 1, Message: fmt.Sprintf("unexpected character '%c'", c)}
+			}
+			tokens = append(tokens, queryToken{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, queryToken{kind: tokEOF, pos: len(source)}), nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+type queryParser struct {
+	source string
+	tokens []queryToken
+	next   int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+func (p *queryParser) advance() queryToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+// accept consumes the next token when it is the given operator.
+func (p *queryParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		return p.errorf(tok, "expected '%s', got %s", op, tok)
+	}
+	return nil
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, args ...interface{}) error {
+	return &QuerySyntaxError{Query: p.source, Column: tok.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	return p.parseLogical("||", p.parseAnd, true)
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	return p.parseLogical("&&", p.parseUnary, false)
+}
+
+// parseLogical parses operands joined by op, which evaluates to decisive as
+// soon as an operand does, and to the last operand otherwise.
+func (p *queryParser) parseLogical(op string, operand func() (queryExpr, error), decisive bool) (queryExpr, error) {
+	tok := p.peek()
+	left, err := operand()
+	if err != nil {
+		return queryExpr{}, err
+	}
+	for {
+		opTok := p.peek()
+		if !p.accept(op) {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return queryExpr{}, err
+		}
+		if left.typ != queryBool {
+			return queryExpr{}, p.errorf(tok, "%s expects conditions, got %s", op, left.typ)
+		}
+		if right.typ != queryBool {
+			return queryExpr{}, p.errorf(opTok, "%s expects conditions, got %s", op, right.typ)
+		}
+		l, r := left.eval, right.eval
+		left = queryExpr{queryBool, func(env *queryEnv) interface{} {
+			if l(env).(bool) == decisive {
+				return decisive
+			}
+			return r(env).(bool)
+		}}
+	}
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	tok := p.peek()
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return queryExpr{}, err
+		}
+		if operand.typ != queryBool {
+			return queryExpr{}, p.errorf(tok, "! expects a condition, got %s", operand.typ)
+		}
+		return queryExpr{queryBool, func(env *queryEnv) interface{} { return !operand.eval(env).(bool) }}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (queryExpr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return queryExpr{}, err
+	}
+
+	opTok := p.peek()
+	if opTok.kind != tokOp || !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, opTok.text) {
+		return left, nil
+	}
+	p.advance()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return queryExpr{}, err
+	}
+
+	if left.typ != right.typ {
+		return queryExpr{}, p.errorf(opTok, "cannot compare %s with %s", left.typ, right.typ)
+	}
+	op := opTok.text
+	switch {
+	case left.typ == queryList:
+		return queryExpr{}, p.errorf(opTok, "cannot compare lists, use has or len")
+	case left.typ == queryBool && op != "==" && op != "!=":
+		return queryExpr{}, p.errorf(opTok, "booleans only compare with == and !=")
+	}
+	return queryExpr{queryBool, func(env *queryEnv) interface{} {
+		return compareQueryValues(left.eval(env), right.eval(env), op)
+	}}, nil
+}
+
+// compareQueryValues compares two strings, ints or bools of the same type.
+func compareQueryValues(left, right interface{}, op string) bool {
+	var cmp int
+	switch l := left.(type) {
+	case string:
+		cmp = strings.Compare(l, right.(string))
+	case int:
+		cmp = l - right.(int)
+	case bool:
+		if l != right.(bool) {
+			cmp = 1
+		}
+	}
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+func (p *queryParser) parsePrimary() (queryExpr, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokString:
+		return queryExpr{queryString, func(*queryEnv) interface{} { return tok.value }}, nil
+	case tokInt:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return queryExpr{}, p.errorf(tok, "invalid integer %s", tok.text)
+		}
+		return queryExpr{queryInt, func(*queryEnv) interface{} { return n }}, nil
+	case tokOp:
+		if tok.text == "(" {
+			expr, err := p.parseOr()
+			if err != nil {
+				return queryExpr{}, err
+			}
+			return expr, p.expect(")")
+		}
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			value := tok.text == "true"
+			return queryExpr{queryBool, func(*queryEnv) interface{} { return value }}, nil
+		case "meta":
+			return p.parseMeta()
+		}
+		if p.peek().kind == tokOp && p.peek().text == "(" {
+			return p.parseCall(tok)
+		}
+		if field, ok := queryFields[tok.text]; ok {
+			return field, nil
+		}
+		return queryExpr{}, p.errorf(tok, "unknown field %s", tok.text)
+	}
+	return queryExpr{}, p.errorf(tok, "unexpected %s", tok)
+}
+
+// parseMeta parses meta.key or meta("key").
+func (p *queryParser) parseMeta() (queryExpr, error) {
+	var key string
+	if p.accept(".") {
+		tok := p.advance()
+		if tok.kind != tokIdent {
+			return queryExpr{}, p.errorf(tok, "expected a metadata key, got %s", tok)
+		}
+		key = tok.text
+	} else {
+		if err := p.expect("("); err != nil {
+			return queryExpr{}, err
+		}
+		tok := p.advance()
+		if tok.kind != tokString {
+			return queryExpr{}, p.errorf(tok, "meta expects a string key, got %s", tok)
+		}
+		key = tok.value
+		if err := p.expect(")"); err != nil {
+			return queryExpr{}, err
+		}
+	}
+	return queryExpr{queryString, func(env *queryEnv) interface{} { return env.entry.Metadata[key] }}, nil
+}
+
+func (p *queryParser) parseCall(name queryToken) (queryExpr, error) {
+	p.advance() // (
+	var args []queryExpr
+	var argTokens []queryToken
+	if !p.accept(")") {
+		for {
+			argTokens = append(argTokens, p.peek())
+			arg, err := p.parseOr()
+			if err != nil {
+				return queryExpr{}, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return queryExpr{}, err
+			}
+		}
+	}
+
+	arity := func(n int) error {
+		if len(args) != n {
+			return p.errorf(name, "%s expects %d arguments, got %d", name.text, n, len(args))
+		}
+		return nil
+	}
+	argError := func(i int, expected string) error {
+		return p.errorf(argTokens[i], "argument %d of %s must be %s, got %s", i+1, name.text, expected, args[i].typ)
+	}
+
+	switch name.text {
+	case "len":
+		if err := arity(1); err != nil {
+			return queryExpr{}, err
+		}
+		arg := args[0]
+		switch arg.typ {
+		case queryList:
+			return queryExpr{queryInt, func(env *queryEnv) interface{} { return len(arg.eval(env).([]string)) }}, nil
+		case queryString:
+			return queryExpr{queryInt, func(env *queryEnv) interface{} { return len(arg.eval(env).(string)) }}, nil
+		}
+		return queryExpr{}, argError(0, "a list or a string")
+	case "has":
+		if err := arity(2); err != nil {
+			return queryExpr{}, err
+		}
+		haystack, needle := args[0], args[1]
+		if needle.typ != queryString {
+			return queryExpr{}, argError(1, "a string")
+		}
+		switch haystack.typ {
+		case queryList:
+			return queryExpr{queryBool, func(env *queryEnv) interface{} {
+				return slices.Contains(haystack.eval(env).([]string), needle.eval(env).(string))
+			}}, nil
+		case queryString:
+			return queryExpr{queryBool, func(env *queryEnv) interface{} {
+				return strings.Contains(haystack.eval(env).(string), needle.eval(env).(string))
+			}}, nil
+		}
+		return queryExpr{}, argError(0, "a list or a string")
+	case "matches":
+		if err := arity(2); err != nil {
+			return queryExpr{}, err
+		}
+		if args[0].typ != queryString {
+			return queryExpr{}, argError(0, "a string")
+		}
+		if argTokens[1].kind != tokString || args[1].typ != queryString {
+			return queryExpr{}, p.errorf(argTokens[1], "argument 2 of matches must be a string literal")
+		}
+		re, err := regexp.Compile(argTokens[1].value)
+		if err != nil {
+			return queryExpr{}, p.errorf(argTokens[1], "invalid regular expression: %v", err)
+		}
+		subject := args[0]
+		return queryExpr{queryBool, func(env *queryEnv) interface{} { return re.MatchString(subject.eval(env).(string)) }}, nil
+	case "depth":
+		if err := arity(1); err != nil {
+			return queryExpr{}, err
+		}
+		if argTokens[0].kind != tokIdent || (argTokens[0].text != "deps" && argTokens[0].text != "rdeps") {
+			return queryExpr{}, p.errorf(argTokens[0], "depth expects deps or rdeps")
+		}
+		reverse := argTokens[0].text == "rdeps"
+		return queryExpr{queryInt, func(env *queryEnv) interface{} { return env.depth(reverse) }}, nil
+	}
+	return queryExpr{}, p.errorf(name, "unknown function %s", name.text)
+}
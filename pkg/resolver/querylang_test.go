@@ -0,0 +1,98 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupQueryResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "disk", Category: "storage"},
+		{Id: "fs", Category: "storage", Requires: []string{"disk"}},
+		{Id: "db", Category: "database", Owners: []string{"team-data"}, Requires: []string{"fs"}, Metadata: map[string]string{"tier": "critical"}},
+		{Id: "cache", Category: "database", Desc: "In-memory cache", Deprecated: true},
+		{Id: "api", Category: "service", Requires: []string{"db", "cache"}, Metadata: map[string]string{"team-name": "web"}},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestQueryResources(t *testing.T) {
+	resolver := setupQueryResolver()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{`category == "database"`, []string{"db", "cache"}},
+		{`category == "database" && !deprecated`, []string{"db"}},
+		{`depth(deps) >= 2`, []string{"db", "api"}},
+		{`depth(rdeps) == 0`, []string{"api"}},
+		{`has(owners, "team-data") || has(desc, "memory")`, []string{"db", "cache"}},
+		{`len(deps) > 1 || len(rdeps) > 1`, []string{"api"}},
+		{`has(rdeps, "api")`, []string{"db", "cache"}},
+		{`meta.tier == "critical"`, []string{"db"}},
+		{`meta("team-name") == "web"`, []string{"api"}},
+		{`matches(id, "^d") && (category != "storage" || id == "disk")`, []string{"disk", "db"}},
+		{`deprecated == true`, []string{"cache"}},
+		{`id < "c"`, []string{"api"}},
+	}
+	for _, tt := range tests {
+		entries, err := resolver.QueryResources(tt.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.query, err)
+			continue
+		}
+		if ids := entryIds(entries); !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.expected, ids)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`category == `, "invalid query at column 13: unexpected end of query"},
+		{`category = "x"`, "invalid query at column 10: unexpected character '='"},
+		{`category == 3`, "invalid query at column 10: cannot compare a string with an integer"},
+		{`tags == "x"`, "invalid query at column 1: unknown field tags"},
+		{`len(deps)`, "invalid query at column 1: the query must be a condition, got an integer"},
+		{`deps == rdeps`, "invalid query at column 6: cannot compare lists, use has or len"},
+		{`has(deps)`, "invalid query at column 1: has expects 2 arguments, got 1"},
+		{`has(deps, 1)`, "invalid query at column 11: argument 2 of has must be a string, got an integer"},
+		{`depth(requires) > 1`, "invalid query at column 7: depth expects deps or rdeps"},
+		{`matches(id, "(")`, "invalid query at column 13: invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		{`id == "x" && name`, "invalid query at column 11: && expects conditions, got a string"},
+		{`(id == "x"`, "invalid query at column 11: expected ')', got end of query"},
+		{`id == "x`, "invalid query at column 7: unterminated string"},
+		{`frobnicate(id)`, "invalid query at column 1: unknown function frobnicate"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		var syntaxErr *QuerySyntaxError
+		if !errors.As(err, &syntaxErr) || err.Error() != tt.expected {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.expected, err)
+		}
+	}
+}
+
+func TestHandleQueryCommand(t *testing.T) {
+	resolver := setupQueryResolver()
+
+	output := captureOutput(func() {
+		if err := resolver.HandleQueryCommand([]string{"category", "==", `"storage"`}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "disk") || !strings.Contains(output, "fs") || strings.Contains(output, "db") {
+		t.Errorf("Expected the storage resources, got %q", output)
+	}
+	if err := resolver.HandleQueryCommand(nil); err == nil {
+		t.Error("Expected an error without a query")
+	}
+}
@@ -0,0 +1,123 @@
+package resolver
+
+import "slices"
+
+// reachBudget bounds the memory, in bytes, of the cached reachability sets of
+// an idGraph. A set takes one bit per interned ID, so caching one for every
+// resource of a large catalog is quadratic; past the budget the cache starts
+// over.
+const reachBudget = 64 << 20
+
+// reachable returns the indices reachable from i along edges in one or more
+// steps, caching the set in sets on first use. i itself is only in the set
+// when it is part of a cycle. Callers must hold the cache lock, and must not
+// modify the result.
+func (g *idGraph) reachable(i int32, edges [][]int32, sets *[]bitset) bitset {
+	if int(i) < len(*sets) && (*sets)[i] != nil {
+		return (*sets)[i]
+	}
+
+	set := newBitset(len(g.ids))
+	stack := []int32{i}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, succ := range edges[node] {
+			if !set.has(succ) {
+				set.set(succ)
+				stack = append(stack, succ)
+			}
+		}
+	}
+
+	if g.reachBytes += 8 * len(set); g.reachBytes > reachBudget {
+		g.reach, g.reachedBy, g.reachBytes = nil, nil, 8*len(set)
+	}
+	if len(*sets) < len(g.ids) {
+		*sets = append(*sets, make([]bitset, len(g.ids)-len(*sets))...)
+	}
+	(*sets)[i] = set
+	return set
+}
+
+// dropReach drops the cached reachability sets that changing the requirements
+// of i affects: the ones of the indices reaching i, and the reverse ones of
+// the indices i reached. setDeps calls reachAfter once the change is made for
+// the indices i reaches now.
+func (g *idGraph) dropReach(i int32) {
+	for j, set := range g.reach {
+		if set != nil && (int32(j) == i || set.has(i)) {
+			g.reach[j] = nil
+		}
+	}
+	for j, set := range g.reachedBy {
+		if set != nil && set.has(i) {
+			g.reachedBy[j] = nil
+		}
+	}
+}
+
+func (g *idGraph) reachAfter(i int32) {
+	if len(g.reachedBy) == 0 {
+		return
+	}
+	set := g.reachable(i, g.deps, &g.reach)
+	for j := range g.reachedBy {
+		if set.has(int32(j)) {
+			g.reachedBy[j] = nil
+		}
+	}
+}
+
+// reaches reports whether to is in the closure of from, that is whether from
+// requires it directly or transitively. After the first query from a
+// resource, it is a single bit test. Callers must hold the read lock.
+func (dr *DependencyResolver) reaches(from, to string) bool {
+	g := dr.ids()
+	i, ok := g.index[from]
+	j, found := g.index[to]
+	if !ok || !found {
+		return false
+	}
+	dr.cache.mu.Lock()
+	defer dr.cache.mu.Unlock()
+	return g.reachable(i, g.deps, &g.reach).has(j)
+}
+
+// reaching returns whether a resource is id or requires it, directly or
+// transitively. Only those are worth descending into when looking for paths
+// to id. Callers must hold the read lock while using the result.
+func (dr *DependencyResolver) reaching(id string) func(string) bool {
+	g := dr.ids()
+	i, ok := g.index[id]
+	if !ok {
+		return func(other string) bool { return other == id }
+	}
+	dr.cache.mu.Lock()
+	set := g.reachable(i, g.dependents, &g.reachedBy)
+	dr.cache.mu.Unlock()
+	return func(other string) bool {
+		j, ok := g.index[other]
+		return other == id || ok && set.has(j)
+	}
+}
+
+// inClosure returns whether a resource is one of the targets or in the
+// closure of one of them. Callers must hold the read lock while using the
+// result.
+func (dr *DependencyResolver) inClosure(targets []string) func(string) bool {
+	g := dr.ids()
+	set := newBitset(len(g.ids))
+	dr.cache.mu.Lock()
+	for _, target := range targets {
+		if i, ok := g.index[target]; ok {
+			set.set(i)
+			set.or(g.reachable(i, g.deps, &g.reach))
+		}
+	}
+	dr.cache.mu.Unlock()
+	return func(id string) bool {
+		j, ok := g.index[id]
+		return ok && set.has(j) || slices.Contains(targets, id)
+	}
+}
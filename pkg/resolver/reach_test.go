@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// inCycle reports whether id requires itself, directly or transitively.
+func inCycle(dr *DependencyResolver, id string) bool {
+	for _, dep := range dr.ResourceDependencies[id] {
+		if dep == id || slices.Contains(dr.closure(dep, 0), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// assertReaches fails unless reaches, reaching and inClosure agree with the
+// closures of every resource.
+func assertReaches(t *testing.T, dr *DependencyResolver) {
+	t.Helper()
+	ids := dr.resourceIDs()
+	for _, from := range ids {
+		closure := dr.closure(from, 0)
+		for _, to := range ids {
+			want := slices.Contains(closure, to) || from == to && inCycle(dr, from)
+			if got := dr.reaches(from, to); got != want {
+				t.Errorf("reaches(%s, %s): expected %v, got %v", from, to, want, got)
+			}
+			if got := dr.reaching(to)(from); got != (want || from == to) {
+				t.Errorf("reaching(%s)(%s): expected %v, got %v", to, from, want || from == to, got)
+			}
+			if got := dr.inClosure([]string{from})(to); got != (want || from == to) {
+				t.Errorf("inClosure(%s)(%s): expected %v, got %v", from, to, want || from == to, got)
+			}
+		}
+	}
+}
+
+func TestReaches(t *testing.T) {
+	ids, deps := randomGraph(60, 90)
+	resolver := setupTestResolver()
+	for _, id := range ids {
+		resolver.Resources = append(resolver.Resources, ResourceNodeEntry{Id: id, Requires: deps[id]})
+	}
+	resolver.BuildDependencies()
+	assertReaches(t, resolver)
+
+	if resolver.reaches("r1", "unknown") || resolver.reaches("unknown", "r1") {
+		t.Error("Expected unknown resources not to be reachable")
+	}
+	if !resolver.reaching("unknown")("unknown") {
+		t.Error("Expected a resource to reach itself")
+	}
+	if !resolver.inClosure([]string{"unknown"})("unknown") {
+		t.Error("Expected the targets to be in their closure")
+	}
+}
+
+func TestReaches_Invalidation(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = incrementalCatalog()
+	resolver.BuildDependencies()
+	assertReaches(t, resolver)
+
+	// Move 'b' to the second component, then close a cycle through it.
+	updates := []ResourceNodeEntry{
+		{Id: "b", Requires: []string{"e"}},
+		{Id: "e", Requires: []string{"a"}},
+		{Id: "b", Requires: []string{}},
+	}
+	for _, update := range updates {
+		if err := resolver.UpdateResource(update); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resolver.cache.ids == nil {
+			t.Fatal("Expected the interned graph to be updated in place")
+		}
+		assertReaches(t, resolver)
+	}
+}
+
+func TestReaches_Budget(t *testing.T) {
+	g := newIDGraph([]string{"a", "b"}, map[string][]string{"a": {"b"}})
+	g.reachBytes = reachBudget
+	g.reachedBy = make([]bitset, 2)
+	g.reachedBy[1] = newBitset(2)
+
+	if !g.reachable(0, g.deps, &g.reach).has(1) {
+		t.Error("Expected 'b' to be reachable from 'a'")
+	}
+	if g.reachedBy != nil || g.reachBytes != 8 {
+		t.Errorf("Expected the cache to start over past the budget, got %d bytes", g.reachBytes)
+	}
+}
+
+func BenchmarkCheckConflicts_Chain10k(b *testing.B) {
+	resolver := setupChainResolver(10000)
+	resolver.Resources[0].Conflicts = []string{"missing"}
+	targets := []string{"n9999"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := resolver.CheckConflicts(context.Background(), targets); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package resolver
+
+// RedundantEdge is a requirement already implied by another requirement of
+// the same resource. Via is the dependency path that implies it.
+type RedundantEdge struct {
+	From string   `json:"from" yaml:"from"`
+	To   string   `json:"to" yaml:"to"`
+	Via  []string `json:"via" yaml:"via"`
+}
+
+// RedundantEdges returns the requirements that are also reachable through
+// another requirement of the same resource, in catalog order. In a graph with
+// cycles each reported edge is redundant on its own, but removing all of them
+// at once may disconnect resources of the cycle. Resources are checked
+// concurrently, see Workers.
+func (dr *DependencyResolver) RedundantEdges() []RedundantEdge {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return runPasses(dr, dr.redundantEdges)
+}
+
+// redundantEdges returns the redundant requirements of the resource at
+// position i of Resources, unless it is a later definition of the resource.
+func (dr *DependencyResolver) redundantEdges(i int, entry ResourceNodeEntry) []RedundantEdge {
+	if dr.resourceIndexOf(entry.Id) != i {
+		return nil
+	}
+
+	var redundant []RedundantEdge
+	deps := dr.ResourceDependencies[entry.Id]
+	for _, to := range deps {
+		for _, other := range deps {
+			if other == to || other == entry.Id || !dr.reaches(other, to) {
+				continue
+			}
+			if path := dr.graph().ShortestPath(other, to); path != nil {
+				via := append([]string{entry.Id}, path...)
+				redundant = append(redundant, RedundantEdge{From: entry.Id, To: to, Via: via})
+				break
+			}
+		}
+	}
+	return redundant
+}
+
+// TransitiveReduction returns the dependencies of every resource without its
+// redundant requirements, keeping the closure of every acyclic resource
+// unchanged.
+func (dr *DependencyResolver) TransitiveReduction() map[string][]string {
+	redundant := make(map[Edge]bool)
+	for _, edge := range dr.RedundantEdges() {
+		redundant[Edge{From: edge.From, To: edge.To}] = true
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	reduced := make(map[string][]string, len(dr.ResourceDependencies))
+	for id, deps := range dr.ResourceDependencies {
+		kept := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			if !redundant[Edge{From: id, To: dep}] {
+				kept = append(kept, dep)
+			}
+		}
+		reduced[id] = kept
+	}
+	return reduced
+}
@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedundantEdges(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a"},
+		{Id: "b", Requires: []string{"a"}},
+		{Id: "c", Requires: []string{"a", "b"}},
+		{Id: "d", Requires: []string{"c", "a"}},
+	}
+	resolver.BuildDependencies()
+
+	expected := []RedundantEdge{
+		{From: "c", To: "a", Via: []string{"c", "b", "a"}},
+		{From: "d", To: "a", Via: []string{"d", "c", "a"}},
+	}
+	if redundant := resolver.RedundantEdges(); !reflect.DeepEqual(redundant, expected) {
+		t.Errorf("Expected %v, got %v", expected, redundant)
+	}
+
+	reduced := resolver.TransitiveReduction()
+	expectedReduced := map[string][]string{
+		"a": {},
+		"b": {"a"},
+		"c": {"b"},
+		"d": {"c"},
+	}
+	if !reflect.DeepEqual(reduced, expectedReduced) {
+		t.Errorf("Expected %v, got %v", expectedReduced, reduced)
+	}
+}
+
+func TestRedundantEdges_None(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	if redundant := resolver.RedundantEdges(); len(redundant) != 0 {
+		t.Errorf("Expected no redundant edges, got %v", redundant)
+	}
+}
@@ -0,0 +1,151 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// fetchTimeout bounds the download of a catalog, or of a response of the API
+// of an HTTPStore, so that a server that stops responding fails the load
+// instead of blocking it.
+const fetchTimeout = 30 * time.Second
+
+// fetchClient is the client of the catalog downloads and HTTPStores.
+var fetchClient = &http.Client{Timeout: fetchTimeout}
+
+// cachedResponse is the metadata saved next to a cached catalog to
+// revalidate it.
+type cachedResponse struct {
+	URL          string `yaml:"url"`
+	ETag         string `yaml:"etag,omitempty"`
+	LastModified string `yaml:"lastModified,omitempty"`
+}
+
+// isURL reports whether the path is an HTTP(S) URL.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// LoadResourcesFromURL loads the resources of a catalog published over
+// HTTP(S). When CacheDir is set, the catalog is cached there and revalidated
+// with ETag and If-Modified-Since on the next loads, and the cached copy is
+// used when the server cannot be reached.
+func (dr *DependencyResolver) LoadResourcesFromURL(url string) error {
+	return dr.loadResourceFiles([]string{url})
+}
+
+// fetchURL returns the body of the URL, going through the cache when
+// CacheDir is set.
+func (dr *DependencyResolver) fetchURL(url string) ([]byte, error) {
+	if dr.CacheDir == "" {
+		return download(url, nil)
+	}
+
+	key := sha256.Sum256([]byte(url))
+	dataPath := filepath.Join(dr.CacheDir, "catalogs", hex.EncodeToString(key[:]))
+	metaPath := dataPath + ".meta"
+
+	var meta cachedResponse
+	cached, err := afero.ReadFile(dr.Fs, dataPath)
+	if err == nil {
+		if metaData, err := afero.ReadFile(dr.Fs, metaPath); err == nil {
+			yaml.Unmarshal(metaData, &meta)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading cached catalog %s: %w", dataPath, err)
+	}
+
+	resp, err := request(url, func(req *http.Request) {
+		if cached == nil {
+			return
+		}
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	})
+	if err != nil {
+		if cached != nil {
+			dr.logWarn("Using the cached copy of a catalog", "url", url, "err", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		dr.logDebug("Catalog not modified, using the cached copy", "url", url)
+		return cached, nil
+	}
+	data, err := readResponse(url, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	meta = cachedResponse{URL: url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	metaData, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling cache metadata: %w", err)
+	}
+	if err := dr.Fs.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %w", err)
+	}
+	if err := afero.WriteFile(dr.Fs, dataPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("error caching catalog %s: %w", url, err)
+	}
+	if err := afero.WriteFile(dr.Fs, metaPath, metaData, 0644); err != nil {
+		return nil, fmt.Errorf("error caching catalog %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// download returns the body of the URL.
+func download(url string, prepare func(*http.Request)) ([]byte, error) {
+	resp, err := request(url, prepare)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return readResponse(url, resp)
+}
+
+// request sends a GET request for the URL, prepared by prepare unless nil.
+func request(url string, prepare func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file from URL %s: %w", url, err)
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file from URL %s: %w", url, err)
+	}
+	return resp, nil
+}
+
+// readResponse returns the body of a successful response.
+func readResponse(url string, resp *http.Response) ([]byte, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading file from URL %s, status code: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file content from URL %s: %w", url, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const remoteCatalog = "resources:\n  - id: remote\n    name: Remote\n"
+
+func TestLoadResourcesFromURL_Cache(t *testing.T) {
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(remoteCatalog))
+	}))
+
+	resolver := setupTestResolver()
+	resolver.CacheDir = "/cache"
+	if err := resolver.LoadResourcesFromURL(server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := resolver.GetResource("remote"); !ok {
+		t.Fatal("Expected the remote resource to be loaded")
+	}
+
+	// Revalidated with the ETag of the cached copy.
+	if _, err := resolver.fetchURL(server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("Expected the cached copy to be revalidated, got %d requests", requests.Load())
+	}
+
+	// Served from the cache when the server is down.
+	server.Close()
+	data, err := resolver.fetchURL(server.URL)
+	if err != nil || string(data) != remoteCatalog {
+		t.Errorf("Expected the cached copy, got %q, %v", data, err)
+	}
+}
+
+func TestLoadResourcesFromURL_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	defer func(timeout time.Duration) { fetchClient.Timeout = timeout }(fetchClient.Timeout)
+	fetchClient.Timeout = 50 * time.Millisecond
+
+	resolver := setupTestResolver()
+	if err := resolver.LoadResourcesFromURL(server.URL); err == nil {
+		t.Error("Expected a server that does not respond to fail the load")
+	}
+	if _, err := NewHTTPStore(server.URL).List(); err == nil {
+		t.Error("Expected a server that does not respond to fail the store")
+	}
+}
+
+func TestLoadResourcesFromURL_Errors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	resolver := setupTestResolver()
+	resolver.CacheDir = "/cache"
+	if err := resolver.LoadResourcesFromURL(server.URL); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+
+	server.Close()
+	if err := resolver.LoadResourcesFromURL(server.URL + "/uncached"); err == nil {
+		t.Error("Expected an error for an unreachable URL without a cached copy")
+	}
+}
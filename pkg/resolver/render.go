@@ -0,0 +1,219 @@
+package resolver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats of the listing commands.
+const (
+	FormatPlain = "plain"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+	// FormatJSONL writes one compact JSON record per line, streamed as the
+	// records are produced by the commands that can list millions of them.
+	FormatJSONL = "jsonl"
+	// FormatGitHub writes the findings of validate as GitHub Actions
+	// workflow commands, which annotate the files of a pull request. Other
+	// commands do not support it.
+	FormatGitHub = "github"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{FormatPlain, FormatJSON, FormatYAML, FormatTable, FormatJSONL, FormatGitHub}
+
+// Table is tabular output, rendered with aligned columns. The rows of the
+// table of a command are also its porcelain records.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// ValidateFormat returns an error for unsupported output formats.
+func ValidateFormat(format string) error {
+	for _, supported := range Formats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format '%s', expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// RenderJSON writes v as indented JSON.
+func RenderJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// JSONLWriter writes records as JSON lines: one compact JSON value per line,
+// written as soon as its buffer fills up rather than once every record is
+// known.
+type JSONLWriter struct {
+	bw      *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewJSONLWriter returns a JSONLWriter writing to w. Call Flush once every
+// record is written.
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	bw := bufio.NewWriter(w)
+	return &JSONLWriter{bw: bw, encoder: json.NewEncoder(bw)}
+}
+
+// Write writes v as a JSON line.
+func (jw *JSONLWriter) Write(v interface{}) error {
+	return jw.encoder.Encode(v)
+}
+
+// Flush writes the buffered records.
+func (jw *JSONLWriter) Flush() error {
+	return jw.bw.Flush()
+}
+
+// RenderJSONL writes v as JSON lines: every element of a slice on its own
+// line, every entry of a map as a single-key object, sorted by key, and any
+// other value as a single line.
+func RenderJSONL(w io.Writer, v interface{}) error {
+	jw := NewJSONLWriter(w)
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := jw.Write(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+		for _, key := range keys {
+			entry := map[string]interface{}{fmt.Sprint(key.Interface()): value.MapIndex(key).Interface()}
+			if err := jw.Write(entry); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := jw.Write(v); err != nil {
+			return err
+		}
+	}
+	return jw.Flush()
+}
+
+// RenderYAML writes v as YAML.
+func RenderYAML(w io.Writer, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling YAML: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// RenderTable writes the table with its columns aligned.
+func RenderTable(w io.Writer, table Table) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(table.Header, "\t"))
+	for _, row := range table.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// ResourceTable returns the table listing the given resources.
+func ResourceTable(entries []ResourceNodeEntry) Table {
+	table := Table{Header: []string{"ID", "NAME", "CATEGORY", "REQUIRES", "DESCRIPTION"}}
+	for _, entry := range entries {
+		table.Rows = append(table.Rows, []string{entry.Id, entry.Name, entry.Category, strings.Join(entry.Requires, ","), entry.Desc})
+	}
+	return table
+}
+
+// pairTable returns a two-column table pairing every resource, in the given
+// order, with each of its values.
+func pairTable(header []string, resources []string, values map[string][]string) Table {
+	table := Table{Header: header}
+	for _, res := range resources {
+		for _, value := range values[res] {
+			table.Rows = append(table.Rows, []string{res, value})
+		}
+	}
+	return table
+}
+
+// nonNil returns an empty slice instead of nil, so it is rendered as an empty
+// list rather than null.
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
+
+// render writes v to stdout in the output format of the resolver. The plain
+// format keeps the historical output, written by plain; the table format uses
+// the table built by table. A Template takes precedence over the format, and
+// is executed with v, the data of the JSON and YAML output, as its data. The
+// porcelain output, taking precedence over both, writes the records built by
+// porcelain for the requested version.
+func (dr *DependencyResolver) render(v interface{}, table func() Table, porcelain Porcelain, plain func()) error {
+	if dr.Porcelain != "" {
+		return RenderPorcelain(os.Stdout, porcelain, dr.Porcelain)
+	}
+	if dr.Template != "" {
+		return RenderTemplate(os.Stdout, dr.Template, v)
+	}
+	switch dr.OutputFormat {
+	case "", FormatPlain:
+		plain()
+		return nil
+	case FormatJSON:
+		return RenderJSON(os.Stdout, v)
+	case FormatYAML:
+		return RenderYAML(os.Stdout, v)
+	case FormatTable:
+		return RenderTable(os.Stdout, table())
+	case FormatJSONL:
+		return RenderJSONL(os.Stdout, v)
+	case FormatGitHub:
+		return fmt.Errorf("output format '%s' is only supported by validate", FormatGitHub)
+	default:
+		return ValidateFormat(dr.OutputFormat)
+	}
+}
+
+// streaming reports whether listings are written as JSON lines, which the
+// commands producing the largest results stream instead of rendering.
+func (dr *DependencyResolver) streaming() bool {
+	return dr.OutputFormat == FormatJSONL && dr.Template == "" && dr.Porcelain == ""
+}
+
+// streamClosures writes a ClosureRecord, built by record, for every resource
+// of the closure of each of the given resources, walked by closure down to
+// MaxDepth.
+func (dr *DependencyResolver) streamClosures(resources []string, closure func(string, int) iter.Seq[string], record func(res, id string) ClosureRecord) error {
+	jw := NewJSONLWriter(os.Stdout)
+	for _, res := range resources {
+		visited := []string{res}
+		for id := range closure(res, dr.MaxDepth) {
+			if err := jw.Write(record(res, id)); err != nil {
+				return err
+			}
+			visited = append(visited, id)
+		}
+		dr.warnTraversed(visited)
+	}
+	return jw.Flush()
+}
@@ -0,0 +1,142 @@
+package resolver
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range Formats {
+		if err := ValidateFormat(format); err != nil {
+			t.Errorf("Expected %s to be supported, got %v", format, err)
+		}
+	}
+	if err := ValidateFormat("xml"); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderJSON(&buf, []ResourceNodeEntry{{Id: "a", Name: "A", Requires: []string{"b"}}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `[
+  {
+    "id": "a",
+    "name": "A",
+    "desc": "",
+    "category": "",
+    "requires": [
+      "b"
+    ]
+  }
+]
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRenderYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderYAML(&buf, map[string][]string{"a": {"b", "c"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "a:\n- b\n- c\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	var buf bytes.Buffer
+	table := pairTable([]string{"RESOURCE", "DEPENDENCY"}, []string{"app", "b"}, map[string][]string{
+		"app": {"database", "b"},
+		"b":   {"c"},
+	})
+	if err := RenderTable(&buf, table); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "RESOURCE  DEPENDENCY\napp       database\napp       b\nb         c\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRenderJSONL(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{[]string{"a", "b"}, "\"a\"\n\"b\"\n"},
+		{[]ResourceNodeEntry{{Id: "a"}}, `{"id":"a","name":"","desc":"","category":"","requires":null}` + "\n"},
+		{map[string][]string{"b": {"c"}, "a": nil}, "{\"a\":null}\n{\"b\":[\"c\"]}\n"},
+		{GraphStats{Resources: 2}, ""},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := RenderJSONL(&buf, test.value); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if test.expected == "" {
+			if strings.Count(buf.String(), "\n") != 1 {
+				t.Errorf("Expected a single line for %T, got %q", test.value, buf.String())
+			}
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Expected:\n%s\nGot:\n%s", test.expected, buf.String())
+		}
+	}
+}
+
+func TestHandleDependsCommand_JSONL(t *testing.T) {
+	resolver := setupDiamondResolver()
+	resolver.OutputFormat = FormatJSONL
+
+	output := captureOutput(func() {
+		if err := resolver.HandleDependsCommand([]string{"A", "B"}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+	expected := `{"resource":"A","dependency":"B"}
+{"resource":"A","dependency":"C"}
+{"resource":"A","dependency":"D"}
+{"resource":"B","dependency":"D"}
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+
+	resolver.MaxDepth = 1
+	output = captureOutput(func() { resolver.HandleRDependsCommand([]string{"D"}) })
+	if expected := "{\"resource\":\"D\",\"dependent\":\"B\"}\n{\"resource\":\"D\",\"dependent\":\"C\"}\n"; output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+func BenchmarkHandleDependsCommand_JSONL_Chain100k(b *testing.B) {
+	resolver := setupChainResolver(100000)
+	resolver.OutputFormat = FormatJSONL
+	stdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := resolver.HandleDependsCommand([]string{"n99999"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Resolve returns the execution order for the given targets, with every
+// dependency listed before the resources that require it. Independent
+// resources keep the order of the targets and of the requirements that pull
+// them in, so the order is the same on every run. Targets naming a
+// virtual resource are resolved to their provider, and targets naming an
+// alias to the resource declaring it. A closure exceeding MaxTraversalDepth
+// or MaxClosureSize fails with a TraversalLimitError, and one holding a
+// dependency cycle with a CycleError, since no order runs its resources after
+// their dependencies. Resolution stops with the context's error once ctx is
+// done.
+func (dr *DependencyResolver) Resolve(ctx context.Context, targets []string) (stack []string, err error) {
+	start := time.Now()
+	defer func() { resolutionDuration.Observe(time.Since(start).Seconds()) }()
+
+	ctx, span := tracer.Start(ctx, "resolve", trace.WithAttributes(attrTargets.StringSlice(targets)))
+	defer func() {
+		span.SetAttributes(attrResources.Int(len(stack)))
+		endSpan(span, err)
+	}()
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	resolved := make([]string, len(targets))
+	for i, target := range targets {
+		provider, err := dr.resolveProvider(target)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = provider
+	}
+
+	if err := dr.checkLimits(resolved, false, 0); err != nil {
+		return nil, err
+	}
+
+	if err := dr.checkProviders(ctx, resolved); err != nil {
+		return nil, err
+	}
+
+	if err := dr.checkConflicts(ctx, resolved); err != nil {
+		return nil, err
+	}
+
+	visited := make(map[string]bool)
+	for _, target := range resolved {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		stack = append(stack, dr.graph().PostOrder(target, visited, 0)...)
+	}
+	for _, component := range dr.graph().Components(stack) {
+		if len(component) > 1 || slices.Contains(dr.ResourceDependencies[component[0]], component[0]) {
+			return nil, &CycleError{Resources: component}
+		}
+	}
+	dr.warnAliases(targets, stack)
+	dr.warnDeprecated(stack)
+	return stack, nil
+}
+
+// dependencyPaths walks the closure of the targets breadth-first and returns,
+// for every reached resource, the path that first pulled it in.
+func (dr *DependencyResolver) dependencyPaths(ctx context.Context, targets []string) (map[string][]string, error) {
+	paths := make(map[string][]string)
+	var queue []string
+	for _, target := range targets {
+		if _, seen := paths[target]; seen {
+			continue
+		}
+		paths[target] = []string{target}
+		queue = append(queue, target)
+	}
+
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		node := queue[0]
+		queue = queue[1:]
+		for _, dep := range dr.ResourceDependencies[node] {
+			if _, seen := paths[dep]; seen {
+				continue
+			}
+			path := make([]string, len(paths[node]), len(paths[node])+1)
+			copy(path, paths[node])
+			paths[dep] = append(path, dep)
+			queue = append(queue, dep)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,63 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	resolver := setupTestResolver()
+
+	stack, err := resolver.Resolve(context.Background(), []string{"c", "e"})
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+
+	expected := "a b c d e"
+	if strings.Join(stack, " ") != expected {
+		t.Errorf("Expected order %s, got %v", expected, stack)
+	}
+}
+
+func TestResolve_Cycle(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"db"}},
+		{Id: "db", Requires: []string{"app"}},
+		{Id: "web", Requires: []string{"app"}},
+	}
+	resolver.BuildDependencies()
+
+	var cycle *CycleError
+	if _, err := resolver.Resolve(context.Background(), []string{"web"}); !errors.As(err, &cycle) || len(cycle.Resources) != 2 {
+		t.Errorf("Expected a cycle error between app and db, got %v", err)
+	}
+}
+
+func TestDependencyPaths(t *testing.T) {
+	resolver := setupTestResolver()
+
+	paths, err := resolver.dependencyPaths(context.Background(), []string{"d"})
+	if err != nil {
+		t.Fatalf("Failed to compute paths: %v", err)
+	}
+	if len(paths) != 4 {
+		t.Errorf("Expected 4 resources in the closure, got %d", len(paths))
+	}
+	if path := strings.Join(paths["a"], " -> "); path != "d -> c -> b -> a" {
+		t.Errorf("Expected path d -> c -> b -> a, got %s", path)
+	}
+}
+
+func TestResolve_Cancelled(t *testing.T) {
+	resolver := setupTestResolver()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := resolver.Resolve(ctx, []string{"z"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
@@ -2,23 +2,97 @@ package resolver
 
 import (
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
-	"github.com/charmbracelet/log"
 	"github.com/jjuliano/runner/pkg/runnerexec"
 	"github.com/kdeps/kartographer/graph"
 	"github.com/spf13/afero"
 )
 
+// DependencyResolver holds the loaded resources and their dependency graph.
+//
+// All methods are safe for concurrent use: queries take a read lock, while
+// loading, reloading and rebuilding the dependencies take the write lock, so
+// readers always observe a consistent catalog. The exported fields must not be
+// modified directly once the resolver is shared between goroutines; use
+// AddResourceEntry, UpdateResource and RemoveResource instead, which also
+// write the change to the Store, when set.
 type DependencyResolver struct {
+	mu      sync.RWMutex
+	cache   closureCache
+	index   *resourceIndex
+	locked  map[string][]string
+	sources map[string]string
+	// storeIDs maps the IDs of the resources loaded from the Store, and not
+	// changed by an overlay since, to their IDs in the Store.
+	storeIDs map[string]string
+	// storeListed is set once LoadStore loaded every resource of the Store.
+	storeListed bool
+	// recordsMu serializes the updates of the RecordsFile by concurrent runs.
+	recordsMu sync.Mutex
+
+	// loadedSources and loadedOverlays are what the catalog was loaded from,
+	// for Reload.
+	loadedSources  []string
+	loadedOverlays []string
+	warnings       []Warning
+
+	preRunHooks   []Hook
+	postRunHooks  []Hook
+	rollbackHooks []Hook
+	listeners     []*Listener
+	deliveries    webhookSender
+	types         map[string]TypeHandler
+
 	Fs                   afero.Fs
 	Resources            []ResourceNodeEntry
 	ResourceDependencies map[string][]string
 	DependencyGraph      []string
 	VisitedPaths         map[string]bool
-	Logger               *log.Logger
+	Logger               Logger
 	Graph                *graph.DependencyGraph
 	WorkDir              string
 	ShellSession         *runnerexec.ShellSession
+	IncludeOptional      bool
+	WarnOnConflict       bool
+	ProviderPreferences  map[string]string
+	SelectProvider       func(name string, candidates []string) (string, error)
+	MaxPaths             int
+	OutputFormat         string
+	// Porcelain is the version of the porcelain output, tab-separated
+	// records with stable fields for scripts, replacing the output format
+	// when set.
+	Porcelain         string
+	Template          string
+	MetadataFilter    map[string]string
+	Page              Page
+	MaxDepth          int
+	MaxTraversalDepth int
+	MaxClosureSize    int
+	CompactPaths      bool
+	Retry             RetryPolicy
+	Timeout           time.Duration
+	FailurePolicy     FailurePolicy
+	CheckpointFile    string
+	RecordsFile       string
+	Force             bool
+	Resume            bool
+	RollbackOnFailure bool
+	StepOutput        io.Writer
+	Quiet             bool
+	Webhooks          []Webhook
+	PluginPath        []string
+	CacheDir          string
+	Verifications     []CatalogVerification
+	NewWatcher        func() (Watcher, error)
+	StrictEnv         bool
+	LookupEnv         func(name string) (string, bool)
+	LoadMode          LoadMode
+	IDs               IDPolicy
+	Workers           int
+	Store             ResourceStore
 }
 
 type RunStep struct {
@@ -43,28 +117,115 @@ type StepKey struct {
 	node string
 }
 
+// ResourceNodeEntry is a resource of the catalog. Aliases are former IDs of
+// the resource, which requirements and targets can still use. A Deprecated
+// resource should no longer be required, in favor of ReplacedBy when set.
+// Metadata holds free-form attributes, kept as they are.
 type ResourceNodeEntry struct {
-	Id       string    `yaml:"id"`
-	Name     string    `yaml:"name"`
-	Desc     string    `yaml:"desc"`
-	Category string    `yaml:"category"`
-	Requires []string  `yaml:"requires"`
-	Run      []RunStep `yaml:"run"`
+	Id         string            `yaml:"id" json:"id"`
+	Aliases    []string          `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Name       string            `yaml:"name" json:"name"`
+	Version    string            `yaml:"version,omitempty" json:"version,omitempty"`
+	Desc       string            `yaml:"desc" json:"desc"`
+	Category   string            `yaml:"category" json:"category"`
+	License    string            `yaml:"license,omitempty" json:"license,omitempty"`
+	Owners     []string          `yaml:"owners,omitempty" json:"owners,omitempty"`
+	Metadata   map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Deprecated bool              `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	ReplacedBy string            `yaml:"replacedBy,omitempty" json:"replacedBy,omitempty"`
+	Requires   []string          `yaml:"requires" json:"requires"`
+	Optional   []string          `yaml:"optional,omitempty" json:"optional,omitempty"`
+	Conflicts  []string          `yaml:"conflicts,omitempty" json:"conflicts,omitempty"`
+	Provides   []string          `yaml:"provides,omitempty" json:"provides,omitempty"`
+	Inputs     []string          `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	PreRun     []RunStep         `yaml:"preRun,omitempty" json:"-"`
+	Run        []RunStep         `yaml:"run" json:"-"`
+	PostRun    []RunStep         `yaml:"postRun,omitempty" json:"-"`
+	Rollback   []RunStep         `yaml:"rollback,omitempty" json:"-"`
+	Retry      *RetryPolicy      `yaml:"retry,omitempty" json:"retry,omitempty"`
+	Timeout    time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Runner     string            `yaml:"runner,omitempty" json:"runner,omitempty"`
+	Type       string            `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
-func NewGraphResolver(fs afero.Fs, logger *log.Logger, workDir string, shellSession *runnerexec.ShellSession) (*DependencyResolver, error) {
+func NewGraphResolver(fs afero.Fs, logger Logger, workDir string, shellSession *runnerexec.ShellSession) (*DependencyResolver, error) {
 	dependencyResolver := &DependencyResolver{
 		Fs:                   fs,
 		ResourceDependencies: make(map[string][]string),
 		VisitedPaths:         make(map[string]bool),
+		ProviderPreferences:  make(map[string]string),
 		Logger:               logger,
 		WorkDir:              workDir,
 		ShellSession:         shellSession,
 	}
 
-	dependencyResolver.Graph = graph.NewDependencyGraph(fs, logger, dependencyResolver.ResourceDependencies)
+	dependencyResolver.Graph = graph.NewDependencyGraph(fs, charmLoggerOf(logger), dependencyResolver.ResourceDependencies)
 	if dependencyResolver.Graph == nil {
 		return nil, fmt.Errorf("failed to initialize dependency graph")
 	}
 	return dependencyResolver, nil
 }
+
+// BuildDependencies rebuilds ResourceDependencies from the loaded resources.
+// Virtual requirements are replaced by their provider, and optional
+// requirements are only included when they exist in the catalog, unless
+// IncludeOptional is set.
+func (dr *DependencyResolver) BuildDependencies() {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.buildDependencies()
+}
+
+// buildDependencies is BuildDependencies for callers holding the write lock.
+func (dr *DependencyResolver) buildDependencies() {
+	dr.cache.reset()
+	dr.buildIndex()
+
+	known := dr.knownResources()
+
+	// The graph holds a reference to this map, so it is updated in place.
+	for id := range dr.ResourceDependencies {
+		delete(dr.ResourceDependencies, id)
+	}
+
+	edges := 0
+	for _, entry := range dr.Resources {
+		deps := dr.entryDependencies(entry, known)
+		dr.ResourceDependencies[entry.Id] = deps
+		edges += len(deps)
+	}
+
+	graphResources.Set(float64(len(dr.ResourceDependencies)))
+	graphEdges.Set(float64(edges))
+}
+
+// entryDependencies returns the resolved requirements of an entry. Resources
+// loaded from a lockfile keep the requirements recorded in it.
+func (dr *DependencyResolver) entryDependencies(entry ResourceNodeEntry, known map[string]bool) []string {
+	if locked, ok := dr.locked[entry.Id]; ok {
+		return locked
+	}
+
+	deps := make([]string, 0, len(entry.Requires)+len(entry.Optional))
+	for _, req := range entry.Requires {
+		deps = append(deps, dr.providerFor(req, known))
+	}
+	for _, opt := range entry.Optional {
+		opt = dr.providerFor(opt, known)
+		if dr.IncludeOptional || known[opt] {
+			deps = append(deps, opt)
+		} else {
+			dr.logDebug("Skipping missing optional dependency", entryFields(entry, "", "dependency", opt)...)
+		}
+	}
+	return deps
+}
+
+// knownResources returns the set of loaded resource IDs.
+func (dr *DependencyResolver) knownResources() map[string]bool {
+	known := make(map[string]bool, len(dr.Resources))
+	for _, entry := range dr.Resources {
+		known[entry.Id] = true
+	}
+	return known
+}
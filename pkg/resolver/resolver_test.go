@@ -21,7 +21,7 @@ func setupTestResolver() *DependencyResolver {
 	}
 	defer session.Close()
 
-	resolver, err := NewGraphResolver(fs, logger, "", session)
+	resolver, err := NewGraphResolver(fs, NewCharmLogger(logger), "", session)
 	if err != nil {
 		log.Fatalf("Failed to create dependency resolver: %v", err)
 	}
@@ -229,3 +229,32 @@ a -> c -> b
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output.String())
 	}
 }
+
+func TestBuildDependencies_Optional(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Name: "A"},
+		{Id: "b", Name: "B", Requires: []string{"a"}, Optional: []string{"c", "missing"}},
+		{Id: "c", Name: "C"},
+	}
+	resolver.BuildDependencies()
+
+	deps := resolver.ResourceDependencies["b"]
+	if len(deps) != 2 || deps[0] != "a" || deps[1] != "c" {
+		t.Errorf("Expected dependencies [a c], got %v", deps)
+	}
+	if _, exists := resolver.ResourceDependencies["z"]; exists {
+		t.Errorf("Expected stale dependencies to be removed")
+	}
+
+	resolver.IncludeOptional = true
+	resolver.BuildDependencies()
+
+	deps = resolver.ResourceDependencies["b"]
+	if len(deps) != 3 || deps[2] != "missing" {
+		t.Errorf("Expected dependencies [a c missing], got %v", deps)
+	}
+	if len(resolver.Resources[1].Requires) != 1 {
+		t.Errorf("Expected Requires to be left untouched, got %v", resolver.Resources[1].Requires)
+	}
+}
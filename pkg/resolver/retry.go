@@ -0,0 +1,81 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls how the run steps of a failing resource are retried.
+// The delay before each retry starts at Backoff and doubles on every attempt,
+// up to MaxBackoff when it is set.
+type RetryPolicy struct {
+	// Attempts is the maximum number of executions, including the first one.
+	Attempts   int           `yaml:"attempts" json:"attempts"`
+	Backoff    time.Duration `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	MaxBackoff time.Duration `yaml:"maxBackoff,omitempty" json:"maxBackoff,omitempty"`
+	// ExitCodes restricts the retries to commands exiting with one of these
+	// codes. Any failure is retried when empty.
+	ExitCodes []int `yaml:"exitCodes,omitempty" json:"exitCodes,omitempty"`
+}
+
+// delay returns the backoff before the given retry, starting at 1.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retryable reports whether err may succeed when retried.
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if len(p.ExitCodes) == 0 {
+		return true
+	}
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && slices.Contains(p.ExitCodes, exitErr.ExitCode())
+}
+
+// retryPolicy returns the retry policy of a resource, falling back to the
+// resolver's default.
+func (dr *DependencyResolver) retryPolicy(res ResourceNodeEntry) RetryPolicy {
+	if res.Retry != nil {
+		return *res.Retry
+	}
+	return dr.Retry
+}
+
+// withRetry calls fn until it succeeds, fails with an error the policy of the
+// resource does not retry, or the attempts are exhausted.
+func (dr *DependencyResolver) withRetry(ctx context.Context, res ResourceNodeEntry, fn func() error) error {
+	policy := dr.retryPolicy(res)
+
+	err := fn()
+	for attempt := 2; err != nil && attempt <= policy.Attempts && policy.retryable(err); attempt++ {
+		delay := policy.delay(attempt - 1)
+		dr.logger().Warn("Retrying resource", entryFields(res, phaseRun, "delay", delay, "attempt", attempt, "attempts", policy.Attempts, "err", err)...)
+		resourceRetries.Inc(res.Id)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/yaml.v2"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := policy.delay(i + 1); got != want {
+			t.Errorf("Expected delay %s before retry %d, got %s", want, i+1, got)
+		}
+	}
+}
+
+func TestRun_Retry(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+
+	resolver := setupTestResolver()
+	var buf bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&buf))
+	resolver.Resources = []ResourceNodeEntry{
+		{
+			Id: "flaky",
+			// Fails the first time only.
+			Run:   []RunStep{{Name: "fetch", Exec: "test -f " + marker + " || { touch " + marker + "; exit 75; }"}},
+			Retry: &RetryPolicy{Attempts: 3, Backoff: time.Millisecond, ExitCodes: []int{75}},
+		},
+	}
+	resolver.BuildDependencies()
+
+	retries := resourceRetries.Value("flaky")
+	if err := resolver.Run(context.Background(), []string{"flaky"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := resourceRetries.Value("flaky") - retries; got != 1 {
+		t.Errorf("Expected 1 retry, got %v", got)
+	}
+	if !strings.Contains(buf.String(), "Retrying resource resource=flaky phase=run delay=1ms attempt=2 attempts=3") {
+		t.Errorf("Expected the retry to be logged, got %q", buf.String())
+	}
+}
+
+func TestRun_RetryExhausted(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "broken", Run: []RunStep{{Name: "fail", Exec: "exit 1"}}},
+	}
+	resolver.Retry = RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	resolver.BuildDependencies()
+
+	retries := resourceRetries.Value("broken")
+	if err := resolver.Run(context.Background(), []string{"broken"}); err == nil {
+		t.Fatal("Expected an error after the last attempt")
+	}
+	if got := resourceRetries.Value("broken") - retries; got != 2 {
+		t.Errorf("Expected 2 retries, got %v", got)
+	}
+}
+
+func TestRun_RetryExitCodes(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+	resolver.Resources = []ResourceNodeEntry{
+		{
+			Id:    "fatal",
+			Run:   []RunStep{{Name: "fail", Exec: "exit 2"}},
+			Retry: &RetryPolicy{Attempts: 3, Backoff: time.Millisecond, ExitCodes: []int{75}},
+		},
+	}
+	resolver.BuildDependencies()
+
+	retries := resourceRetries.Value("fatal")
+	if err := resolver.Run(context.Background(), []string{"fatal"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if got := resourceRetries.Value("fatal") - retries; got != 0 {
+		t.Errorf("Expected no retries for an unlisted exit code, got %v", got)
+	}
+}
+
+func TestRun_RetryCancelled(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "broken", Run: []RunStep{{Name: "fail", Exec: "exit 1"}}},
+	}
+	resolver.Retry = RetryPolicy{Attempts: 2, Backoff: time.Hour}
+	resolver.BuildDependencies()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := resolver.Run(ctx, []string{"broken"}); err != context.DeadlineExceeded {
+		t.Errorf("Expected the context error while waiting to retry, got %v", err)
+	}
+}
+
+func TestRetryPolicy_YAML(t *testing.T) {
+	var entry ResourceNodeEntry
+	data := "id: fetch\nretry:\n  attempts: 5\n  backoff: 2s\n  maxBackoff: 1m\n  exitCodes: [75]\n"
+	if err := yaml.Unmarshal([]byte(data), &entry); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := RetryPolicy{Attempts: 5, Backoff: 2 * time.Second, MaxBackoff: time.Minute, ExitCodes: []int{75}}
+	if entry.Retry == nil || !reflect.DeepEqual(*entry.Retry, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entry.Retry)
+	}
+}
@@ -0,0 +1,228 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// sbomNow returns the creation time of SBOM documents: the time in seconds
+// of SOURCE_DATE_EPOCH when set, so that builds are reproducible, or the
+// current time.
+var sbomNow = func() time.Time {
+	if seconds, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+		return time.Unix(seconds, 0)
+	}
+	return time.Now()
+}
+
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	Version      int                   `json:"version"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXComponent struct {
+	Type        string `json:"type"`
+	BOMRef      string `json:"bom-ref"`
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	Group       string `json:"group,omitempty"`
+	// Licenses holds the license of the resource as an SPDX expression.
+	Licenses []cycloneDXLicense `json:"licenses,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// WriteCycloneDX writes the catalog as a CycloneDX 1.5 JSON SBOM: a component
+// per resource, identified by its ID, and the dependencies between them.
+func (dr *DependencyResolver) WriteCycloneDX(w io.Writer) error {
+	entries, deps := dr.sbomGraph()
+
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		Version:      1,
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+	for _, entry := range entries {
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			Type:        "application",
+			BOMRef:      entry.Id,
+			Name:        sbomName(entry),
+			Version:     entry.Version,
+			Description: entry.Desc,
+			Group:       entry.Category,
+			Licenses:    cycloneDXLicenses(entry.License),
+		})
+		bom.Dependencies = append(bom.Dependencies, cycloneDXDependency{Ref: entry.Id, DependsOn: nonNil(deps[entry.Id])})
+	}
+	return RenderJSON(w, bom)
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string `json:"name"`
+	SPDXID           string `json:"SPDXID"`
+	VersionInfo      string `json:"versionInfo,omitempty"`
+	DownloadLocation string `json:"downloadLocation"`
+	FilesAnalyzed    bool   `json:"filesAnalyzed"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	Description      string `json:"description,omitempty"`
+	Comment          string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxInvalidChars matches the characters not allowed in SPDX identifiers.
+var spdxInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// WriteSPDX writes the catalog as an SPDX 2.3 JSON SBOM: a package per
+// resource, with its ID as comment, DEPENDS_ON relationships between them,
+// and the document describing every package.
+func (dr *DependencyResolver) WriteSPDX(w io.Writer) error {
+	entries, deps := dr.sbomGraph()
+
+	hash := sha256.New()
+	spdxIDs := make(map[string]string, len(entries))
+	used := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		hash.Write([]byte(entry.Id + "\x00" + entry.Version + "\x00"))
+		id := "SPDXRef-" + spdxInvalidChars.ReplaceAllString(entry.Id, "-")
+		for i := 2; used[id]; i++ {
+			id = "SPDXRef-" + spdxInvalidChars.ReplaceAllString(entry.Id, "-") + "-" + strconv.Itoa(i)
+		}
+		used[id] = true
+		spdxIDs[entry.Id] = id
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "runner-catalog",
+		DocumentNamespace: "https://spdx.org/spdxdocs/runner-catalog-" + hex.EncodeToString(hash.Sum(nil))[:16],
+		CreationInfo: spdxCreationInfo{
+			Created:  sbomNow().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: runner"},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+	for _, entry := range entries {
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:             sbomName(entry),
+			SPDXID:           spdxIDs[entry.Id],
+			VersionInfo:      entry.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  spdxLicense(entry.License),
+			Description:      entry.Desc,
+			Comment:          "runner resource " + entry.Id,
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: spdxIDs[entry.Id],
+		})
+	}
+	for _, entry := range entries {
+		for _, dep := range deps[entry.Id] {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      spdxIDs[entry.Id],
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: spdxIDs[dep],
+			})
+		}
+	}
+	return RenderJSON(w, doc)
+}
+
+// sbomGraph returns the resources of the catalog, without duplicate IDs, and
+// their dependencies within the catalog: an SBOM cannot refer to resources it
+// does not list.
+func (dr *DependencyResolver) sbomGraph() ([]ResourceNodeEntry, map[string][]string) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	var entries []ResourceNodeEntry
+	seen := make(map[string]bool, len(dr.Resources))
+	for _, entry := range dr.Resources {
+		if !seen[entry.Id] {
+			seen[entry.Id] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	deps := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		for _, dep := range dr.ResourceDependencies[entry.Id] {
+			if seen[dep] {
+				deps[entry.Id] = append(deps[entry.Id], dep)
+			}
+		}
+	}
+	return entries, deps
+}
+
+// sbomName returns the name of a resource in an SBOM, its ID when it has no
+// name.
+func sbomName(entry ResourceNodeEntry) string {
+	if entry.Name != "" {
+		return entry.Name
+	}
+	return entry.Id
+}
+
+// cycloneDXLicenses returns the licenses of a component with the given
+// license, if any.
+func cycloneDXLicenses(license string) []cycloneDXLicense {
+	if license == "" {
+		return nil
+	}
+	return []cycloneDXLicense{{Expression: license}}
+}
+
+// spdxLicense returns the declared license of a package with the given
+// license, NOASSERTION when it has none.
+func spdxLicense(license string) string {
+	if license == "" {
+		return "NOASSERTION"
+	}
+	return license
+}
@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupSBOMResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.LoadMode = LoadLenient
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "git", Name: "Git", Version: "2.45", Category: "vcs", Desc: "Version control", License: "GPL-2.0-only"},
+		{Id: "tools/lint", Requires: []string{"git", "missing"}},
+		{Id: "tools_lint"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	resolver := setupSBOMResolver()
+
+	var output strings.Builder
+	if err := resolver.WriteCycloneDX(&output); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var bom cycloneDXBOM
+	if err := json.Unmarshal([]byte(output.String()), &bom); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" {
+		t.Errorf("Unexpected header: %+v", bom)
+	}
+	expectedComponent := cycloneDXComponent{Type: "application", BOMRef: "git", Name: "Git", Version: "2.45", Description: "Version control", Group: "vcs",
+		Licenses: []cycloneDXLicense{{Expression: "GPL-2.0-only"}}}
+	if len(bom.Components) != 3 || !reflect.DeepEqual(bom.Components[0], expectedComponent) || bom.Components[1].Name != "tools/lint" || bom.Components[1].Licenses != nil {
+		t.Errorf("Unexpected components: %+v", bom.Components)
+	}
+	expectedDependencies := []cycloneDXDependency{
+		{Ref: "git", DependsOn: []string{}},
+		{Ref: "tools/lint", DependsOn: []string{"git"}},
+		{Ref: "tools_lint", DependsOn: []string{}},
+	}
+	if !reflect.DeepEqual(bom.Dependencies, expectedDependencies) {
+		t.Errorf("Expected %+v, got %+v", expectedDependencies, bom.Dependencies)
+	}
+}
+
+func TestWriteSPDX(t *testing.T) {
+	resolver := setupSBOMResolver()
+	t.Setenv("SOURCE_DATE_EPOCH", "1714564800")
+
+	var output strings.Builder
+	if err := resolver.WriteSPDX(&output); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal([]byte(output.String()), &doc); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2024-05-01T12:00:00Z" || !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/runner-catalog-") {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+
+	var ids []string
+	for _, pkg := range doc.Packages {
+		ids = append(ids, pkg.SPDXID)
+	}
+	if expected := []string{"SPDXRef-git", "SPDXRef-tools-lint", "SPDXRef-tools-lint-2"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected unique SPDX identifiers %v, got %v", expected, ids)
+	}
+	if doc.Packages[0].VersionInfo != "2.45" || doc.Packages[0].LicenseDeclared != "GPL-2.0-only" || doc.Packages[1].LicenseDeclared != "NOASSERTION" {
+		t.Errorf("Unexpected package: %+v", doc.Packages[0])
+	}
+
+	dependsOn := spdxRelationship{SPDXElementID: "SPDXRef-tools-lint", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-git"}
+	if len(doc.Relationships) != 4 || doc.Relationships[3] != dependsOn {
+		t.Errorf("Expected 3 DESCRIBES and 1 DEPENDS_ON relationships, got %+v", doc.Relationships)
+	}
+
+	// The namespace only depends on the catalog.
+	var again strings.Builder
+	resolver.WriteSPDX(&again)
+	if again.String() != output.String() {
+		t.Error("Expected the same catalog to give the same document")
+	}
+}
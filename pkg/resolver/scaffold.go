@@ -0,0 +1,159 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// fieldDoc documents a manifest field in the header of the manifests
+// written by InitCatalog.
+type fieldDoc struct {
+	Name, Doc string
+}
+
+// resourceFieldDocs documents every field of resourceSchema, in the order
+// they are listed in the header.
+var resourceFieldDocs = []fieldDoc{
+	{"id", "unique ID of the resource, required"},
+	{"name", "display name"},
+	{"version", "version of what the resource sets up"},
+	{"desc", "description"},
+	{"category", "category grouping related resources"},
+	{"license", "SPDX license expression"},
+	{"owners", "teams or people owning the resource"},
+	{"metadata", "free-form key: value attributes"},
+	{"aliases", "former IDs, still accepted in requirements"},
+	{"deprecated", "true when other resources should stop requiring it"},
+	{"replacedBy", "resource to require instead of a deprecated one"},
+	{"requires", "resources running before this one"},
+	{"optional", "requirements only included when in the catalog"},
+	{"conflicts", "resources that cannot run together with this one"},
+	{"provides", "virtual resources other resources can require"},
+	{"inputs", "glob patterns of the files rerunning the resource on change"},
+	{"preRun", "steps running before the run steps"},
+	{"run", "steps of the resource"},
+	{"postRun", "steps running after the run steps succeed"},
+	{"rollback", "steps undoing the resource when a later one fails"},
+	{"retry", "attempts, backoff, maxBackoff and exitCodes of retries"},
+	{"timeout", "maximum duration of the resource, such as 5m"},
+	{"runner", "plugin running the steps instead of the shell"},
+	{"type", "shell (the default), noop, http-check or file-template"},
+}
+
+// stepFieldDocs documents every field of stepSchema.
+var stepFieldDocs = []fieldDoc{
+	{"name", "display name"},
+	{"exec", "command to run"},
+	{"skip", "rules skipping the step when they hold"},
+	{"check", "rules that must hold before the step runs"},
+	{"expect", "rules that must hold after the step runs"},
+	{"env", "variables set from a value, exec, input or file"},
+}
+
+// InitOptions controls the catalog written by InitCatalog.
+type InitOptions struct {
+	// ResourceDir is the directory, relative to the catalog, of a layout
+	// with one manifest per resource. A single resources.yaml listing
+	// every resource is written when empty.
+	ResourceDir string
+	// Force overwrites the existing files.
+	Force bool
+}
+
+// exampleResource is the resource of the starter catalog.
+const exampleResource = `id: hello
+name: "Hello"
+desc: "Prints a greeting, replace it with your first resource"
+category: "examples"
+# requires:
+#   - another-resource
+run:
+  - name: "Greet"
+    check:
+      - "CMD:echo"
+    exec: echo "Hello from runner"
+`
+
+// manifestHeader returns the comment documenting the manifest format at the
+// top of the starter manifests.
+func manifestHeader() string {
+	var b strings.Builder
+	b.WriteString("# A manifest lists resources under `resources`, or defines a single\n")
+	b.WriteString("# resource at the top level. The fields of a resource are:\n#\n")
+	writeFieldDocs(&b, resourceFieldDocs)
+	b.WriteString("#\n# The preRun, run, postRun and rollback steps accept:\n#\n")
+	writeFieldDocs(&b, stepFieldDocs)
+	b.WriteString("#\n# Rules are prefixed with ENV:, FILE:, DIR:, URL:, CMD: or EXEC:, negated\n")
+	b.WriteString("# with ! and retried until they hold with @, such as \"!@FILE:/tmp/app.lock\".\n")
+	b.WriteString("# Check this file with `runner validate`.\n\n")
+	return b.String()
+}
+
+func writeFieldDocs(b *strings.Builder, docs []fieldDoc) {
+	for _, doc := range docs {
+		fmt.Fprintf(b, "#   %-12s%s\n", doc.Name, doc.Doc)
+	}
+}
+
+// starterFiles returns the files of the starter catalog, by path relative to
+// the catalog, in the order they are written.
+func starterFiles(opts InitOptions) ([]string, map[string]string) {
+	if opts.ResourceDir == "" {
+		var resource strings.Builder
+		resource.WriteString("resources:\n")
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(exampleResource, "\n"), "\n") {
+			prefix := "    "
+			if strings.HasPrefix(line, "id:") {
+				prefix = "  - "
+			}
+			resource.WriteString(prefix + line)
+		}
+		return []string{"runner.yml", "resources.yaml"}, map[string]string{
+			"runner.yml":     "workflows:\n  - resources.yaml\n",
+			"resources.yaml": manifestHeader() + resource.String() + "\n",
+		}
+	}
+	dir := filepath.ToSlash(filepath.Clean(opts.ResourceDir))
+	manifest := filepath.Join(opts.ResourceDir, "hello.yaml")
+	return []string{"runner.yml", manifest}, map[string]string{
+		"runner.yml": "workflows:\n  - " + dir + "/\n",
+		manifest:     manifestHeader() + exampleResource,
+	}
+}
+
+// InitCatalog writes a starter catalog to dir: a runner.yml loading it, and
+// a manifest documenting the format with an example resource, either in
+// resources.yaml or in its own file under ResourceDir. It returns the paths
+// of the written files, and writes none of them if any exists, returning an
+// error wrapping os.ErrExist, unless Force is set.
+func InitCatalog(fs afero.Fs, dir string, opts InitOptions) ([]string, error) {
+	if filepath.IsAbs(opts.ResourceDir) {
+		return nil, fmt.Errorf("the resource directory %s must be relative to the catalog", opts.ResourceDir)
+	}
+	names, contents := starterFiles(opts)
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+		if opts.Force {
+			continue
+		}
+		if _, err := fs.Stat(paths[i]); err == nil {
+			return nil, fmt.Errorf("%s: %w", paths[i], os.ErrExist)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	for i, name := range names {
+		if err := fs.MkdirAll(filepath.Dir(paths[i]), 0755); err != nil {
+			return nil, err
+		}
+		if err := afero.WriteFile(fs, paths[i], []byte(contents[name]), 0644); err != nil {
+			return nil, fmt.Errorf("error writing %s: %w", paths[i], err)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,98 @@
+package resolver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+func TestFieldDocs_CoverSchema(t *testing.T) {
+	for _, test := range []struct {
+		docs   []fieldDoc
+		schema *schema
+	}{{resourceFieldDocs, resourceSchema}, {stepFieldDocs, stepSchema}} {
+		documented := make(map[string]bool, len(test.docs))
+		for _, doc := range test.docs {
+			if _, ok := test.schema.Fields[doc.Name]; !ok {
+				t.Errorf("Documented field %s is not in the schema", doc.Name)
+			}
+			documented[doc.Name] = true
+		}
+		for name := range test.schema.Fields {
+			if !documented[name] {
+				t.Errorf("Field %s of the schema is not documented", name)
+			}
+		}
+	}
+}
+
+func TestInitCatalog(t *testing.T) {
+	for _, opts := range []InitOptions{{}, {ResourceDir: "resources"}} {
+		resolver := setupTestResolver()
+		resolver.Resources = nil
+
+		paths, err := InitCatalog(resolver.Fs, "svc", opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		manifest := filepath.Join("svc", "resources.yaml")
+		if opts.ResourceDir != "" {
+			manifest = filepath.Join("svc", "resources", "hello.yaml")
+		}
+		if expected := []string{filepath.Join("svc", "runner.yml"), manifest}; !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("Expected %v, got %v", expected, paths)
+		}
+
+		data, _ := afero.ReadFile(resolver.Fs, manifest)
+		if err := validateManifest(data, manifest); err != nil {
+			t.Errorf("Expected a valid manifest, got %v", err)
+		}
+		if !strings.Contains(string(data), "#   replacedBy  ") {
+			t.Errorf("Expected the header to document the fields, got:\n%s", data)
+		}
+
+		config, _ := afero.ReadFile(resolver.Fs, paths[0])
+		var parsed struct{ Workflows []string }
+		if err := yamlv3.Unmarshal(config, &parsed); err != nil || len(parsed.Workflows) != 1 {
+			t.Fatalf("Expected a single workflow, got %q: %v", config, err)
+		}
+		if err := resolver.LoadSource(filepath.Join("svc", parsed.Workflows[0])); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resolver.BuildDependencies()
+		hello, ok := resolver.GetResource("hello")
+		if !ok || len(hello.Run) != 1 || len(hello.Requires) != 0 {
+			t.Errorf("Expected the example resource, got %+v", hello)
+		}
+		if findings := resolver.Validate(); len(findings) != 0 {
+			t.Errorf("Expected no findings, got %v", findings)
+		}
+	}
+}
+
+func TestInitCatalog_Existing(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "runner.yml", []byte("workflows: [mine.yaml]\n"), 0644)
+
+	if _, err := InitCatalog(fs, ".", InitOptions{}); !errors.Is(err, os.ErrExist) {
+		t.Errorf("Expected an existing file to fail, got %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "resources.yaml"); exists {
+		t.Error("Expected no file to be written")
+	}
+	if _, err := InitCatalog(fs, ".", InitOptions{Force: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config, _ := afero.ReadFile(fs, "runner.yml"); string(config) != "workflows:\n  - resources.yaml\n" {
+		t.Errorf("Expected runner.yml to be overwritten, got %q", config)
+	}
+	if _, err := InitCatalog(fs, ".", InitOptions{ResourceDir: "/abs"}); err == nil {
+		t.Error("Expected an absolute resource directory to fail")
+	}
+}
@@ -0,0 +1,33 @@
+package resolver
+
+// StronglyConnectedComponents groups the resources into strongly connected
+// components using Tarjan's algorithm. Every group of more than one resource,
+// or a resource requiring itself, is a dependency cycle. Components are
+// listed in reverse topological order, dependencies first.
+func (dr *DependencyResolver) StronglyConnectedComponents() [][]string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	return dr.graph().Components(dr.resourceIDs())
+}
+
+// Cycles returns the strongly connected components that form dependency
+// cycles, in the order of StronglyConnectedComponents.
+func (dr *DependencyResolver) Cycles() [][]string {
+	var cycles [][]string
+	for _, component := range dr.StronglyConnectedComponents() {
+		if len(component) > 1 || dr.requiresItself(component[0]) {
+			cycles = append(cycles, component)
+		}
+	}
+	return cycles
+}
+
+func (dr *DependencyResolver) requiresItself(id string) bool {
+	for _, dep := range dr.DirectDependencies(id) {
+		if dep == id {
+			return true
+		}
+	}
+	return false
+}
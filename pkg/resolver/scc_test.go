@@ -0,0 +1,41 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b"}},
+		{Id: "b", Requires: []string{"c"}},
+		{Id: "c", Requires: []string{"a", "d"}},
+		{Id: "d", Requires: []string{"e"}},
+		{Id: "e", Requires: []string{"d"}},
+		{Id: "f", Requires: []string{"f"}},
+		{Id: "g"},
+	}
+	resolver.BuildDependencies()
+
+	expected := [][]string{{"e", "d"}, {"c", "b", "a"}, {"f"}, {"g"}}
+	if components := resolver.StronglyConnectedComponents(); !reflect.DeepEqual(components, expected) {
+		t.Errorf("Expected %v, got %v", expected, components)
+	}
+
+	expectedCycles := [][]string{{"e", "d"}, {"c", "b", "a"}, {"f"}}
+	if cycles := resolver.Cycles(); !reflect.DeepEqual(cycles, expectedCycles) {
+		t.Errorf("Expected %v, got %v", expectedCycles, cycles)
+	}
+}
+
+func TestStronglyConnectedComponents_Acyclic(t *testing.T) {
+	resolver := setupChainResolver(100000)
+
+	if components := resolver.StronglyConnectedComponents(); len(components) != 100000 {
+		t.Errorf("Expected 100000 components, got %d", len(components))
+	}
+	if cycles := resolver.Cycles(); len(cycles) != 0 {
+		t.Errorf("Expected no cycles, got %v", cycles)
+	}
+}
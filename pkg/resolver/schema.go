@@ -0,0 +1,325 @@
+package resolver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// schemaKind is the kind of value a manifest field holds.
+type schemaKind int
+
+const (
+	kindString schemaKind = iota
+	kindInt
+	kindBool
+	kindDuration
+	kindList
+	kindObject
+	kindAny
+)
+
+func (k schemaKind) String() string {
+	switch k {
+	case kindString:
+		return "a string"
+	case kindInt:
+		return "an integer"
+	case kindBool:
+		return "a boolean"
+	case kindDuration:
+		return "a duration"
+	case kindList:
+		return "a list"
+	case kindObject:
+		return "a mapping"
+	}
+	return "a value"
+}
+
+// schema describes the values a manifest field accepts.
+type schema struct {
+	Kind schemaKind
+	// Fields are the fields of an object.
+	Fields map[string]*schema
+	// Required are the fields an object must set to a non-empty value.
+	Required []string
+	// Items is the schema of the items of a list.
+	Items *schema
+	// Values is the schema of the values of an object accepting any field.
+	Values *schema
+	// Min is the minimum of an integer or duration.
+	Min int64
+}
+
+var (
+	stringSchema   = &schema{Kind: kindString}
+	stringsSchema  = &schema{Kind: kindList, Items: stringSchema}
+	durationSchema = &schema{Kind: kindDuration}
+	rulesSchema    = &schema{Kind: kindList, Items: &schema{Kind: kindAny}}
+
+	envVarSchema = &schema{Kind: kindObject, Required: []string{"name"}, Fields: map[string]*schema{
+		"name":  stringSchema,
+		"value": stringSchema,
+		"exec":  stringSchema,
+		"input": stringSchema,
+		"file":  stringSchema,
+	}}
+
+	stepSchema = &schema{Kind: kindObject, Fields: map[string]*schema{
+		"name":   stringSchema,
+		"exec":   stringSchema,
+		"skip":   rulesSchema,
+		"check":  rulesSchema,
+		"expect": rulesSchema,
+		"env":    {Kind: kindList, Items: envVarSchema},
+	}}
+	stepsSchema = &schema{Kind: kindList, Items: stepSchema}
+
+	retrySchema = &schema{Kind: kindObject, Fields: map[string]*schema{
+		"attempts":   {Kind: kindInt},
+		"backoff":    durationSchema,
+		"maxBackoff": durationSchema,
+		"exitCodes":  {Kind: kindList, Items: &schema{Kind: kindInt, Min: -1 << 31}},
+	}}
+
+	// resourceSchema is the schema of a resource entry.
+	resourceSchema = &schema{Kind: kindObject, Required: []string{"id"}, Fields: map[string]*schema{
+		"id":         stringSchema,
+		"name":       stringSchema,
+		"version":    stringSchema,
+		"desc":       stringSchema,
+		"category":   stringSchema,
+		"license":    stringSchema,
+		"owners":     stringsSchema,
+		"metadata":   {Kind: kindObject, Values: stringSchema},
+		"aliases":    stringsSchema,
+		"deprecated": {Kind: kindBool},
+		"replacedBy": stringSchema,
+		"requires":   stringsSchema,
+		"optional":   stringsSchema,
+		"conflicts":  stringsSchema,
+		"provides":   stringsSchema,
+		"inputs":     stringsSchema,
+		"preRun":     stepsSchema,
+		"run":        stepsSchema,
+		"postRun":    stepsSchema,
+		"rollback":   stepsSchema,
+		"retry":      retrySchema,
+		"timeout":    durationSchema,
+		"runner":     stringSchema,
+		"type":       stringSchema,
+	}}
+
+	// manifestSchema is the schema of a manifest listing resources.
+	manifestSchema = &schema{Kind: kindObject, Fields: map[string]*schema{
+		"resources": {Kind: kindList, Items: resourceSchema},
+		"include":   stringsSchema,
+	}}
+)
+
+// singleResourceSchema is the schema of a manifest defining a single
+// resource at the top level.
+var singleResourceSchema = func() *schema {
+	fields := map[string]*schema{"include": stringsSchema}
+	for name, field := range resourceSchema.Fields {
+		fields[name] = field
+	}
+	return &schema{Kind: kindObject, Required: resourceSchema.Required, Fields: fields}
+}()
+
+// SchemaError is a violation of the manifest schema, at the given line and
+// column of a manifest file.
+type SchemaError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+
+	// unknown is set for unknown fields, which lenient mode only warns about.
+	unknown bool
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// SchemaErrors is every violation of the schema found in a manifest.
+type SchemaErrors []*SchemaError
+
+func (e SchemaErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// validateManifest checks the manifest against the schema. Documents that
+// are not valid YAML are left to the parser to report, and documents that
+// define neither resources nor includes are not manifests, such as
+// configuration files next to them, and are not checked.
+func validateManifest(data []byte, filePath string) error {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return SchemaErrors{{File: filePath, Line: root.Line, Column: root.Column, Message: "expected a mapping of resources"}}
+	}
+
+	s := manifestSchema
+	switch {
+	case mappingValue(root, "id") != nil:
+		s = singleResourceSchema
+	case mappingValue(root, "resources") == nil && mappingValue(root, "include") == nil:
+		return nil
+	}
+
+	v := &schemaValidator{file: filePath}
+	v.validate(root, s, "")
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+// mappingValue returns the value of the key in a mapping node, or nil.
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+type schemaValidator struct {
+	file   string
+	errors SchemaErrors
+}
+
+func (v *schemaValidator) errorf(node *yamlv3.Node, format string, args ...interface{}) {
+	v.errors = append(v.errors, &SchemaError{File: v.file, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks the node against the schema, path being the name of the
+// field in messages.
+func (v *schemaValidator) validate(node *yamlv3.Node, s *schema, path string) {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	field := path
+	if field == "" {
+		field = "manifest"
+	}
+
+	switch s.Kind {
+	case kindAny:
+	case kindString:
+		if node.Kind != yamlv3.ScalarNode {
+			v.errorf(node, "%s must be %s", field, s.Kind)
+		}
+	case kindInt:
+		var n int64
+		if node.Kind != yamlv3.ScalarNode || node.Tag != "!!int" || node.Decode(&n) != nil {
+			v.errorf(node, "%s must be %s", field, s.Kind)
+		} else if n < s.Min {
+			v.errorf(node, "%s must be at least %d, got %d", field, s.Min, n)
+		}
+	case kindBool:
+		if node.Kind != yamlv3.ScalarNode || node.Tag != "!!bool" {
+			v.errorf(node, "%s must be %s", field, s.Kind)
+		}
+	case kindDuration:
+		if node.Kind != yamlv3.ScalarNode {
+			v.errorf(node, "%s must be %s", field, s.Kind)
+		} else if node.Tag != "!!int" {
+			if d, err := time.ParseDuration(node.Value); err != nil {
+				v.errorf(node, "%s must be a duration such as 30s or 5m, got %q", field, node.Value)
+			} else if int64(d) < s.Min {
+				v.errorf(node, "%s must not be negative, got %q", field, node.Value)
+			}
+		}
+	case kindList:
+		if node.Kind != yamlv3.SequenceNode {
+			v.errorf(node, "%s must be %s", field, s.Kind)
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case kindObject:
+		if node.Kind != yamlv3.MappingNode {
+			v.errorf(node, "%s must be %s", field, s.Kind)
+			return
+		}
+		v.validateObject(node, s, path)
+	}
+}
+
+func (v *schemaValidator) validateObject(node *yamlv3.Node, s *schema, path string) {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if seen[key.Value] {
+			v.errorf(key, "duplicate field %s%s", prefix, key.Value)
+			continue
+		}
+		seen[key.Value] = true
+
+		if s.Values != nil {
+			v.validate(value, s.Values, prefix+key.Value)
+			continue
+		}
+		field, ok := s.Fields[key.Value]
+		if !ok {
+			v.errorf(key, "unknown field %s%s%s", prefix, key.Value, suggestField(key.Value, s))
+			v.errors[len(v.errors)-1].unknown = true
+			continue
+		}
+		v.validate(value, field, prefix+key.Value)
+	}
+
+	for _, name := range s.Required {
+		value := mappingValue(node, name)
+		if value == nil || value.Kind == yamlv3.ScalarNode && value.Value == "" {
+			v.errorf(node, "missing required field %s%s", prefix, name)
+		}
+	}
+}
+
+// suggestField returns a hint naming the known field closest to an unknown
+// one, if any is close enough to be a typo.
+func suggestField(name string, s *schema) string {
+	fields := make([]string, 0, len(s.Fields))
+	for field := range s.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	best, bestDistance := "", 3
+	for _, field := range fields {
+		if distance := fuzzy.LevenshteinDistance(strings.ToLower(name), strings.ToLower(field)); distance < bestDistance {
+			best, bestDistance = field, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestValidateManifest(t *testing.T) {
+	valid := []string{
+		"resources:\n  - id: git\n    requires: [a]\n    retry:\n      attempts: 3\n      backoff: 2s\n    timeout: 5m\n",
+		"id: git\ninclude: [other.yaml]\nrun:\n  - name: build\n    exec: make\n    check: [\"FILE:/tmp/out\"]\n    env:\n      - name: GOOS\n        value: linux\n",
+		`{"resources": [{"id": "git", "requires": []}]}`,
+		"workflows: [catalog.yaml]\n",
+		"",
+	}
+	for _, manifest := range valid {
+		if err := validateManifest([]byte(manifest), "catalog.yaml"); err != nil {
+			t.Errorf("Unexpected error for %q: %v", manifest, err)
+		}
+	}
+
+	tests := []struct {
+		manifest string
+		errors   []string
+	}{
+		{"resources:\n  - id: git\n    requiers: [a]\n", []string{"catalog.yaml:3:5: unknown field resources[0].requiers (did you mean requires?)"}},
+		{"resources:\n  - id: git\n    requires: a\n", []string{"catalog.yaml:3:15: resources[0].requires must be a list"}},
+		{"resources:\n  - name: Git\n", []string{"catalog.yaml:2:5: missing required field resources[0].id"}},
+		{"id: git\ntimeout: soon\n", []string{"catalog.yaml:2:10: timeout must be a duration such as 30s or 5m, got \"soon\""}},
+		{"id: git\nretry:\n  attempts: many\n", []string{"catalog.yaml:3:13: retry.attempts must be an integer"}},
+		{"id: git\nretry:\n  attempts: -1\n", []string{"catalog.yaml:3:13: retry.attempts must be at least 0, got -1"}},
+		{"id: git\nmetadata:\n  team: [a]\n", []string{"catalog.yaml:3:9: metadata.team must be a string"}},
+		{"id: git\ndeprecated: soon\n", []string{"catalog.yaml:2:13: deprecated must be a boolean"}},
+		{"id: git\nid: tag\n", []string{"catalog.yaml:2:1: duplicate field id"}},
+		{"id: git\nrun:\n  - exec: make\n    env:\n      - value: x\n", []string{"catalog.yaml:5:9: missing required field run[0].env[0].name"}},
+		{"- id: git\n", []string{"catalog.yaml:1:1: expected a mapping of resources"}},
+		{
+			"resources:\n  - id: git\n    name: [Git]\n  - id: tag\n    desc: {}\n",
+			[]string{"catalog.yaml:3:11: resources[0].name must be a string", "catalog.yaml:5:11: resources[1].desc must be a string"},
+		},
+	}
+	for _, test := range tests {
+		var schemaErrs SchemaErrors
+		err := validateManifest([]byte(test.manifest), "catalog.yaml")
+		if !errors.As(err, &schemaErrs) || len(schemaErrs) != len(test.errors) {
+			t.Errorf("Expected %d schema errors for %q, got %v", len(test.errors), test.manifest, err)
+			continue
+		}
+		for i, expected := range test.errors {
+			if schemaErrs[i].Error() != expected {
+				t.Errorf("Expected %q, got %q", expected, schemaErrs[i].Error())
+			}
+		}
+	}
+}
+
+func TestLoadSource_SchemaErrors(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	afero.WriteFile(resolver.Fs, "catalog.yaml", []byte("resources:\n  - id: git\n    requires: git-core\n"), 0644)
+	err := resolver.LoadSource("catalog.yaml")
+	if err == nil || !strings.Contains(err.Error(), "catalog.yaml:3:15") {
+		t.Errorf("Expected the error to locate the invalid field, got %v", err)
+	}
+	if len(resolver.ListResources()) != 0 {
+		t.Error("Expected nothing to be loaded on error")
+	}
+}
@@ -0,0 +1,130 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// SelfDependencyError is returned when a resource lists itself in its
+// requirements or optional requirements. File, Line and Column locate the
+// requirement, when known.
+type SelfDependencyError struct {
+	Id     string
+	File   string
+	Line   int
+	Column int
+}
+
+func (e *SelfDependencyError) Error() string {
+	message := fmt.Sprintf("resource '%s' requires itself", e.Id)
+	switch {
+	case e.File == "":
+		return message
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, message)
+}
+
+// requiresItself reports whether the entry lists its own ID in its
+// requirements or optional requirements. Requirements resolving to the entry
+// through its aliases or the names it provides are found once the
+// dependencies are built, by checkSelfDependencies.
+func requiresItself(entry ResourceNodeEntry) bool {
+	return slices.Contains(entry.Requires, entry.Id) || slices.Contains(entry.Optional, entry.Id)
+}
+
+// checkSelfDependencies returns a SelfDependencyError for the first resource whose
+// resolved requirements include itself, such as through one of its aliases or
+// a name it provides, naming the source defining it. In lenient mode the
+// requirements resolving to their resource are dropped with a warning instead,
+// and nil is returned. The caller must hold the write lock.
+func (dr *DependencyResolver) checkSelfDependencies(lenient bool) *SelfDependencyError {
+	known := dr.knownResources()
+	dropped := false
+	for i, entry := range dr.Resources {
+		if !slices.Contains(dr.ResourceDependencies[entry.Id], entry.Id) {
+			continue
+		}
+		if !lenient {
+			return &SelfDependencyError{Id: entry.Id, File: dr.sources[entry.Id]}
+		}
+		isSelf := func(req string) bool { return dr.providerFor(req, known) == entry.Id }
+		entry.Requires = slices.DeleteFunc(slices.Clone(entry.Requires), isSelf)
+		entry.Optional = slices.DeleteFunc(slices.Clone(entry.Optional), isSelf)
+		dr.Resources[i] = entry
+		dr.warn(Warning{Code: CodeSelfDependency, Resource: entry.Id, File: dr.sources[entry.Id], Message: fmt.Sprintf("resource '%s' requires itself, ignoring the requirement", entry.Id)})
+		dropped = true
+	}
+	if dropped {
+		dr.buildDependencies()
+	}
+	return nil
+}
+
+// replaceResources replaces the catalog with the resources defined in the
+// sources, and rebuilds the dependencies. A resource requiring itself once
+// its requirements are resolved is a SelfDependencyError restoring the
+// previous catalog, unless lenient is set. The caller must hold the write
+// lock.
+func (dr *DependencyResolver) replaceResources(resources []ResourceNodeEntry, sources map[string]string, lenient bool) *SelfDependencyError {
+	previous, previousSources := dr.Resources, dr.sources
+	dr.Resources, dr.sources = resources, sources
+	dr.buildDependencies()
+	if err := dr.checkSelfDependencies(lenient); err != nil {
+		dr.Resources, dr.sources = previous, previousSources
+		dr.buildDependencies()
+		return err
+	}
+	return nil
+}
+
+// withoutSelf returns the entry without the requirements on itself.
+func withoutSelf(entry ResourceNodeEntry) ResourceNodeEntry {
+	isSelf := func(id string) bool { return id == entry.Id }
+	entry.Requires = slices.DeleteFunc(slices.Clone(entry.Requires), isSelf)
+	entry.Optional = slices.DeleteFunc(slices.Clone(entry.Optional), isSelf)
+	return entry
+}
+
+// selfDependency returns the SelfDependencyError of the entry of the
+// manifest, locating the requirement in its data when possible.
+func (dr *DependencyResolver) selfDependency(manifest manifestFile, entry ResourceNodeEntry) *SelfDependencyError {
+	err := &SelfDependencyError{Id: entry.Id, File: manifest.Path}
+	if node := dr.locateRequirement(manifest.data, entry.Id); node != nil {
+		err.Line, err.Column = node.Line, node.Column
+	}
+	return err
+}
+
+// locateRequirement returns the node of the first requirement of the
+// resource id on itself in the manifest data, or nil. IDs are compared once
+// normalized following the IDPolicy.
+func (dr *DependencyResolver) locateRequirement(data []byte, id string) *yamlv3.Node {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	resources := []*yamlv3.Node{root}
+	if list := mappingValue(root, "resources"); list != nil {
+		resources = list.Content
+	}
+	for _, resource := range resources {
+		if idNode := mappingValue(resource, "id"); idNode == nil || dr.IDs.ID(idNode.Value) != id {
+			continue
+		}
+		for _, field := range []string{"requires", "optional"} {
+			if list := mappingValue(resource, field); list != nil {
+				for _, item := range list.Content {
+					if dr.IDs.ID(item.Value) == id {
+						return item
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
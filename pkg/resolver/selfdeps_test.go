@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func setupSelfDependencyResolver(mode LoadMode) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	resolver.LoadMode = mode
+
+	afero.WriteFile(resolver.Fs, "catalog.yaml", []byte("resources:\n  - id: db\n  - id: app\n    requires: [db,\n      app]\n"), 0644)
+	return resolver
+}
+
+func TestSelfDependency_Strict(t *testing.T) {
+	resolver := setupSelfDependencyResolver(LoadStrict)
+
+	var selfErr *SelfDependencyError
+	err := resolver.LoadSource("catalog.yaml")
+	if !errors.As(err, &selfErr) || *selfErr != (SelfDependencyError{Id: "app", File: "catalog.yaml", Line: 5, Column: 7}) {
+		t.Fatalf("Expected a located self-dependency, got %v", err)
+	}
+	if expected := "catalog.yaml:5:7: resource 'app' requires itself"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestSelfDependency_Lenient(t *testing.T) {
+	resolver := setupSelfDependencyResolver(LoadLenient)
+	if err := resolver.LoadSource("catalog.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if deps := resolver.DependenciesWithin("app", 0); !reflect.DeepEqual(deps, []string{"db"}) {
+		t.Errorf("Expected the requirement on itself to be dropped, got %v", deps)
+	}
+	expected := []Warning{{Code: CodeSelfDependency, Resource: "app", File: "catalog.yaml", Line: 5, Column: 7, Message: "resource 'app' requires itself, ignoring the requirement"}}
+	if warnings := resolver.LoadWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected %v, got %v", expected, warnings)
+	}
+}
+
+func TestSelfDependency_Mutations(t *testing.T) {
+	resolver := setupSelfDependencyResolver(LoadStrict)
+	resolver.Resources = []ResourceNodeEntry{{Id: "db"}}
+	resolver.BuildDependencies()
+
+	var selfErr *SelfDependencyError
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "app", Optional: []string{"app"}}); !errors.As(err, &selfErr) || selfErr.Id != "app" {
+		t.Errorf("Expected adding a self-dependency to fail, got %v", err)
+	}
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "db", Requires: []string{"db"}}); !errors.As(err, &selfErr) {
+		t.Errorf("Expected updating to a self-dependency to fail, got %v", err)
+	}
+	if err := resolver.ApplyOverlays([]ResourceOverlay{{Id: "db", AddRequires: []string{"db"}}}); !errors.As(err, &selfErr) {
+		t.Errorf("Expected an overlay adding a self-dependency to fail, got %v", err)
+	}
+	if deps := resolver.DirectDependencies("db"); len(deps) != 0 {
+		t.Errorf("Expected the catalog to be unchanged, got %v", deps)
+	}
+}
+
+func TestSelfDependency_Resolved(t *testing.T) {
+	resolver := setupSelfDependencyResolver(LoadStrict)
+	afero.WriteFile(resolver.Fs, "resolved.yaml", []byte("resources:\n  - id: x1\n    aliases: [oldx]\n    requires: [oldx]\n"), 0644)
+
+	var selfErr *SelfDependencyError
+	if err := resolver.LoadSource("resolved.yaml"); !errors.As(err, &selfErr) || *selfErr != (SelfDependencyError{Id: "x1", File: "resolved.yaml"}) {
+		t.Fatalf("Expected a requirement on an alias of itself to fail, got %v", err)
+	}
+	if _, ok := resolver.GetResource("x1"); ok {
+		t.Error("Expected the catalog to be unchanged")
+	}
+
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "y1", Provides: []string{"svc"}, Requires: []string{"svc"}}); !errors.As(err, &selfErr) || selfErr.Id != "y1" {
+		t.Errorf("Expected a requirement on a name provided by itself to fail, got %v", err)
+	}
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "y1", Provides: []string{"svc"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "y1", Provides: []string{"svc"}, Optional: []string{"svc"}}); !errors.As(err, &selfErr) {
+		t.Errorf("Expected updating to a self-dependency to fail, got %v", err)
+	}
+	if err := resolver.ApplyOverlays([]ResourceOverlay{{Id: "y1", AddRequires: []string{"svc"}}}); !errors.As(err, &selfErr) {
+		t.Errorf("Expected an overlay adding a self-dependency to fail, got %v", err)
+	}
+	if entry, _ := resolver.GetResource("y1"); len(entry.Requires)+len(entry.Optional) != 0 {
+		t.Errorf("Expected the resource to be unchanged, got %+v", entry)
+	}
+	if cycles := resolver.Cycles(); len(cycles) != 0 {
+		t.Errorf("Expected no self-dependency in the graph, got %v", cycles)
+	}
+}
+
+func TestSelfDependency_ResolvedLenient(t *testing.T) {
+	resolver := setupSelfDependencyResolver(LoadLenient)
+	afero.WriteFile(resolver.Fs, "resolved.yaml", []byte("resources:\n  - id: db\n  - id: x1\n    aliases: [oldx]\n    requires: [db, oldx]\n"), 0644)
+	if err := resolver.LoadSource("resolved.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if deps := resolver.DirectDependencies("x1"); !reflect.DeepEqual(deps, []string{"db"}) {
+		t.Errorf("Expected the requirement on itself to be dropped, got %v", deps)
+	}
+	expected := []Warning{{Code: CodeSelfDependency, Resource: "x1", File: "resolved.yaml", Message: "resource 'x1' requires itself, ignoring the requirement"}}
+	if warnings := resolver.LoadWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected %v, got %v", expected, warnings)
+	}
+}
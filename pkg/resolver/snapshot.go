@@ -0,0 +1,159 @@
+package resolver
+
+import (
+	"io"
+	"maps"
+	"slices"
+)
+
+// Snapshot is a read-only copy of the catalog and dependency graph of a
+// resolver. It doesn't change when the resolver is mutated or reloaded, so
+// concurrent readers, such as the requests of the server, see a consistent
+// catalog for as long as they hold it. All methods are safe for concurrent
+// use, and the returned values must not be modified.
+type Snapshot struct {
+	dr *DependencyResolver
+}
+
+// Snapshot returns a deep copy of the catalog and dependency graph. The
+// snapshot is shared until the catalog changes, so taking one per request is
+// cheap.
+func (dr *DependencyResolver) Snapshot() *Snapshot {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	dr.cache.mu.Lock()
+	defer dr.cache.mu.Unlock()
+	if dr.cache.snapshot == nil {
+		dr.cache.snapshot = &Snapshot{dr: dr.clone()}
+	}
+	return dr.cache.snapshot
+}
+
+// clone returns a resolver holding a deep copy of the catalog and the
+// dependencies, without any of the run settings. The caller must hold the
+// lock.
+func (dr *DependencyResolver) clone() *DependencyResolver {
+	clone := &DependencyResolver{
+		Resources:            make([]ResourceNodeEntry, len(dr.Resources)),
+		ResourceDependencies: make(map[string][]string, len(dr.ResourceDependencies)),
+		Logger:               dr.Logger,
+		WorkDir:              dr.WorkDir,
+		sources:              maps.Clone(dr.sources),
+		warnings:             slices.Clone(dr.warnings),
+		MaxTraversalDepth:    dr.MaxTraversalDepth,
+		MaxClosureSize:       dr.MaxClosureSize,
+		MaxPaths:             dr.MaxPaths,
+	}
+	for i, entry := range dr.Resources {
+		clone.Resources[i] = cloneEntry(entry)
+	}
+	for id, deps := range dr.ResourceDependencies {
+		clone.ResourceDependencies[id] = slices.Clone(deps)
+	}
+	clone.buildIndex()
+	return clone
+}
+
+// cloneEntry returns a copy of entry sharing no slice or map with it.
+func cloneEntry(entry ResourceNodeEntry) ResourceNodeEntry {
+	entry.Aliases = slices.Clone(entry.Aliases)
+	entry.Owners = slices.Clone(entry.Owners)
+	entry.Metadata = maps.Clone(entry.Metadata)
+	entry.Requires = slices.Clone(entry.Requires)
+	entry.Optional = slices.Clone(entry.Optional)
+	entry.Conflicts = slices.Clone(entry.Conflicts)
+	entry.Provides = slices.Clone(entry.Provides)
+	entry.PreRun = cloneSteps(entry.PreRun)
+	entry.Run = cloneSteps(entry.Run)
+	entry.PostRun = cloneSteps(entry.PostRun)
+	entry.Rollback = cloneSteps(entry.Rollback)
+	if entry.Retry != nil {
+		retry := *entry.Retry
+		entry.Retry = &retry
+	}
+	return entry
+}
+
+func cloneSteps(steps []RunStep) []RunStep {
+	steps = slices.Clone(steps)
+	for i := range steps {
+		steps[i].Env = slices.Clone(steps[i].Env)
+	}
+	return steps
+}
+
+// Resources returns the resource entries of the snapshot.
+func (s *Snapshot) Resources() []ResourceNodeEntry {
+	return s.dr.ListResources()
+}
+
+// Warnings returns the warnings of the catalog of the snapshot.
+func (s *Snapshot) Warnings() []Warning {
+	return s.dr.Warnings()
+}
+
+// ResourcesPage returns a page of the resources, in catalog order, and the
+// total number of resources.
+func (s *Snapshot) ResourcesPage(page Page) ([]ResourceNodeEntry, int) {
+	return s.dr.ListResourcesPage(page)
+}
+
+// Resource returns the resource entry with the given ID.
+func (s *Snapshot) Resource(id string) (ResourceNodeEntry, bool) {
+	return s.dr.GetResource(id)
+}
+
+// Edges returns every dependency edge of the graph, in catalog order.
+func (s *Snapshot) Edges() []Edge {
+	return s.dr.Edges()
+}
+
+// DirectDependencies returns the resources the given resource requires.
+func (s *Snapshot) DirectDependencies(id string) []string {
+	return s.dr.DirectDependencies(id)
+}
+
+// ReverseDependencies returns the resources that directly require the given
+// resource, in catalog order.
+func (s *Snapshot) ReverseDependencies(id string) []string {
+	return s.dr.ReverseDependencies(id)
+}
+
+// DependenciesWithin returns the resources at most maxDepth requirements away
+// from the given resource, in breadth-first order, or its whole closure when
+// maxDepth is 0.
+func (s *Snapshot) DependenciesWithin(id string, maxDepth int) []string {
+	return s.dr.DependenciesWithin(id, maxDepth)
+}
+
+// DependentsWithin returns the resources requiring the given resource through
+// at most maxDepth requirements, in breadth-first order, or all of them when
+// maxDepth is 0.
+func (s *Snapshot) DependentsWithin(id string, maxDepth int) []string {
+	return s.dr.DependentsWithin(id, maxDepth)
+}
+
+// DependencyOrder returns the closure of the given resource in execution
+// order, limited to resources at most maxDepth requirements away unless
+// maxDepth is 0. A closure exceeding the limits of the resolver fails with a
+// TraversalLimitError.
+func (s *Snapshot) DependencyOrder(id string, maxDepth int) ([]string, error) {
+	return s.dr.DependencyOrder(id, maxDepth)
+}
+
+// Depths returns the length of the longest requirement chain starting at every
+// resource of the snapshot.
+func (s *Snapshot) Depths() map[string]int {
+	return s.dr.Depths()
+}
+
+// WriteDOT writes the dependency graph of the snapshot in Graphviz DOT format.
+func (s *Snapshot) WriteDOT(w io.Writer) error {
+	return s.dr.WriteDOT(w)
+}
+
+// ExportGraphML writes the dependency graph of the snapshot in GraphML format.
+func (s *Snapshot) ExportGraphML(w io.Writer) error {
+	return s.dr.ExportGraphML(w)
+}
@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func setupSnapshotResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Owners: []string{"team-web"}, Metadata: map[string]string{"tier": "web"}, Requires: []string{"api"}},
+		{Id: "api", Requires: []string{"db"}, Run: []RunStep{{Name: "start", Env: []EnvVar{{Name: "PORT", Value: "80"}}}}},
+		{Id: "db"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestSnapshot(t *testing.T) {
+	resolver := setupSnapshotResolver()
+	snapshot := resolver.Snapshot()
+
+	if len(snapshot.Resources()) != 3 {
+		t.Fatalf("Expected 3 resources, got %v", snapshot.Resources())
+	}
+	if deps := snapshot.DependenciesWithin("app", 0); !reflect.DeepEqual(deps, []string{"api", "db"}) {
+		t.Errorf("Expected [api db], got %v", deps)
+	}
+	if rdeps := snapshot.DependentsWithin("db", 1); !reflect.DeepEqual(rdeps, []string{"api"}) {
+		t.Errorf("Expected [api], got %v", rdeps)
+	}
+	if order, _ := snapshot.DependencyOrder("app", 0); !reflect.DeepEqual(order, []string{"db", "api", "app"}) {
+		t.Errorf("Expected [db api app], got %v", order)
+	}
+	if edges := snapshot.Edges(); len(edges) != 2 {
+		t.Errorf("Expected 2 edges, got %v", edges)
+	}
+
+	var dot bytes.Buffer
+	if err := snapshot.WriteDOT(&dot); err != nil || !strings.Contains(dot.String(), `"app" -> "api"`) {
+		t.Errorf("Expected a DOT graph with app -> api, got %q (%v)", dot.String(), err)
+	}
+	var graphML bytes.Buffer
+	if err := snapshot.ExportGraphML(&graphML); err != nil || !strings.Contains(graphML.String(), "<graphml") {
+		t.Errorf("Expected a GraphML document, got %q (%v)", graphML.String(), err)
+	}
+}
+
+func TestSnapshot_Isolation(t *testing.T) {
+	resolver := setupSnapshotResolver()
+	snapshot := resolver.Snapshot()
+
+	if resolver.Snapshot() != snapshot {
+		t.Errorf("Expected the snapshot to be shared until the catalog changes")
+	}
+
+	resolver.Resources[0].Owners[0] = "changed"
+	resolver.Resources[0].Metadata["tier"] = "changed"
+	resolver.Resources[1].Run[0].Env[0].Value = "changed"
+	resolver.ResourceDependencies["app"][0] = "changed"
+
+	app, _ := snapshot.Resource("app")
+	if app.Owners[0] != "team-web" || app.Metadata["tier"] != "web" {
+		t.Errorf("Expected the snapshot to share no data with the catalog, got %+v", app)
+	}
+	api, _ := snapshot.Resource("api")
+	if api.Run[0].Env[0].Value != "80" {
+		t.Errorf("Expected the run steps to be copied, got %+v", api.Run)
+	}
+	if deps := snapshot.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"api"}) {
+		t.Errorf("Expected the dependencies to be copied, got %v", deps)
+	}
+
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "cache", Requires: []string{"db"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := snapshot.Resource("cache"); ok {
+		t.Errorf("Expected the snapshot not to see resources added later")
+	}
+	if rdeps := snapshot.ReverseDependencies("db"); !reflect.DeepEqual(rdeps, []string{"api"}) {
+		t.Errorf("Expected [api], got %v", rdeps)
+	}
+
+	fresh := resolver.Snapshot()
+	if fresh == snapshot {
+		t.Fatalf("Expected a new snapshot after the catalog changed")
+	}
+	if _, ok := fresh.Resource("cache"); !ok {
+		t.Errorf("Expected the new snapshot to include the added resource")
+	}
+}
+
+func TestSnapshot_ConcurrentMutations(t *testing.T) {
+	resolver := setupSnapshotResolver()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				snapshot := resolver.Snapshot()
+				order, _ := snapshot.DependencyOrder("app", 0)
+				if _, ok := snapshot.Resource(order[0]); !ok {
+					t.Errorf("Expected %s to be in the snapshot", order[0])
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		entry := ResourceNodeEntry{Id: "api", Requires: []string{"db"}}
+		if i%2 == 0 {
+			entry.Requires = nil
+		}
+		if err := resolver.UpdateResource(entry); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,178 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// gitSourcePrefix marks a catalog source as a git repository.
+const gitSourcePrefix = "git+"
+
+// LoadSource loads the resources of a catalog source, which is one of:
+//
+//   - a git repository, as git+<url>[//<dir>][#<ref>]
+//   - an HTTP(S) URL
+//   - a glob pattern
+//   - a directory of manifest files
+//   - a manifest file
+//
+// The source is remembered, so Reload can load it again.
+func (dr *DependencyResolver) LoadSource(source string) (err error) {
+	_, span := tracer.Start(context.Background(), "load catalog", trace.WithAttributes(attrSource.String(source)))
+	defer func() { endSpan(span, err) }()
+
+	if err := dr.loadSource(source); err != nil {
+		return err
+	}
+	dr.mu.Lock()
+	dr.loadedSources = append(dr.loadedSources, source)
+	span.SetAttributes(attrResources.Int(len(dr.Resources)))
+	dr.mu.Unlock()
+	return nil
+}
+
+// loadSource is LoadSource without remembering the source.
+func (dr *DependencyResolver) loadSource(source string) error {
+	switch {
+	case strings.HasPrefix(source, gitSourcePrefix):
+		return dr.LoadResourcesFromGit(source)
+	case isURL(source):
+		return dr.LoadResourcesFromURL(source)
+	case IsGlob(source):
+		return dr.LoadResourcesGlob(source)
+	}
+	if isDir, _ := afero.IsDir(dr.Fs, source); isDir {
+		return dr.LoadResourcesFromDir(source)
+	}
+	return dr.loadResourceFiles([]string{source})
+}
+
+// GitSource is a catalog stored in a git repository.
+type GitSource struct {
+	// URL is the repository to clone.
+	URL string
+	// Dir is the directory of the catalog in the repository, the root when
+	// empty.
+	Dir string
+	// Ref is the branch, tag or commit to check out, the default branch when
+	// empty.
+	Ref string
+}
+
+// ParseGitSource parses a git source of the form git+<url>[//<dir>][#<ref>],
+// such as git+https://example.com/catalog.git//resources#v1.2.0. The URL and
+// the ref cannot start with "-", which git would take for an option, and the
+// directory must stay inside the repository.
+func ParseGitSource(source string) (GitSource, error) {
+	if !strings.HasPrefix(source, gitSourcePrefix) {
+		return GitSource{}, fmt.Errorf("invalid git source '%s': expected the %s prefix", source, gitSourcePrefix)
+	}
+	rest := strings.TrimPrefix(source, gitSourcePrefix)
+
+	var git GitSource
+	rest, git.Ref, _ = strings.Cut(rest, "#")
+
+	// The directory follows the first "//" after the scheme.
+	schemeEnd := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+	if i := strings.Index(rest[schemeEnd:], "//"); i >= 0 {
+		git.Dir = rest[schemeEnd+i+2:]
+		rest = rest[:schemeEnd+i]
+	}
+	git.URL = rest
+
+	if git.URL == "" {
+		return GitSource{}, fmt.Errorf("invalid git source '%s': missing repository URL", source)
+	}
+	if strings.HasPrefix(git.URL, "-") || strings.HasPrefix(git.Ref, "-") {
+		return GitSource{}, fmt.Errorf("invalid git source '%s': the repository URL and ref cannot start with '-'", source)
+	}
+	if git.Dir != "" {
+		git.Dir = path.Clean(git.Dir)
+		if !filepath.IsLocal(filepath.FromSlash(git.Dir)) {
+			return GitSource{}, fmt.Errorf("invalid git source '%s': directory '%s' is outside the repository", source, git.Dir)
+		}
+	}
+	return git, nil
+}
+
+// LoadResourcesFromGit loads the catalog of a git source. The repository is
+// fetched into CacheDir, so later loads only fetch the requested ref. Running
+// git requires CacheDir to be on the OS filesystem.
+func (dr *DependencyResolver) LoadResourcesFromGit(source string) error {
+	git, err := ParseGitSource(source)
+	if err != nil {
+		return err
+	}
+	if dr.CacheDir == "" {
+		return fmt.Errorf("cannot load %s: no cache directory to clone into", source)
+	}
+
+	key := sha256.Sum256([]byte(git.URL))
+	checkout := filepath.Join(dr.CacheDir, "git", hex.EncodeToString(key[:]))
+
+	if exists, _ := afero.DirExists(dr.Fs, filepath.Join(checkout, ".git")); !exists {
+		if err := dr.Fs.MkdirAll(checkout, 0755); err != nil {
+			return fmt.Errorf("error creating cache directory: %w", err)
+		}
+		if _, err := runGit(checkout, "init", "--quiet"); err != nil {
+			return err
+		}
+		if _, err := runGit(checkout, "remote", "add", "--end-of-options", "origin", git.URL); err != nil {
+			return err
+		}
+	}
+
+	ref := git.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := runGit(checkout, "fetch", "--quiet", "--depth", "1", "--end-of-options", "origin", ref); err != nil {
+		return err
+	}
+	if _, err := runGit(checkout, "checkout", "--quiet", "--force", "FETCH_HEAD"); err != nil {
+		return err
+	}
+	commit, err := runGit(checkout, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	dr.logger().Info("Loading catalog", "url", git.URL, "ref", ref, "commit", commit)
+
+	dir := filepath.Join(checkout, filepath.FromSlash(git.Dir))
+	if _, ok := dr.verification(source); ok {
+		listing, err := dr.catalogListing(dir)
+		if err != nil {
+			return err
+		}
+		if err := dr.verifyCatalog(source, listing); err != nil {
+			return err
+		}
+	}
+	return dr.LoadResourcesFromDir(dir)
+}
+
+// runGit runs git in the directory, returning its trimmed output.
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
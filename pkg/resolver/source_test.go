@@ -0,0 +1,104 @@
+package resolver
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected GitSource
+	}{
+		{"git+https://example.com/catalog.git", GitSource{URL: "https://example.com/catalog.git"}},
+		{"git+https://example.com/catalog.git#v1.2.0", GitSource{URL: "https://example.com/catalog.git", Ref: "v1.2.0"}},
+		{"git+https://example.com/catalog.git//resources/prod#main", GitSource{URL: "https://example.com/catalog.git", Dir: "resources/prod", Ref: "main"}},
+		{"git+/srv/catalog//resources", GitSource{URL: "/srv/catalog", Dir: "resources"}},
+		{"git+/srv/catalog//resources/./prod/", GitSource{URL: "/srv/catalog", Dir: "resources/prod"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseGitSource(tt.source)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", tt.source, err)
+		}
+		if got != tt.expected {
+			t.Errorf("Expected %+v for %s, got %+v", tt.expected, tt.source, got)
+		}
+	}
+
+	for _, source := range []string{
+		"https://example.com/catalog.git",
+		"git+#main",
+		"git+https://example.com/catalog.git#--upload-pack=touch /tmp/x;",
+		"git+--upload-pack=touch /tmp/x;",
+		"git+https://example.com/catalog.git//../../etc",
+		"git+https://example.com/catalog.git//resources/../..",
+		"git+/srv/catalog///etc",
+	} {
+		if _, err := ParseGitSource(source); err == nil {
+			t.Errorf("Expected an error for %s", source)
+		}
+	}
+}
+
+func TestLoadResourcesFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Join(repo, filepath.Dir(name)), 0755)
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "--quiet")
+	write("resources/git.yaml", "id: git\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	write("resources/tag.yaml", "id: tag\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v2")
+
+	cacheDir := t.TempDir()
+	load := func(source string) []string {
+		t.Helper()
+		resolver := setupTestResolver()
+		resolver.Resources = nil
+		resolver.Fs = afero.NewOsFs()
+		resolver.CacheDir = cacheDir
+		if err := resolver.LoadSource(source); err != nil {
+			t.Fatalf("Unexpected error loading %s: %v", source, err)
+		}
+		var ids []string
+		for _, entry := range resolver.ListResources() {
+			ids = append(ids, entry.Id)
+		}
+		return ids
+	}
+
+	if ids := load("git+" + repo + "//resources#v1"); !reflect.DeepEqual(ids, []string{"git"}) {
+		t.Errorf("Expected the catalog at v1, got %v", ids)
+	}
+	// The cached checkout is updated to the requested ref.
+	if ids := load("git+" + repo + "//resources"); !reflect.DeepEqual(ids, []string{"git", "tag"}) {
+		t.Errorf("Expected the catalog at HEAD, got %v", ids)
+	}
+}
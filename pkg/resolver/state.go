@@ -0,0 +1,261 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// StatusVersion is the format version written to the RecordsFile.
+const StatusVersion = 1
+
+// ResourceState is the last known state of a resource across runs.
+type ResourceState string
+
+const (
+	// StateInstalled is a resource whose last run succeeded, and whose
+	// definition did not change since.
+	StateInstalled ResourceState = "installed"
+	// StatePending is a resource never run, or changed since it was installed.
+	StatePending ResourceState = "pending"
+	// StateFailed is a resource whose last run failed.
+	StateFailed ResourceState = "failed"
+)
+
+// ResourceRecord is what a run recorded about a resource: its state and the
+// fingerprint of its definition and inputs when it ran.
+type ResourceRecord struct {
+	State       ResourceState `yaml:"state"`
+	Fingerprint string        `yaml:"fingerprint"`
+	Updated     time.Time     `yaml:"updated"`
+	Error       string        `yaml:"error,omitempty"`
+}
+
+// ResourceRecords is the content of the RecordsFile, recording the last known
+// state of every resource run, by ID.
+type ResourceRecords struct {
+	StatusVersion int                       `yaml:"statusVersion"`
+	Resources     map[string]ResourceRecord `yaml:"resources"`
+}
+
+// ResourceStatusEntry is the state of a resource reported by Status.
+type ResourceStatusEntry struct {
+	Id    string        `json:"id" yaml:"id"`
+	State ResourceState `json:"state" yaml:"state"`
+	// Changed is set when the definition or the inputs of the resource
+	// changed since its last run.
+	Changed bool       `json:"changed,omitempty" yaml:"changed,omitempty"`
+	Updated *time.Time `json:"updated,omitempty" yaml:"updated,omitempty"`
+	Error   string     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// LoadRecords reads the records saved in the resolver's RecordsFile. There are
+// no records when the file does not exist or RecordsFile is empty.
+func (dr *DependencyResolver) LoadRecords() (*ResourceRecords, error) {
+	records := &ResourceRecords{StatusVersion: StatusVersion, Resources: make(map[string]ResourceRecord)}
+	if dr.RecordsFile == "" {
+		return records, nil
+	}
+	data, err := afero.ReadFile(dr.Fs, dr.RecordsFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return records, nil
+		}
+		return nil, fmt.Errorf("error reading records file %s: %w", dr.RecordsFile, err)
+	}
+
+	if err := yaml.Unmarshal(data, records); err != nil {
+		return nil, fmt.Errorf("error unmarshalling records file %s: %w", dr.RecordsFile, err)
+	}
+	if records.StatusVersion != StatusVersion {
+		return nil, fmt.Errorf("unsupported records file version %d in %s", records.StatusVersion, dr.RecordsFile)
+	}
+	if records.Resources == nil {
+		records.Resources = make(map[string]ResourceRecord)
+	}
+	return records, nil
+}
+
+// saveRecords writes the records to the RecordsFile. It does nothing when
+// RecordsFile is empty.
+func (dr *DependencyResolver) saveRecords(records *ResourceRecords) error {
+	if dr.RecordsFile == "" {
+		return nil
+	}
+	data, err := yaml.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("error marshalling status: %w", err)
+	}
+	if err := writeFileAtomic(dr.Fs, dr.RecordsFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing records file %s: %w", dr.RecordsFile, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes the file through a temporary file renamed into
+// place, so that readers and interrupted runs see either the previous content
+// or the new one, never a partial write. The directory of the file is created
+// when missing.
+func writeFileAtomic(fs afero.Fs, path string, data []byte, perm os.FileMode) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := afero.TempFile(fs, filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fs.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = fs.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		fs.Remove(tmp.Name())
+	}
+	return err
+}
+
+// recordResult records the outcome of running a resource, failed when err is
+// not nil, in the records of the run, and saves it to the RecordsFile. The
+// file is read again before saving, so that concurrent runs keep the
+// outcomes recorded by each other.
+func (dr *DependencyResolver) recordResult(records *ResourceRecords, entry ResourceNodeEntry, err error) error {
+	if dr.RecordsFile == "" {
+		return nil
+	}
+	fingerprint, fpErr := dr.fingerprint(entry)
+	if fpErr != nil {
+		return fpErr
+	}
+	record := ResourceRecord{State: StateInstalled, Fingerprint: fingerprint, Updated: time.Now().UTC()}
+	if err != nil {
+		record.State, record.Error = StateFailed, err.Error()
+	}
+	records.Resources[entry.Id] = record
+
+	dr.recordsMu.Lock()
+	defer dr.recordsMu.Unlock()
+	latest, loadErr := dr.LoadRecords()
+	if loadErr != nil {
+		return loadErr
+	}
+	latest.Resources[entry.Id] = record
+	return dr.saveRecords(latest)
+}
+
+// Status returns the state of the resources in the closure of the targets, in
+// execution order, or of every resource when no target is given, according to
+// the records of the RecordsFile.
+func (dr *DependencyResolver) Status(targets []string) ([]ResourceStatusEntry, error) {
+	var entries []ResourceNodeEntry
+	if len(targets) == 0 {
+		entries = dr.ListResources()
+	} else {
+		stack, err := dr.Resolve(context.Background(), targets)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range stack {
+			if entry, ok := dr.GetResource(id); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	records, err := dr.LoadRecords()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]ResourceStatusEntry, 0, len(entries))
+	for _, entry := range entries {
+		status := ResourceStatusEntry{Id: entry.Id, State: StatePending}
+		if record, ok := records.Resources[entry.Id]; ok {
+			fingerprint, err := dr.fingerprint(entry)
+			if err != nil {
+				return nil, err
+			}
+			updated := record.Updated
+			status.Updated = &updated
+			status.Error = record.Error
+			status.Changed = record.Fingerprint != fingerprint
+			status.State = record.State
+			if status.State == StateInstalled && status.Changed {
+				status.State = StatePending
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// StatusTable returns the state of resources as a table.
+func StatusTable(statuses []ResourceStatusEntry) Table {
+	table := Table{Header: []string{"RESOURCE", "STATE", "CHANGED", "UPDATED", "ERROR"}}
+	for _, status := range statuses {
+		changed, updated := "", ""
+		if status.Changed {
+			changed = "yes"
+		}
+		if status.Updated != nil {
+			updated = status.Updated.Format(time.RFC3339)
+		}
+		table.Rows = append(table.Rows, []string{status.Id, string(status.State), changed, updated, status.Error})
+	}
+	return table
+}
+
+// statusPorcelain returns the porcelain records of the state of resources.
+func statusPorcelain(statuses []ResourceStatusEntry) Porcelain {
+	return Porcelain{PorcelainV1: func() [][]string {
+		records := make([][]string, 0, len(statuses))
+		for _, status := range statuses {
+			changed, updated := "", ""
+			if status.Changed {
+				changed = "yes"
+			}
+			if status.Updated != nil {
+				updated = status.Updated.Format(time.RFC3339)
+			}
+			records = append(records, []string{status.Id, string(status.State), changed, updated, status.Error})
+		}
+		return records
+	}}
+}
+
+// HandleStatusCommand handles the 'status' command, showing which resources
+// in the closure of the given resources, or in the catalog, are installed,
+// pending or failed.
+func (dr *DependencyResolver) HandleStatusCommand(resources []string) error {
+	statuses, err := dr.Status(resources)
+	if err != nil {
+		return err
+	}
+	return dr.render(nonNil(statuses), func() Table { return StatusTable(statuses) }, statusPorcelain(statuses), func() {
+		for _, status := range statuses {
+			switch {
+			case status.State == StateInstalled:
+				Println("✅", styles.id.Render(status.Id))
+			case status.State == StateFailed:
+				Println("❌", styles.id.Render(status.Id)+":", styles.err.Render(status.Error))
+			case status.Changed:
+				Println("⏳", styles.id.Render(status.Id), "(changed)")
+			default:
+				Println("⏳", styles.id.Render(status.Id))
+			}
+		}
+	})
+}
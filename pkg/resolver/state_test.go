@@ -0,0 +1,163 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// states returns the state of every resource of the statuses, by ID.
+func states(statuses []ResourceStatusEntry) map[string]ResourceState {
+	got := make(map[string]ResourceState, len(statuses))
+	for _, status := range statuses {
+		got[status.Id] = status.State
+	}
+	return got
+}
+
+func TestStatus(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	resolver.RecordsFile = "status.yaml"
+	resolver.FailurePolicy = ContinueAll
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "base", Run: []RunStep{{Name: "ok", Exec: "true"}}},
+		{Id: "broken", Requires: []string{"base"}, Run: []RunStep{{Name: "fail", Exec: "false"}}},
+		{Id: "app", Requires: []string{"base"}, Run: []RunStep{{Name: "ok", Exec: "true"}}},
+		{Id: "idle", Run: []RunStep{{Name: "ok", Exec: "true"}}},
+	}
+	resolver.BuildDependencies()
+
+	statuses, err := resolver.Status(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for id, state := range states(statuses) {
+		if state != StatePending {
+			t.Errorf("Expected '%s' to be pending before any run, got %s", id, state)
+		}
+	}
+
+	if _, err := resolver.Execute(context.Background(), []string{"broken", "app"}); err == nil {
+		t.Fatal("Expected the run to fail")
+	}
+
+	statuses, err = resolver.Status(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]ResourceState{"base": StateInstalled, "broken": StateFailed, "app": StateInstalled, "idle": StatePending}
+	for id, want := range expected {
+		if got := states(statuses)[id]; got != want {
+			t.Errorf("Expected '%s' to be %s, got %s", id, want, got)
+		}
+	}
+
+	// Changing an installed resource makes it pending again.
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "app", Requires: []string{"base"}, Run: []RunStep{{Name: "ok", Exec: "echo changed"}}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	statuses, err = resolver.Status([]string{"app"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Id != "base" || statuses[1].Id != "app" {
+		t.Fatalf("Expected the closure of 'app' in execution order, got %+v", statuses)
+	}
+	if statuses[1].State != StatePending || !statuses[1].Changed {
+		t.Errorf("Expected 'app' to be pending and changed, got %+v", statuses[1])
+	}
+}
+
+func TestStatus_NoStatusFile(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	resolver.Resources = []ResourceNodeEntry{{Id: "base", Run: []RunStep{{Name: "ok", Exec: "true"}}}}
+	resolver.BuildDependencies()
+
+	if _, err := resolver.Execute(context.Background(), []string{"base"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	statuses, err := resolver.Status(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := states(statuses)["base"]; got != StatePending {
+		t.Errorf("Expected 'base' to be pending without a records file, got %s", got)
+	}
+}
+
+func TestRecordResult_ConcurrentRuns(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.RecordsFile = "records.yaml"
+
+	// Runs load the records when starting, and each saves its resources
+	// without dropping the ones the other saved since.
+	first, _ := resolver.LoadRecords()
+	second, _ := resolver.LoadRecords()
+	if err := resolver.recordResult(first, ResourceNodeEntry{Id: "api"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.recordResult(second, ResourceNodeEntry{Id: "db"}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	records, err := resolver.LoadRecords()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records.Resources) != 2 || records.Resources["api"].State != StateInstalled || records.Resources["db"].State != StateInstalled {
+		t.Errorf("Expected the resources of both runs to be recorded, got %+v", records.Resources)
+	}
+}
+
+func TestLoadRecords_UnsupportedVersion(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.RecordsFile = "status.yaml"
+	if err := afero.WriteFile(resolver.Fs, "status.yaml", []byte("statusVersion: 99\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolver.LoadRecords(); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected an unsupported version error, got %v", err)
+	}
+}
+
+func TestHandleStatusCommand(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.RecordsFile = "status.yaml"
+	resolver.OutputFormat = FormatTable
+
+	output := captureOutput(func() {
+		if err := resolver.HandleStatusCommand([]string{"a"}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "STATE") || !strings.Contains(output, "pending") {
+		t.Errorf("Expected a table of pending resources, got %q", output)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("state", 0755)
+	for _, content := range []string{"old", "new"} {
+		if err := writeFileAtomic(fs, "state/status.yaml", []byte(content), 0644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if data, _ := afero.ReadFile(fs, "state/status.yaml"); string(data) != "new" {
+		t.Errorf("Expected the file to be replaced, got %q", data)
+	}
+	if infos, _ := afero.ReadDir(fs, "state"); len(infos) != 1 {
+		t.Errorf("Expected no temporary file to be left, got %d files", len(infos))
+	}
+
+	// A failed write leaves the previous content in place.
+	if err := writeFileAtomic(afero.NewReadOnlyFs(fs), "state/status.yaml", []byte("partial"), 0644); err == nil {
+		t.Error("Expected the write to fail")
+	}
+	if data, _ := afero.ReadFile(fs, "state/status.yaml"); string(data) != "new" {
+		t.Errorf("Expected the previous content to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,106 @@
+package resolver
+
+import "sort"
+
+// ResourceCount pairs a resource with a count, such as its number of
+// dependents.
+type ResourceCount struct {
+	Id    string `json:"id" yaml:"id"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+// GraphStats summarizes the size and shape of the dependency graph.
+type GraphStats struct {
+	Resources      int             `json:"resources" yaml:"resources"`
+	Edges          int             `json:"edges" yaml:"edges"`
+	MaxDepth       int             `json:"maxDepth" yaml:"maxDepth"`
+	AverageFanIn   float64         `json:"averageFanIn" yaml:"averageFanIn"`
+	AverageFanOut  float64         `json:"averageFanOut" yaml:"averageFanOut"`
+	LargestClosure ResourceCount   `json:"largestClosure" yaml:"largestClosure"`
+	Categories     int             `json:"categories" yaml:"categories"`
+	MostDepended   []ResourceCount `json:"mostDepended" yaml:"mostDepended"`
+}
+
+// mostDependedLimit is the number of resources listed in MostDepended.
+const mostDependedLimit = 10
+
+// Stats returns statistics about the dependency graph. MaxDepth is the length
+// of the longest requirement chain, ignoring edges that close a cycle.
+// MostDepended lists the resources with the most transitive dependents.
+func (dr *DependencyResolver) Stats() GraphStats {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	ids := dr.resourceIDs()
+	stats := GraphStats{Resources: len(ids), MostDepended: []ResourceCount{}}
+	if len(ids) == 0 {
+		return stats
+	}
+
+	known := dr.knownResources()
+	categories := make(map[string]bool)
+	for _, entry := range dr.Resources {
+		if entry.Category != "" {
+			categories[entry.Category] = true
+		}
+	}
+	stats.Categories = len(categories)
+
+	fanIn := 0
+	for _, id := range ids {
+		for _, dep := range dr.ResourceDependencies[id] {
+			stats.Edges++
+			if known[dep] {
+				fanIn++
+			}
+		}
+	}
+	stats.AverageFanOut = float64(stats.Edges) / float64(len(ids))
+	stats.AverageFanIn = float64(fanIn) / float64(len(ids))
+
+	for _, depth := range dr.graph().Depths(ids) {
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+	}
+
+	for _, id := range ids {
+		closure := dr.cache.memoize(&dr.cache.closures, id, func() []string {
+			return dr.closure(id, 0)
+		})
+		if len(closure) > stats.LargestClosure.Count || stats.LargestClosure.Id == "" {
+			stats.LargestClosure = ResourceCount{Id: id, Count: len(closure)}
+		}
+
+		dependents := dr.cache.memoize(&dr.cache.dependents, id, func() []string {
+			return dr.dependentClosure(id, 0)
+		})
+		if len(dependents) > 0 {
+			stats.MostDepended = append(stats.MostDepended, ResourceCount{Id: id, Count: len(dependents)})
+		}
+	}
+
+	sort.SliceStable(stats.MostDepended, func(i, j int) bool {
+		return stats.MostDepended[i].Count > stats.MostDepended[j].Count
+	})
+	if len(stats.MostDepended) > mostDependedLimit {
+		stats.MostDepended = stats.MostDepended[:mostDependedLimit]
+	}
+	return stats
+}
+
+// Depths returns the length of the longest requirement chain starting at every
+// resource, ignoring edges that close a cycle. Resources without requirements
+// have a depth of 0.
+func (dr *DependencyResolver) Depths() map[string]int {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	ids := dr.resourceIDs()
+	depths := dr.graph().Depths(ids)
+	known := dr.knownResources()
+	for id := range depths {
+		if !known[id] {
+			delete(depths, id)
+		}
+	}
+	return depths
+}
@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Category: "base"},
+		{Id: "b", Category: "base", Requires: []string{"a"}},
+		{Id: "c", Category: "app", Requires: []string{"a", "b"}},
+		{Id: "d", Requires: []string{"c"}},
+	}
+	resolver.BuildDependencies()
+
+	expected := GraphStats{
+		Resources:      4,
+		Edges:          4,
+		MaxDepth:       3,
+		AverageFanIn:   1,
+		AverageFanOut:  1,
+		LargestClosure: ResourceCount{Id: "d", Count: 3},
+		Categories:     2,
+		MostDepended: []ResourceCount{
+			{Id: "a", Count: 3},
+			{Id: "b", Count: 2},
+			{Id: "c", Count: 1},
+		},
+	}
+	if stats := resolver.Stats(); !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestStats_Cycle(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"b"}},
+		{Id: "b", Requires: []string{"a"}},
+	}
+	resolver.BuildDependencies()
+
+	if stats := resolver.Stats(); stats.MaxDepth != 1 || stats.Edges != 2 {
+		t.Errorf("Expected a depth of 1 and 2 edges, got %+v", stats)
+	}
+}
+
+func TestStats_Empty(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	if stats := resolver.Stats(); stats.Resources != 0 || stats.MostDepended == nil {
+		t.Errorf("Expected empty statistics, got %+v", stats)
+	}
+}
+
+func TestDepths(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "a", Requires: []string{"missing"}},
+		{Id: "b", Requires: []string{"a"}},
+		{Id: "c", Requires: []string{"a", "b"}},
+	}
+	resolver.BuildDependencies()
+
+	expected := map[string]int{"a": 1, "b": 2, "c": 3}
+	if depths := resolver.Depths(); !reflect.DeepEqual(depths, expected) {
+		t.Errorf("Expected %v, got %v", expected, depths)
+	}
+}
@@ -0,0 +1,512 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v2"
+)
+
+// storeSource is the source recorded for the resources loaded from a
+// ResourceStore.
+const storeSource = "the store"
+
+// ResourceStore is where the resources of a catalog are kept, separately from
+// their resolution. A store knows the requirements declared by each resource,
+// not the providers and optional requirements the resolver picks for them.
+type ResourceStore interface {
+	// Get returns the resource with the given ID, and whether it exists.
+	Get(id string) (ResourceNodeEntry, bool, error)
+	// List returns every resource.
+	List() ([]ResourceNodeEntry, error)
+	// ByCategory returns the resources of the category.
+	ByCategory(category string) ([]ResourceNodeEntry, error)
+	// Dependents returns the IDs of the resources declaring a requirement,
+	// mandatory or optional, on the given name, in the order of List. A store
+	// does not resolve virtual requirements to their providers.
+	Dependents(id string) ([]string, error)
+	// Put adds the resource, or replaces the one with the same ID.
+	Put(entry ResourceNodeEntry) error
+	// Delete removes the resource with the given ID, if any.
+	Delete(id string) error
+}
+
+// ErrReadOnlyStore is returned when modifying a store that cannot be
+// modified.
+var ErrReadOnlyStore = errors.New("the resource store is read-only")
+
+// UseStore sets the store the resources of the catalog are loaded from, and
+// written back to when added, updated or removed through the resolver. No
+// resource is loaded: LoadStored loads the ones a query needs from the store,
+// and LoadStore every one.
+func (dr *DependencyResolver) UseStore(store ResourceStore) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.Store = store
+	dr.storeListed = false
+}
+
+// LoadStore loads every resource of the Store into the catalog. The
+// dependency graph is resolved from the resources listed, and Reload lists
+// them again, while GetResource and ResourcesByCategory query the Store for
+// the resources loaded from it, so they return the resources as currently
+// stored. A resource changed by an overlay is looked up in the catalog
+// instead.
+func (dr *DependencyResolver) LoadStore() (err error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	_, span := tracer.Start(context.Background(), "load catalog", trace.WithAttributes(attrSource.String(fmt.Sprintf("%T", dr.Store))))
+	defer func() { endSpan(span, err) }()
+
+	stored, err := dr.Store.List()
+	if err != nil {
+		return fmt.Errorf("error listing the resources of the store: %w", err)
+	}
+	if err := dr.addStored(stored); err != nil {
+		return err
+	}
+	dr.storeListed = true
+	span.SetAttributes(attrResources.Int(len(dr.Resources)))
+	return nil
+}
+
+// LoadStored loads the resources with the given IDs from the Store into the
+// catalog, with their requirements and, when dependents is set, the
+// resources requiring them, transitively. The Store is queried one resource
+// at a time through Get and Dependents rather than listed, so only the
+// resources reached are held in memory. The Store does not know the
+// providers of virtual requirements, so they are only resolved to the
+// providers otherwise loaded.
+func (dr *DependencyResolver) LoadStored(ids []string, dependents bool) (err error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	_, span := tracer.Start(context.Background(), "load catalog", trace.WithAttributes(attrSource.String(fmt.Sprintf("%T", dr.Store))))
+	defer func() { endSpan(span, err) }()
+
+	var stored []ResourceNodeEntry
+	seen := make(map[string]bool)
+	queue := append([]string(nil), ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, ok := dr.getResource(dr.IDs.ID(id))
+		if !ok {
+			if entry, ok, err = dr.Store.Get(id); err != nil {
+				return fmt.Errorf("error reading resource '%s' from the store: %w", id, err)
+			}
+			if !ok {
+				continue
+			}
+			stored = append(stored, entry)
+		}
+		queue = append(queue, entry.Requires...)
+		queue = append(queue, entry.Optional...)
+		if !dependents {
+			continue
+		}
+		for _, name := range append([]string{id}, entry.Provides...) {
+			names, err := dr.Store.Dependents(name)
+			if err != nil {
+				return fmt.Errorf("error querying the dependents of '%s' in the store: %w", name, err)
+			}
+			queue = append(queue, names...)
+		}
+	}
+	if err := dr.addStored(stored); err != nil {
+		return err
+	}
+	span.SetAttributes(attrResources.Int(len(dr.Resources)))
+	return nil
+}
+
+// addStored adds the resources read from the Store to the catalog.
+func (dr *DependencyResolver) addStored(stored []ResourceNodeEntry) error {
+	sources := dr.resourceSources()
+	entries, warnings, err := dr.addManifests(sources, []manifestFile{{Path: storeSource, Entries: stored}})
+	if err != nil {
+		return err
+	}
+
+	storeIDs := make(map[string]string, len(entries))
+	for id, storeID := range dr.storeIDs {
+		storeIDs[id] = storeID
+	}
+	loaded := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		loaded[entry.Id] = true
+	}
+	for _, entry := range stored {
+		// The first definition wins, as when loading.
+		if id := dr.IDs.entry(entry).Id; loaded[id] {
+			storeIDs[id] = entry.Id
+			delete(loaded, id)
+		}
+	}
+
+	if err := dr.replaceResources(append(dr.Resources, entries...), sources, dr.lenient()); err != nil {
+		return err
+	}
+	dr.storeIDs = storeIDs
+	dr.warn(warnings...)
+	return nil
+}
+
+// storeResource returns the resource loaded from the Store under storeID as
+// the Store now holds it, normalized as UseStore loads it. The loaded copy is
+// returned when the Store fails, since lookups cannot fail.
+func (dr *DependencyResolver) storeResource(id, storeID string) (ResourceNodeEntry, bool) {
+	entry, ok, err := dr.Store.Get(storeID)
+	if err != nil {
+		dr.logger().Warn("Failed to read the resource from the store, using the loaded one", "id", id, "error", err)
+		return dr.getResource(id)
+	}
+	if !ok {
+		return ResourceNodeEntry{}, false
+	}
+	return dr.storedEntry(entry), true
+}
+
+// storeCategories replaces the resources loaded from the Store among the
+// entries found by category with the ones the Store now holds in the
+// categories, in catalog order. The entries are returned unchanged when the
+// Store fails.
+func (dr *DependencyResolver) storeCategories(entries []ResourceNodeEntry, categories []string) []ResourceNodeEntry {
+	matches := slices.DeleteFunc(slices.Clone(entries), func(entry ResourceNodeEntry) bool {
+		_, stored := dr.storeIDs[entry.Id]
+		return stored
+	})
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		stored, err := dr.Store.ByCategory(category)
+		if err != nil {
+			dr.logger().Warn("Failed to query the store, using the loaded resources", "category", category, "error", err)
+			return entries
+		}
+		for _, entry := range stored {
+			entry = dr.storedEntry(entry)
+			if _, ok := dr.storeIDs[entry.Id]; ok && !seen[entry.Id] {
+				seen[entry.Id] = true
+				matches = append(matches, entry)
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return dr.resourceIndexOf(matches[i].Id) < dr.resourceIndexOf(matches[j].Id)
+	})
+	return matches
+}
+
+// storedEntry normalizes a resource of the Store as UseStore loads it.
+func (dr *DependencyResolver) storedEntry(entry ResourceNodeEntry) ResourceNodeEntry {
+	entry = dr.IDs.entry(entry)
+	if requiresItself(entry) {
+		entry = withoutSelf(entry)
+	}
+	return entry
+}
+
+// declaredDependents returns the IDs of the entries requiring id, or listing
+// it as an optional requirement.
+func declaredDependents(entries []ResourceNodeEntry, id string) []string {
+	var dependents []string
+	for _, entry := range entries {
+		if slices.Contains(entry.Requires, id) || slices.Contains(entry.Optional, id) {
+			dependents = append(dependents, entry.Id)
+		}
+	}
+	return dependents
+}
+
+// inCategory returns the entries of the category.
+func inCategory(entries []ResourceNodeEntry, category string) []ResourceNodeEntry {
+	var matches []ResourceNodeEntry
+	for _, entry := range entries {
+		if entry.Category == category {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// MemoryStore is a ResourceStore kept in memory, in insertion order.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries []ResourceNodeEntry
+}
+
+// NewMemoryStore returns a MemoryStore holding the given resources.
+func NewMemoryStore(entries ...ResourceNodeEntry) *MemoryStore {
+	return &MemoryStore{entries: append([]ResourceNodeEntry(nil), entries...)}
+}
+
+// Get returns the resource with the given ID, and whether it exists.
+func (s *MemoryStore) Get(id string) (ResourceNodeEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := s.index(id); i >= 0 {
+		return s.entries[i], true, nil
+	}
+	return ResourceNodeEntry{}, false, nil
+}
+
+// List returns every resource, in insertion order.
+func (s *MemoryStore) List() ([]ResourceNodeEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ResourceNodeEntry(nil), s.entries...), nil
+}
+
+// ByCategory returns the resources of the category, in insertion order.
+func (s *MemoryStore) ByCategory(category string) ([]ResourceNodeEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return inCategory(s.entries, category), nil
+}
+
+// Dependents returns the IDs of the resources declaring a requirement on
+// the given name, in insertion order.
+func (s *MemoryStore) Dependents(id string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return declaredDependents(s.entries, id), nil
+}
+
+// Put adds the resource at the end, or replaces the one with the same ID in
+// place.
+func (s *MemoryStore) Put(entry ResourceNodeEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.index(entry.Id); i >= 0 {
+		s.entries[i] = entry
+	} else {
+		s.entries = append(s.entries, entry)
+	}
+	return nil
+}
+
+// Delete removes the resource with the given ID, if any.
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.index(id); i >= 0 {
+		s.entries = append(s.entries[:i:i], s.entries[i+1:]...)
+	}
+	return nil
+}
+
+func (s *MemoryStore) index(id string) int {
+	return slices.IndexFunc(s.entries, func(entry ResourceNodeEntry) bool { return entry.Id == id })
+}
+
+// FileStore is a ResourceStore keeping each resource in its own manifest file
+// of a directory, named after its ID. Resources are listed in ID order.
+type FileStore struct {
+	Fs  afero.Fs
+	Dir string
+}
+
+// NewFileStore returns a FileStore for the directory, which is created on
+// the first Put when missing.
+func NewFileStore(fs afero.Fs, dir string) *FileStore {
+	return &FileStore{Fs: fs, Dir: dir}
+}
+
+// Get reads the manifest file of the resource, and reports whether it
+// exists.
+func (s *FileStore) Get(id string) (ResourceNodeEntry, bool, error) {
+	entry, err := s.read(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ResourceNodeEntry{}, false, nil
+	}
+	return entry, err == nil, err
+}
+
+// List reads every manifest file of the directory, in ID order. A missing
+// directory holds no resources.
+func (s *FileStore) List() ([]ResourceNodeEntry, error) {
+	infos, err := afero.ReadDir(s.Fs, s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading store directory %s: %w", s.Dir, err)
+	}
+
+	var entries []ResourceNodeEntry
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".yaml" {
+			continue
+		}
+		entry, err := s.read(filepath.Join(s.Dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Id < entries[j].Id })
+	return entries, nil
+}
+
+// ByCategory returns the resources of the category, in ID order.
+func (s *FileStore) ByCategory(category string) ([]ResourceNodeEntry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return inCategory(entries, category), nil
+}
+
+// Dependents returns the IDs of the resources declaring a requirement on
+// the given name, in ID order.
+func (s *FileStore) Dependents(id string) ([]string, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return declaredDependents(entries, id), nil
+}
+
+// Put writes the manifest file of the resource, creating the directory
+// when missing.
+func (s *FileStore) Put(entry ResourceNodeEntry) error {
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding resource '%s': %w", entry.Id, err)
+	}
+	if err := s.Fs.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("error creating store directory %s: %w", s.Dir, err)
+	}
+	if err := afero.WriteFile(s.Fs, s.path(entry.Id), data, 0644); err != nil {
+		return fmt.Errorf("error storing resource '%s': %w", entry.Id, err)
+	}
+	return nil
+}
+
+// Delete removes the manifest file of the resource, if any.
+func (s *FileStore) Delete(id string) error {
+	if err := s.Fs.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting resource '%s': %w", id, err)
+	}
+	return nil
+}
+
+// path returns the manifest file of the resource. The ID is escaped, so IDs
+// containing slashes stay in the directory.
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.Dir, url.PathEscape(id)+".yaml")
+}
+
+func (s *FileStore) read(path string) (ResourceNodeEntry, error) {
+	data, err := afero.ReadFile(s.Fs, path)
+	if err != nil {
+		return ResourceNodeEntry{}, err
+	}
+	entries, _, err := parseResourceEntries(data, path)
+	if err != nil {
+		return ResourceNodeEntry{}, err
+	}
+	if len(entries) != 1 {
+		return ResourceNodeEntry{}, fmt.Errorf("error reading %s: expected a single resource, got %d", path, len(entries))
+	}
+	return entries[0], nil
+}
+
+// HTTPStore is a read-only ResourceStore reading the resources served by
+// `runner serve` at URL. The API does not expose the steps of the resources,
+// so they are left out.
+type HTTPStore struct {
+	URL string
+}
+
+// NewHTTPStore returns an HTTPStore for the API at the given base URL.
+func NewHTTPStore(baseURL string) *HTTPStore {
+	return &HTTPStore{URL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Get fetches the resource with the given ID, and reports whether the API
+// has it.
+func (s *HTTPStore) Get(id string) (ResourceNodeEntry, bool, error) {
+	var entry ResourceNodeEntry
+	found, err := s.get("/resources/"+url.PathEscape(id), &entry)
+	return entry, found && err == nil, err
+}
+
+// List fetches every resource, in catalog order.
+func (s *HTTPStore) List() ([]ResourceNodeEntry, error) {
+	var entries []ResourceNodeEntry
+	if _, err := s.get("/resources", &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ByCategory returns the resources of the category, in catalog order.
+func (s *HTTPStore) ByCategory(category string) ([]ResourceNodeEntry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return inCategory(entries, category), nil
+}
+
+// Dependents returns the IDs of the resources declaring a requirement on
+// the given name, in catalog order. The dependents reported by the API are
+// resolved by the serving resolver, so they are computed from List instead.
+func (s *HTTPStore) Dependents(id string) ([]string, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return declaredDependents(entries, id), nil
+}
+
+// Put returns ErrReadOnlyStore.
+func (s *HTTPStore) Put(entry ResourceNodeEntry) error {
+	return ErrReadOnlyStore
+}
+
+// Delete returns ErrReadOnlyStore.
+func (s *HTTPStore) Delete(id string) error {
+	return ErrReadOnlyStore
+}
+
+// get decodes the JSON response of the API path into v, and reports whether
+// the resource it concerns exists.
+func (s *HTTPStore) get(path string, v interface{}) (bool, error) {
+	resp, err := request(s.URL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	data, err := readResponse(s.URL+path, resp)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("error decoding the response of %s: %w", s.URL+path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,315 @@
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+var storeEntries = []ResourceNodeEntry{
+	{Id: "git", Category: "vcs", Requires: []string{}},
+	{Id: "go", Category: "language", Requires: []string{}},
+	{Id: "tag", Category: "vcs", Requires: []string{"git"}},
+	{Id: "app", Requires: []string{"go"}, Optional: []string{"tag"}},
+}
+
+// testStore checks the behavior shared by the writable stores, which hold
+// storeEntries.
+func testStore(t *testing.T, store ResourceStore) {
+	t.Helper()
+
+	if entry, ok, err := store.Get("tag"); err != nil || !ok || !reflect.DeepEqual(entry.Requires, []string{"git"}) {
+		t.Errorf("Expected the resource, got %+v, %v, %v", entry, ok, err)
+	}
+	if _, ok, err := store.Get("missing"); err != nil || ok {
+		t.Errorf("Expected a missing resource not to be found, got %v, %v", ok, err)
+	}
+	if entries, err := store.ByCategory("vcs"); err != nil || len(entries) != 2 {
+		t.Errorf("Expected 2 vcs resources, got %+v, %v", entries, err)
+	}
+	if dependents, err := store.Dependents("tag"); err != nil || !reflect.DeepEqual(dependents, []string{"app"}) {
+		t.Errorf("Expected the optional requirement to count, got %v, %v", dependents, err)
+	}
+
+	if err := store.Put(ResourceNodeEntry{Id: "git", Name: "Git"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry, _, _ := store.Get("git"); entry.Name != "Git" {
+		t.Errorf("Expected the resource to be replaced, got %+v", entry)
+	}
+	if err := store.Delete("tag"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Delete("tag"); err != nil {
+		t.Errorf("Expected deleting a missing resource to succeed, got %v", err)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 3 {
+		t.Errorf("Expected 3 resources, got %+v, %v", entries, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(storeEntries...)
+	testStore(t, store)
+
+	entries, _ := store.List()
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+	if expected := []string{"git", "go", "app"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected the insertion order, got %v", ids)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	store := NewFileStore(fs, "store")
+	if entries, err := store.List(); err != nil || len(entries) != 0 {
+		t.Errorf("Expected a missing directory to be empty, got %+v, %v", entries, err)
+	}
+	for _, entry := range storeEntries {
+		if err := store.Put(entry); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	testStore(t, store)
+
+	store.Put(ResourceNodeEntry{Id: "tools/lint"})
+	if exists, _ := afero.Exists(fs, "store/tools%2Flint.yaml"); !exists {
+		t.Error("Expected the ID to be escaped in the file name")
+	}
+	entries, _ := store.List()
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+	if expected := []string{"app", "git", "go", "tools/lint"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected the ID order, got %v", ids)
+	}
+}
+
+func TestHTTPStore(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /resources", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(storeEntries)
+	})
+	mux.HandleFunc("GET /resources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, entry := range storeEntries {
+			if entry.Id == r.PathValue("id") {
+				json.NewEncoder(w).Encode(entry)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := NewHTTPStore(server.URL + "/")
+	if entry, ok, err := store.Get("tag"); err != nil || !ok || entry.Category != "vcs" {
+		t.Errorf("Expected the resource, got %+v, %v, %v", entry, ok, err)
+	}
+	if _, ok, err := store.Get("missing"); err != nil || ok {
+		t.Errorf("Expected a missing resource not to be found, got %v, %v", ok, err)
+	}
+	if entries, err := store.ByCategory("language"); err != nil || len(entries) != 1 || entries[0].Id != "go" {
+		t.Errorf("Expected the language resources, got %+v, %v", entries, err)
+	}
+	if dependents, err := store.Dependents("go"); err != nil || !reflect.DeepEqual(dependents, []string{"app"}) {
+		t.Errorf("Expected the dependents of go, got %v, %v", dependents, err)
+	}
+	if err := store.Put(ResourceNodeEntry{Id: "new"}); !errors.Is(err, ErrReadOnlyStore) {
+		t.Errorf("Expected ErrReadOnlyStore, got %v", err)
+	}
+}
+
+func TestUseStore(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	store := NewMemoryStore(storeEntries...)
+	resolver.UseStore(store)
+	if err := resolver.LoadStore(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.TransitiveDependencies("app"); !reflect.DeepEqual(deps, []string{"go", "tag", "git"}) {
+		t.Errorf("Expected the resources of the store to be resolved, got %v", deps)
+	}
+
+	// Changes made through the resolver are written to the store.
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "docs", Requires: []string{"git"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "go", Name: "Go"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.RemoveResource("app", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entries, _ := store.List()
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+	if expected := []string{"git", "go", "tag", "docs"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected the store to be updated, got %v", ids)
+	}
+	if entry, _, _ := store.Get("go"); entry.Name != "Go" {
+		t.Errorf("Expected the update to be stored, got %+v", entry)
+	}
+
+	// A failed write leaves the catalog unchanged.
+	resolver.Store = NewHTTPStore("http://127.0.0.1:0")
+	if err := resolver.AddResourceEntry(ResourceNodeEntry{Id: "lint"}); !errors.Is(err, ErrReadOnlyStore) {
+		t.Errorf("Expected ErrReadOnlyStore, got %v", err)
+	}
+	if _, ok := resolver.GetResource("lint"); ok {
+		t.Error("Expected the resource not to be added")
+	}
+
+	// Reload reads the store again.
+	resolver.Store = store
+	store.Put(ResourceNodeEntry{Id: "lint"})
+	if err := resolver.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := resolver.GetResource("lint"); !ok {
+		t.Error("Expected the reloaded catalog to include the new resource")
+	}
+}
+
+// unlistedStore is a MemoryStore failing to list, to check that resources
+// are loaded one at a time.
+type unlistedStore struct {
+	*MemoryStore
+}
+
+func (s unlistedStore) List() ([]ResourceNodeEntry, error) {
+	return nil, errors.New("listed the store")
+}
+
+func TestLoadStored(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+
+	store := NewMemoryStore(append(storeEntries, ResourceNodeEntry{Id: "lint", Requires: []string{"go"}}, ResourceNodeEntry{Id: "docs"})...)
+	resolver.UseStore(unlistedStore{store})
+	if entries := resolver.ListResources(); len(entries) != 0 {
+		t.Errorf("Expected no resources to be loaded, got %+v", entries)
+	}
+
+	// The requirements of the resources are loaded.
+	if err := resolver.LoadStored([]string{"app"}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deps := resolver.TransitiveDependencies("app"); !reflect.DeepEqual(deps, []string{"go", "tag", "git"}) {
+		t.Errorf("Expected the closure to be loaded, got %v", deps)
+	}
+	if _, ok := resolver.GetResource("lint"); ok {
+		t.Error("Expected the resources not required not to be loaded")
+	}
+
+	// So are the resources requiring them, for dependents.
+	if err := resolver.LoadStored([]string{"go"}, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dependents := resolver.TransitiveDependents("go"); !reflect.DeepEqual(dependents, []string{"app", "lint"}) {
+		t.Errorf("Expected the dependents to be loaded, got %v", dependents)
+	}
+	if _, ok := resolver.GetResource("docs"); ok {
+		t.Error("Expected unrelated resources not to be loaded")
+	}
+
+	// Reload reads the same resources again.
+	store.Put(ResourceNodeEntry{Id: "lint", Name: "Lint", Requires: []string{"go"}})
+	if err := resolver.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry, ok := resolver.GetResource("lint"); !ok || entry.Name != "Lint" {
+		t.Errorf("Expected the reloaded resource, got %+v, %v", entry, ok)
+	}
+	if err := resolver.LoadStore(); err == nil {
+		t.Error("Expected LoadStore to list the store")
+	}
+}
+
+func TestUseStore_Lookups(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.IDs.CaseInsensitive = true
+	resolver.BuildDependencies()
+
+	store := NewMemoryStore(ResourceNodeEntry{Id: "Git", Category: "vcs"}, ResourceNodeEntry{Id: "app", Category: "apps"})
+	resolver.UseStore(store)
+	if err := resolver.LoadStore(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Lookups return the resources as stored since loading.
+	store.Put(ResourceNodeEntry{Id: "Git", Name: "Git", Category: "tools"})
+	if entry, ok := resolver.GetResource("git"); !ok || entry.Id != "git" || entry.Name != "Git" {
+		t.Errorf("Expected the stored resource under its normalized ID, got %+v, %v", entry, ok)
+	}
+	if entries := resolver.ResourcesByCategory("vcs", "tools"); len(entries) != 1 || entries[0].Category != "tools" {
+		t.Errorf("Expected the stored category, got %+v", entries)
+	}
+
+	// A resource changed by an overlay is looked up in the catalog.
+	if err := resolver.ApplyOverlays([]ResourceOverlay{{Id: "app", Name: "App"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry, _ := resolver.GetResource("app"); entry.Name != "App" {
+		t.Errorf("Expected the overlay to apply, got %+v", entry)
+	}
+	if entries := resolver.ResourcesByCategory("apps"); len(entries) != 1 || entries[0].Name != "App" {
+		t.Errorf("Expected the overlaid resource, got %+v", entries)
+	}
+
+	store.Delete("Git")
+	if _, ok := resolver.GetResource("git"); ok {
+		t.Error("Expected a resource deleted from the store not to be found")
+	}
+}
+
+func TestUseStore_NormalizedWrites(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.IDs.CaseInsensitive = true
+	resolver.BuildDependencies()
+
+	store := NewMemoryStore(ResourceNodeEntry{Id: "Git"}, ResourceNodeEntry{Id: "DB"})
+	resolver.UseStore(store)
+	if err := resolver.LoadStore(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	storedIDs := func() []string {
+		entries, _ := store.List()
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.Id)
+		}
+		return ids
+	}
+
+	// Writes replace the resources stored under their IDs before normalizing.
+	if err := resolver.UpdateResource(ResourceNodeEntry{Id: "GIT", Name: "Git"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := resolver.RemoveResource("Db", false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := storedIDs(); !reflect.DeepEqual(ids, []string{"git"}) {
+		t.Errorf("Expected the store to hold the updated resource once, got %v", ids)
+	}
+	if entry, ok := resolver.GetResource("GIT"); !ok || entry.Name != "Git" {
+		t.Errorf("Expected the updated resource, got %+v, %v", entry, ok)
+	}
+}
@@ -0,0 +1,56 @@
+package resolver
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// outputStyles highlights parts of the terminal output.
+type outputStyles struct {
+	id       lipgloss.Style
+	name     lipgloss.Style
+	category lipgloss.Style
+	err      lipgloss.Style
+}
+
+var styles = newStyles(os.Stdout)
+
+// newStyles returns the styles for the given output. Colors are disabled when
+// the output isn't a terminal or NO_COLOR is set.
+func newStyles(out *os.File) outputStyles {
+	renderer := lipgloss.NewRenderer(out)
+	if !colorEnabled(out) {
+		renderer.SetColorProfile(termenv.Ascii)
+	}
+	return outputStyles{
+		id:       renderer.NewStyle().Bold(true).Foreground(lipgloss.Color("12")),
+		name:     renderer.NewStyle().Foreground(lipgloss.Color("10")),
+		category: renderer.NewStyle().Foreground(lipgloss.Color("13")),
+		err:      renderer.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+	}
+}
+
+func colorEnabled(out *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isatty.IsTerminal(out.Fd()) || isatty.IsCygwinTerminal(out.Fd())
+}
+
+// styledID highlights a resource ID.
+func styledID(id string) string {
+	return styles.id.Render(id)
+}
+
+// styledPath joins the resources of a path, highlighting every resource.
+func styledPath(path []string, sep string) string {
+	styled := make([]string, len(path))
+	for i, id := range path {
+		styled[i] = styles.id.Render(id)
+	}
+	return strings.Join(styled, sep)
+}
@@ -0,0 +1,35 @@
+package resolver
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNewStyles_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	s := newStyles(os.Stdout)
+	if got := s.id.Render("res1"); got != "res1" {
+		t.Errorf("Expected plain text with NO_COLOR set, got %q", got)
+	}
+}
+
+func TestNewStyles_NotATerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	s := newStyles(w)
+	if got := s.err.Render("failed"); got != "failed" {
+		t.Errorf("Expected plain text when not writing to a terminal, got %q", got)
+	}
+
+	defer func(previous outputStyles) { styles = previous }(styles)
+	styles = s
+	if got := styledPath([]string{"a", "b"}, " -> "); got != "a -> b" {
+		t.Errorf("Expected an unstyled path, got %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// templateFuncs are the functions available to output templates, besides the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"json": func(v interface{}) (string, error) {
+		var out strings.Builder
+		err := RenderJSON(&out, v)
+		return strings.TrimSuffix(out.String(), "\n"), err
+	},
+	"yaml": func(v interface{}) (string, error) {
+		data, err := yaml.Marshal(v)
+		return strings.TrimSuffix(string(data), "\n"), err
+	},
+}
+
+// parseTemplate parses an output template.
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ValidateTemplate returns an error when the output template doesn't parse.
+func ValidateTemplate(text string) error {
+	_, err := parseTemplate(text)
+	return err
+}
+
+// RenderTemplate executes the text/template text with v as its data.
+func RenderTemplate(w io.Writer, text string, v interface{}) error {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, v); err != nil {
+		return fmt.Errorf("error executing the output template: %w", err)
+	}
+	return nil
+}
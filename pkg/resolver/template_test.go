@@ -0,0 +1,60 @@
+package resolver
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	entries := []ResourceNodeEntry{
+		{Id: "git", Name: "Git", Requires: []string{}},
+		{Id: "tag", Requires: []string{"git", "go"}},
+	}
+
+	tests := map[string]string{
+		`{{range .}}{{.Id}}: {{join .Requires " "}}{{"\n"}}{{end}}`: "git: \ntag: git go\n",
+		`{{range .}}{{upper .Id}}{{end}}`:                           "GITTAG",
+		`{{(index . 0).Name | lower}}`:                              "git",
+		`{{json (index . 1).Requires}}`:                             "[\n  \"git\",\n  \"go\"\n]",
+		`{{yaml (index . 1).Requires}}`:                             "- git\n- go",
+	}
+	for text, expected := range tests {
+		var output strings.Builder
+		if err := RenderTemplate(&output, text, entries); err != nil {
+			t.Errorf("Unexpected error for %s: %v", text, err)
+		}
+		if output.String() != expected {
+			t.Errorf("Template %s: expected %q, got %q", text, expected, output.String())
+		}
+	}
+
+	if err := ValidateTemplate("{{.Id"); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+	if err := RenderTemplate(io.Discard, "{{.Missing}}", entries); err == nil {
+		t.Error("Expected an unknown field to fail")
+	}
+}
+
+func TestTemplateOutput(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Template = `{{range $res, $deps := .}}{{$res}}:{{range $deps}} {{.}}{{end}}{{"\n"}}{{end}}`
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := resolver.HandleDependsCommand([]string{"c"})
+	w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var output strings.Builder
+	io.Copy(&output, r)
+	if expected := "c: b a\n"; output.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, output.String())
+	}
+}
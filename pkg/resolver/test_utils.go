@@ -1,7 +1,6 @@
 package resolver
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
@@ -75,46 +74,10 @@ func writeEnvToFile(envFilePath string) error {
 	return nil
 }
 
+// sourceEnvFile sources the file with a resolver logging to the default
+// logger.
 func sourceEnvFile(envFilePath string) error {
-	LogWarn(fmt.Sprintf("Sourcing environment file from path: %s", envFilePath))
-
-	file, err := os.Open(envFilePath)
-	if err != nil {
-		return LogError(fmt.Sprintf("Failed to open environment file: %s - %v", envFilePath, err), err)
-	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-			LogError(fmt.Sprintf("Failed to close environment file: %s - %v", envFilePath, err), err)
-		} else {
-			LogInfo(fmt.Sprintf("Successfully closed environment file: %s", envFilePath))
-		}
-	}(file)
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		LogDebug(fmt.Sprintf("Processing line: %s", line))
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return LogError(fmt.Sprintf("Invalid environment variable declaration: %s in file: %s", line, envFilePath), err)
-		}
-
-		key := parts[0]
-		value := strings.Trim(parts[1], "\"")
-		if err := os.Setenv(key, value); err != nil {
-			return LogError(fmt.Sprintf("Failed to set environment variable %s: %v - %s", key, err, envFilePath), err)
-		}
-		LogInfo(fmt.Sprintf("Set environment variable %s=%s", key, value))
-	}
-
-	if err := scanner.Err(); err != nil {
-		return LogError(fmt.Sprintf("Error reading environment file: %s - %v", envFilePath, err), err)
-	}
-
-	LogInfo(fmt.Sprintf("Successfully sourced environment file: %s", envFilePath))
-	return nil
+	return new(DependencyResolver).SourceEnvFile(envFilePath)
 }
 
 func setup() (string, func()) {
@@ -0,0 +1,64 @@
+package resolver
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records the spans of catalog loading, resolution and execution
+// through the global tracer provider, which drops them unless one is
+// installed, such as by the tracing package.
+var tracer = otel.Tracer("github.com/jjuliano/runner/pkg/resolver")
+
+// Span attributes.
+const (
+	attrResourceID = attribute.Key("runner.resource.id")
+	attrCategory   = attribute.Key("runner.resource.category")
+	attrWave       = attribute.Key("runner.wave")
+	attrResult     = attribute.Key("runner.result")
+	attrResumed    = attribute.Key("runner.resumed")
+	attrUpToDate   = attribute.Key("runner.up_to_date")
+	attrTargets    = attribute.Key("runner.targets")
+	attrSource     = attribute.Key("runner.source")
+	attrResources  = attribute.Key("runner.resources")
+)
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// waves returns the execution wave of every resource of a resolved stack:
+// resources without requirements in the stack are in wave 0, and every other
+// resource is one wave after its latest requirement. Resources of a wave are
+// independent of each other.
+func (dr *DependencyResolver) waves(stack []string) map[string]int {
+	wave := make(map[string]int, len(stack))
+	for _, id := range stack {
+		w := 0
+		for _, dep := range dr.DirectDependencies(id) {
+			if depWave, ok := wave[dep]; ok {
+				w = max(w, depWave+1)
+			}
+		}
+		wave[id] = w
+	}
+	return wave
+}
+
+// startResourceSpan starts the span of a resource executed in a run.
+func startResourceSpan(ctx context.Context, entry ResourceNodeEntry, wave int) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "resource "+entry.Id, trace.WithAttributes(
+		attrResourceID.String(entry.Id),
+		attrCategory.String(entry.Category),
+		attrWave.Int(wave),
+	))
+}
@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spanExporter    = tracetest.NewInMemoryExporter()
+	installProvider sync.Once
+)
+
+// recordSpans installs a tracer provider recording the spans of the test.
+// The resolver's tracer is bound to the first provider installed, so every
+// test shares one exporter, reset for each of them.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	installProvider.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+	})
+	spanExporter.Reset()
+	t.Cleanup(spanExporter.Reset)
+	return spanExporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTracing_Execute(t *testing.T) {
+	exporter := recordSpans(t)
+	resolver := setupPolicyResolver(ContinueAll)
+	resolver.Execute(context.Background(), []string{"all"})
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	run, ok := spans["run"]
+	if !ok {
+		t.Fatalf("Expected a run span, got %v", exporter.GetSpans())
+	}
+	if resolve := spans["resolve"]; resolve.Parent.SpanID() != run.SpanContext.SpanID() {
+		t.Error("Expected the resolve span to be a child of the run span")
+	}
+
+	expected := map[string]struct {
+		wave   int64
+		result string
+	}{
+		"broken": {0, "failed"},
+		"app":    {1, "succeeded"},
+		"docs":   {0, "succeeded"},
+		"all":    {2, "succeeded"},
+	}
+	for id, want := range expected {
+		span, ok := spans["resource "+id]
+		if !ok {
+			t.Errorf("Expected a span for %s", id)
+			continue
+		}
+		attrs := spanAttributes(span)
+		if attrs[attrResourceID].AsString() != id || attrs[attrWave].AsInt64() != want.wave || attrs[attrResult].AsString() != want.result {
+			t.Errorf("Unexpected attributes for %s: %v", id, span.Attributes)
+		}
+		if span.Parent.SpanID() != run.SpanContext.SpanID() {
+			t.Errorf("Expected the span of %s to be a child of the run span", id)
+		}
+	}
+	if spans["resource broken"].Status.Code != codes.Error || run.Status.Code != codes.Error {
+		t.Error("Expected the failure to be recorded on the resource and run spans")
+	}
+}
+
+func TestTracing_Skipped(t *testing.T) {
+	exporter := recordSpans(t)
+	resolver := setupPolicyResolver(FailFast)
+	resolver.Execute(context.Background(), []string{"all"})
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "resource app" {
+			if result := spanAttributes(span)[attrResult].AsString(); result != "skipped" {
+				t.Errorf("Expected app to be skipped, got %s", result)
+			}
+			return
+		}
+	}
+	t.Error("Expected a span for the skipped resource")
+}
+
+func TestTracing_LoadSource(t *testing.T) {
+	exporter := recordSpans(t)
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.Fs = afero.NewMemMapFs()
+	afero.WriteFile(resolver.Fs, "workflow.yml", []byte("resources:\n  - id: a\n  - id: b\n"), 0644)
+
+	if err := resolver.LoadSource("workflow.yml"); err != nil {
+		t.Fatalf("Failed to load source: %v", err)
+	}
+	resolver.LoadSource("missing.yml")
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	attrs := spanAttributes(spans[0])
+	if spans[0].Name != "load catalog" || attrs[attrSource].AsString() != "workflow.yml" || attrs[attrResources].AsInt64() != 2 {
+		t.Errorf("Unexpected span: %s %v", spans[0].Name, spans[0].Attributes)
+	}
+	if spans[1].Status.Code != codes.Error {
+		t.Error("Expected the failed load to be recorded")
+	}
+}
@@ -0,0 +1,166 @@
+package resolver
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/jjuliano/runner/pkg/dag"
+)
+
+// graph returns ResourceDependencies as a graph. Callers must hold the read
+// lock and must not modify the result.
+func (dr *DependencyResolver) graph() dag.Graph[string] {
+	return dr.ResourceDependencies
+}
+
+// ListDirectDependencies prints the path from the given resource to every
+// resource in its closure, in depth-first order. Paths stop maxDepth
+// requirements away from the resource, unless maxDepth is 0. With
+// CompactPaths, only the last requirement of every path is printed. Printing
+// stops after MaxPaths paths, when set.
+func (dr *DependencyResolver) ListDirectDependencies(node string, maxDepth int) {
+	if err := dr.WriteDependencyPaths(dr.stdout(), node, maxDepth); err != nil {
+		dr.logError("Failed to write dependency paths", err, "resource", node)
+	}
+}
+
+// ListReverseDependencies prints the path from the given resource to every
+// resource that transitively requires it, in depth-first order, down to
+// maxDepth levels unless maxDepth is 0. With CompactPaths, only the last
+// requirement of every path is printed.
+func (dr *DependencyResolver) ListReverseDependencies(node string, maxDepth int) {
+	if err := dr.WriteDependentPaths(dr.stdout(), node, maxDepth); err != nil {
+		dr.logError("Failed to write dependent paths", err, "resource", node)
+	}
+}
+
+// WriteDependencyPaths writes the paths printed by ListDirectDependencies to
+// w. Once MaxPaths paths are written, it stops with a TraversalLimitError.
+func (dr *DependencyResolver) WriteDependencyPaths(w io.Writer, node string, maxDepth int) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.writePaths(w, dr.graph(), node, maxDepth, false)
+}
+
+// WriteDependentPaths writes the paths printed by ListReverseDependencies to
+// w, stopping like WriteDependencyPaths.
+func (dr *DependencyResolver) WriteDependentPaths(w io.Writer, node string, maxDepth int) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.writePaths(w, dr.reverseDependencies(), node, maxDepth, true)
+}
+
+// writePaths writes the path from root to every node reachable from it along
+// g, one per line, or the ones of its dependents when dependents is set. A
+// full path repeats the one of its parent, so the output of a chain of n
+// resources grows with n²; compact paths only hold the parent and the node,
+// keeping the output linear. IDs are styled once and paths written straight
+// to a buffer, so memory stays linear either way.
+func (dr *DependencyResolver) writePaths(w io.Writer, g dag.Graph[string], root string, maxDepth int, dependents bool) error {
+	out := bufio.NewWriter(w)
+	styled := make(map[string]string)
+	write := func(id string) {
+		s, ok := styled[id]
+		if !ok {
+			s = styledID(id)
+			styled[id] = s
+		}
+		out.WriteString(s)
+	}
+
+	var err error
+	paths := 0
+	g.WalkPaths(root, maxDepth, func(path []string) bool {
+		if paths++; dr.MaxPaths > 0 && paths > dr.MaxPaths {
+			err = &TraversalLimitError{Resource: root, Dependents: dependents, Limit: LimitPaths, Max: dr.MaxPaths}
+			return false
+		}
+		if dr.CompactPaths && len(path) > 2 {
+			path = path[len(path)-2:]
+		}
+		for i, id := range path {
+			if i > 0 {
+				out.WriteString(" -> ")
+			}
+			write(id)
+		}
+		out.WriteByte('\n')
+		return true
+	})
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// ListDependencyTree prints every path from the given resource down to a
+// resource without requirements, or down to maxDepth levels unless maxDepth is
+// 0. Paths running into a cycle are not printed.
+func (dr *DependencyResolver) ListDependencyTree(node string, maxDepth int) {
+	paths, err := dr.DependencyTree(node, maxDepth)
+	if err != nil {
+		dr.logError("Failed to list dependency tree", err, "resource", node)
+		return
+	}
+	for _, path := range paths {
+		dr.println(styledPath(path, " <- "))
+	}
+}
+
+// DependencyTree returns every path from the given resource down to a
+// resource without requirements, in depth-first order. When maxDepth is not
+// 0, paths are cut maxDepth levels below the resource. Paths running into a
+// cycle are skipped. The number of paths can grow exponentially with the
+// closure, so a closure exceeding MaxTraversalDepth or MaxClosureSize, or
+// taking more than MaxPaths paths, fails with a TraversalLimitError.
+func (dr *DependencyResolver) DependencyTree(node string, maxDepth int) ([][]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	if err := dr.checkLimits([]string{node}, false, maxDepth); err != nil {
+		return nil, err
+	}
+	var paths [][]string
+	for path := range dr.graph().Branches(node, maxDepth) {
+		if dr.MaxPaths > 0 && len(paths) == dr.MaxPaths {
+			return nil, &TraversalLimitError{Resource: node, Limit: LimitPaths, Max: dr.MaxPaths}
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ListDependencyTreeTopDown prints the closure of the given resource in
+// execution order, dependencies first, limited to resources at most maxDepth
+// requirements away unless maxDepth is 0.
+func (dr *DependencyResolver) ListDependencyTreeTopDown(node string, maxDepth int) {
+	order, err := dr.DependencyOrder(node, maxDepth)
+	if err != nil {
+		dr.logError("Failed to list dependency order", err, "resource", node)
+		return
+	}
+	for _, res := range order {
+		dr.println(styles.id.Render(res))
+	}
+}
+
+// BuildDependencyStack returns the closure of the given resource in execution
+// order, skipping resources already marked in visited.
+func (dr *DependencyResolver) BuildDependencyStack(node string, visited map[string]bool) []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.graph().PostOrder(node, visited, 0)
+}
+
+// DependencyOrder returns the closure of the given resource in execution
+// order, limited to resources at most maxDepth requirements away unless
+// maxDepth is 0. A closure exceeding MaxTraversalDepth or MaxClosureSize
+// fails with a TraversalLimitError.
+func (dr *DependencyResolver) DependencyOrder(node string, maxDepth int) ([]string, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	if err := dr.checkLimits([]string{node}, false, maxDepth); err != nil {
+		return nil, err
+	}
+	return dr.graph().PostOrder(node, make(map[string]bool), maxDepth), nil
+}
@@ -0,0 +1,238 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupDiamondResolver() *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "A", Requires: []string{"B", "C"}},
+		{Id: "B", Requires: []string{"D"}},
+		{Id: "C", Requires: []string{"D"}},
+		{Id: "D"},
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+// setupChainResolver builds a chain where node i requires node i-1.
+func setupChainResolver(length int) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = make([]ResourceNodeEntry, length)
+	for i := range resolver.Resources {
+		resolver.Resources[i].Id = fmt.Sprintf("n%d", i)
+		if i > 0 {
+			resolver.Resources[i].Requires = []string{fmt.Sprintf("n%d", i-1)}
+		}
+	}
+	resolver.BuildDependencies()
+	return resolver
+}
+
+func TestDependencyResolver_ListDirectDependencies(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	output := captureOutput(func() { resolver.ListDirectDependencies("A", 0) })
+
+	expectedOutput := "A\nA -> B\nA -> B -> D\nA -> C\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+
+	chain := setupTestResolver()
+	output = captureOutput(func() { chain.ListDirectDependencies("c", 0) })
+	if output != "c\nc -> b\nc -> b -> a\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+}
+
+func TestDependencyResolver_CompactPaths(t *testing.T) {
+	resolver := setupDiamondResolver()
+	resolver.CompactPaths = true
+
+	output := captureOutput(func() { resolver.ListDirectDependencies("A", 0) })
+	if output != "A\nA -> B\nB -> D\nA -> C\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+	output = captureOutput(func() { resolver.ListReverseDependencies("D", 0) })
+	if output != "D\nD -> B\nB -> A\nD -> C\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+
+	const length = 100000
+	chain := setupChainResolver(length)
+	chain.CompactPaths = true
+	var out strings.Builder
+	if err := chain.WriteDependencyPaths(&out, fmt.Sprintf("n%d", length-1), 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != length {
+		t.Errorf("Expected %d lines, got %d", length, lines)
+	}
+	if out.Len() > 20*length {
+		t.Errorf("Expected the output to grow linearly, got %d bytes for %d resources", out.Len(), length)
+	}
+}
+
+func TestDependencyResolver_ListReverseDependencies(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	output := captureOutput(func() { resolver.ListReverseDependencies("D", 0) })
+
+	expectedOutput := "D\nD -> B\nD -> B -> A\nD -> C\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestDependencyResolver_ListDependencyTree(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	output := captureOutput(func() { resolver.ListDependencyTree("A", 0) })
+
+	expectedOutput := "A <- B <- D\nA <- C <- D\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestDependencyResolver_ListDependencyTree_Circular(t *testing.T) {
+	resolver := setupDiamondResolver()
+	resolver.Resources[3].Requires = []string{"A"}
+	resolver.BuildDependencies()
+
+	output := captureOutput(func() { resolver.ListDependencyTree("A", 0) })
+	if output != "" {
+		t.Errorf("Expected no output, got:\n%s", output)
+	}
+}
+
+func TestDependencyResolver_ListDependencyTreeTopDown(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	output := captureOutput(func() { resolver.ListDependencyTreeTopDown("A", 0) })
+
+	expectedOutput := "D\nB\nC\nA\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestBuildDependencyStack_Circular(t *testing.T) {
+	resolver := setupDiamondResolver()
+	resolver.Resources[3].Requires = []string{"A"}
+	resolver.BuildDependencies()
+
+	stack := resolver.BuildDependencyStack("A", make(map[string]bool))
+	if strings.Join(stack, ",") != "D,B,C,A" {
+		t.Errorf("Expected stack [D B C A], got %v", stack)
+	}
+}
+
+func TestTraversal_DeepChain(t *testing.T) {
+	const length = 100000
+	resolver := setupChainResolver(length)
+	last := fmt.Sprintf("n%d", length-1)
+
+	stack := resolver.BuildDependencyStack(last, make(map[string]bool))
+	if len(stack) != length || stack[0] != "n0" || stack[length-1] != last {
+		t.Fatalf("Expected a stack of %d resources from n0 to %s, got %d", length, last, len(stack))
+	}
+
+	visits := 0
+	resolver.graph().WalkPaths(last, 0, func(path []string) bool {
+		visits++
+		if len(path) != visits {
+			t.Fatalf("Expected a path of length %d, got %d", visits, len(path))
+		}
+		return true
+	})
+	if visits != length {
+		t.Errorf("Expected %d visits, got %d", length, visits)
+	}
+
+	output := captureOutput(func() { resolver.ListDependencyTree(last, 0) })
+	if strings.Count(output, " <- ") != length-1 || !strings.HasSuffix(output, " <- n0\n") {
+		t.Errorf("Expected a single path through the whole chain")
+	}
+}
+
+func TestTraversal_MaxDepth(t *testing.T) {
+	resolver := setupChainResolver(5)
+
+	output := captureOutput(func() { resolver.ListDirectDependencies("n4", 2) })
+	if output != "n4\nn4 -> n3\nn4 -> n3 -> n2\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+
+	output = captureOutput(func() { resolver.ListReverseDependencies("n0", 1) })
+	if output != "n0\nn0 -> n1\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+
+	if paths, _ := resolver.DependencyTree("n4", 2); !reflect.DeepEqual(paths, [][]string{{"n4", "n3", "n2"}}) {
+		t.Errorf("Expected a path cut after 2 levels, got %v", paths)
+	}
+
+	if order, _ := resolver.DependencyOrder("n4", 1); !reflect.DeepEqual(order, []string{"n3", "n4"}) {
+		t.Errorf("Expected [n3 n4], got %v", order)
+	}
+}
+
+// TestTraversal_MaxDepthShortcut checks that the depth-limited traversals
+// agree with DependenciesWithin when a resource is first reached at the depth
+// limit and then through a shorter path.
+func TestTraversal_MaxDepthShortcut(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "A", Requires: []string{"B", "C"}},
+		{Id: "B", Requires: []string{"C"}},
+		{Id: "C", Requires: []string{"D"}},
+		{Id: "D"},
+	}
+	resolver.BuildDependencies()
+
+	if deps := resolver.DependenciesWithin("A", 2); !reflect.DeepEqual(deps, []string{"B", "C", "D"}) {
+		t.Errorf("Expected [B C D], got %v", deps)
+	}
+	if order, _ := resolver.DependencyOrder("A", 2); !reflect.DeepEqual(order, []string{"D", "C", "B", "A"}) {
+		t.Errorf("Expected [D C B A], got %v", order)
+	}
+	resolver.CompactPaths = true
+	output := captureOutput(func() { resolver.ListDirectDependencies("A", 2) })
+	if output != "A\nA -> B\nB -> C\nA -> C\nC -> D\n" {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+}
+
+func benchmarkDependencyPaths(b *testing.B, length int, compact bool) {
+	resolver := setupChainResolver(length)
+	resolver.CompactPaths = compact
+	last := fmt.Sprintf("n%d", length-1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := resolver.WriteDependencyPaths(io.Discard, last, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDependencyPaths_Chain10k(b *testing.B) {
+	benchmarkDependencyPaths(b, 10000, false)
+}
+
+func BenchmarkDependencyPaths_Chain10kCompact(b *testing.B) {
+	benchmarkDependencyPaths(b, 10000, true)
+}
+
+// Full paths of a 100k chain add up to about 40 GB, so only compact paths are
+// benchmarked at that size.
+func BenchmarkDependencyPaths_Chain100kCompact(b *testing.B) {
+	benchmarkDependencyPaths(b, 100000, true)
+}
@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"fmt"
+	"io"
+)
+
+// treeFrame is a resource of the tree being rendered, with the prefix of its
+// children and the index of the next child to render.
+type treeFrame struct {
+	node   string
+	prefix string
+	depth  int
+	next   int
+}
+
+// WriteDependencyTree writes the requirements of root as an indented tree,
+// down to maxDepth levels below root, or the whole closure when maxDepth is 0.
+// Resources already expanded elsewhere in the tree are marked with (*), and
+// requirements leading back to a resource on the current branch with (cycle).
+func (dr *DependencyResolver) WriteDependencyTree(w io.Writer, root string, maxDepth int) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return writeTree(w, root, dr.ResourceDependencies, maxDepth, styledID)
+}
+
+// WriteDependencyTreeText writes the tree of WriteDependencyTree without
+// colors, for files.
+func (dr *DependencyResolver) WriteDependencyTreeText(w io.Writer, root string, maxDepth int) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return writeTree(w, root, dr.ResourceDependencies, maxDepth, func(id string) string { return id })
+}
+
+// WriteDependentTree writes the resources requiring root as an indented tree,
+// like WriteDependencyTree.
+func (dr *DependencyResolver) WriteDependentTree(w io.Writer, root string, maxDepth int) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return writeTree(w, root, dr.reverseDependencies(), maxDepth, styledID)
+}
+
+// writeTree writes the tree of root along edges, rendering the IDs with
+// render.
+func writeTree(w io.Writer, root string, edges map[string][]string, maxDepth int, render func(string) string) error {
+	if _, err := fmt.Fprintln(w, render(root)); err != nil {
+		return err
+	}
+
+	expanded := map[string]bool{root: true}
+	onBranch := map[string]bool{root: true}
+	stack := []treeFrame{{node: root}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		children := edges[top.node]
+		if top.next >= len(children) {
+			onBranch[top.node] = false
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		child := children[top.next]
+		top.next++
+		branch, indent := "├── ", "│   "
+		if top.next == len(children) {
+			branch, indent = "└── ", "    "
+		}
+
+		marker := ""
+		switch {
+		case onBranch[child]:
+			marker = " (cycle)"
+		case expanded[child] && len(edges[child]) > 0:
+			marker = " (*)"
+		}
+		if _, err := fmt.Fprintln(w, top.prefix+branch+render(child)+marker); err != nil {
+			return err
+		}
+		if marker != "" || expanded[child] || (maxDepth > 0 && top.depth+1 >= maxDepth) {
+			continue
+		}
+
+		expanded[child] = true
+		onBranch[child] = true
+		stack = append(stack, treeFrame{node: child, prefix: top.prefix + indent, depth: top.depth + 1})
+	}
+	return nil
+}
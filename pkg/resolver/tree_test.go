@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDependencyTree(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"api", "web"}},
+		{Id: "api", Requires: []string{"lib", "db"}},
+		{Id: "web", Requires: []string{"lib"}},
+		{Id: "lib", Requires: []string{"base"}},
+		{Id: "db", Requires: []string{"api"}},
+		{Id: "base"},
+	}
+	resolver.BuildDependencies()
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		expected string
+	}{
+		{"full", 0, `app
+├── api
+│   ├── lib
+│   │   └── base
+│   └── db
+│       └── api (cycle)
+└── web
+    └── lib (*)
+`},
+		{"depth", 1, `app
+├── api
+└── web
+`},
+		{"depth 2", 2, `app
+├── api
+│   ├── lib
+│   └── db
+└── web
+    └── lib
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := resolver.WriteDependencyTree(&buf, "app", tt.maxDepth); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteDependentTree(t *testing.T) {
+	resolver := setupDiamondResolver()
+
+	var buf bytes.Buffer
+	if err := resolver.WriteDependentTree(&buf, "D", 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `D
+├── B
+│   └── A
+└── C
+    └── A
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
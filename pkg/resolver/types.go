@@ -0,0 +1,272 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/afero"
+)
+
+// Built-in resource types. Resources without a Type are shell resources.
+const (
+	// TypeShell runs the exec command of every step with the shell session,
+	// evaluating the skip, check and expect rules of the steps.
+	TypeShell = "shell"
+	// TypeNoop does nothing, for resources grouping their requirements.
+	TypeNoop = "noop"
+	// TypeHTTPCheck requests the URL in the exec field of every step, and
+	// fails unless the response status is 2xx or 3xx.
+	TypeHTTPCheck = "http-check"
+	// TypeFileTemplate renders a Go template for every step, whose exec field
+	// holds the template file and the destination file, separated by spaces.
+	// Templates are given the resource as .Resource and the environment
+	// variables as .Env.
+	TypeFileTemplate = "file-template"
+)
+
+// TypeRun is the execution of the steps of one phase of a resource, handed to
+// the handler of its type.
+type TypeRun struct {
+	Resource ResourceNodeEntry
+	// Phase is preRun, run, postRun or rollback.
+	Phase string
+	Steps []RunStep
+	// Fs is the filesystem of the resolver, and WorkDir its work directory.
+	Fs      afero.Fs
+	WorkDir string
+	Client  *http.Client
+	// Output receives the output of the steps, printed like the output of a
+	// shell step.
+	Output io.Writer
+}
+
+// TypeHandler executes the steps of a resource of a given type. Returning an
+// error fails the resource, which is retried according to its retry policy
+// during the run phase.
+type TypeHandler func(ctx context.Context, run TypeRun) error
+
+// builtinTypes are the handlers of the built-in types, besides TypeShell which
+// runs with the resolver's shell session.
+var builtinTypes = map[string]TypeHandler{
+	TypeNoop:         func(context.Context, TypeRun) error { return nil },
+	TypeHTTPCheck:    runHTTPCheck,
+	TypeFileTemplate: runFileTemplate,
+}
+
+// UnknownTypeError is returned for a resource whose type has no handler.
+type UnknownTypeError struct {
+	Resource string
+	Type     string
+	Known    []string
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("unknown type '%s' of resource '%s', expected one of: %s", e.Type, e.Resource, strings.Join(e.Known, ", "))
+}
+
+// RegisterType registers the handler executing the steps of the resources of
+// the given type, replacing the built-in handler of the type, if any.
+func (dr *DependencyResolver) RegisterType(name string, handler TypeHandler) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if dr.types == nil {
+		dr.types = make(map[string]TypeHandler)
+	}
+	dr.types[name] = handler
+}
+
+// Types returns the names of the resource types with a handler, sorted.
+func (dr *DependencyResolver) Types() []string {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.typeNames()
+}
+
+func (dr *DependencyResolver) typeNames() []string {
+	names := []string{TypeShell}
+	for name := range builtinTypes {
+		names = append(names, name)
+	}
+	for name := range dr.types {
+		if _, builtin := builtinTypes[name]; !builtin && name != TypeShell {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// typeHandler returns the handler of a resource type, or nil for TypeShell
+// when it is not replaced.
+func (dr *DependencyResolver) typeHandler(name string) (TypeHandler, bool) {
+	if name == "" {
+		name = TypeShell
+	}
+	dr.mu.RLock()
+	handler, ok := dr.types[name]
+	dr.mu.RUnlock()
+	if ok {
+		return handler, true
+	}
+	if name == TypeShell {
+		return nil, true
+	}
+	handler, ok = builtinTypes[name]
+	return handler, ok
+}
+
+// ValidateTypes returns an UnknownTypeError for every resource whose type has
+// no handler, and an error for every resource declaring both a type and a
+// runner plugin. Resources are checked concurrently, see Workers.
+func (dr *DependencyResolver) ValidateTypes() error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return errors.Join(runPasses(dr, func(_ int, entry ResourceNodeEntry) []error {
+		if err := dr.checkType(entry); err != nil {
+			return []error{err}
+		}
+		return nil
+	})...)
+}
+
+// checkTypes is ValidateTypes for the given entries, for callers holding the
+// read lock.
+func (dr *DependencyResolver) checkTypes(entries []ResourceNodeEntry) error {
+	var errs []error
+	for _, entry := range entries {
+		if err := dr.checkType(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (dr *DependencyResolver) checkType(entry ResourceNodeEntry) error {
+	if entry.Type == "" {
+		return nil
+	}
+	if entry.Runner != "" {
+		return fmt.Errorf("resource '%s' declares both type '%s' and runner '%s'", entry.Id, entry.Type, entry.Runner)
+	}
+	_, custom := dr.types[entry.Type]
+	_, builtin := builtinTypes[entry.Type]
+	if !custom && !builtin && entry.Type != TypeShell {
+		return &UnknownTypeError{Resource: entry.Id, Type: entry.Type, Known: dr.typeNames()}
+	}
+	return nil
+}
+
+// checkStackTypes returns the errors of checkTypes for the resources of a
+// resolved stack, so a run fails before executing anything.
+func (dr *DependencyResolver) checkStackTypes(stack []string) error {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	entries := make([]ResourceNodeEntry, 0, len(stack))
+	for _, id := range stack {
+		if entry, ok := dr.getResource(id); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return dr.checkTypes(entries)
+}
+
+// runType hands the steps of a phase of a resource to the handler of its
+// type, printing their output like the output of a step.
+func (dr *DependencyResolver) runType(ctx context.Context, handler TypeHandler, res ResourceNodeEntry, steps []RunStep, logs *RunnerLogs, client *http.Client) error {
+	var output bytes.Buffer
+	err := handler(ctx, TypeRun{
+		Resource: res,
+		Phase:    phaseOf(ctx),
+		Steps:    steps,
+		Fs:       dr.Fs,
+		WorkDir:  dr.WorkDir,
+		Client:   client,
+		Output:   &output,
+	})
+	if output.Len() > 0 {
+		logs.Add(StepLog{
+			targetRes: res.Id,
+			command:   res.Type,
+			id:        res.Id,
+			name:      res.Type,
+			message:   strings.TrimRight(output.String(), "\n"),
+		})
+	}
+	if err != nil {
+		return dr.logError("Resource type failed", err, entryFields(res, phaseOf(ctx), "type", res.Type)...)
+	}
+	return nil
+}
+
+func runHTTPCheck(ctx context.Context, run TypeRun) error {
+	for _, step := range run.Steps {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, step.Exec, nil)
+		if err != nil {
+			return fmt.Errorf("invalid URL of step '%s': %w", step.Name, err)
+		}
+		resp, err := run.Client.Do(req)
+		if err != nil {
+			return fmt.Errorf("step '%s' failed: %w", step.Name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("step '%s' failed: %s returned %s", step.Name, step.Exec, resp.Status)
+		}
+		fmt.Fprintf(run.Output, "%s: %s\n", step.Exec, resp.Status)
+	}
+	return nil
+}
+
+// templateData is the data of the templates rendered by file-template
+// resources.
+type templateData struct {
+	Resource ResourceNodeEntry
+	Env      map[string]string
+}
+
+func runFileTemplate(ctx context.Context, run TypeRun) error {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = value
+		}
+	}
+
+	for _, step := range run.Steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		paths := strings.Fields(step.Exec)
+		if len(paths) != 2 {
+			return fmt.Errorf("step '%s' must give the template and destination files, got '%s'", step.Name, step.Exec)
+		}
+		src, dest := paths[0], paths[1]
+
+		text, err := afero.ReadFile(run.Fs, src)
+		if err != nil {
+			return fmt.Errorf("error reading template of step '%s': %w", step.Name, err)
+		}
+		tmpl, err := template.New(filepath.Base(src)).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("invalid template of step '%s': %w", step.Name, err)
+		}
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, templateData{Resource: run.Resource, Env: env}); err != nil {
+			return fmt.Errorf("error rendering template of step '%s': %w", step.Name, err)
+		}
+		if err := afero.WriteFile(run.Fs, dest, rendered.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("error writing %s: %w", dest, err)
+		}
+		fmt.Fprintf(run.Output, "rendered %s to %s\n", src, dest)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTypes_Noop(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "group", Type: TypeNoop, Run: []RunStep{{Name: "never", Exec: "exit 1"}}},
+		{Id: "app", Requires: []string{"group"}, Run: []RunStep{{Name: "start", Exec: "echo app"}}},
+	}
+	resolver.BuildDependencies()
+
+	results, err := resolver.Execute(context.Background(), []string{"app"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := statuses(results); got["group"] != StatusSucceeded || got["app"] != StatusSucceeded {
+		t.Errorf("Expected both resources to succeed, got %v", got)
+	}
+}
+
+func TestTypes_HTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/healthz", false},
+		{"/missing", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resolver := setupTestResolver()
+			resolver.StepOutput = &strings.Builder{}
+			resolver.Resources = []ResourceNodeEntry{
+				{Id: "healthy", Type: TypeHTTPCheck, Run: []RunStep{{Name: "ping", Exec: server.URL + tt.path}}},
+			}
+			resolver.BuildDependencies()
+
+			_, err := resolver.Execute(context.Background(), []string{"healthy"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypes_FileTemplate(t *testing.T) {
+	t.Setenv("RUNNER_TEST_PORT", "8080")
+
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	if err := afero.WriteFile(resolver.Fs, "/app.conf.tmpl", []byte("name={{.Resource.Name}} port={{.Env.RUNNER_TEST_PORT}}"), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "config", Name: "App", Type: TypeFileTemplate, Run: []RunStep{{Name: "render", Exec: "/app.conf.tmpl /app.conf"}}},
+	}
+	resolver.BuildDependencies()
+
+	if _, err := resolver.Execute(context.Background(), []string{"config"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := afero.ReadFile(resolver.Fs, "/app.conf")
+	if err != nil {
+		t.Fatalf("Failed to read rendered file: %v", err)
+	}
+	if got, want := string(data), "name=App port=8080"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestTypes_RegisterType(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	var phases []string
+	resolver.RegisterType("custom", func(ctx context.Context, run TypeRun) error {
+		phases = append(phases, run.Phase+":"+run.Steps[0].Name)
+		return nil
+	})
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "thing", Type: "custom",
+			PreRun: []RunStep{{Name: "prepare"}},
+			Run:    []RunStep{{Name: "create"}}},
+	}
+	resolver.BuildDependencies()
+
+	if err := resolver.ValidateTypes(); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+	if _, err := resolver.Execute(context.Background(), []string{"thing"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, want := strings.Join(phases, ","), "preRun:prepare,run:create"; got != want {
+		t.Errorf("Expected phases %q, got %q", want, got)
+	}
+	if types := resolver.Types(); !strings.Contains(strings.Join(types, ","), "custom") {
+		t.Errorf("Expected custom in types, got %v", types)
+	}
+}
+
+func TestTypes_Unknown(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.StepOutput = &strings.Builder{}
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "first", Run: []RunStep{{Name: "touch", Exec: "echo first"}}},
+		{Id: "odd", Type: "teleport", Requires: []string{"first"}, Run: []RunStep{{Name: "go", Exec: "now"}}},
+	}
+	resolver.BuildDependencies()
+
+	var unknown *UnknownTypeError
+	if err := resolver.ValidateTypes(); !errors.As(err, &unknown) || unknown.Resource != "odd" {
+		t.Errorf("Expected an UnknownTypeError for odd, got %v", err)
+	}
+	if err := resolver.HandleValidateCommand(false, false); !errors.As(err, &unknown) {
+		t.Errorf("Expected validate to fail with an UnknownTypeError, got %v", err)
+	}
+
+	results, err := resolver.Execute(context.Background(), []string{"odd"})
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected an UnknownTypeError, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected nothing to run, got %v", statuses(results))
+	}
+}
+
+func TestTypes_TypeAndRunner(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "both", Type: TypeNoop, Runner: "terraform"},
+	}
+	resolver.BuildDependencies()
+
+	if err := resolver.ValidateTypes(); err == nil || !strings.Contains(err.Error(), "both type") {
+		t.Errorf("Expected an error for a resource with a type and a runner, got %v", err)
+	}
+}
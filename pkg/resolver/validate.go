@@ -0,0 +1,129 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Severity tells whether a Finding fails the validation of the catalog.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Codes of the findings that are errors rather than warnings.
+const (
+	// CodeUnknownType is a resource of a type without a handler.
+	CodeUnknownType FindingCode = "unknown-type"
+	// CodeInvalidType is a resource declaring both a type and a runner.
+	CodeInvalidType FindingCode = "invalid-type"
+)
+
+// loadErrorCodes are the codes of the warnings of lenient loading that are
+// errors in strict mode.
+var loadErrorCodes = []FindingCode{CodeUnknownField, CodeDuplicateResource, CodeMissingRequirement, CodeInvalidID, CodeSelfDependency}
+
+// Finding is an issue found by Validate, an error or a warning.
+type Finding struct {
+	Severity Severity `json:"severity" yaml:"severity"`
+	Warning  `yaml:",inline"`
+	// err is the error the finding comes from, if any.
+	err error
+}
+
+func (f Finding) String() string {
+	return f.Warning.String() + " [" + string(f.Code) + "]"
+}
+
+// ValidationError is returned when the validation of the catalog finds
+// errors, or warnings in strict mode. It wraps the errors the findings come
+// from, such as UnknownTypeErrors.
+type ValidationError struct {
+	Errors   int
+	Warnings int
+	Strict   bool
+	errs     []error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Errors == 0 {
+		return fmt.Sprintf("the catalog has %d warnings, failing in strict mode", e.Warnings)
+	}
+	return fmt.Sprintf("the catalog has %d errors and %d warnings", e.Errors, e.Warnings)
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.errs
+}
+
+// Validate runs every check of the catalog and returns its findings, the
+// errors first, each group in the order of the checks. The load warnings of
+// lenient loading that strict loading reports as errors, such as duplicate
+// resources, are errors unless LoadMode is lenient, so a catalog loaded
+// leniently can be validated as if it had been loaded strictly. The other
+// findings are the type errors, the dependency cycles, which are errors
+// wrapping a CycleError since the resources in them cannot run, and the
+// Warnings.
+func (dr *DependencyResolver) Validate() []Finding {
+	cycles := make(map[string][]string)
+	for _, cycle := range dr.Cycles() {
+		cycles[cycle[0]] = cycle
+	}
+
+	var findings, cycleErrs, warnings []Finding
+	for _, warning := range dr.Warnings() {
+		if warning.Code == CodeDependencyCycle {
+			cycleErrs = append(cycleErrs, Finding{Severity: SeverityError, Warning: warning, err: &CycleError{Resources: cycles[warning.Resource]}})
+		} else if !dr.lenient() && slices.Contains(loadErrorCodes, warning.Code) {
+			findings = append(findings, Finding{Severity: SeverityError, Warning: warning})
+		} else {
+			warnings = append(warnings, Finding{Severity: SeverityWarning, Warning: warning})
+		}
+	}
+
+	dr.mu.RLock()
+	typeErrs := runPasses(dr, func(_ int, entry ResourceNodeEntry) []Finding {
+		err := dr.checkType(entry)
+		if err == nil {
+			return nil
+		}
+		code := CodeInvalidType
+		var unknown *UnknownTypeError
+		if errors.As(err, &unknown) {
+			code = CodeUnknownType
+		}
+		return []Finding{{
+			Severity: SeverityError,
+			Warning:  Warning{Code: code, Resource: entry.Id, File: dr.sources[entry.Id], Message: err.Error()},
+			err:      err,
+		}}
+	})
+	dr.mu.RUnlock()
+
+	findings = append(findings, typeErrs...)
+	findings = append(findings, cycleErrs...)
+	return append(findings, warnings...)
+}
+
+// validationError returns the ValidationError of the findings, or nil when
+// they hold no error, and no warning in strict mode.
+func validationError(findings []Finding, strict bool) error {
+	err := &ValidationError{Strict: strict}
+	for _, finding := range findings {
+		if finding.Severity == SeverityWarning {
+			err.Warnings++
+			continue
+		}
+		err.Errors++
+		if finding.err != nil {
+			err.errs = append(err.errs, finding.err)
+		}
+	}
+	if err.Errors == 0 && (!strict || err.Warnings == 0) {
+		return nil
+	}
+	return err
+}
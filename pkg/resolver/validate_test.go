@@ -0,0 +1,56 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	resolver := setupTestResolver()
+	resolver.Resources = []ResourceNodeEntry{
+		{Id: "app", Requires: []string{"db"}, Optional: []string{"docs"}},
+		{Id: "db", Requires: []string{"app"}, Type: "unknown"},
+	}
+	resolver.BuildDependencies()
+	resolver.warnings = []Warning{{Code: CodeDuplicateResource, Resource: "db", Message: "resource 'db' is defined twice"}}
+
+	var codes []FindingCode
+	var severities []Severity
+	findings := resolver.Validate()
+	for _, finding := range findings {
+		codes = append(codes, finding.Code)
+		severities = append(severities, finding.Severity)
+	}
+	expectedCodes := []FindingCode{CodeDuplicateResource, CodeUnknownType, CodeDependencyCycle, CodeMissingOptional}
+	expectedSeverities := []Severity{SeverityError, SeverityError, SeverityError, SeverityWarning}
+	if !reflect.DeepEqual(codes, expectedCodes) || !reflect.DeepEqual(severities, expectedSeverities) {
+		t.Errorf("Expected %v %v, got %v %v", expectedCodes, expectedSeverities, codes, severities)
+	}
+	if expected := "dependency cycle between db, app [dependency-cycle]"; findings[2].String() != expected {
+		t.Errorf("Expected %q, got %q", expected, findings[2].String())
+	}
+	var cycle *CycleError
+	if !errors.As(findings[2].err, &cycle) || !reflect.DeepEqual(cycle.Resources, []string{"db", "app"}) {
+		t.Errorf("Expected the cycle finding to wrap a cycle error, got %v", findings[2].err)
+	}
+
+	var validationErr *ValidationError
+	var unknown *UnknownTypeError
+	err := validationError(findings, false)
+	if !errors.As(err, &validationErr) || validationErr.Errors != 3 || validationErr.Warnings != 1 || !errors.As(err, &unknown) {
+		t.Errorf("Expected a validation error wrapping the type error, got %v", err)
+	}
+
+	resolver.LoadMode = LoadLenient
+	if findings := resolver.Validate(); findings[0].Code != CodeUnknownType || findings[2].Severity != SeverityWarning {
+		t.Errorf("Expected lenient load issues to be warnings, got %v", findings)
+	}
+	warningsOnly := findings[3:]
+	if err := validationError(warningsOnly, false); err != nil {
+		t.Errorf("Expected warnings to pass, got %v", err)
+	}
+	if err := validationError(warningsOnly, true); !errors.As(err, &validationErr) || !validationErr.Strict {
+		t.Errorf("Expected warnings to fail in strict mode, got %v", err)
+	}
+}
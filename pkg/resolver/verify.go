@@ -0,0 +1,201 @@
+package resolver
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/blake2b"
+)
+
+// CatalogVerification describes how to check a remote catalog before
+// trusting it. The catalog of a URL is its content; the catalog of a git
+// source is a listing of its manifest files, formatted like the output of
+// sha256sum, with paths relative to the catalog directory.
+type CatalogVerification struct {
+	// Source is the URL or git source the verification applies to.
+	Source string
+	// SHA256 is the hex digest of the catalog.
+	SHA256 string
+	// Signature is the file or URL of a minisign signature of the catalog,
+	// <source>.minisig by default for URLs.
+	Signature string
+	// PublicKey is the minisign public key the signature must be made with.
+	PublicKey string
+}
+
+// ChecksumMismatchError is returned when the digest of a catalog differs from
+// the expected one.
+type ChecksumMismatchError struct {
+	Source   string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.Source, e.Expected, e.Actual)
+}
+
+// SignatureError is returned when the signature of a catalog cannot be
+// verified.
+type SignatureError struct {
+	Source string
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("invalid signature for %s: %s", e.Source, e.Reason)
+}
+
+// verification returns the verification configured for the source.
+func (dr *DependencyResolver) verification(source string) (CatalogVerification, bool) {
+	for _, v := range dr.Verifications {
+		if v.Source == source {
+			return v, true
+		}
+	}
+	return CatalogVerification{}, false
+}
+
+// verifyCatalog checks the catalog of the source against its configured
+// verification, if any.
+func (dr *DependencyResolver) verifyCatalog(source string, catalog []byte) error {
+	v, ok := dr.verification(source)
+	if !ok {
+		return nil
+	}
+
+	if v.SHA256 != "" {
+		sum := sha256.Sum256(catalog)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, v.SHA256) {
+			return &ChecksumMismatchError{Source: source, Expected: v.SHA256, Actual: actual}
+		}
+	}
+
+	if v.PublicKey != "" {
+		signature := v.Signature
+		if signature == "" {
+			if !isURL(source) {
+				return &SignatureError{Source: source, Reason: "no signature file configured"}
+			}
+			signature = source + ".minisig"
+		}
+		data, err := dr.readManifest(signature)
+		if err != nil {
+			return &SignatureError{Source: source, Reason: err.Error()}
+		}
+		if err := verifyMinisign(v.PublicKey, data, catalog); err != nil {
+			return &SignatureError{Source: source, Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+// catalogListing lists the manifest files under dir with their digest, one
+// per line in path order, like sha256sum.
+func (dr *DependencyResolver) catalogListing(dir string) ([]byte, error) {
+	var lines []string
+	err := afero.Walk(dr.Fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || !isManifest(path) {
+			return nil
+		}
+		data, err := afero.ReadFile(dr.Fs, path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		lines = append(lines, hex.EncodeToString(sum[:])+"  "+filepath.ToSlash(rel)+"\n")
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing catalog %s: %w", dir, err)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i][66:] < lines[j][66:] })
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// verifyMinisign verifies a minisign signature of message, including its
+// trusted comment, made with the public key.
+func verifyMinisign(publicKey string, signature, message []byte) error {
+	key, err := decodeMinisignLine([]byte(publicKey), 42)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	if string(key[:2]) != "Ed" {
+		return fmt.Errorf("unsupported public key algorithm %q", key[:2])
+	}
+	keyID, pub := key[2:10], ed25519.PublicKey(key[10:])
+
+	lines := strings.Split(strings.ReplaceAll(string(signature), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("malformed signature file")
+	}
+	sig, err := decodeMinisignLine([]byte(lines[1]), 74)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed trusted comment signature")
+	}
+	if !bytes.Equal(sig[2:10], keyID) {
+		return fmt.Errorf("signed with key %X, expected %X", sig[2:10], keyID)
+	}
+
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		hash := blake2b.Sum512(message)
+		message = hash[:]
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", sig[:2])
+	}
+	if !ed25519.Verify(pub, message, sig[10:]) {
+		return fmt.Errorf("signature does not match the content")
+	}
+
+	trusted := append(append([]byte(nil), sig[10:]...), strings.TrimPrefix(lines[2], "trusted comment: ")...)
+	if !ed25519.Verify(pub, trusted, globalSig) {
+		return fmt.Errorf("trusted comment signature does not match")
+	}
+	return nil
+}
+
+// decodeMinisignLine decodes the base64 line of a minisign key or signature,
+// skipping its untrusted comment, and checks its length.
+func decodeMinisignLine(data []byte, size int) ([]byte, error) {
+	var line string
+	for _, l := range strings.Split(string(data), "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" && !strings.HasPrefix(l, "untrusted comment:") {
+			line = l
+			break
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(decoded))
+	}
+	return decoded, nil
+}
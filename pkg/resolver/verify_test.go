@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignKey returns a key pair with its minisign encoded public key.
+func minisignKey(t *testing.T) (string, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := append([]byte("Ed12345678"), pub...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(key) + "\n", priv
+}
+
+// minisign signs message like minisign, prehashed unless legacy is set.
+func minisign(priv ed25519.PrivateKey, message []byte, legacy bool) []byte {
+	alg := "ED"
+	if legacy {
+		alg = "Ed"
+	} else {
+		hash := blake2b.Sum512(message)
+		message = hash[:]
+	}
+	sig := append([]byte(alg+"12345678"), ed25519.Sign(priv, message)...)
+	comment := "timestamp:1700000000"
+	global := ed25519.Sign(priv, append(append([]byte(nil), sig[10:]...), comment...))
+	return []byte("untrusted comment: signature\n" +
+		base64.StdEncoding.EncodeToString(sig) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	pub, priv := minisignKey(t)
+	message := []byte(remoteCatalog)
+
+	for _, legacy := range []bool{false, true} {
+		if err := verifyMinisign(pub, minisign(priv, message, legacy), message); err != nil {
+			t.Errorf("Unexpected error (legacy %v): %v", legacy, err)
+		}
+	}
+	if err := verifyMinisign(pub, minisign(priv, message, false), []byte("tampered")); err == nil {
+		t.Error("Expected an error for tampered content")
+	}
+
+	otherPub, _ := minisignKey(t)
+	if err := verifyMinisign(otherPub, minisign(priv, message, false), message); err == nil {
+		t.Error("Expected an error for another key")
+	}
+}
+
+func TestLoadResourcesFromURL_Verification(t *testing.T) {
+	pub, priv := minisignKey(t)
+	signature := minisign(priv, []byte(remoteCatalog), false)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/catalog.yaml.minisig" {
+			w.Write(signature)
+			return
+		}
+		w.Write([]byte(remoteCatalog))
+	}))
+	defer server.Close()
+	url := server.URL + "/catalog.yaml"
+
+	sum := sha256.Sum256([]byte(remoteCatalog))
+	digest := hex.EncodeToString(sum[:])
+
+	resolver := setupTestResolver()
+	resolver.Verifications = []CatalogVerification{{Source: url, SHA256: digest, PublicKey: pub}}
+	if err := resolver.LoadResourcesFromURL(url); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resolver = setupTestResolver()
+	resolver.Verifications = []CatalogVerification{{Source: url, SHA256: "00" + digest[2:]}}
+	var mismatch *ChecksumMismatchError
+	if err := resolver.LoadResourcesFromURL(url); !errors.As(err, &mismatch) || mismatch.Actual != digest {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	otherPub, _ := minisignKey(t)
+	resolver = setupTestResolver()
+	resolver.Verifications = []CatalogVerification{{Source: url, PublicKey: otherPub}}
+	var sigErr *SignatureError
+	if err := resolver.LoadResourcesFromURL(url); !errors.As(err, &sigErr) {
+		t.Errorf("Expected a signature error, got %v", err)
+	}
+	if _, ok := resolver.GetResource("remote"); ok {
+		t.Error("Expected an unverified catalog not to be loaded")
+	}
+}
+
+func TestCatalogListing(t *testing.T) {
+	resolver := setupTestResolver()
+	afero.WriteFile(resolver.Fs, "catalog/b/tag.yaml", []byte("id: tag\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/git.yaml", []byte("id: git\n"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/.git/config.yaml", []byte("ignored"), 0644)
+	afero.WriteFile(resolver.Fs, "catalog/README.md", []byte("ignored"), 0644)
+
+	listing, err := resolver.catalogListing("catalog")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	digest := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	expected := digest("id: tag\n") + "  b/tag.yaml\n" + digest("id: git\n") + "  git.yaml\n"
+	if string(listing) != expected {
+		t.Errorf("Expected %q, got %q", expected, listing)
+	}
+}
@@ -0,0 +1,396 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+)
+
+// watchDebounce is how long Watch waits for changes to settle before
+// reloading.
+const watchDebounce = 100 * time.Millisecond
+
+// CatalogReloaded is sent once Watch reloaded the catalog, with the error
+// that kept the previous catalog, if any.
+const CatalogReloaded EventType = "CatalogReloaded"
+
+// Watcher reports changes to files and directories.
+type Watcher interface {
+	// Add starts watching a file, or the entries of a directory.
+	Add(path string) error
+	// Events receives the paths that changed.
+	Events() <-chan string
+	// Errors receives the errors of the watcher.
+	Errors() <-chan error
+	Close() error
+}
+
+// NewFSWatcher returns a Watcher of the OS filesystem, using fsnotify.
+func NewFSWatcher() (Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	fw := &fsWatcher{w: w, events: make(chan string), done: make(chan struct{})}
+	go fw.forward()
+	return fw, nil
+}
+
+type fsWatcher struct {
+	w      *fsnotify.Watcher
+	events chan string
+	done   chan struct{}
+}
+
+func (fw *fsWatcher) forward() {
+	defer close(fw.events)
+	for event := range fw.w.Events {
+		select {
+		case fw.events <- event.Name:
+		case <-fw.done:
+			return
+		}
+	}
+}
+
+func (fw *fsWatcher) Add(path string) error { return fw.w.Add(path) }
+func (fw *fsWatcher) Events() <-chan string { return fw.events }
+func (fw *fsWatcher) Errors() <-chan error  { return fw.w.Errors }
+
+func (fw *fsWatcher) Close() error {
+	close(fw.done)
+	return fw.w.Close()
+}
+
+// NewPollingWatcher returns a Watcher of any afero filesystem, comparing the
+// size and modification time of the watched files every interval.
+func NewPollingWatcher(fs afero.Fs, interval time.Duration) Watcher {
+	pw := &pollingWatcher{
+		fs:     fs,
+		events: make(chan string),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go pw.poll(interval)
+	return pw
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+type pollingWatcher struct {
+	fs     afero.Fs
+	events chan string
+	errors chan error
+	done   chan struct{}
+
+	mu    sync.Mutex
+	added []string
+}
+
+// Add starts watching the path from the next poll.
+func (pw *pollingWatcher) Add(path string) error {
+	if _, err := pw.fs.Stat(path); err != nil {
+		return err
+	}
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.added = append(pw.added, path)
+	return nil
+}
+
+func (pw *pollingWatcher) Events() <-chan string { return pw.events }
+func (pw *pollingWatcher) Errors() <-chan error  { return pw.errors }
+
+func (pw *pollingWatcher) Close() error {
+	close(pw.done)
+	return nil
+}
+
+func (pw *pollingWatcher) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	watched := make(map[string]bool)
+	stamps := make(map[string]fileStamp)
+	for {
+		select {
+		case <-pw.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := make(map[string]fileStamp, len(stamps))
+		for path := range watched {
+			for file, stamp := range pw.scan(path) {
+				current[file] = stamp
+			}
+		}
+		changed := changedFiles(stamps, current)
+
+		// Paths added since the last poll only give the stamps to compare
+		// the next poll with.
+		pw.mu.Lock()
+		added := pw.added
+		pw.added = nil
+		pw.mu.Unlock()
+		for _, path := range added {
+			watched[path] = true
+			for file, stamp := range pw.scan(path) {
+				current[file] = stamp
+			}
+		}
+		stamps = current
+
+		for _, file := range changed {
+			select {
+			case pw.events <- file:
+			case <-pw.done:
+				return
+			}
+		}
+	}
+}
+
+// scan returns the stamps of the path and of the entries of a directory.
+func (pw *pollingWatcher) scan(path string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	info, err := pw.fs.Stat(path)
+	if err != nil {
+		return stamps
+	}
+	stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	if info.IsDir() {
+		entries, _ := afero.ReadDir(pw.fs, path)
+		for _, entry := range entries {
+			stamps[filepath.Join(path, entry.Name())] = fileStamp{size: entry.Size(), modTime: entry.ModTime()}
+		}
+	}
+	return stamps
+}
+
+// changedFiles returns the files added, removed or modified between two
+// scans.
+func changedFiles(before, after map[string]fileStamp) []string {
+	var changed []string
+	for file, stamp := range after {
+		if previous, ok := before[file]; !ok || previous != stamp {
+			changed = append(changed, file)
+		}
+	}
+	for file := range before {
+		if _, ok := after[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+// newWatcher returns the Watcher of NewWatcher, or a default one for the
+// filesystem.
+func (dr *DependencyResolver) newWatcher() (Watcher, error) {
+	if dr.NewWatcher != nil {
+		return dr.NewWatcher()
+	}
+	if _, ok := dr.Fs.(*afero.OsFs); ok {
+		return NewFSWatcher()
+	}
+	return NewPollingWatcher(dr.Fs, 500*time.Millisecond), nil
+}
+
+// watchPaths returns the directories holding the local sources and overlays,
+// with their subdirectories. Directories are watched rather than files, so
+// files replaced by editors keep being watched.
+func (dr *DependencyResolver) watchPaths() []string {
+	dr.mu.RLock()
+	sources := append(append([]string(nil), dr.loadedSources...), dr.loadedOverlays...)
+	// The files defining resources include the manifests included by the
+	// sources.
+	for _, file := range dr.sources {
+		if !slices.Contains(sources, file) {
+			sources = append(sources, file)
+		}
+	}
+	dr.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, source := range sources {
+		if isURL(source) || strings.HasPrefix(source, gitSourcePrefix) {
+			continue
+		}
+		root := source
+		if IsGlob(source) {
+			root = globRoot(filepath.ToSlash(filepath.Clean(source)))
+		} else if isDir, _ := afero.IsDir(dr.Fs, source); !isDir {
+			if exists, _ := afero.DirExists(dr.Fs, filepath.Dir(source)); exists {
+				add(filepath.Dir(source))
+			}
+			continue
+		}
+		afero.Walk(dr.Fs, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if info.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				add(path)
+			}
+			return nil
+		})
+	}
+	return paths
+}
+
+// Watch watches the local files the catalog was loaded from, and reloads it
+// when they change, until ctx is done. A catalog failing to load keeps the
+// previous one. The listeners registered with OnEvent receive a
+// CatalogReloaded event after every reload.
+func (dr *DependencyResolver) Watch(ctx context.Context) error {
+	watcher, err := dr.newWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, path := range dr.watchPaths() {
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("error watching %s: %w", path, err)
+		}
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case path, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			dr.logDebug("Catalog file changed", "path", path)
+			debounce = time.After(watchDebounce)
+		case err, ok := <-watcher.Errors():
+			if ok {
+				dr.logger().Warn("Watcher error", "err", err)
+			}
+		case <-debounce:
+			debounce = nil
+			err := dr.Reload()
+			if err != nil {
+				dr.logger().Warn("Keeping the previous catalog", "err", err)
+			} else {
+				dr.logger().Info("🔄 Reloaded the catalog", "resources", len(dr.ListResources()))
+			}
+			// Watch directories created since the last reload.
+			for _, path := range dr.watchPaths() {
+				watcher.Add(path)
+			}
+			dr.emit(Event{Type: CatalogReloaded, Err: err})
+		}
+	}
+}
+
+// Reload loads the catalog again from the sources, Store and overlays it was
+// loaded from, listing the Store again when LoadStore loaded it, or else
+// reading the resources loaded from it. The new catalog replaces the current one at once, and the current one
+// is kept when loading fails. When the reloaded catalog has the same resources,
+// only the ones that changed are updated, keeping the cached closures the
+// change does not affect.
+func (dr *DependencyResolver) Reload() error {
+	dr.mu.RLock()
+	fresh := &DependencyResolver{
+		Fs:                   dr.Fs,
+		ResourceDependencies: make(map[string][]string),
+		IncludeOptional:      dr.IncludeOptional,
+		ProviderPreferences:  dr.ProviderPreferences,
+		SelectProvider:       dr.SelectProvider,
+		CacheDir:             dr.CacheDir,
+		Verifications:        dr.Verifications,
+		StrictEnv:            dr.StrictEnv,
+		LookupEnv:            dr.LookupEnv,
+		LoadMode:             dr.LoadMode,
+		IDs:                  dr.IDs,
+	}
+	sources := append([]string(nil), dr.loadedSources...)
+	overlays := append([]string(nil), dr.loadedOverlays...)
+	store, storeListed := dr.Store, dr.storeListed
+	var stored []string
+	for _, entry := range dr.Resources {
+		if storeID, ok := dr.storeIDs[entry.Id]; ok {
+			stored = append(stored, storeID)
+		} else if dr.sources[entry.Id] == storeSource {
+			stored = append(stored, entry.Id)
+		}
+	}
+	dr.mu.RUnlock()
+
+	if len(sources) == 0 && store == nil {
+		return fmt.Errorf("no catalog sources to reload")
+	}
+
+	for _, source := range sources {
+		if err := fresh.LoadSource(source); err != nil {
+			return err
+		}
+	}
+	if store != nil {
+		fresh.UseStore(store)
+		load := func() error { return fresh.LoadStored(stored, false) }
+		if storeListed {
+			load = fresh.LoadStore
+		}
+		if err := load(); err != nil {
+			return err
+		}
+	}
+	for _, overlay := range overlays {
+		if err := fresh.LoadOverlays(overlay); err != nil {
+			return err
+		}
+	}
+	if err := fresh.CheckRequirements(); err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.sources = fresh.sources
+	dr.storeIDs = fresh.storeIDs
+	dr.storeListed = fresh.storeListed
+	dr.loadedSources = fresh.loadedSources
+	dr.loadedOverlays = fresh.loadedOverlays
+	dr.warnings = fresh.warnings
+	if changed, ok := dr.changedResources(fresh.Resources); ok && dr.locked == nil {
+		previous := make(map[int]ResourceNodeEntry, len(changed))
+		for _, i := range changed {
+			previous[i] = dr.Resources[i]
+			dr.Resources[i] = fresh.Resources[i]
+		}
+		dr.updateDependencies(previous)
+		return nil
+	}
+	dr.Resources = fresh.Resources
+	dr.locked = nil
+	dr.buildDependencies()
+	return nil
+}
@@ -0,0 +1,166 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+)
+
+func setupWatchResolver(t *testing.T) *DependencyResolver {
+	resolver := setupTestResolver()
+	resolver.Resources = nil
+	resolver.BuildDependencies()
+	resolver.Logger = NewCharmLogger(log.New(&bytes.Buffer{}))
+	resolver.NewWatcher = func() (Watcher, error) {
+		return NewPollingWatcher(resolver.Fs, 10*time.Millisecond), nil
+	}
+
+	afero.WriteFile(resolver.Fs, "catalog/git.yaml", []byte("id: git\n"), 0644)
+	if err := resolver.LoadSource("catalog"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return resolver
+}
+
+func resourceIds(dr *DependencyResolver) []string {
+	var ids []string
+	for _, entry := range dr.ListResources() {
+		ids = append(ids, entry.Id)
+	}
+	return ids
+}
+
+func TestReload(t *testing.T) {
+	resolver := setupWatchResolver(t)
+	afero.WriteFile(resolver.Fs, "overlays.yaml", []byte("overlays:\n  - id: git\n    name: Git\n"), 0644)
+	if err := resolver.LoadOverlays("overlays.yaml"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	afero.WriteFile(resolver.Fs, "catalog/tag.yaml", []byte("id: tag\nrequires: [git]\n"), 0644)
+	if err := resolver.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"git", "tag"}) {
+		t.Errorf("Expected the new resource to be loaded, got %v", ids)
+	}
+	if deps := resolver.DirectDependencies("tag"); !reflect.DeepEqual(deps, []string{"git"}) {
+		t.Errorf("Expected the dependencies to be rebuilt, got %v", deps)
+	}
+	if entry, _ := resolver.GetResource("git"); entry.Name != "Git" {
+		t.Errorf("Expected the overlays to be applied again, got %+v", entry)
+	}
+
+	afero.WriteFile(resolver.Fs, "catalog/dup.yaml", []byte("id: git\n"), 0644)
+	if err := resolver.Reload(); err == nil {
+		t.Error("Expected an error for a duplicate resource")
+	}
+	if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"git", "tag"}) {
+		t.Errorf("Expected the previous catalog to be kept, got %v", ids)
+	}
+}
+
+func TestReload_NoSources(t *testing.T) {
+	resolver := setupTestResolver()
+	if err := resolver.Reload(); err == nil {
+		t.Error("Expected an error without sources")
+	}
+	if len(resolver.ListResources()) == 0 {
+		t.Error("Expected the catalog to be kept")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	resolver := setupWatchResolver(t)
+
+	reloads := make(chan Event, 10)
+	resolver.OnEvent(func(event Event) {
+		if event.Type == CatalogReloaded {
+			reloads <- event
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- resolver.Watch(ctx) }()
+
+	// Let the watcher take its first scan.
+	time.Sleep(50 * time.Millisecond)
+	afero.WriteFile(resolver.Fs, "catalog/tag.yaml", []byte("id: tag\nrequires: [git]\n"), 0644)
+
+	select {
+	case event := <-reloads:
+		if event.Err != nil {
+			t.Errorf("Unexpected error: %v", event.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the catalog to be reloaded")
+	}
+	if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"git", "tag"}) {
+		t.Errorf("Expected the new resource to be loaded, got %v", ids)
+	}
+
+	afero.WriteFile(resolver.Fs, "catalog/tag.yaml", []byte("resources:\n  - name: Nameless\n"), 0644)
+	select {
+	case event := <-reloads:
+		if event.Err == nil {
+			t.Error("Expected the reload to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the catalog to be reloaded")
+	}
+	if ids := resourceIds(resolver); !reflect.DeepEqual(ids, []string{"git", "tag"}) {
+		t.Errorf("Expected the previous catalog to be kept, got %v", ids)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected Watch to stop with the context, got %v", err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	now := time.Now()
+	before := map[string]fileStamp{
+		"a": {size: 1, modTime: now},
+		"b": {size: 1, modTime: now},
+		"c": {size: 1, modTime: now},
+	}
+	after := map[string]fileStamp{
+		"a": {size: 1, modTime: now},
+		"b": {size: 2, modTime: now},
+		"d": {size: 1, modTime: now},
+	}
+	changed := changedFiles(before, after)
+	if len(changed) != 3 {
+		t.Errorf("Expected b, c and d to change, got %v", changed)
+	}
+}
+
+func TestFSWatcher(t *testing.T) {
+	dir := t.TempDir()
+	watcher, err := NewFSWatcher()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	afero.WriteFile(afero.NewOsFs(), filepath.Join(dir, "git.yaml"), []byte("id: git\n"), 0644)
+	select {
+	case path := <-watcher.Events():
+		if path != filepath.Join(dir, "git.yaml") {
+			t.Errorf("Expected the new file, got %s", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event for the new file")
+	}
+}
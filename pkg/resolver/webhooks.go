@@ -0,0 +1,172 @@
+package resolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook is a URL notified of the progress of runs, such as a Slack or
+// PagerDuty integration.
+type Webhook struct {
+	// URL receives every event as a WebhookPayload in a JSON POST request.
+	URL string
+	// Events lists the types of the events sent to the webhook, by default
+	// RunStarted, ResourceFailed and RunCompleted.
+	Events []EventType
+	// Headers are added to every request, such as an authorization token.
+	Headers map[string]string
+}
+
+// defaultWebhookEvents are the events sent to webhooks without Events.
+var defaultWebhookEvents = []EventType{RunStarted, ResourceFailed, RunCompleted}
+
+// webhookTimeout bounds the delivery of an event to a webhook.
+const webhookTimeout = 10 * time.Second
+
+// webhookQueueSize is the number of events waiting for delivery before
+// sending more blocks the run.
+const webhookQueueSize = 64
+
+// WebhookPayload is the body of the requests sent to webhooks.
+type WebhookPayload struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	// Resource is the resource the event is about, for ResourceFailed.
+	Resource string `json:"resource,omitempty"`
+	// Resources lists the resources of the run, for RunStarted.
+	Resources []string `json:"resources,omitempty"`
+	// Duration is the execution time of the resource, or of the whole run,
+	// in seconds.
+	Duration float64 `json:"duration,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	// Succeeded tells whether every resource succeeded, for RunCompleted.
+	Succeeded bool `json:"succeeded,omitempty"`
+	// Results holds the result of every resource, for RunCompleted.
+	Results []ResourceResult `json:"results,omitempty"`
+}
+
+// ValidateWebhooks returns an error for a webhook without an HTTP(S) URL, or
+// listing an unknown event type.
+func ValidateWebhooks(webhooks []Webhook) error {
+	known := []EventType{RunStarted, ResourceStarted, ResourceFinished, ResourceFailed, ResourceSkipped, RunCompleted}
+	for _, webhook := range webhooks {
+		if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL '%s', expected an http or https URL", webhook.URL)
+		}
+		for _, event := range webhook.Events {
+			if !slices.Contains(known, event) {
+				names := make([]string, len(known))
+				for i, k := range known {
+					names[i] = string(k)
+				}
+				return fmt.Errorf("unknown webhook event '%s', expected one of: %s", event, strings.Join(names, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// wants reports whether the webhook receives events of the given type.
+func (w Webhook) wants(event EventType) bool {
+	if len(w.Events) == 0 {
+		return slices.Contains(defaultWebhookEvents, event)
+	}
+	return slices.Contains(w.Events, event)
+}
+
+func newWebhookPayload(event Event) WebhookPayload {
+	payload := WebhookPayload{
+		Type:      event.Type,
+		Time:      event.Time,
+		Resource:  event.Resource,
+		Resources: event.Resources,
+		Duration:  event.Duration.Seconds(),
+		Results:   event.Results,
+	}
+	if event.Err != nil {
+		payload.Error = event.Err.Error()
+	}
+	if event.Type == RunCompleted {
+		payload.Succeeded = event.Err == nil
+	}
+	return payload
+}
+
+// webhookDelivery is an event on its way to a webhook.
+type webhookDelivery struct {
+	webhook Webhook
+	payload WebhookPayload
+	logger  Logger
+}
+
+// webhookSender delivers events to webhooks from a goroutine of its own, in
+// the order they were sent, so runs don't wait for the webhooks to answer.
+type webhookSender struct {
+	start   sync.Once
+	queue   chan webhookDelivery
+	pending sync.WaitGroup
+	client  *http.Client
+}
+
+// send queues the event for the webhooks receiving its type. Failed
+// deliveries are logged to logger.
+func (s *webhookSender) send(logger Logger, webhooks []Webhook, event Event) {
+	s.start.Do(func() {
+		s.queue = make(chan webhookDelivery, webhookQueueSize)
+		s.client = &http.Client{Timeout: webhookTimeout}
+		go s.deliverAll()
+	})
+
+	for _, webhook := range webhooks {
+		if webhook.wants(event.Type) {
+			s.pending.Add(1)
+			s.queue <- webhookDelivery{webhook: webhook, payload: newWebhookPayload(event), logger: logger}
+		}
+	}
+}
+
+// wait returns once the events sent so far are delivered, or failed to be.
+func (s *webhookSender) wait() {
+	s.pending.Wait()
+}
+
+func (s *webhookSender) deliverAll() {
+	for delivery := range s.queue {
+		if err := s.deliver(delivery); err != nil {
+			delivery.logger.Warn("Webhook delivery failed", "url", delivery.webhook.URL, "event", delivery.payload.Type, "err", err)
+		}
+		s.pending.Done()
+	}
+}
+
+func (s *webhookSender) deliver(delivery webhookDelivery) error {
+	body, err := json.Marshal(delivery.payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range delivery.webhook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
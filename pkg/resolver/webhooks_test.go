@@ -0,0 +1,130 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+// webhookRecorder is a webhook endpoint recording the payloads it receives.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	payloads []WebhookPayload
+	headers  []http.Header
+}
+
+func newWebhookRecorder(t *testing.T, status int) (*webhookRecorder, *httptest.Server) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		recorder.mu.Lock()
+		recorder.payloads = append(recorder.payloads, payload)
+		recorder.headers = append(recorder.headers, r.Header.Clone())
+		recorder.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return recorder, server
+}
+
+func (r *webhookRecorder) types() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var types []string
+	for _, payload := range r.payloads {
+		types = append(types, string(payload.Type))
+	}
+	return types
+}
+
+func TestWebhooks_DefaultEvents(t *testing.T) {
+	recorder, server := newWebhookRecorder(t, http.StatusOK)
+	resolver := setupPolicyResolver(ContinueAll)
+	resolver.Webhooks = []Webhook{{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}}
+
+	resolver.Execute(context.Background(), []string{"all"})
+
+	if got := strings.Join(recorder.types(), ","); got != "RunStarted,ResourceFailed,RunCompleted" {
+		t.Fatalf("Expected the default events, got %s", got)
+	}
+	started, failed, completed := recorder.payloads[0], recorder.payloads[1], recorder.payloads[2]
+	if len(started.Resources) != 4 {
+		t.Errorf("Expected the resources of the run, got %v", started.Resources)
+	}
+	if failed.Resource != "broken" || failed.Error == "" {
+		t.Errorf("Expected the failure of broken, got %+v", failed)
+	}
+	if completed.Succeeded || completed.Error == "" || len(completed.Results) != 4 {
+		t.Errorf("Expected the failed run with its results, got %+v", completed)
+	}
+	if auth := recorder.headers[0].Get("Authorization"); auth != "Bearer token" {
+		t.Errorf("Expected the configured header, got %q", auth)
+	}
+}
+
+func TestWebhooks_Events(t *testing.T) {
+	recorder, server := newWebhookRecorder(t, http.StatusOK)
+	resolver := setupPolicyResolver(ContinueAll)
+	resolver.Webhooks = []Webhook{{URL: server.URL, Events: []EventType{RunCompleted}}}
+
+	resolver.Execute(context.Background(), []string{"docs"})
+
+	if got := strings.Join(recorder.types(), ","); got != "RunCompleted" {
+		t.Fatalf("Expected only RunCompleted, got %s", got)
+	}
+	if !recorder.payloads[0].Succeeded {
+		t.Error("Expected the run to succeed")
+	}
+}
+
+func TestWebhooks_FailedDelivery(t *testing.T) {
+	recorder, server := newWebhookRecorder(t, http.StatusInternalServerError)
+	resolver := setupPolicyResolver(ContinueAll)
+	resolver.Webhooks = []Webhook{{URL: server.URL, Events: []EventType{RunCompleted}}}
+
+	var logs bytes.Buffer
+	resolver.Logger = NewCharmLogger(log.New(&logs))
+
+	if _, err := resolver.Execute(context.Background(), []string{"docs"}); err != nil {
+		t.Errorf("Expected the run to succeed despite the webhook, got %v", err)
+	}
+	if len(recorder.types()) != 1 {
+		t.Errorf("Expected one delivery, got %v", recorder.types())
+	}
+	if !strings.Contains(logs.String(), "Webhook delivery failed") || !strings.Contains(logs.String(), "500 Internal Server Error") {
+		t.Errorf("Expected the failed delivery to be logged, got %q", logs.String())
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		webhook Webhook
+		wantErr string
+	}{
+		{Webhook{URL: "https://hooks.example.com/run"}, ""},
+		{Webhook{URL: "http://localhost:8080", Events: []EventType{ResourceStarted}}, ""},
+		{Webhook{URL: "ftp://example.com"}, "invalid webhook URL 'ftp://example.com'"},
+		{Webhook{URL: "hooks.example.com"}, "invalid webhook URL"},
+		{Webhook{URL: "https://example.com", Events: []EventType{"RunFinished"}}, "unknown webhook event 'RunFinished'"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateWebhooks([]Webhook{tt.webhook})
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("Unexpected error for %+v: %v", tt.webhook, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Expected error %q for %+v, got %v", tt.wantErr, tt.webhook, err)
+		}
+	}
+}
@@ -1,69 +1,207 @@
 package resolver
 
 import (
-	"io/ioutil"
-	"net/http"
-	"strings"
+	"errors"
+	"fmt"
 
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v2"
 )
 
 func (dr *DependencyResolver) LoadResourceEntries(filePath string) error {
-	var data []byte
-	var err error
+	files, err := dr.readManifestFiles(filePath, make(map[string]bool))
+	if err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	sources := dr.resourceSources()
+	entries, warnings, err := dr.addManifests(sources, files)
+	if err != nil {
+		return err
+	}
 
-	// Check if filePath is a URL
-	if strings.HasPrefix(filePath, "http://") || strings.HasPrefix(filePath, "https://") {
-		// Download the file content from the URL
-		resp, err := http.Get(filePath)
+	// Update resource entries and dependencies
+	if err := dr.replaceResources(append(dr.Resources, entries...), sources, dr.lenient()); err != nil {
+		return err
+	}
+	dr.warn(warnings...)
+	return nil
+}
+
+// ReloadResourceEntries replaces the loaded resources with the ones read from
+// the given files. The files are read before the catalog is swapped, so
+// concurrent readers see either the old or the new catalog, and the old one is
+// kept when any file fails to load.
+func (dr *DependencyResolver) ReloadResourceEntries(filePaths ...string) error {
+	var manifests []manifestFile
+	read := make(map[string]bool)
+	for _, filePath := range filePaths {
+		files, err := dr.readManifestFiles(filePath, read)
 		if err != nil {
-			LogErrorExit("Error downloading file from URL "+filePath, err)
+			return err
 		}
-		defer resp.Body.Close()
+		manifests = append(manifests, files...)
+	}
+	sources := make(map[string]string)
+	resources, warnings, err := dr.addManifests(sources, manifests)
+	if err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
 
-		if resp.StatusCode != http.StatusOK {
-			LogErrorExit("Error downloading file from URL, status code: "+resp.Status, err)
+	previousWarnings, previousLocked := dr.warnings, dr.locked
+	dr.warnings, dr.locked = warnings, nil
+	if err := dr.replaceResources(resources, sources, dr.lenient()); err != nil {
+		dr.warnings, dr.locked = previousWarnings, previousLocked
+		dr.buildDependencies()
+		return err
+	}
+	dr.storeIDs = nil
+	dr.loadedSources = append([]string(nil), filePaths...)
+	dr.loadedOverlays = nil
+	return nil
+}
+
+// DuplicateResourceError is returned when a resource ID is defined more than
+// once, with the files defining it.
+type DuplicateResourceError struct {
+	Id           string
+	FirstSource  string
+	SecondSource string
+}
+
+func (e *DuplicateResourceError) Error() string {
+	if e.FirstSource == e.SecondSource {
+		return fmt.Sprintf("resource '%s' is defined twice in %s", e.Id, e.FirstSource)
+	}
+	return fmt.Sprintf("resource '%s' is defined in both %s and %s", e.Id, e.FirstSource, e.SecondSource)
+}
+
+// resourceSources returns a copy of the files defining the loaded resources.
+// Resources added without a file are reported as defined in the catalog.
+func (dr *DependencyResolver) resourceSources() map[string]string {
+	sources := make(map[string]string, len(dr.Resources))
+	for _, entry := range dr.Resources {
+		sources[entry.Id] = "the catalog"
+	}
+	for id, source := range dr.sources {
+		sources[id] = source
+	}
+	return sources
+}
+
+// addSources records the source of every entry, failing on the first ID
+// already present in sources.
+func addSources(sources map[string]string, entries []ResourceNodeEntry, source string) error {
+	for _, entry := range entries {
+		if first, ok := sources[entry.Id]; ok {
+			return &DuplicateResourceError{Id: entry.Id, FirstSource: first, SecondSource: source}
 		}
+		sources[entry.Id] = source
+	}
+	return nil
+}
 
-		data, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			LogErrorExit("Error reading file content from URL "+filePath, err)
+// readResourceEntries reads, validates and parses the resources of a local
+// file or URL, expanding the environment variables they refer to. Unknown
+// fields are warnings in lenient mode.
+func (dr *DependencyResolver) readResourceEntries(filePath string) (manifestFile, error) {
+	manifest := manifestFile{Path: filePath}
+	data, err := dr.readManifest(filePath)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.data = data
+	if err := validateManifest(data, filePath); err != nil {
+		var schemaErrs SchemaErrors
+		if !dr.lenient() || !errors.As(err, &schemaErrs) {
+			return manifest, err
+		}
+		var remaining SchemaErrors
+		for _, schemaErr := range schemaErrs {
+			if schemaErr.unknown {
+				manifest.Warnings = append(manifest.Warnings, Warning{Code: CodeUnknownField, File: schemaErr.File, Line: schemaErr.Line, Column: schemaErr.Column, Message: schemaErr.Message})
+			} else {
+				remaining = append(remaining, schemaErr)
+			}
 		}
-	} else {
-		// Read the file from the filesystem
-		data, err = afero.ReadFile(dr.Fs, filePath)
+		if len(remaining) > 0 {
+			return manifest, remaining
+		}
+	}
+	manifest.Entries, manifest.Includes, err = parseResourceEntries(data, filePath)
+	if err != nil {
+		return manifest, err
+	}
+	if err := dr.interpolateEntries(manifest.Entries, filePath); err != nil {
+		return manifest, err
+	}
+	return manifest, nil
+}
+
+// readManifest returns the content of a local file or URL.
+func (dr *DependencyResolver) readManifest(filePath string) ([]byte, error) {
+	if isURL(filePath) {
+		data, err := dr.fetchURL(filePath)
 		if err != nil {
-			LogErrorExit("Error reading file "+filePath, err)
+			return nil, err
+		}
+		if err := dr.verifyCatalog(filePath, data); err != nil {
+			return nil, err
 		}
+		return data, nil
 	}
 
-	// Unmarshal YAML data
+	// Read the file from the filesystem
+	data, err := afero.ReadFile(dr.Fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+	}
+	return data, nil
+}
+
+// parseResourceEntries parses the resources listed under `resources`, or the
+// single resource defined by a file with a top-level `id`, and the manifests
+// listed under `include`. JSON documents are parsed as YAML.
+func parseResourceEntries(data []byte, filePath string) ([]ResourceNodeEntry, []string, error) {
 	var fileResources struct {
 		Resources []ResourceNodeEntry `yaml:"resources"`
+		Id        string              `yaml:"id"`
+		Include   []string            `yaml:"include"`
 	}
-
 	if err := yaml.Unmarshal(data, &fileResources); err != nil {
-		LogErrorExit("Error unmarshalling YAML data from file "+filePath, err)
+		return nil, nil, fmt.Errorf("error unmarshalling YAML data from file %s: %w", filePath, err)
 	}
 
-	// Update resource entries and dependencies
-	dr.Resources = append(dr.Resources, fileResources.Resources...)
-	for _, entry := range fileResources.Resources {
-		dr.ResourceDependencies[entry.Id] = entry.Requires
+	if fileResources.Resources == nil && fileResources.Id != "" {
+		var entry ResourceNodeEntry
+		if err := yaml.Unmarshal(data, &entry); err != nil {
+			return nil, nil, fmt.Errorf("error unmarshalling YAML data from file %s: %w", filePath, err)
+		}
+		return []ResourceNodeEntry{entry}, fileResources.Include, nil
 	}
-	return nil
+
+	for i, entry := range fileResources.Resources {
+		if entry.Id == "" {
+			return nil, nil, fmt.Errorf("resource %d in %s has no id", i+1, filePath)
+		}
+	}
+	return fileResources.Resources, fileResources.Include, nil
 }
 
 func (dr *DependencyResolver) ShowResourceEntry(res string) error {
-	for _, entry := range dr.Resources {
-		if entry.Id == res {
-			PrintMessage("📦 Id: %s\n📛 Name: %s\n📝 Description: %s\n🏷️  Category: %s\n🔗 Requirements: %v\n",
-				entry.Id, entry.Name, entry.Desc, entry.Category, entry.Requires)
-			return nil
-		}
+	entry, ok := dr.GetResource(res)
+	if !ok {
+		return &NotFoundError{Id: res}
 	}
-	LogErrorExit("Id "+res+" not found", nil)
+
+	PrintMessage("📦 Id: %s\n📛 Name: %s\n📝 Description: %s\n🏷️  Category: %s\n🔗 Requirements: %v\n",
+		styles.id.Render(entry.Id), styles.name.Render(entry.Name), entry.Desc, styles.category.Render(entry.Category), entry.Requires)
 	return nil
 }
 
@@ -71,17 +209,16 @@ func (dr *DependencyResolver) SaveResourceEntries(filePath string) error {
 	data := struct {
 		Resources []ResourceNodeEntry `yaml:"resources"`
 	}{
-		Resources: dr.Resources,
+		Resources: dr.ListResources(),
 	}
 
 	content, err := yaml.Marshal(data)
 	if err != nil {
-		LogErrorExit("Error marshalling YAML", err)
+		return fmt.Errorf("error marshalling YAML: %w", err)
 	}
 
-	err = afero.WriteFile(dr.Fs, filePath, content, 0644)
-	if err != nil {
-		LogErrorExit("Error writing file "+filePath, err)
+	if err := afero.WriteFile(dr.Fs, filePath, content, 0644); err != nil {
+		return fmt.Errorf("error writing file %s: %w", filePath, err)
 	}
 	return nil
 }
@@ -1,8 +1,11 @@
 package resolver
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/charmbracelet/log"
@@ -18,7 +21,7 @@ func TestLoadResourceEntries(t *testing.T) {
 	}
 	defer session.Close()
 
-	dr, err := NewGraphResolver(afero.NewOsFs(), logger, "", session)
+	dr, err := NewGraphResolver(afero.NewOsFs(), NewCharmLogger(logger), "", session)
 	if err != nil {
 		log.Fatalf("Failed to create dependency resolver: %v", err)
 	}
@@ -88,7 +91,7 @@ func TestLoadResourceEntries_CircularDependency(t *testing.T) {
 	}
 	defer session.Close()
 
-	dr, err := NewGraphResolver(afero.NewOsFs(), logger, "", session)
+	dr, err := NewGraphResolver(afero.NewOsFs(), NewCharmLogger(logger), "", session)
 	if err != nil {
 		log.Fatalf("Failed to create dependency resolver: %v", err)
 	}
@@ -143,3 +146,151 @@ resources:
 		}
 	}
 }
+
+func TestLoadResourceEntries_OptionalDependencies(t *testing.T) {
+	logger := log.New(nil)
+	session, err := runnerexec.NewShellSession()
+	if err != nil {
+		logger.Fatalf("Failed to create shell session: %v", err)
+	}
+	defer session.Close()
+
+	memFs := afero.NewMemMapFs()
+	dr, err := NewGraphResolver(memFs, NewCharmLogger(logger), "", session)
+	if err != nil {
+		log.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+
+	yamlData := `
+resources:
+  - id: "a"
+    name: "Id A"
+    requires: []
+    optional:
+      - "b"
+      - "unknown"
+`
+	afero.WriteFile(memFs, "optional.yaml", []byte(yamlData), 0644)
+	afero.WriteFile(memFs, "b.yaml", []byte("resources:\n  - id: \"b\"\n    name: \"Id B\"\n"), 0644)
+
+	dr.LoadResourceEntries("optional.yaml")
+	if deps := dr.ResourceDependencies["a"]; len(deps) != 0 {
+		t.Errorf("Expected no dependencies before 'b' is loaded, got %v", deps)
+	}
+
+	dr.LoadResourceEntries("b.yaml")
+	if deps := dr.ResourceDependencies["a"]; len(deps) != 1 || deps[0] != "b" {
+		t.Errorf("Expected optional dependency 'b', got %v", deps)
+	}
+}
+
+func TestReloadResourceEntries(t *testing.T) {
+	logger := log.New(nil)
+	memFs := afero.NewMemMapFs()
+	dr, err := NewGraphResolver(memFs, NewCharmLogger(logger), "", nil)
+	if err != nil {
+		log.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+
+	afero.WriteFile(memFs, "v1.yaml", []byte("resources:\n  - id: \"a\"\n  - id: \"b\"\n    requires: [\"a\"]\n"), 0644)
+	afero.WriteFile(memFs, "v2.yaml", []byte("resources:\n  - id: \"c\"\n"), 0644)
+
+	if err := dr.ReloadResourceEntries("v1.yaml"); err != nil {
+		t.Fatalf("Failed to reload resources: %v", err)
+	}
+	if err := dr.ReloadResourceEntries("v2.yaml"); err != nil {
+		t.Fatalf("Failed to reload resources: %v", err)
+	}
+
+	if len(dr.Resources) != 1 || dr.Resources[0].Id != "c" {
+		t.Errorf("Expected only resource 'c', got %v", dr.Resources)
+	}
+	if _, exists := dr.ResourceDependencies["b"]; exists {
+		t.Errorf("Expected dependencies of 'b' to be removed")
+	}
+
+	if err := dr.ReloadResourceEntries("missing.yaml"); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+	if len(dr.Resources) != 1 {
+		t.Errorf("Expected the previous catalog to be kept, got %v", dr.Resources)
+	}
+}
+
+func TestReloadResourceEntries_ConcurrentReaders(t *testing.T) {
+	logger := log.New(nil)
+	memFs := afero.NewMemMapFs()
+	dr, err := NewGraphResolver(memFs, NewCharmLogger(logger), "", nil)
+	if err != nil {
+		log.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+
+	afero.WriteFile(memFs, "chain.yaml", []byte("resources:\n  - id: \"a\"\n  - id: \"b\"\n    requires: [\"a\"]\n  - id: \"c\"\n    requires: [\"b\"]\n"), 0644)
+	if err := dr.ReloadResourceEntries("chain.yaml"); err != nil {
+		t.Fatalf("Failed to load resources: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				stack, err := dr.Resolve(context.Background(), []string{"c"})
+				if err != nil || len(stack) != 3 {
+					t.Errorf("Expected a consistent catalog, got %v (%v)", stack, err)
+					return
+				}
+				dr.TransitiveDependencies("c")
+				dr.ListResources()
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := dr.ReloadResourceEntries("chain.yaml"); err != nil {
+			t.Fatalf("Failed to reload resources: %v", err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestLoadResourceEntries_Duplicates(t *testing.T) {
+	logger := log.New(nil)
+	memFs := afero.NewMemMapFs()
+	dr, err := NewGraphResolver(memFs, NewCharmLogger(logger), "", nil)
+	if err != nil {
+		log.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+
+	afero.WriteFile(memFs, "first.yaml", []byte("resources:\n  - id: \"a\"\n  - id: \"b\"\n"), 0644)
+	afero.WriteFile(memFs, "second.yaml", []byte("resources:\n  - id: \"c\"\n  - id: \"a\"\n"), 0644)
+	afero.WriteFile(memFs, "twice.yaml", []byte("resources:\n  - id: \"d\"\n  - id: \"d\"\n"), 0644)
+
+	if err := dr.LoadResourceEntries("first.yaml"); err != nil {
+		t.Fatalf("Failed to load resources: %v", err)
+	}
+
+	err = dr.LoadResourceEntries("second.yaml")
+	var duplicate *DuplicateResourceError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("Expected a DuplicateResourceError, got %v", err)
+	}
+	expected := DuplicateResourceError{Id: "a", FirstSource: "first.yaml", SecondSource: "second.yaml"}
+	if *duplicate != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *duplicate)
+	}
+	if len(dr.Resources) != 2 {
+		t.Errorf("Expected the catalog to be unchanged, got %v", dr.Resources)
+	}
+
+	err = dr.ReloadResourceEntries("twice.yaml")
+	if !errors.As(err, &duplicate) || err.Error() != "resource 'd' is defined twice in twice.yaml" {
+		t.Errorf("Expected a duplicate within a file, got %v", err)
+	}
+
+	err = dr.ReloadResourceEntries("first.yaml", "second.yaml")
+	if !errors.As(err, &duplicate) || err.Error() != "resource 'a' is defined in both first.yaml and second.yaml" {
+		t.Errorf("Expected a duplicate across files, got %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+// Package resolverpb holds the protobuf messages and gRPC stubs of the
+// resolver service, generated from resolver.proto.
+package resolverpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative resolver.proto
@@ -0,0 +1,169 @@
+// Package rpc serves the resolver over gRPC, following the service defined in
+// resolverpb/resolver.proto.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/jjuliano/runner/pkg/resolver"
+	pb "github.com/jjuliano/runner/pkg/rpc/resolverpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type server struct {
+	pb.UnimplementedResolverServer
+	dr      *resolver.DependencyResolver
+	options Options
+}
+
+// Options are the settings of the gRPC API.
+type Options struct {
+	// AllowExecute serves ExecutePlan, which runs the steps of the resources
+	// as shell commands for any client reaching the server, so the server
+	// should authenticate its clients, such as with mutual TLS. ExecutePlan
+	// fails with PermissionDenied unless it is set.
+	AllowExecute bool
+}
+
+// NewServer returns a gRPC server exposing the resolver's dependency graph,
+// and its executor when options allow it. Queries are served from a snapshot
+// of the catalog, like the REST API. Pass grpc.Creds in opts to serve over
+// TLS, and to require client certificates.
+func NewServer(dr *resolver.DependencyResolver, options Options, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	pb.RegisterResolverServer(srv, &server{dr: dr, options: options})
+	return srv
+}
+
+// ListenAndServe serves the gRPC API for the resolver on the given address.
+// It returns the error of listening, before serving, or of serving.
+func ListenAndServe(addr string, dr *resolver.DependencyResolver, options Options, opts ...grpc.ServerOption) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	dr.LoggerOrDefault().Info("Serving gRPC", "addr", addr)
+	return NewServer(dr, options, opts...).Serve(lis)
+}
+
+func (s *server) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	if len(req.Targets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no targets given")
+	}
+	order, err := s.dr.Resolve(ctx, s.dr.IDs.IDs(req.Targets))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ResolveResponse{Order: order}, nil
+}
+
+func (s *server) GetResource(ctx context.Context, req *pb.GetResourceRequest) (*pb.Resource, error) {
+	entry, err := lookup(s.dr.Snapshot(), s.dr.IDs.ID(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	return toResource(entry), nil
+}
+
+func (s *server) ListDependents(ctx context.Context, req *pb.ListDependentsRequest) (*pb.ListDependentsResponse, error) {
+	if req.Depth < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid depth '%d'", req.Depth)
+	}
+	snapshot := s.dr.Snapshot()
+	entry, err := lookup(snapshot, s.dr.IDs.ID(req.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if req.Transitive {
+		ids = snapshot.DependentsWithin(entry.Id, int(req.Depth))
+	} else {
+		ids = snapshot.ReverseDependencies(entry.Id)
+	}
+	return &pb.ListDependentsResponse{Ids: ids}, nil
+}
+
+func (s *server) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.ValidateResponse, error) {
+	snapshot := s.dr.Snapshot()
+	resp := &pb.ValidateResponse{Resources: int32(len(snapshot.Resources()))}
+	for _, w := range snapshot.Warnings() {
+		resp.Warnings = append(resp.Warnings, &pb.Warning{
+			Code:     string(w.Code),
+			Resource: w.Resource,
+			File:     w.File,
+			Line:     int32(w.Line),
+			Column:   int32(w.Column),
+			Message:  w.Message,
+		})
+	}
+	return resp, nil
+}
+
+// ExecutePlan runs the targets until the run completes or the call is
+// cancelled. Failed resources are reported in the results rather than as an
+// error, which is kept for runs that could not start, or are not allowed.
+func (s *server) ExecutePlan(ctx context.Context, req *pb.ExecutePlanRequest) (*pb.ExecutePlanResponse, error) {
+	if !s.options.AllowExecute {
+		return nil, status.Error(codes.PermissionDenied, "executing plans is disabled on this server")
+	}
+	if len(req.Targets) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no targets given")
+	}
+	results, err := s.dr.Execute(ctx, s.dr.IDs.IDs(req.Targets))
+	if results == nil && err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &pb.ExecutePlanResponse{Succeeded: err == nil}
+	for _, result := range results {
+		resp.Results = append(resp.Results, &pb.ResourceResult{
+			Id:     result.Id,
+			Status: string(result.Status),
+			Error:  result.Error,
+		})
+	}
+	return resp, nil
+}
+
+func lookup(snapshot *resolver.Snapshot, id string) (resolver.ResourceNodeEntry, error) {
+	entry, ok := snapshot.Resource(id)
+	if !ok {
+		return entry, status.Errorf(codes.NotFound, "resource '%s' not found", id)
+	}
+	return entry, nil
+}
+
+// statusError maps an error of the resolver to a gRPC status. Cancelled and
+// expired calls keep their code, and the other errors come from the state of
+// the catalog.
+func statusError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
+func toResource(entry resolver.ResourceNodeEntry) *pb.Resource {
+	return &pb.Resource{
+		Id:         entry.Id,
+		Aliases:    entry.Aliases,
+		Name:       entry.Name,
+		Version:    entry.Version,
+		Desc:       entry.Desc,
+		Category:   entry.Category,
+		License:    entry.License,
+		Owners:     entry.Owners,
+		Metadata:   entry.Metadata,
+		Deprecated: entry.Deprecated,
+		ReplacedBy: entry.ReplacedBy,
+		Requires:   entry.Requires,
+		Optional:   entry.Optional,
+		Conflicts:  entry.Conflicts,
+		Provides:   entry.Provides,
+	}
+}
@@ -0,0 +1,184 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	pb "github.com/jjuliano/runner/pkg/rpc/resolverpb"
+	"github.com/jjuliano/runner/pkg/runnerexec"
+	"github.com/spf13/afero"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupTestClient(t *testing.T) pb.ResolverClient {
+	return setupTestClientWith(t, Options{AllowExecute: true})
+}
+
+func setupTestClientWith(t *testing.T, options Options) pb.ResolverClient {
+	session, err := runnerexec.NewShellSession()
+	if err != nil {
+		t.Fatalf("Failed to create shell session: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", session)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.Quiet = true
+	dr.Resources = []resolver.ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs", Run: []resolver.RunStep{{Name: "install", Exec: "true"}}},
+		{Id: "tag", Name: "Tag", Requires: []string{"git"}, Run: []resolver.RunStep{{Name: "tag", Exec: "exit 1"}}},
+		{Id: "organize", Name: "Organize", Requires: []string{"tag"}},
+	}
+	dr.BuildDependencies()
+	return dialServer(t, dr, options)
+}
+
+// dialServer serves the resolver and returns a client connected to it.
+func dialServer(t *testing.T, dr *resolver.DependencyResolver, options Options) pb.ResolverClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(dr, options)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewResolverClient(conn)
+}
+
+func TestResolve(t *testing.T) {
+	client := setupTestClient(t)
+
+	resp, err := client.Resolve(context.Background(), &pb.ResolveRequest{Targets: []string{"organize"}})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if strings.Join(resp.Order, ",") != "git,tag,organize" {
+		t.Errorf("Expected order [git tag organize], got %v", resp.Order)
+	}
+
+	_, err = client.Resolve(context.Background(), &pb.ResolveRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without targets, got %v", err)
+	}
+}
+
+func TestGetResource(t *testing.T) {
+	client := setupTestClient(t)
+
+	resource, err := client.GetResource(context.Background(), &pb.GetResourceRequest{Id: "tag"})
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	if resource.Name != "Tag" || strings.Join(resource.Requires, ",") != "git" {
+		t.Errorf("Unexpected resource: %v", resource)
+	}
+
+	_, err = client.GetResource(context.Background(), &pb.GetResourceRequest{Id: "unknown"})
+	if status.Code(err) != codes.NotFound || status.Convert(err).Message() != "resource 'unknown' not found" {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestGetResource_CaseInsensitive(t *testing.T) {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.IDs.CaseInsensitive = true
+	dr.AddResourceEntry(resolver.ResourceNodeEntry{Id: "DB"})
+	dr.AddResourceEntry(resolver.ResourceNodeEntry{Id: "App", Requires: []string{"DB"}})
+	client := dialServer(t, dr, Options{})
+
+	resource, err := client.GetResource(context.Background(), &pb.GetResourceRequest{Id: "DB"})
+	if err != nil || resource.Id != "db" {
+		t.Errorf("Expected the resource under its normalized ID, got %v, %v", resource, err)
+	}
+	resp, err := client.Resolve(context.Background(), &pb.ResolveRequest{Targets: []string{"APP"}})
+	if err != nil || strings.Join(resp.GetOrder(), ",") != "db,app" {
+		t.Errorf("Expected order [db app], got %v, %v", resp.GetOrder(), err)
+	}
+}
+
+func TestListDependents(t *testing.T) {
+	client := setupTestClient(t)
+
+	resp, err := client.ListDependents(context.Background(), &pb.ListDependentsRequest{Id: "git"})
+	if err != nil {
+		t.Fatalf("ListDependents failed: %v", err)
+	}
+	if strings.Join(resp.Ids, ",") != "tag" {
+		t.Errorf("Expected dependents [tag], got %v", resp.Ids)
+	}
+
+	resp, err = client.ListDependents(context.Background(), &pb.ListDependentsRequest{Id: "git", Transitive: true})
+	if err != nil {
+		t.Fatalf("ListDependents failed: %v", err)
+	}
+	if strings.Join(resp.Ids, ",") != "tag,organize" {
+		t.Errorf("Expected dependents [tag organize], got %v", resp.Ids)
+	}
+
+	_, err = client.ListDependents(context.Background(), &pb.ListDependentsRequest{Id: "git", Transitive: true, Depth: -1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a negative depth, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	client := setupTestClient(t)
+
+	resp, err := client.Validate(context.Background(), &pb.ValidateRequest{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if resp.Resources != 3 || len(resp.Warnings) != 0 {
+		t.Errorf("Expected 3 resources without warnings, got %v", resp)
+	}
+}
+
+func TestExecutePlan(t *testing.T) {
+	client := setupTestClient(t)
+
+	resp, err := client.ExecutePlan(context.Background(), &pb.ExecutePlanRequest{Targets: []string{"organize"}})
+	if err != nil {
+		t.Fatalf("ExecutePlan failed: %v", err)
+	}
+	if resp.Succeeded {
+		t.Error("Expected the run to fail")
+	}
+
+	var got []string
+	for _, result := range resp.Results {
+		got = append(got, result.Id+"="+result.Status)
+	}
+	if strings.Join(got, ",") != "git=succeeded,tag=failed,organize=skipped" {
+		t.Errorf("Unexpected results: %v", got)
+	}
+	if resp.Results[1].Error == "" {
+		t.Error("Expected the error of the failed resource")
+	}
+}
+
+func TestExecutePlan_NotAllowed(t *testing.T) {
+	client := setupTestClientWith(t, Options{})
+
+	_, err := client.ExecutePlan(context.Background(), &pb.ExecutePlanRequest{Targets: []string{"git"}})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied, got %v", err)
+	}
+}
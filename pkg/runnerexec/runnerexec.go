@@ -2,6 +2,7 @@ package runnerexec
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -9,8 +10,12 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
+// waitDelay bounds how long a cancelled command may keep its output open.
+const waitDelay = 500 * time.Millisecond
+
 // CommandResult holds the output, exit code, and error of a command execution.
 type CommandResult struct {
 	Output   string
@@ -62,6 +67,11 @@ func (s *ShellSession) Close() error {
 
 // ExecuteCommand runs a shell command and returns its output, exit code, and error if any.
 func (s *ShellSession) ExecuteCommand(execCmd string) <-chan CommandResult {
+	return s.ExecuteCommandContext(context.Background(), execCmd)
+}
+
+// ExecuteCommandContext is like ExecuteCommand but kills the command once ctx is done.
+func (s *ShellSession) ExecuteCommandContext(ctx context.Context, execCmd string) <-chan CommandResult {
 	resultChan := make(chan CommandResult)
 
 	go func() {
@@ -70,9 +80,11 @@ func (s *ShellSession) ExecuteCommand(execCmd string) <-chan CommandResult {
 		var outbuf, errbuf bytes.Buffer
 
 		// Use a new command to execute the input command within the session
-		cmd := exec.Command("sh", "-c", execCmd)
+		cmd := exec.CommandContext(ctx, "sh", "-c", execCmd)
 		cmd.Stdout = &outbuf
 		cmd.Stderr = &errbuf
+		// Don't wait for children of the shell holding the output open once killed.
+		cmd.WaitDelay = waitDelay
 
 		err := cmd.Run()
 		output := outbuf.String() + errbuf.String()
@@ -82,6 +94,9 @@ func (s *ShellSession) ExecuteCommand(execCmd string) <-chan CommandResult {
 			if exitError, ok := err.(*exec.ExitError); ok {
 				exitCode = exitError.ExitCode()
 			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
 		}
 
 		resultChan <- CommandResult{Output: output, ExitCode: exitCode, Err: err}
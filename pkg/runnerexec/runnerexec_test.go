@@ -1,9 +1,12 @@
 package runnerexec
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestExecuteCommand(t *testing.T) {
@@ -72,3 +75,23 @@ func TestExecuteCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestExecuteCommandContext_Cancelled(t *testing.T) {
+	session, err := NewShellSession()
+	if err != nil {
+		t.Fatalf("Failed to create shell session: %v", err)
+	}
+	defer session.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := <-session.ExecuteCommandContext(ctx, "sleep 5")
+	if !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", result.Err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the command to be killed, took %s", elapsed)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	server := httptest.NewServer(NewDebugHandler())
+	defer server.Close()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap?debug=1", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestMetrics_Runtime(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, name := range []string{"go_goroutines ", "go_memstats_heap_alloc_bytes ", "process_uptime_seconds "} {
+		if !strings.Contains(string(body), "\n"+name) {
+			t.Errorf("Expected %s in the metrics, got:\n%s", name, body)
+		}
+	}
+}
+
+func TestDebugHandler_NotOnAPI(t *testing.T) {
+	server := setupTestServer(t)
+	recorder := httptest.NewRecorder()
+	server.Config.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/pprof/", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected the profiles not to be served with the API, got status %d", recorder.Code)
+	}
+}
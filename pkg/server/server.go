@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jjuliano/runner/pkg/metrics"
+	"github.com/jjuliano/runner/pkg/resolver"
+)
+
+type server struct {
+	dr *resolver.DependencyResolver
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns an http.Handler exposing the resolver's resources and
+// dependency graph as a read-only REST API. Every request is served from a
+// snapshot of the catalog, so reloading it never mixes two versions in a
+// response. Its metrics include the Go runtime statistics of the server.
+func NewHandler(dr *resolver.DependencyResolver) http.Handler {
+	s := &server{dr: dr}
+	metrics.Default.RegisterRuntime()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /resources", s.listResources)
+	mux.HandleFunc("GET /resources/{id}", s.getResource)
+	mux.HandleFunc("GET /resources/{id}/deps", s.dependencies)
+	mux.HandleFunc("GET /resources/{id}/rdeps", s.reverseDependencies)
+	mux.HandleFunc("GET /graph.dot", s.graphDOT)
+	mux.HandleFunc("GET /graph.graphml", s.graphML)
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+	return mux
+}
+
+// ListenAndServe serves the REST API for the resolver on the given address.
+func ListenAndServe(addr string, dr *resolver.DependencyResolver) error {
+	return Serve(addr, NewHandler(dr))
+}
+
+// Serve serves the given handler on the given address, such as the REST API
+// combined with other APIs.
+func Serve(addr string, handler http.Handler) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	resolver.LogInfo("Serving resources", "addr", addr)
+	return ServeListener(lis, handler)
+}
+
+// ServeListener serves the given handler on the listener, for callers
+// listening first to report the errors of listening before serving.
+func ServeListener(lis net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.Serve(lis)
+}
+
+// listResources lists the resources. Pass limit and offset to list a page of
+// them; the X-Total-Count header holds the number of resources.
+func (s *server) listResources(w http.ResponseWriter, r *http.Request) {
+	page, ok := s.queryPage(w, r)
+	if !ok {
+		return
+	}
+	resources, total := s.dr.Snapshot().ResourcesPage(page)
+	if resources == nil {
+		resources = []resolver.ResourceNodeEntry{}
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	s.writeJSON(w, http.StatusOK, resources)
+}
+
+func (s *server) getResource(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.lookup(w, r, s.dr.Snapshot())
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, entry)
+}
+
+// dependencies lists the requirements of a resource. Pass transitive=true to
+// list its whole closure instead, limited to depth levels when depth is set.
+func (s *server) dependencies(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.dr.Snapshot()
+	entry, ok := s.lookup(w, r, snapshot)
+	if !ok {
+		return
+	}
+	depth, ok := s.queryDepth(w, r)
+	if !ok {
+		return
+	}
+
+	var deps []string
+	if r.URL.Query().Get("transitive") == "true" {
+		deps = snapshot.DependenciesWithin(entry.Id, depth)
+	} else {
+		deps = snapshot.DirectDependencies(entry.Id)
+	}
+	s.writeJSON(w, http.StatusOK, nonNil(deps))
+}
+
+// reverseDependencies lists the resources requiring a resource. Pass
+// transitive=true to list every resource that transitively requires it,
+// limited to depth levels when depth is set.
+func (s *server) reverseDependencies(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.dr.Snapshot()
+	entry, ok := s.lookup(w, r, snapshot)
+	if !ok {
+		return
+	}
+	depth, ok := s.queryDepth(w, r)
+	if !ok {
+		return
+	}
+
+	var deps []string
+	if r.URL.Query().Get("transitive") == "true" {
+		deps = snapshot.DependentsWithin(entry.Id, depth)
+	} else {
+		deps = snapshot.ReverseDependencies(entry.Id)
+	}
+	s.writeJSON(w, http.StatusOK, nonNil(deps))
+}
+
+func (s *server) graphDOT(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/vnd.graphviz")
+	if err := s.dr.Snapshot().WriteDOT(w); err != nil {
+		s.dr.LoggerOrDefault().Error("Failed to write DOT graph", "err", err)
+	}
+}
+
+func (s *server) graphML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/graphml+xml")
+	if err := s.dr.Snapshot().ExportGraphML(w); err != nil {
+		s.dr.LoggerOrDefault().Error("Failed to write GraphML graph", "err", err)
+	}
+}
+
+// lookup returns the resource named by the id path value, normalized by the
+// resolver's ID policy, writing a not found response when it is missing.
+func (s *server) lookup(w http.ResponseWriter, r *http.Request, snapshot *resolver.Snapshot) (resolver.ResourceNodeEntry, bool) {
+	id := s.dr.IDs.ID(r.PathValue("id"))
+	entry, ok := snapshot.Resource(id)
+	if !ok {
+		s.writeJSON(w, http.StatusNotFound, errorResponse{Error: "resource '" + id + "' not found"})
+	}
+	return entry, ok
+}
+
+// queryDepth parses the depth query parameter, writing a bad request response
+// when it isn't a non-negative integer. A missing depth is 0, for no limit.
+func (s *server) queryDepth(w http.ResponseWriter, r *http.Request) (int, bool) {
+	return s.queryInt(w, r, "depth")
+}
+
+// queryPage parses the limit and offset query parameters like queryDepth. A
+// missing limit is 0, for no limit.
+func (s *server) queryPage(w http.ResponseWriter, r *http.Request) (resolver.Page, bool) {
+	limit, ok := s.queryInt(w, r, "limit")
+	if !ok {
+		return resolver.Page{}, false
+	}
+	offset, ok := s.queryInt(w, r, "offset")
+	return resolver.Page{Offset: offset, Limit: limit}, ok
+}
+
+// queryInt parses a non-negative integer query parameter, 0 when missing,
+// writing a bad request response when it is invalid.
+func (s *server) queryInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid " + name + " '" + value + "'"})
+		return 0, false
+	}
+	return n, true
+}
+
+func (s *server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.dr.LoggerOrDefault().Error("Failed to encode response", "err", err)
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+)
+
+func setupTestServer(t *testing.T) *httptest.Server {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.Resources = []resolver.ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs"},
+		{Id: "tag", Name: "Tag", Requires: []string{"git"}},
+		{Id: "organize", Name: "Organize", Requires: []string{"tag"}},
+	}
+	dr.BuildDependencies()
+
+	server := httptest.NewServer(NewHandler(dr))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode response from %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestListResources(t *testing.T) {
+	server := setupTestServer(t)
+
+	var resources []resolver.ResourceNodeEntry
+	if status := getJSON(t, server.URL+"/resources", &resources); status != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", status)
+	}
+	if len(resources) != 3 || resources[0].Id != "git" || resources[0].Category != "vcs" {
+		t.Errorf("Unexpected resources: %+v", resources)
+	}
+}
+
+func TestListResources_Page(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp, err := http.Get(server.URL + "/resources?limit=1&offset=1")
+	if err != nil {
+		t.Fatalf("Failed to GET /resources: %v", err)
+	}
+	defer resp.Body.Close()
+	var resources []resolver.ResourceNodeEntry
+	if err := json.NewDecoder(resp.Body).Decode(&resources); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Id != "tag" {
+		t.Errorf("Expected the second resource, got %+v", resources)
+	}
+	if total := resp.Header.Get("X-Total-Count"); total != "3" {
+		t.Errorf("Expected a total of 3 resources, got %q", total)
+	}
+
+	if status := getJSON(t, server.URL+"/resources?offset=5", &resources); status != http.StatusOK || len(resources) != 0 {
+		t.Errorf("Expected no resource past the end, got %d: %+v", status, resources)
+	}
+	var errResp errorResponse
+	if status := getJSON(t, server.URL+"/resources?limit=-1", &errResp); status != http.StatusBadRequest || errResp.Error != "invalid limit '-1'" {
+		t.Errorf("Expected a bad request, got %d: %+v", status, errResp)
+	}
+}
+
+func TestGetResource(t *testing.T) {
+	server := setupTestServer(t)
+
+	var entry resolver.ResourceNodeEntry
+	if status := getJSON(t, server.URL+"/resources/tag", &entry); status != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", status)
+	}
+	if entry.Name != "Tag" || strings.Join(entry.Requires, ",") != "git" {
+		t.Errorf("Unexpected resource: %+v", entry)
+	}
+
+	var errResp errorResponse
+	if status := getJSON(t, server.URL+"/resources/unknown", &errResp); status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", status)
+	}
+	if errResp.Error != "resource 'unknown' not found" {
+		t.Errorf("Unexpected error: %s", errResp.Error)
+	}
+}
+
+func TestGetResource_CaseInsensitive(t *testing.T) {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create dependency resolver: %v", err)
+	}
+	dr.IDs.CaseInsensitive = true
+	if err := dr.AddResourceEntry(resolver.ResourceNodeEntry{Id: "DB", Name: "Database"}); err != nil {
+		t.Fatalf("Failed to add resource: %v", err)
+	}
+	server := httptest.NewServer(NewHandler(dr))
+	t.Cleanup(server.Close)
+
+	var entry resolver.ResourceNodeEntry
+	if status := getJSON(t, server.URL+"/resources/DB", &entry); status != http.StatusOK || entry.Id != "db" {
+		t.Errorf("Expected the resource under its normalized ID, got %d: %+v", status, entry)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	server := setupTestServer(t)
+
+	var deps []string
+	getJSON(t, server.URL+"/resources/organize/deps", &deps)
+	if strings.Join(deps, ",") != "tag" {
+		t.Errorf("Expected dependencies [tag], got %v", deps)
+	}
+
+	getJSON(t, server.URL+"/resources/organize/deps?transitive=true", &deps)
+	if strings.Join(deps, ",") != "tag,git" {
+		t.Errorf("Expected dependencies [tag git], got %v", deps)
+	}
+
+	getJSON(t, server.URL+"/resources/git/deps", &deps)
+	if deps == nil || len(deps) != 0 {
+		t.Errorf("Expected an empty list, got %v", deps)
+	}
+
+	deps = nil
+	getJSON(t, server.URL+"/resources/organize/deps?transitive=true&depth=1", &deps)
+	if strings.Join(deps, ",") != "tag" {
+		t.Errorf("Expected dependencies [tag], got %v", deps)
+	}
+
+	var errResp errorResponse
+	if status := getJSON(t, server.URL+"/resources/organize/deps?transitive=true&depth=x", &errResp); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", status)
+	}
+}
+
+func TestReverseDependencies(t *testing.T) {
+	server := setupTestServer(t)
+
+	var deps []string
+	getJSON(t, server.URL+"/resources/git/rdeps", &deps)
+	if strings.Join(deps, ",") != "tag" {
+		t.Errorf("Expected reverse dependencies [tag], got %v", deps)
+	}
+
+	getJSON(t, server.URL+"/resources/git/rdeps?transitive=true", &deps)
+	if strings.Join(deps, ",") != "tag,organize" {
+		t.Errorf("Expected reverse dependencies [tag organize], got %v", deps)
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp, err := http.Get(server.URL + "/graph.dot")
+	if err != nil {
+		t.Fatalf("Failed to GET graph: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read graph: %v", err)
+	}
+	if !strings.Contains(string(body), `"organize" -> "tag";`) {
+		t.Errorf("Expected an edge from organize to tag, got:\n%s", body)
+	}
+}
+
+func TestGraphML(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp, err := http.Get(server.URL + "/graph.graphml")
+	if err != nil {
+		t.Fatalf("Failed to GET graph: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read graph: %v", err)
+	}
+	if !strings.Contains(string(body), `<edge source="organize" target="tag"></edge>`) {
+		t.Errorf("Expected an edge from organize to tag, got:\n%s", body)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to GET metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+	if !strings.Contains(string(body), "runner_graph_resources 3\n") {
+		t.Errorf("Expected the graph size metric, got:\n%s", body)
+	}
+}
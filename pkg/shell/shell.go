@@ -0,0 +1,263 @@
+// Package shell reads commands at an interactive prompt, with line editing,
+// history and completion when reading from a terminal.
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+)
+
+// Shell runs the commands read at its prompt until the input ends, or the
+// user enters exit or quit.
+type Shell struct {
+	Prompt string
+	In     io.Reader
+	Out    io.Writer
+	// Exec runs the words of a command line.
+	Exec func(words []string) error
+	// Complete returns the completions of toComplete, the last word of the
+	// line being edited, following words. It may be nil.
+	Complete func(words []string, toComplete string) []string
+
+	history []string
+}
+
+// Run reads and runs commands until the input ends, or the user quits. The
+// errors of the commands are printed, and don't end the shell.
+func (s *Shell) Run() error {
+	read := s.scanLine()
+	if f, ok := s.In.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		read = s.editLine
+	}
+	for {
+		line, err := read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		words, err := Split(line)
+		if err != nil {
+			fmt.Fprintf(s.Out, "❌ %v\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "exit" || words[0] == "quit" {
+			return nil
+		}
+		if len(s.history) == 0 || s.history[len(s.history)-1] != line {
+			s.history = append(s.history, line)
+		}
+		if err := s.Exec(words); err != nil {
+			fmt.Fprintf(s.Out, "❌ %v\n", err)
+		}
+	}
+}
+
+// scanLine returns a reader of the lines of the input, without prompting,
+// for scripts piped to the shell.
+func (s *Shell) scanLine() func() (string, error) {
+	scanner := bufio.NewScanner(s.In)
+	return func() (string, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return scanner.Text(), nil
+	}
+}
+
+// editLine prompts for a line on the terminal.
+func (s *Shell) editLine() (string, error) {
+	m, err := tea.NewProgram(newLineModel(s.Prompt, s.history, s.Complete), tea.WithInput(s.In), tea.WithOutput(s.Out)).Run()
+	if err != nil {
+		return "", err
+	}
+	line := m.(lineModel)
+	if line.eof {
+		fmt.Fprintln(s.Out)
+		return "", io.EOF
+	}
+	return line.input.Value(), nil
+}
+
+// lineModel is the bubbletea model editing a command line. Up and down
+// recall the history, tab completes the last word, ctrl+c clears the line
+// and ctrl+d on an empty line ends the input.
+type lineModel struct {
+	input    textinput.Model
+	history  []string
+	index    int
+	complete func(words []string, toComplete string) []string
+	// choices are the completions listed after an ambiguous tab.
+	choices []string
+	done    bool
+	eof     bool
+}
+
+func newLineModel(prompt string, history []string, complete func([]string, string) []string) lineModel {
+	input := textinput.New()
+	input.Prompt = prompt
+	input.Focus()
+	return lineModel{input: input, history: history, index: len(history), complete: complete}
+}
+
+// Init implements tea.Model.
+func (m lineModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model.
+func (m lineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		m.choices = nil
+		switch key.Type {
+		case tea.KeyEnter:
+			m.done = true
+			return m, tea.Quit
+		case tea.KeyCtrlC:
+			m.input.SetValue("")
+			m.done = true
+			return m, tea.Quit
+		case tea.KeyCtrlD:
+			if m.input.Value() == "" {
+				m.done, m.eof = true, true
+				return m, tea.Quit
+			}
+		case tea.KeyUp:
+			if m.index > 0 {
+				m.index--
+				m.input.SetValue(m.history[m.index])
+				m.input.CursorEnd()
+			}
+			return m, nil
+		case tea.KeyDown:
+			if m.index < len(m.history) {
+				m.index++
+				m.input.SetValue("")
+				if m.index < len(m.history) {
+					m.input.SetValue(m.history[m.index])
+				}
+				m.input.CursorEnd()
+			}
+			return m, nil
+		case tea.KeyTab:
+			m.completeWord()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// completeWord completes the last word of the line: entirely when a single
+// completion matches, else up to the prefix the completions share, listing
+// them when there is none to add.
+func (m *lineModel) completeWord() {
+	if m.complete == nil {
+		return
+	}
+	value := m.input.Value()
+	words, err := Split(value)
+	if err != nil {
+		return
+	}
+	toComplete := ""
+	if len(words) > 0 && !strings.HasSuffix(value, " ") {
+		toComplete, words = words[len(words)-1], words[:len(words)-1]
+	}
+	completions := m.complete(words, toComplete)
+	if len(completions) == 0 {
+		return
+	}
+	prefix := completions[0]
+	for _, completion := range completions[1:] {
+		for !strings.HasPrefix(completion, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(completions) == 1 && !strings.HasSuffix(prefix, "=") && !strings.HasSuffix(prefix, ",") {
+		prefix += " "
+	}
+	if prefix == toComplete {
+		m.choices = completions
+		return
+	}
+	m.input.SetValue(strings.TrimSuffix(value, toComplete) + prefix)
+	m.input.CursorEnd()
+}
+
+// View implements tea.Model.
+func (m lineModel) View() string {
+	if m.done {
+		// Keep the line entered above the output of the command.
+		if m.eof {
+			return m.input.Prompt
+		}
+		return m.input.Prompt + m.input.Value() + "\n"
+	}
+	view := m.input.View()
+	if len(m.choices) > 0 {
+		view += "\n" + strings.Join(m.choices, "  ")
+	}
+	return view
+}
+
+// Split splits a command line into words separated by spaces. Single and
+// double quotes group words with spaces, and a backslash escapes the next
+// character outside single quotes.
+func Split(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		word.WriteRune('\\')
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
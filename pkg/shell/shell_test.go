@@ -0,0 +1,112 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"", nil},
+		{"  tree   z depth=3 ", []string{"tree", "z", "depth=3"}},
+		{`query "category == \"example\""`, []string{"query", `category == "example"`}},
+		{`show 'a b' c\ d ""`, []string{"show", "a b", "c d", ""}},
+		{`echo 'a\b'`, []string{"echo", `a\b`}},
+	}
+	for _, test := range tests {
+		words, err := Split(test.line)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.line, err)
+		} else if !reflect.DeepEqual(words, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.line, test.expected, words)
+		}
+	}
+	if _, err := Split(`show "a`); err == nil {
+		t.Error("Expected an unterminated quote to fail")
+	}
+}
+
+func TestShellRun(t *testing.T) {
+	var out bytes.Buffer
+	var run [][]string
+	s := &Shell{
+		In:  strings.NewReader("show b\n\n  tree z depth=3\nfail 'x\nfail\nexit\nshow c\n"),
+		Out: &out,
+		Exec: func(words []string) error {
+			run = append(run, words)
+			if words[0] == "fail" {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		},
+	}
+	if err := s.Run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{{"show", "b"}, {"tree", "z", "depth=3"}, {"fail"}}
+	if !reflect.DeepEqual(run, expected) {
+		t.Errorf("Expected the commands up to exit to run, got %q", run)
+	}
+	if got := out.String(); got != "❌ unterminated ' quote\n❌ failed\n" {
+		t.Errorf("Expected the errors to be printed, got %q", got)
+	}
+}
+
+func press(m tea.Model, keys ...tea.KeyMsg) lineModel {
+	for _, key := range keys {
+		m, _ = m.Update(key)
+	}
+	return m.(lineModel)
+}
+
+func typed(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+func TestLineModel(t *testing.T) {
+	complete := func(words []string, toComplete string) []string {
+		var completions []string
+		for _, id := range []string{"zlib", "zookeeper", "app"} {
+			if strings.HasPrefix(id, toComplete) && len(words) > 0 {
+				completions = append(completions, id)
+			}
+		}
+		return completions
+	}
+	m := press(newLineModel("> ", []string{"show b", "rdeps m"}, complete), typed("tree z"), tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.input.Value(); got != "tree z" || !strings.Contains(m.View(), "zlib  zookeeper") {
+		t.Errorf("Expected the completions to be listed, got %q and %q", got, m.View())
+	}
+	m = press(m, typed("l"), tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.input.Value(); got != "tree zlib " || strings.Contains(m.View(), "zookeeper") {
+		t.Errorf("Expected the single completion to be entered, got %q", got)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.input.Value(); got != "show b" {
+		t.Errorf("Expected the first line of the history, got %q", got)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown})
+	if got := m.input.Value(); got != "" {
+		t.Errorf("Expected an empty line past the history, got %q", got)
+	}
+
+	m = press(m, typed("rdeps a"), tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.done || m.eof || m.View() != "> rdeps a\n" {
+		t.Errorf("Expected the line to be entered, got %q", m.View())
+	}
+	if m = press(newLineModel("> ", nil, nil), tea.KeyMsg{Type: tea.KeyCtrlD}); !m.eof {
+		t.Error("Expected ctrl+d to end the input")
+	}
+	if m = press(newLineModel("> ", nil, nil), typed("x"), tea.KeyMsg{Type: tea.KeyCtrlC}); !m.done || m.input.Value() != "" {
+		t.Errorf("Expected ctrl+c to clear the line, got %q", m.input.Value())
+	}
+}
@@ -0,0 +1,303 @@
+// Package sqlitestore persists a resolved catalog in a SQLite database, so
+// catalogs with hundreds of thousands of resources can be queried without
+// loading them into memory.
+package sqlitestore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jjuliano/runner/pkg/resolver"
+	"gopkg.in/yaml.v2"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS resources (
+	id       TEXT PRIMARY KEY,
+	position INTEGER NOT NULL,
+	category TEXT NOT NULL DEFAULT '',
+	entry    BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS resources_position ON resources (position);
+CREATE INDEX IF NOT EXISTS resources_category ON resources (category);
+CREATE TABLE IF NOT EXISTS dependencies (
+	resource   TEXT NOT NULL,
+	dependency TEXT NOT NULL,
+	position   INTEGER NOT NULL,
+	PRIMARY KEY (resource, position)
+);
+CREATE INDEX IF NOT EXISTS dependencies_dependency ON dependencies (dependency);
+CREATE TABLE IF NOT EXISTS requirements (
+	resource    TEXT NOT NULL,
+	requirement TEXT NOT NULL,
+	PRIMARY KEY (resource, requirement)
+);
+CREATE INDEX IF NOT EXISTS requirements_requirement ON requirements (requirement);
+`
+
+// Store is a catalog stored in a SQLite database, and a
+// resolver.ResourceStore: resources are kept in catalog order, with the
+// dependencies they were resolved to when imported, or their declared
+// requirements when put. The queries of resolver.Catalog are answered from
+// the database, and return their errors.
+type Store struct {
+	db *sql.DB
+}
+
+var _ resolver.ResourceStore = (*Store)(nil)
+
+// Open opens the SQLite database at path, creating it when missing.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening catalog database %s: %w", path, err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating catalog database %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Import replaces the stored catalog with the given one, in a single
+// transaction.
+func (s *Store) Import(catalog resolver.Catalog) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM resources; DELETE FROM dependencies; DELETE FROM requirements;`); err != nil {
+		return fmt.Errorf("error clearing the catalog: %w", err)
+	}
+	insertResource, err := tx.Prepare(`INSERT INTO resources (id, position, category, entry) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertResource.Close()
+	insertDependency, err := tx.Prepare(`INSERT INTO dependencies (resource, dependency, position) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertDependency.Close()
+	insertRequirement, err := tx.Prepare(`INSERT OR IGNORE INTO requirements (resource, requirement) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertRequirement.Close()
+
+	for i, entry := range catalog.ListResources() {
+		data, err := yaml.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error encoding resource '%s': %w", entry.Id, err)
+		}
+		if _, err := insertResource.Exec(entry.Id, i, entry.Category, data); err != nil {
+			return fmt.Errorf("error storing resource '%s': %w", entry.Id, err)
+		}
+		for j, dep := range catalog.DirectDependencies(entry.Id) {
+			if _, err := insertDependency.Exec(entry.Id, dep, j); err != nil {
+				return fmt.Errorf("error storing the dependencies of '%s': %w", entry.Id, err)
+			}
+		}
+		for _, name := range append(append([]string(nil), entry.Requires...), entry.Optional...) {
+			if _, err := insertRequirement.Exec(entry.Id, name); err != nil {
+				return fmt.Errorf("error storing the requirements of '%s': %w", entry.Id, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Put stores the resource, replacing the stored resource with the same ID. A
+// new resource is added at the end of the catalog. Its dependencies are its
+// declared requirements, followed by its optional ones, as only the resolver
+// knows which of them are resolved.
+func (s *Store) Put(entry resolver.ResourceNodeEntry) error {
+	dependencies := append(append([]string(nil), entry.Requires...), entry.Optional...)
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding resource '%s': %w", entry.Id, err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO resources (id, position, category, entry)
+		VALUES (?, (SELECT COALESCE(MAX(position), -1) + 1 FROM resources), ?, ?)
+		ON CONFLICT (id) DO UPDATE SET category = excluded.category, entry = excluded.entry`,
+		entry.Id, entry.Category, data)
+	if err != nil {
+		return fmt.Errorf("error storing resource '%s': %w", entry.Id, err)
+	}
+	for _, table := range []string{"dependencies", "requirements"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE resource = ?`, entry.Id); err != nil {
+			return fmt.Errorf("error storing the dependencies of '%s': %w", entry.Id, err)
+		}
+	}
+	for i, dep := range dependencies {
+		if _, err := tx.Exec(`INSERT INTO dependencies (resource, dependency, position) VALUES (?, ?, ?)`, entry.Id, dep, i); err != nil {
+			return fmt.Errorf("error storing the dependencies of '%s': %w", entry.Id, err)
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO requirements (resource, requirement) VALUES (?, ?)`, entry.Id, dep); err != nil {
+			return fmt.Errorf("error storing the requirements of '%s': %w", entry.Id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete removes the resource and its dependencies. Requirements of other
+// resources on it are kept.
+func (s *Store) Delete(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM resources WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("error deleting resource '%s': %w", id, err)
+	}
+	for _, table := range []string{"dependencies", "requirements"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE resource = ?`, id); err != nil {
+			return fmt.Errorf("error deleting resource '%s': %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Get returns the stored resource with the given ID.
+func (s *Store) Get(id string) (resolver.ResourceNodeEntry, bool, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT entry FROM resources WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return resolver.ResourceNodeEntry{}, false, nil
+	}
+	var entry resolver.ResourceNodeEntry
+	if err == nil {
+		err = yaml.Unmarshal(data, &entry)
+	}
+	if err != nil {
+		return resolver.ResourceNodeEntry{}, false, fmt.Errorf("error reading resource '%s': %w", id, err)
+	}
+	return entry, true, nil
+}
+
+// List returns every stored resource, in catalog order.
+func (s *Store) List() ([]resolver.ResourceNodeEntry, error) {
+	return s.entries(`SELECT entry FROM resources ORDER BY position`)
+}
+
+// ByCategory returns the stored resources of the category, in catalog order.
+func (s *Store) ByCategory(category string) ([]resolver.ResourceNodeEntry, error) {
+	return s.entries(`SELECT entry FROM resources WHERE category = ? ORDER BY position`, category)
+}
+
+// Dependents returns the stored resources declaring a requirement, mandatory
+// or optional, on the given name, in catalog order. Unlike
+// ReverseDependencies, it does not follow the providers the catalog was
+// resolved to when imported.
+func (s *Store) Dependents(id string) ([]string, error) {
+	return s.ids(`SELECT q.resource FROM requirements q JOIN resources r ON r.id = q.resource
+		WHERE q.requirement = ? ORDER BY r.position`, id)
+}
+
+// DirectDependencies returns the stored dependencies of the resource.
+func (s *Store) DirectDependencies(id string) ([]string, error) {
+	return s.ids(`SELECT dependency FROM dependencies WHERE resource = ? ORDER BY position`, id)
+}
+
+// ReverseDependencies returns the stored resources directly requiring the
+// resource, in catalog order.
+func (s *Store) ReverseDependencies(id string) ([]string, error) {
+	return s.ids(`SELECT d.resource FROM dependencies d JOIN resources r ON r.id = d.resource
+		WHERE d.dependency = ? ORDER BY r.position`, id)
+}
+
+// TransitiveDependencies returns the closure of the resource, excluding
+// itself, in breadth-first order.
+func (s *Store) TransitiveDependencies(id string) ([]string, error) {
+	return s.closure(id, s.DirectDependencies)
+}
+
+// TransitiveDependents returns the resources transitively requiring the
+// resource, in breadth-first order.
+func (s *Store) TransitiveDependents(id string) ([]string, error) {
+	return s.closure(id, s.ReverseDependencies)
+}
+
+// closure walks the edges from id breadth-first, querying the edges of one
+// resource at a time so only the visited IDs are held in memory.
+func (s *Store) closure(id string, edges func(string) ([]string, error)) ([]string, error) {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	var closure []string
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		deps, err := edges(node)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deps {
+			if !seen[dep] {
+				seen[dep] = true
+				closure = append(closure, dep)
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return closure, nil
+}
+
+// entries returns the resources selected by the query.
+func (s *Store) entries(query string, args ...interface{}) ([]resolver.ResourceNodeEntry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []resolver.ResourceNodeEntry
+	for rows.Next() {
+		var data []byte
+		var entry resolver.ResourceNodeEntry
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("error decoding a stored resource: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// ids returns the IDs selected by the query.
+func (s *Store) ids(query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,183 @@
+package sqlitestore
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/jjuliano/runner/pkg/resolver"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+func setupStore(t *testing.T) (*Store, *resolver.DependencyResolver) {
+	dr, err := resolver.NewGraphResolver(afero.NewMemMapFs(), resolver.NewCharmLogger(log.New(nil)), "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	dr.Resources = []resolver.ResourceNodeEntry{
+		{Id: "git", Name: "Git", Category: "vcs"},
+		{Id: "go", Name: "Go", Category: "language", Provides: []string{"compiler"}},
+		{Id: "tag", Requires: []string{"git"}, Timeout: time.Minute, Run: []resolver.RunStep{{Name: "tag", Exec: "git tag"}}},
+		{Id: "app", Requires: []string{"tag", "compiler"}},
+		{Id: "docs", Requires: []string{"git"}},
+	}
+	dr.BuildDependencies()
+
+	store, err := Open(filepath.Join(t.TempDir(), "catalog.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Import(dr); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return store, dr
+}
+
+func TestStore_MatchesResolver(t *testing.T) {
+	store, dr := setupStore(t)
+
+	// Entries are stored as YAML, which does not tell nil and empty lists
+	// apart, so they are compared in that form.
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stored, _ := yaml.Marshal(entries)
+	expected, _ := yaml.Marshal(dr.ListResources())
+	if string(stored) != string(expected) {
+		t.Errorf("Expected the stored resources to match, got %s", stored)
+	}
+	queries := []struct {
+		name     string
+		store    func(string) ([]string, error)
+		resolver func(string) []string
+	}{
+		{"DirectDependencies", store.DirectDependencies, dr.DirectDependencies},
+		{"ReverseDependencies", store.ReverseDependencies, dr.ReverseDependencies},
+		{"TransitiveDependencies", store.TransitiveDependencies, dr.TransitiveDependencies},
+		{"TransitiveDependents", store.TransitiveDependents, dr.TransitiveDependents},
+	}
+	for _, id := range []string{"git", "go", "tag", "app", "docs", "missing"} {
+		for _, query := range queries {
+			got, err := query.store(id)
+			if err != nil {
+				t.Errorf("%s(%s): unexpected error: %v", query.name, id, err)
+			}
+			if expected := query.resolver(id); !reflect.DeepEqual(got, expected) {
+				t.Errorf("%s(%s): expected %v, got %v", query.name, id, expected, got)
+			}
+		}
+	}
+
+	entry, ok, err := store.Get("tag")
+	if err != nil || !ok || entry.Timeout != time.Minute || len(entry.Run) != 1 || entry.Run[0].Exec != "git tag" {
+		t.Errorf("Expected the whole entry to be stored, got %+v, %v", entry, err)
+	}
+}
+
+func TestStore_Errors(t *testing.T) {
+	store, _ := setupStore(t)
+	store.Close()
+
+	if _, _, err := store.Get("git"); err == nil {
+		t.Error("Expected the error of the query")
+	}
+	if _, err := store.TransitiveDependents("git"); err == nil {
+		t.Error("Expected the error of the query")
+	}
+	if _, err := store.Dependents("git"); err == nil {
+		t.Error("Expected the error of the query")
+	}
+}
+
+func TestStore_PutDelete(t *testing.T) {
+	store, _ := setupStore(t)
+
+	if err := store.Put(resolver.ResourceNodeEntry{Id: "git", Name: "Git SCM"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Put(resolver.ResourceNodeEntry{Id: "release", Requires: []string{"app"}, Optional: []string{"docs"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries, _ := store.List()
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+	if expected := []string{"git", "go", "tag", "app", "docs", "release"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected replaced resources to keep their position, got %v", ids)
+	}
+	if entry, _, _ := store.Get("git"); entry.Name != "Git SCM" {
+		t.Errorf("Expected the resource to be replaced, got %+v", entry)
+	}
+	if dependents, _ := store.TransitiveDependents("git"); !reflect.DeepEqual(dependents, []string{"tag", "docs", "app", "release"}) {
+		t.Errorf("Expected the new resource to be a dependent, got %v", dependents)
+	}
+	if deps, _ := store.DirectDependencies("release"); !reflect.DeepEqual(deps, []string{"app", "docs"}) {
+		t.Errorf("Expected the declared requirements to be stored, got %v", deps)
+	}
+
+	if err := store.Delete("tag"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok, _ := store.Get("tag"); ok {
+		t.Error("Expected the resource to be deleted")
+	}
+	if deps, _ := store.DirectDependencies("app"); !reflect.DeepEqual(deps, []string{"tag", "go"}) {
+		t.Errorf("Expected the requirements on the deleted resource to be kept, got %v", deps)
+	}
+	if dependents, _ := store.ReverseDependencies("git"); !reflect.DeepEqual(dependents, []string{"docs"}) {
+		t.Errorf("Expected the dependencies of the deleted resource to be removed, got %v", dependents)
+	}
+}
+
+func TestStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.Put(resolver.ResourceNodeEntry{Id: "git"})
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+	if _, ok, _ := store.Get("git"); !ok {
+		t.Error("Expected the catalog to persist")
+	}
+}
+
+func TestStore_ResourceStore(t *testing.T) {
+	store, _ := setupStore(t)
+
+	if entry, ok, err := store.Get("go"); err != nil || !ok || entry.Name != "Go" {
+		t.Errorf("Expected the resource, got %+v, %v, %v", entry, ok, err)
+	}
+	if _, ok, err := store.Get("missing"); err != nil || ok {
+		t.Errorf("Expected a missing resource not to be found, got %v, %v", ok, err)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 5 {
+		t.Errorf("Expected 5 resources, got %d, %v", len(entries), err)
+	}
+	if entries, err := store.ByCategory("vcs"); err != nil || len(entries) != 1 || entries[0].Id != "git" {
+		t.Errorf("Expected the vcs resources, got %+v, %v", entries, err)
+	}
+	if dependents, err := store.Dependents("git"); err != nil || !reflect.DeepEqual(dependents, []string{"tag", "docs"}) {
+		t.Errorf("Expected the dependents of git, got %v, %v", dependents, err)
+	}
+	// Dependents are the declared requirements, not the resolved providers.
+	if dependents, err := store.Dependents("compiler"); err != nil || !reflect.DeepEqual(dependents, []string{"app"}) {
+		t.Errorf("Expected the dependents of compiler, got %v, %v", dependents, err)
+	}
+	if dependents, err := store.Dependents("go"); err != nil || len(dependents) != 0 {
+		t.Errorf("Expected go to have no declared dependents, got %v, %v", dependents, err)
+	}
+}